@@ -1,5 +1,10 @@
 package babel
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 //babel api request for text to text conversion
 type BabelTextRequest struct {
 	Text       []string `json:"text" validate:"required"`
@@ -17,3 +22,26 @@ type BabelResponse struct {
 	Translations []string `json:"translations" validate:"required"`
 	Code         string
 }
+
+//babel translation of one requested text
+type BabelTranslation struct {
+	DetectedSourceLanguage string `json:"detected_source_language"`
+	Text                   string `json:"text"`
+}
+
+// Texts translated texts of the response body, in the order of the requested texts
+func (r BabelResponse) Texts() ([]string, error) {
+	texts := make([]string, 0)
+	for _, body := range r.Translations {
+		var decoded struct {
+			Translations []BabelTranslation `json:"translations"`
+		}
+		if err := json.Unmarshal([]byte(body), &decoded); err != nil {
+			return nil, fmt.Errorf("error unmarshaling babel response: %w", err)
+		}
+		for _, translation := range decoded.Translations {
+			texts = append(texts, translation.Text)
+		}
+	}
+	return texts, nil
+}
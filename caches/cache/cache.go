@@ -19,6 +19,7 @@ type Cache interface {
 	GetHash(ctx context.Context, key string, field string) (string, error)
 	InvalidateHash(ctx context.Context, key string) error
 	Keys(ctx context.Context, pattern string) ([]string, error)
+	Scan(ctx context.Context, pattern string) ([]string, error)
 	SetJSON(ctx context.Context, key string, value interface{}) error
 	GetJSON(ctx context.Context, key string) (result interface{}, err error)
 }
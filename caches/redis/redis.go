@@ -17,6 +17,9 @@ const (
 
 	// EmptyCache ...
 	EmptyCache = "redis: nil"
+
+	// ScanCount keys asked for per SCAN batch
+	ScanCount = 500
 )
 
 type redisContainer struct {
@@ -123,6 +126,17 @@ func (me *redisContainer) Keys(ctx context.Context, pattern string) ([]string, e
 	return me.client.Keys(ctx, pattern).Result()
 }
 
+// Scan retrieves all keys matching the given pattern a batch at a time, unlike Keys it does not
+// block redis while the keyspace is walked
+func (me *redisContainer) Scan(ctx context.Context, pattern string) ([]string, error) {
+	keys := make([]string, 0)
+	iter := me.client.Scan(ctx, 0, pattern, ScanCount).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
 // SetJSON stores a JSON object under a specific key in Redis
 func (me *redisContainer) SetJSON(ctx context.Context, key string, value interface{}) error {
 	// Serialize the value to JSON
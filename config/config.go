@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"swallow-supplier/utils/constant"
 	"swallow-supplier/utils/gcp/secret_manager"
 
 	"github.com/go-kit/log"
@@ -22,6 +25,8 @@ type AppConfig struct {
 	AppServiceName      string `envconfig:"APP_SERVICE_NAME"`
 	AuthScopeConfigPath string `envconfig:"AUTH_SCOPE_DEF_PATH"`
 	AuthorizationKey    string `envconfig:"AUTHORIZATION_KEY"`
+	// AuthLegacyKeyEnabled "false" stops accepting AUTHORIZATION_KEY once every caller has its own api client
+	AuthLegacyKeyEnabled string `envconfig:"AUTH_LEGACY_KEY_ENABLED"`
 
 	// postgres
 
@@ -51,6 +56,14 @@ type AppConfig struct {
 	CircuitBreakerRequests     string `envconfig:"CIRCUIT_BREAKER_REQUESTS"`
 	CircuitBreakerFailureRatio string `envconfig:"CIRCUIT_BREAKER_FAILURE_RATIO"`
 
+	// per service circuit breaker, e.g. CIRCUIT_BREAKER_YANOLJA_REQUESTS, empty values fall back to the global ones
+	CircuitBreakerYanolja     CircuitBreakerConfig `envconfig:"CIRCUIT_BREAKER_YANOLJA"`
+	CircuitBreakerTravolution CircuitBreakerConfig `envconfig:"CIRCUIT_BREAKER_TRAVOLUTION"`
+	CircuitBreakerTrip        CircuitBreakerConfig `envconfig:"CIRCUIT_BREAKER_TRIP"`
+	CircuitBreakerBabel       CircuitBreakerConfig `envconfig:"CIRCUIT_BREAKER_BABEL"`
+	CircuitBreakerPdfVoucher  CircuitBreakerConfig `envconfig:"CIRCUIT_BREAKER_PDF_VOUCHER"`
+	CircuitBreakerOdoo        CircuitBreakerConfig `envconfig:"CIRCUIT_BREAKER_ODOO"`
+
 	//GGT
 	ChannelCode string `envconfig:"CHANNEL_CODE"`
 
@@ -67,6 +80,13 @@ type AppConfig struct {
 	TravolutionAuthorizationKey string `envconfig:"TRAVOLUTION_AUTHORIZATION_KEY"`
 	TravolutionDomain           string `envconfig:"TRAVOLUTION_DOMAIN"`
 
+	// callback signatures, HMAC secrets shared with each supplier
+	YanoljaWebhookSecret     string `envconfig:"YANOLJA_WEBHOOK_SECRET"`
+	TravolutionWebhookSecret string `envconfig:"TRAVOLUTION_WEBHOOK_SECRET"`
+	// WebhookSignatureMode "enforce" rejects failed verifications, any other value only records them
+	// for suppliers without a signing secret. A configured secret is always enforced
+	WebhookSignatureMode string `envconfig:"WEBHOOK_SIGNATURE_MODE"`
+
 	//distributor Config
 	//Trip
 	Trip           string `envconfig:"TRIP"`
@@ -75,14 +95,47 @@ type AppConfig struct {
 	TripAdminToken string `envconfig:"TRIP_ADMIN_TOKEN"`
 	TripSyncUrl    string `envconfig:"TRIP_SYNC_URL"`
 	PdfVoucherUrl  string `envconfig:"PDF_VOUCHER"`
+
+	// pre orders trip does not pay within the hold window of their supplier are canceled, e.g.
+	// HOLD_WINDOW_MINUTES=Yanolja:30. Suppliers without a window use the default one
+	HoldWindowMinutes map[string]int `envconfig:"HOLD_WINDOW_MINUTES"`
+
+	// PartialCancelEnabled lets orders be canceled per variant at suppliers supporting it, none of
+	// the current suppliers do so it stays off
+	PartialCancelEnabled bool `envconfig:"PARTIAL_CANCEL_ENABLED"`
+
+	// TripEnvelopeMode "encrypted" requires the encrypted envelope from trip, any other value accepts unencrypted requests.
+	// Encrypted mode refuses every request until trip's sign scheme is agreed
+	TripEnvelopeMode string `envconfig:"TRIP_ENVELOPE_MODE"`
+	TripAesKey       string `envconfig:"TRIP_AES_KEY"`
+	TripSignKey      string `envconfig:"TRIP_SIGN_KEY"`
+
+	// Odoo, orders and products are pushed over json-rpc once ODOO_URL is set
+	OdooUrl       string `envconfig:"ODOO_URL"`
+	OdooDB        string `envconfig:"ODOO_DB"`
+	OdooLogin     string `envconfig:"ODOO_LOGIN"`
+	OdooApiKey    string `envconfig:"ODOO_API_KEY"`
+	OdooPartnerId int64  `envconfig:"ODOO_PARTNER_ID"`
+
 	//Excel
 	YGTFilePath     string `envconfig:"YGT_FILE_PATH"`
 	CleanedDataPath string `envconfig:"CLEANED_DATA_PATH"`
 
+	//FX rates, json file imported into fx_rates on schedule
+	FxRateFilePath string `envconfig:"FX_RATE_FILE_PATH"`
+
 	//SCHEDULE
 	Schedule int `envconfig:"SCHEDULE"`
 }
 
+// CircuitBreakerConfig circuit breaker settings of one upstream service
+type CircuitBreakerConfig struct {
+	Requests     string `envconfig:"REQUESTS"`
+	FailureRatio string `envconfig:"FAILURE_RATIO"`
+	Interval     string `envconfig:"INTERVAL"`
+	Timeout      string `envconfig:"TIMEOUT"`
+}
+
 var (
 	instance *AppConfig
 	logger   log.Logger
@@ -122,12 +175,20 @@ func Init() {
 			cfg.YanoljaApiKey, _ = secretManager.FetchSecret(ctx, "YANOLJA_API_KEY_PROD")
 			cfg.AuthorizationKey, _ = secretManager.FetchSecret(ctx, "YANOLJA_AUTHORIZATION_KEY_PROD")
 			cfg.DatabaseConnectionMongo, _ = secretManager.FetchSecret(ctx, "MONGO_DB_URI_PROD")
+			cfg.YanoljaWebhookSecret, _ = secretManager.FetchSecret(ctx, "YANOLJA_WEBHOOK_SECRET_PROD")
+			cfg.TravolutionWebhookSecret, _ = secretManager.FetchSecret(ctx, "TRAVOLUTION_WEBHOOK_SECRET_PROD")
+			cfg.TripAesKey, cfg.TripSignKey = fetchTripKeys(ctx, logger, secretManager, "PROD")
+			cfg.OdooApiKey, _ = secretManager.FetchSecret(ctx, "ODOO_API_KEY_PROD")
 			level.Info(logger).Log("msg", "Running in PRODUCTION mode, using system environment variables and secrets")
 
 		} else if appEnv == "DEVELOPMENT" {
 			cfg.YanoljaApiKey, _ = secretManager.FetchSecret(ctx, "YANOLJA_API_KEY_DEV")
 			cfg.AuthorizationKey, _ = secretManager.FetchSecret(ctx, "YANOLJA_AUTHORIZATION_KEY_DEV")
 			cfg.DatabaseConnectionMongo, _ = secretManager.FetchSecret(ctx, "MONGO_DB_URI_DEV")
+			cfg.YanoljaWebhookSecret, _ = secretManager.FetchSecret(ctx, "YANOLJA_WEBHOOK_SECRET_DEV")
+			cfg.TravolutionWebhookSecret, _ = secretManager.FetchSecret(ctx, "TRAVOLUTION_WEBHOOK_SECRET_DEV")
+			cfg.TripAesKey, cfg.TripSignKey = fetchTripKeys(ctx, logger, secretManager, "DEV")
+			cfg.OdooApiKey, _ = secretManager.FetchSecret(ctx, "ODOO_API_KEY_DEV")
 
 			level.Info(logger).Log("msg", "Running in DEVELOPMENT mode, using system environment variables and secrets")
 		}
@@ -143,6 +204,20 @@ func Init() {
 	level.Info(logger).Log("msg", "Configuration initialized successfully")
 }
 
+// fetchTripKeys envelope keys of trip for the environment. A key which can not be fetched is
+// left empty and logged, the envelope refuses to open with an empty key
+func fetchTripKeys(ctx context.Context, logger log.Logger, secretManager secret_manager.SecretManager, env string) (aesKey string, signKey string) {
+	aesKey, err := secretManager.FetchSecret(ctx, "TRIP_AES_KEY_"+env)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to fetch trip aes key", "env", env, "err", err)
+	}
+	signKey, err = secretManager.FetchSecret(ctx, "TRIP_SIGN_KEY_"+env)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to fetch trip sign key", "env", env, "err", err)
+	}
+	return aesKey, signKey
+}
+
 // Instance returns the singleton configuration instance
 func Instance() *AppConfig {
 	if instance == nil {
@@ -150,3 +225,58 @@ func Instance() *AppConfig {
 	}
 	return instance
 }
+
+// SetInstance replace the configuration instance, used by tests which run without .env
+func SetInstance(cfg *AppConfig) {
+	instance = cfg
+}
+
+// CircuitBreaker settings of the service, values not configured for the service fall back to the global ones
+func (c *AppConfig) CircuitBreaker(serviceName string) CircuitBreakerConfig {
+	var cb CircuitBreakerConfig
+	switch serviceName {
+	case "Yanolja":
+		cb = c.CircuitBreakerYanolja
+	case "Travolution":
+		cb = c.CircuitBreakerTravolution
+	case "Trip":
+		cb = c.CircuitBreakerTrip
+	case "Babel":
+		cb = c.CircuitBreakerBabel
+	case "Voucher_Pdf_Generator":
+		cb = c.CircuitBreakerPdfVoucher
+	case "Odoo":
+		cb = c.CircuitBreakerOdoo
+	}
+
+	if cb.Requests == "" {
+		cb.Requests = c.CircuitBreakerRequests
+	}
+	if cb.FailureRatio == "" {
+		cb.FailureRatio = c.CircuitBreakerFailureRatio
+	}
+
+	return cb
+}
+
+// WebhookSecret callback signing secret of the supplier, empty when none is configured
+func (c *AppConfig) WebhookSecret(supplier string) string {
+	switch supplier {
+	case "Yanolja":
+		return c.YanoljaWebhookSecret
+	case "Travolution":
+		return c.TravolutionWebhookSecret
+	}
+	return ""
+}
+
+// HoldWindow time a pre order of the supplier is held for trip's payment, the supplier is matched
+// case insensitive
+func (c *AppConfig) HoldWindow(supplier string) time.Duration {
+	for name, minutes := range c.HoldWindowMinutes {
+		if strings.EqualFold(name, supplier) && minutes > 0 {
+			return time.Duration(minutes) * time.Minute
+		}
+	}
+	return constant.DefaultHoldMinutes * time.Minute
+}
@@ -32,6 +32,10 @@ const (
 	CtxRemoteAddr HeaderLabelKey = "remote_addr"
 	// CtxAubAuthorization represents Authorization label
 	CtxAuthorization HeaderLabelKey = "Authorization"
+	// CtxLabelClientID represents client_id label of the authenticated api client
+	CtxLabelClientID HeaderLabelKey = "Client_id"
+	// CtxLabelClientName represents client_name label of the authenticated api client
+	CtxLabelClientName HeaderLabelKey = "Client_name"
 )
 
 // RequestIDHeaderExtractor is a go-kit before handler that extracts X-Request-ID.
@@ -68,6 +72,17 @@ func GGTAuthorizationExtractor(ctx context.Context, r *http.Request) context.Con
 	return ctxWithHeader(ctx, CtxAuthorization, r.Header.Get(string(Authorization)))
 }
 
+// CtxWithClient puts the api client resolved by the auth middleware in the context, its channel
+// code is used when the caller did not send one
+func CtxWithClient(ctx context.Context, id, name, channelCode string) context.Context {
+	ctx = ctxWithHeader(ctx, CtxLabelClientID, id)
+	ctx = ctxWithHeader(ctx, CtxLabelClientName, name)
+	if channelCode != "" && GetCtxHeader(ctx, CtxLabelChannelCode) == "" {
+		ctx = ctxWithHeader(ctx, CtxLabelChannelCode, channelCode)
+	}
+	return ctx
+}
+
 // GetCtxHeader returns the header from context
 func GetCtxHeader(ctx context.Context, label HeaderLabelKey) string {
 	v := ctx.Value(label)
@@ -52,6 +52,7 @@ var (
 	ErrBadRequest                   = errors.New("Invalid request parameters")
 	ErrTokenNotProvided             = errors.New("Token is omitted")
 	ErrInvalidtoken                 = errors.New("Invalid Token")
+	ErrInvalidSignature             = errors.New("access denied due to invalid request signature")
 )
 
 // AllErrors list of all errors
@@ -250,6 +251,10 @@ var AllErrors = errorDetails{
 		"leisure-api-00025",
 		http.StatusGatewayTimeout,
 		"Client Gateway Timeout",
+	}, {
+		"leisure-api-00027",
+		http.StatusConflict,
+		"Sold out for the requested date",
 	}, {
 		// When product version is not greater than existing one
 		"leisure-api-0026",
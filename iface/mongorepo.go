@@ -2,15 +2,25 @@ package iface
 
 import (
 	"context"
+	"swallow-supplier/mongo/domain/apiclient"
+	"swallow-supplier/mongo/domain/job"
+	"swallow-supplier/mongo/domain/money"
 	"swallow-supplier/mongo/domain/odoo"
+	"swallow-supplier/mongo/domain/pricing"
+	"swallow-supplier/mongo/domain/reconciliation"
+	"swallow-supplier/mongo/domain/refund"
+	"swallow-supplier/mongo/domain/translation"
 	travolution_domain "swallow-supplier/mongo/domain/travolution"
 	trip_domain "swallow-supplier/mongo/domain/trip"
+	"swallow-supplier/mongo/domain/webhook"
 	"swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/request_response/common"
 	"swallow-supplier/request_response/heartbeat"
 	"swallow-supplier/request_response/travolution"
 	"swallow-supplier/request_response/trip"
 	req_resp "swallow-supplier/request_response/yanolja"
+	"swallow-supplier/utils/pagination"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -48,7 +58,7 @@ type MongoRepository interface {
 	GetOrderbyPartnerOrderId(ctx context.Context, partnerOrderId string) (record yanolja.Model, err error)
 
 	// UpdateOrderByOrderId
-	UpdateOrderByOrderId(ctx context.Context, orderid int64, update map[string]any) (id string, err error)
+	UpdateOrderByOrderId(ctx context.Context, orderid int64, update map[string]any, transitions ...yanolja.StatusTransition) (id string, err error)
 
 	// DeleteOrderByOrderId
 	DeleteOrderByOrderId(ctx context.Context, orderid int64) (id string, err error)
@@ -63,7 +73,7 @@ type MongoRepository interface {
 	GetTotalQuantityPurchasedByPersonToday(ctx context.Context, name, email, tel string, productId int64, variantId int64) (totalQuantity int32, err error)
 
 	// FindCategory
-	FindCategory(ctx context.Context) (record []yanolja.Category, err error)
+	FindCategory(ctx context.Context, q pagination.Query) (record []yanolja.Category, total int64, next string, err error)
 
 	//FindCategoryByCategoryId
 	FindCategoryByCategoryId(ctx context.Context, categoryId int64) (record yanolja.Category, err error)
@@ -78,7 +88,7 @@ type MongoRepository interface {
 	UpdateRegionsByRegionId(ctx context.Context, regiondId int64, update map[string]any) (Id string, err error)
 
 	// FindRegion
-	FindRegion(ctx context.Context) (records []yanolja.Region, err error)
+	FindRegion(ctx context.Context, q pagination.Query) (records []yanolja.Region, total int64, next string, err error)
 
 	//FindRecordByRegionId
 	FindRecordByRegionId(ctx context.Context, regionId int64) (record yanolja.Region, err error)
@@ -123,7 +133,7 @@ type MongoRepository interface {
 	UpdateOrderVoucherIndividually(ctx context.Context, orderid int64, partnerOrderId string, orderVariantId, orderVariantItemId int64, update map[string]any) (err error)
 
 	//ForcedCancellationReasonUpdate
-	ForcedCancellationReasonUpdate(ctx context.Context, orderid int64, partnerOrderId string, orderVariantId int64, forceCancelTypeCode string) (err error)
+	ForcedCancellationReasonUpdate(ctx context.Context, orderid int64, partnerOrderId string, orderVariantId int64, forceCancelTypeCode string, transitions []yanolja.StatusTransition) (err error)
 
 	//UpdateReconcilationDetail
 	//UpdateReconciliationDetailByDay(ctx context.Context, req, update map[string]any) (err error)
@@ -141,19 +151,25 @@ type MongoRepository interface {
 	UpdateCancelDetailsForVariants(ctx context.Context, orderId int64, productId int64, orderVariantId int64, cancelFailReasonCode string, cancelStatusCode string) error
 
 	//UpdateProcessingRestoringOfOrder
-	UpdateProcessingRestoringOfOrder(ctx context.Context, orderId int64, updaterec map[string]any) (err error)
+	UpdateProcessingRestoringOfOrder(ctx context.Context, orderId int64, updaterec map[string]any, transitions []yanolja.StatusTransition) (err error)
 
 	//UpdateForcedOrderDetail
-	UpdateForcedCancelOrderDetail(ctx context.Context, partnerOrderId string, newStatus string) (id string, err error)
+	UpdateForcedCancelOrderDetail(ctx context.Context, partnerOrderId string, newStatus string, transitions []yanolja.StatusTransition) (id string, err error)
 
 	//UpdateOrderVariantStatusByOrderId
-	UpdateOrderVariantStatusByOrderId(ctx context.Context, orderid int64, variantStatus string) (err error)
+	UpdateOrderVariantStatusByOrderId(ctx context.Context, orderid int64, variantStatus string, transitions []yanolja.StatusTransition) (err error)
+
+	//UpdateCancelRequest
+	UpdateCancelRequest(ctx context.Context, orderid int64, category string, orderVariantIds []int64, quote refund.Quote, transitions []yanolja.StatusTransition) (err error)
+
+	//UpdateRefundQuote
+	UpdateRefundQuote(ctx context.Context, orderid int64, quote refund.Quote) (err error)
 
 	//UpdateOrderCancelAck
-	UpdateOrderCancelAck(ctx context.Context, orderid int64, partnerOrderId string, canceltypecode string, orderstatus string) (err error)
+	UpdateOrderCancelAck(ctx context.Context, orderid int64, partnerOrderId string, canceltypecode string, orderstatus string, orderVariantIds []int64, transitions []yanolja.StatusTransition) (err error)
 
 	//UpdateRefusalToCancelInfo
-	UpdateRefusalToCancelInfo(ctx context.Context, orderid int64, partnerOrderId string, ovariantId int64, cancelRejectTypeCode, message string) (err error)
+	UpdateRefusalToCancelInfo(ctx context.Context, orderid int64, partnerOrderId string, ovariantId int64, cancelRejectTypeCode, message string, transitions []yanolja.StatusTransition) (err error)
 
 	//UpdateForceCancelVariants
 	UpdateForceCancelVariants(ctx context.Context, orderid int64, partnerOrderId string, forceCancelVariants []req_resp.CancelledVariants) (err error)
@@ -230,6 +246,21 @@ type MongoRepository interface {
 	//UpdateCallBackStatus
 	UpdateCallBackStatus(ctx context.Context, id, status string) error
 
+	//InsertNotificationOutbox
+	InsertNotificationOutbox(ctx context.Context, msg trip_domain.NotificationOutbox) (id string, err error)
+
+	//ClaimDueNotificationOutbox
+	ClaimDueNotificationOutbox(ctx context.Context, lease time.Duration) (trip_domain.NotificationOutbox, error)
+
+	//UpdateNotificationOutbox
+	UpdateNotificationOutbox(ctx context.Context, id string, update map[string]any) error
+
+	//GetNotificationOutbox
+	GetNotificationOutbox(ctx context.Context, status string, skip, limit int64) ([]trip_domain.NotificationOutbox, int64, error)
+
+	//GetNotificationOutboxById
+	GetNotificationOutboxById(ctx context.Context, id string) (trip_domain.NotificationOutbox, error)
+
 	//FetchPluByKey
 	FetchPluByKey(ctx context.Context, Key string) (string, error)
 
@@ -261,6 +292,9 @@ type MongoRepository interface {
 	//InsertFullCancelOrderRequestFromTrip
 	InsertFullCancelOrderRequestFromTrip(ctx context.Context, cancelRequest trip.CancellationRequest) (err error)
 
+	// InsertPartialCancelOrderRequestFromTrip
+	InsertPartialCancelOrderRequestFromTrip(ctx context.Context, cancelRequest trip.CancellationRequest) (err error)
+
 	//FetchTripRequests
 	FetchTripRequests(ctx context.Context) (triporders [][]trip.ResponseForTripRequest, err error)
 
@@ -283,7 +317,7 @@ type MongoRepository interface {
 	GetSequenceIDByKey(ctx context.Context, key string, typeOfcollection string) (string, error)
 
 	// GetOrdersByChannelCodeAndCustomerEmail
-	GetOrdersByChannelCodeAndCustomerEmail(ctx context.Context, channelCode string, cutomerEmail string) ([]yanolja.Model, error)
+	GetOrdersByChannelCodeAndCustomerEmail(ctx context.Context, channelCode string, cutomerEmail string, q pagination.Query) ([]yanolja.Model, int64, string, error)
 
 	// GetOdooOrderbyOrderId
 	GetOdooOrderbyOrderId(ctx context.Context, orderid int64) (record odoo.Order, err error)
@@ -308,7 +342,7 @@ type MongoRepository interface {
 	GetProductViewByProductId(ctx context.Context, productId int64) (product yanolja.ProductView, err error)
 
 	// GetProductByProductId
-	GetAllProductViews(ctx context.Context) (products []yanolja.ProductView, err error)
+	GetAllProductViews(ctx context.Context, q pagination.Query) (products []yanolja.ProductView, total int64, next string, err error)
 
 	// GetProductNameByProductID
 	GetProductNameByProductID(ctx context.Context, productID int64) (string, error)
@@ -325,6 +359,9 @@ type MongoRepository interface {
 	// GetOrderByOrderNumber
 	GetOrderByOrderNumber(ctx context.Context, orderNumber string) (order travolution_domain.Order, err error)
 
+	// GetOrderByReferenceNumber
+	GetOrderByReferenceNumber(ctx context.Context, referenceNumber string) (order travolution_domain.Order, err error)
+
 	// InsertWebhook
 	UpsertTravolutionWebhook(ctx context.Context, payload travolution_domain.Webhook) (string, error)
 
@@ -333,4 +370,187 @@ type MongoRepository interface {
 
 	// UpdateOrderByOrderNumber
 	UpdateOrderByOrderNumber(ctx context.Context, orderNumber string, update map[string]any) error
+
+	// GetPricingRules
+	GetPricingRules(ctx context.Context) (rules []pricing.Rule, err error)
+
+	// GetPricingRuleById
+	GetPricingRuleById(ctx context.Context, id string) (rule pricing.Rule, err error)
+
+	// InsertPricingRule
+	InsertPricingRule(ctx context.Context, rule pricing.Rule) (id string, err error)
+
+	// ReplacePricingRule
+	ReplacePricingRule(ctx context.Context, rule pricing.Rule) error
+
+	// DeletePricingRule
+	DeletePricingRule(ctx context.Context, id string) (deleted bool, err error)
+
+	// EnsurePricingRuleIndexes
+	EnsurePricingRuleIndexes(ctx context.Context) error
+
+	// SeedPricingRules
+	SeedPricingRules(ctx context.Context, rules []pricing.Rule) (inserted int, err error)
+
+	// GetRefundPolicies
+	GetRefundPolicies(ctx context.Context, variantIds []int64) (policies []refund.Policy, err error)
+
+	// GetRefundPolicy
+	GetRefundPolicy(ctx context.Context, variantId int64) (policy refund.Policy, err error)
+
+	// UpsertRefundPolicy
+	UpsertRefundPolicy(ctx context.Context, policy refund.Policy) error
+
+	// DeleteRefundPolicy
+	DeleteRefundPolicy(ctx context.Context, variantId int64) (deleted bool, err error)
+
+	// UpsertFxRates
+	UpsertFxRates(ctx context.Context, rates []money.FxRate) (upserted int64, err error)
+
+	// GetFxRate
+	GetFxRate(ctx context.Context, base, quote, asOf string) (rate money.FxRate, err error)
+
+	// InsertApiClient
+	InsertApiClient(ctx context.Context, client apiclient.ApiClient) (id string, err error)
+
+	// GetApiClients
+	GetApiClients(ctx context.Context) (clients []apiclient.ApiClient, err error)
+
+	// GetApiClientById
+	GetApiClientById(ctx context.Context, id string) (client apiclient.ApiClient, err error)
+
+	// GetApiClientByKeyHash
+	GetApiClientByKeyHash(ctx context.Context, keyHash string) (client apiclient.ApiClient, err error)
+
+	// UpdateApiClient
+	UpdateApiClient(ctx context.Context, id string, update map[string]any) error
+
+	// InsertWebhookRecord
+	InsertWebhookRecord(ctx context.Context, record webhook.Record) (id string, err error)
+
+	// AcquireJobLease
+	AcquireJobLease(ctx context.Context, name, holder string, ttl time.Duration) (acquired bool, err error)
+
+	// ReleaseJobLease
+	ReleaseJobLease(ctx context.Context, name, holder string) error
+
+	// GetResumeToken
+	GetResumeToken(ctx context.Context, name string) (token bson.Raw, err error)
+
+	// SaveResumeToken
+	SaveResumeToken(ctx context.Context, name string, token bson.Raw) error
+
+	// GetJobSettings
+	GetJobSettings(ctx context.Context) (settings []job.Setting, err error)
+
+	// UpdateJobSetting
+	UpdateJobSetting(ctx context.Context, name string, update map[string]any) error
+
+	// ClaimJobTrigger
+	ClaimJobTrigger(ctx context.Context, name string) (claimed bool, err error)
+
+	// EnsureJobRunIndexes
+	EnsureJobRunIndexes(ctx context.Context) error
+
+	// InsertJobRun
+	InsertJobRun(ctx context.Context, run job.Run) (id string, err error)
+
+	// GetJobRuns
+	GetJobRuns(ctx context.Context, name string, limit int64) (runs []job.Run, err error)
+
+	// EnsureOdooSyncIndexes
+	EnsureOdooSyncIndexes(ctx context.Context) error
+
+	// AppendOdooSyncEvent
+	AppendOdooSyncEvent(ctx context.Context, kind string, refId int64) (seq int64, err error)
+
+	// GetOdooSyncEvents
+	GetOdooSyncEvents(ctx context.Context, kind string, since int64, limit int64) (events []odoo.SyncEvent, err error)
+
+	// GetOdooSyncSeq
+	GetOdooSyncSeq(ctx context.Context) (seq int64, err error)
+
+	// GetOdooSyncCursor
+	GetOdooSyncCursor(ctx context.Context, consumer string) (cursor odoo.SyncCursor, err error)
+
+	// AckOdooSyncCursor
+	AckOdooSyncCursor(ctx context.Context, consumer string, seq int64) (cursor odoo.SyncCursor, err error)
+
+	// UpdateOdooPushState
+	UpdateOdooPushState(ctx context.Context, kind string, refId int64, update map[string]any) error
+
+	// GetReconciliationOrders
+	GetReconciliationOrders(ctx context.Context, date string, afterOrderId int64, limit int64) (orders []yanolja.Model, err error)
+
+	// GetTripCallbacksByOrderIds
+	GetTripCallbacksByOrderIds(ctx context.Context, orderIds []int64) (callbacks map[int64][]trip_domain.CallBackDetail, err error)
+
+	// GetTripCallbackOrderIds
+	GetTripCallbackOrderIds(ctx context.Context, date string) (orderIds []int64, err error)
+
+	// GetExistingOrderIds
+	GetExistingOrderIds(ctx context.Context, orderIds []int64) (existing map[int64]bool, err error)
+
+	// UpsertReconciliationReport
+	UpsertReconciliationReport(ctx context.Context, report reconciliation.Report) error
+
+	// GetReconciliationReports
+	GetReconciliationReports(ctx context.Context, limit int64) (reports []reconciliation.Report, err error)
+
+	// GetReconciliationReport
+	GetReconciliationReport(ctx context.Context, date string, mismatchType string, skip int64, limit int64) (report reconciliation.Report, total int64, err error)
+
+	// GetCategoryMappings
+	GetCategoryMappings(ctx context.Context, categoryLevel int, tripCategory string) (mappings []yanolja.CategoryMapping, err error)
+
+	// GetCategoryMapping
+	GetCategoryMapping(ctx context.Context, code int, categoryLevel int) (mapping yanolja.CategoryMapping, err error)
+
+	// InsertCategoryMapping
+	InsertCategoryMapping(ctx context.Context, mapping yanolja.CategoryMapping) (inserted bool, err error)
+
+	// UpdateCategoryMapping
+	UpdateCategoryMapping(ctx context.Context, mapping yanolja.CategoryMapping) (updated bool, err error)
+
+	// DeleteCategoryMapping
+	DeleteCategoryMapping(ctx context.Context, code int, categoryLevel int) (deleted bool, err error)
+
+	// UpsertCategoryMappings
+	UpsertCategoryMappings(ctx context.Context, mappings []yanolja.CategoryMapping) (map[string]int64, error)
+
+	// GetUnmappedCategoryProducts
+	GetUnmappedCategoryProducts(ctx context.Context) (products []yanolja.UnmappedCategoryProduct, err error)
+
+	// GetTranslations
+	GetTranslations(ctx context.Context, targetLang string, sourceHashes []string) (translations map[string]string, err error)
+
+	// InsertTranslations
+	InsertTranslations(ctx context.Context, memories []translation.Memory) error
+
+	// UpsertProductVersion
+	UpsertProductVersion(ctx context.Context, version yanolja.ProductVersion) error
+
+	// GetProductVersions
+	GetProductVersions(ctx context.Context, productId int64) (versions []yanolja.ProductVersion, err error)
+
+	// GetProductVersion
+	GetProductVersion(ctx context.Context, productId int64, version int32) (productVersion yanolja.ProductVersion, err error)
+
+	// GetPreviousProductVersion
+	GetPreviousProductVersion(ctx context.Context, productId int64, version int32) (productVersion yanolja.ProductVersion, err error)
+
+	// GetExpiredHolds
+	GetExpiredHolds(ctx context.Context, supplier string, createdBefore string, afterOrderId int64, limit int64) (orders []yanolja.Model, err error)
+
+	// UpdateHoldExpiry
+	UpdateHoldExpiry(ctx context.Context, orderId int64, expiry yanolja.HoldExpiry, transitions []yanolja.StatusTransition) error
+
+	// GetExpiredTravolutionHolds
+	GetExpiredTravolutionHolds(ctx context.Context, heldBefore string, afterOrderNumber string, limit int64) (orders []travolution_domain.Order, err error)
+
+	// UpdateTravolutionHoldExpiry
+	UpdateTravolutionHoldExpiry(ctx context.Context, orderNumber string, expiry travolution_domain.HoldExpiry) error
+
+	// ReleaseTravolutionHold
+	ReleaseTravolutionHold(ctx context.Context, orderNumber string) error
 }
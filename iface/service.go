@@ -3,6 +3,8 @@ package iface
 import (
 	"context"
 
+	pricing_domain "swallow-supplier/mongo/domain/pricing"
+	refund_domain "swallow-supplier/mongo/domain/refund"
 	travolution_domain "swallow-supplier/mongo/domain/travolution"
 	domain "swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/request_response/common"
@@ -10,6 +12,7 @@ import (
 	"swallow-supplier/request_response/trip"
 	"swallow-supplier/request_response/yanolja"
 	req_resp "swallow-supplier/request_response/yanolja"
+	"swallow-supplier/utils/pagination"
 )
 
 // Service will hold all the available methods on the Service
@@ -36,12 +39,18 @@ type Service interface {
 	// GetCategories get all products from yanolja
 	GetCategories(ctx context.Context) (resp yanolja.Response, err error)
 
+	// GetStoredCategories one page of the categories stored in ggt
+	GetStoredCategories(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error)
+
 	// InsertAllCategories insert all product categories to ggt
 	InsertAllCategories(ctx context.Context) (resp yanolja.Response, err error)
 
 	// GetRegions get all products from yanolja
 	GetRegions(ctx context.Context) (resp yanolja.Response, err error)
 
+	// GetStoredRegions one page of the regions stored in ggt
+	GetStoredRegions(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error)
+
 	// InsertAllRegions  insert all regions into ggt
 	InsertAllRegions(ctx context.Context) (resp yanolja.Response, err error)
 
@@ -57,6 +66,9 @@ type Service interface {
 	// PostCancelOrderEntirly fully cancel the order
 	PostCancelOrderEntirly(ctx context.Context, orderId int64) (resp yanolja.Response, err error)
 
+	// PostCancelOrderVariants cancel some variants of an order
+	PostCancelOrderVariants(ctx context.Context, orderId int64, orderVariantIds []int64) (resp yanolja.Response, err error)
+
 	// PostCancelOrderByReqTimeOut cancel order when request timeout
 	PostCancelOrderByReqTimeOut(ctx context.Context, partnerOrderId string) (resp yanolja.Response, err error)
 
@@ -111,7 +123,7 @@ type Service interface {
 	PostRequestFromGGT(ctx context.Context, req trip.SwallowRequest) (resp yanolja.Response, err error)
 
 	// GetRedisData
-	GetRedisData(ctx context.Context) (resp yanolja.Response, err error)
+	GetRedisData(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error)
 
 	// GetImageSyncStatusToTrip
 	GetImageSyncToTrip(ctx context.Context) (resp yanolja.Response, err error)
@@ -212,7 +224,7 @@ type Service interface {
 	GetOderByPartialPartnerOrderIdSuffix(ctx context.Context, partialPartnerId string) (resp common.Response, err error)
 
 	// GetProductsFromGGT
-	GetProductsFromGGT(ctx context.Context) (resp yanolja.Response, err error)
+	GetProductsFromGGT(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error)
 
 	// GetProductByIdFromGGT
 	GetProductByIdFromGGT(ctx context.Context, productId int64) (resp yanolja.Response, err error)
@@ -228,4 +240,148 @@ type Service interface {
 
 	// OrderWebhookUpdate
 	OrderWebhookUpdate(ctx context.Context, payload travolution_domain.Webhook) (resp travolution.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Notification outbox::::::::::::::::::::::::::::::::::::::::::
+
+	// ProcessNotificationOutbox
+	ProcessNotificationOutbox(ctx context.Context) (resp common.Response, err error)
+
+	// GetNotificationOutbox
+	GetNotificationOutbox(ctx context.Context, req common.NotificationOutboxRequest) (resp common.Response, err error)
+
+	// ReplayNotificationOutbox
+	ReplayNotificationOutbox(ctx context.Context, id string) (resp common.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Circuit breaker:::::::::::::::::::::::::::::::::::::::::::::
+
+	// GetCircuitBreakers
+	GetCircuitBreakers(ctx context.Context) (resp common.Response, err error)
+
+	// UpdateCircuitBreaker
+	UpdateCircuitBreaker(ctx context.Context, req common.CircuitBreakerRequest) (resp common.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Pricing rules:::::::::::::::::::::::::::::::::::::::::::::::
+
+	// GetPricingRules
+	GetPricingRules(ctx context.Context, req common.PricingRuleRequest) (resp common.Response, err error)
+
+	// GetPricingRule
+	GetPricingRule(ctx context.Context, id string) (resp common.Response, err error)
+
+	// CreatePricingRule
+	CreatePricingRule(ctx context.Context, rule pricing_domain.Rule) (resp common.Response, err error)
+
+	// UpdatePricingRule
+	UpdatePricingRule(ctx context.Context, rule pricing_domain.Rule) (resp common.Response, err error)
+
+	// DeletePricingRule
+	DeletePricingRule(ctx context.Context, id string) (resp common.Response, err error)
+
+	// GetRefundPolicy
+	GetRefundPolicy(ctx context.Context, req common.RefundPolicyRequest) (resp common.Response, err error)
+
+	// PutRefundPolicy
+	PutRefundPolicy(ctx context.Context, policy refund_domain.Policy) (resp common.Response, err error)
+
+	// DeleteRefundPolicy
+	DeleteRefundPolicy(ctx context.Context, variantId int64) (resp common.Response, err error)
+
+	// GetVariantAvailability
+	GetVariantAvailability(ctx context.Context, req common.AvailabilityRequest) (resp common.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Api clients:::::::::::::::::::::::::::::::::::::::::::::::::
+
+	// GetApiClients
+	GetApiClients(ctx context.Context) (resp common.Response, err error)
+
+	// IssueApiClient
+	IssueApiClient(ctx context.Context, req common.ApiClientRequest) (resp common.Response, err error)
+
+	// RotateApiClientKey
+	RotateApiClientKey(ctx context.Context, req common.ApiClientRequest) (resp common.Response, err error)
+
+	// RevokeApiClient
+	RevokeApiClient(ctx context.Context, id string) (resp common.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Jobs:::::::::::::::::::::::::::::::::::::::::::::::::
+
+	// GetJobs
+	GetJobs(ctx context.Context) (resp common.Response, err error)
+
+	// GetJobRuns
+	GetJobRuns(ctx context.Context, req common.JobRequest) (resp common.Response, err error)
+
+	// TriggerJob
+	TriggerJob(ctx context.Context, req common.JobRequest) (resp common.Response, err error)
+
+	// SetJobPaused
+	SetJobPaused(ctx context.Context, req common.JobRequest) (resp common.Response, err error)
+
+	// UpdateJobSchedule
+	UpdateJobSchedule(ctx context.Context, req common.JobRequest) (resp common.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Odoo Sync:::::::::::::::::::::::::::::::::::::::::::::::::
+
+	// GetOdooSyncChanges
+	GetOdooSyncChanges(ctx context.Context, req common.OdooSyncRequest) (resp common.Response, err error)
+
+	// PostOdooSyncAck
+	PostOdooSyncAck(ctx context.Context, req common.OdooSyncRequest) (resp common.Response, err error)
+
+	// PushOdooChanges
+	PushOdooChanges(ctx context.Context) (resp common.Response, err error)
+
+	// ExpireHeldOrders
+	ExpireHeldOrders(ctx context.Context) (resp common.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Reconciliation Reports:::::::::::::::::::::::::::::::::::::::::::::::::
+
+	// GenerateReconciliationReport
+	GenerateReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error)
+
+	// GetReconciliationReports
+	GetReconciliationReports(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error)
+
+	// GetReconciliationReport
+	GetReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error)
+
+	// ExportReconciliationReport
+	ExportReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Category mapping:::::::::::::::::::::::::::::::::::::::::::::::::
+
+	// GetCategoryMappings
+	GetCategoryMappings(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error)
+
+	// GetCategoryMapping
+	GetCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error)
+
+	// CreateCategoryMapping
+	CreateCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error)
+
+	// UpdateCategoryMapping
+	UpdateCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error)
+
+	// DeleteCategoryMapping
+	DeleteCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error)
+
+	// UploadCategoryMappings
+	UploadCategoryMappings(ctx context.Context, req common.CategoryMappingUploadRequest) (resp common.Response, err error)
+
+	// ExportCategoryMappings
+	ExportCategoryMappings(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error)
+
+	// GetUnmappedCategoryProducts
+	GetUnmappedCategoryProducts(ctx context.Context) (resp common.Response, err error)
+
+	// ::::::::::::::::::::::::::::::::::::::::Product versions:::::::::::::::::::::::::::::::::::::::::::::::::
+
+	// GetProductVersions
+	GetProductVersions(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error)
+
+	// GetProductVersion
+	GetProductVersion(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error)
+
+	// DiffProductVersions
+	DiffProductVersions(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error)
 }
@@ -0,0 +1,282 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	"swallow-supplier/mongo/domain/apiclient"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/apikey"
+	"swallow-supplier/utils/constant"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetApiClients list api clients, key hashes are never returned
+func (s *service) GetApiClients(ctx context.Context) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetApiClients",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	clients, err := s.mongoRepository[config.Instance().MongoDBName].GetApiClients(ctx)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching api clients", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching api clients, %v", err), "GetApiClients")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = clients
+
+	return resp, nil
+}
+
+// IssueApiClient create an api client and return its key, the key can not be read again later
+func (s *service) IssueApiClient(ctx context.Context, req common.ApiClientRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "IssueApiClient",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	if msg := validateApiClientRequest(req); msg != "" {
+		resp.Code = "400"
+		resp.Status = http.StatusBadRequest
+		return resp, customError.NewError(ctx, "leisure-api-1016", msg, "IssueApiClient")
+	}
+
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		level.Error(logger).Log("error", "generating api key", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1018", fmt.Sprintf("api key could not be generated, %v", err), "IssueApiClient")
+	}
+
+	client := apiclient.ApiClient{
+		Name:        strings.TrimSpace(req.Name),
+		KeyPrefix:   prefix,
+		KeyHash:     hash,
+		ChannelCode: req.ChannelCode,
+		Scopes:      req.Scopes,
+		Enabled:     true,
+		ExpiresAt:   req.ExpiresAt,
+	}
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	id, err := mrepo.InsertApiClient(ctx, client)
+	if err != nil {
+		level.Error(logger).Log("repository error", "inserting api client", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting api client, %v", err), "IssueApiClient")
+	}
+
+	client, err = mrepo.GetApiClientById(ctx, id)
+	if err != nil {
+		return apiClientLookupError(ctx, logger, resp, err, "IssueApiClient")
+	}
+
+	level.Info(logger).Log("info", "api client issued", "id", id, "name", client.Name, "scopes", fmt.Sprint(client.Scopes))
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = common.ApiClientKey{Client: client, Key: key}
+
+	return resp, nil
+}
+
+// RotateApiClientKey replace the key of an api client, the previous key stops working immediately
+// on this instance and within the client cache period on the others
+func (s *service) RotateApiClientKey(ctx context.Context, req common.ApiClientRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "RotateApiClientKey",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		resp.Code = "400"
+		resp.Status = http.StatusBadRequest
+		return resp, customError.NewError(ctx, "leisure-api-1016", "expiresAt must be in the future", "RotateApiClientKey")
+	}
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	client, err := mrepo.GetApiClientById(ctx, req.Id)
+	if err != nil {
+		return apiClientLookupError(ctx, logger, resp, err, "RotateApiClientKey")
+	}
+
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		level.Error(logger).Log("error", "generating api key", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1018", fmt.Sprintf("api key could not be generated, %v", err), "RotateApiClientKey")
+	}
+
+	update := map[string]any{
+		"keyPrefix": prefix,
+		"keyHash":   hash,
+		"enabled":   true,
+		"rotatedAt": time.Now().UTC().Format(time.RFC3339),
+	}
+	if req.ExpiresAt != nil {
+		update["expiresAt"] = req.ExpiresAt
+	}
+	if err = mrepo.UpdateApiClient(ctx, client.Id, update); err != nil {
+		level.Error(logger).Log("repository error", "rotating api client key", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on rotating api client key, %v", err), "RotateApiClientKey")
+	}
+	apikey.Invalidate()
+
+	client, err = mrepo.GetApiClientById(ctx, client.Id)
+	if err != nil {
+		return apiClientLookupError(ctx, logger, resp, err, "RotateApiClientKey")
+	}
+
+	level.Info(logger).Log("info", "api client key rotated", "id", client.Id, "name", client.Name)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = common.ApiClientKey{Client: client, Key: key}
+
+	return resp, nil
+}
+
+// RevokeApiClient disable an api client
+func (s *service) RevokeApiClient(ctx context.Context, id string) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "RevokeApiClient",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	client, err := mrepo.GetApiClientById(ctx, id)
+	if err != nil {
+		return apiClientLookupError(ctx, logger, resp, err, "RevokeApiClient")
+	}
+
+	err = mrepo.UpdateApiClient(ctx, client.Id, map[string]any{
+		"enabled":   false,
+		"revokedAt": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		level.Error(logger).Log("repository error", "revoking api client", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on revoking api client, %v", err), "RevokeApiClient")
+	}
+	apikey.Invalidate()
+
+	client, err = mrepo.GetApiClientById(ctx, client.Id)
+	if err != nil {
+		return apiClientLookupError(ctx, logger, resp, err, "RevokeApiClient")
+	}
+
+	level.Info(logger).Log("info", "api client revoked", "id", client.Id, "name", client.Name)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = client
+
+	return resp, nil
+}
+
+// validateApiClientRequest checks name, scopes and expiry of a new api client
+func validateApiClientRequest(req common.ApiClientRequest) string {
+	if strings.TrimSpace(req.Name) == "" {
+		return "name is required"
+	}
+	if len(req.Scopes) == 0 {
+		return "at least one scope is required"
+	}
+	for _, scope := range req.Scopes {
+		if scope != constant.ScopeClient && scope != constant.ScopeSuperUser {
+			return fmt.Sprintf("scope %d is not defined", scope)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return "expiresAt must be in the future"
+	}
+
+	return ""
+}
+
+// apiClientLookupError maps a failed api client lookup to 404 or 500
+func apiClientLookupError(ctx context.Context, logger log.Logger, resp common.Response, err error, source string) (common.Response, error) {
+	if err == mongo.ErrNoDocuments {
+		level.Error(logger).Log("repository error", "no api client exist for id")
+		resp.Code = "404"
+		resp.Status = http.StatusNotFound
+		return resp, customError.NewErrorCustom(ctx, resp.Code, "api client not found", "", http.StatusNotFound, source)
+	}
+
+	level.Error(logger).Log("repository error", "fetching api client", "error", err)
+	resp.Code = "500"
+	return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching api client, %v", err), source)
+}
@@ -0,0 +1,172 @@
+package implementation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"swallow-supplier/caches/cache"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/request_response/supplier"
+	"swallow-supplier/request_response/yanolja"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/availability"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// variantAvailability inventory of the variant answered to an availability query
+type variantAvailability struct {
+	availability.Inventory
+	Requested int32 `json:"requested"`
+	Available bool  `json:"available"`
+}
+
+// GetVariantAvailability whether the quantity of a variant can be booked on the date, inventories
+// are read from the cache while they are fresh
+func (s *service) GetVariantAvailability(ctx context.Context, req common.AvailabilityRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetVariantAvailability",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	inventory, err := availability.Check(ctx, inventoryStore(ctx, logger), variantInventory, availability.Query{VariantId: req.VariantId, Date: req.Date, Time: req.Time}, time.Now())
+	if err != nil {
+		level.Error(logger).Log("error", "request to supplier for variant inventory raised error", "variantId", req.VariantId, "err", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-0005", fmt.Sprintf("error on fetching inventory of variantId %d, %v", req.VariantId, err), "GetVariantAvailability")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = variantAvailability{
+		Inventory: inventory,
+		Requested: req.Quantity,
+		Available: inventory.Available(req.Quantity),
+	}
+
+	return resp, nil
+}
+
+// checkAvailability refuse the pre order when a selected variant is sold out for its date and
+// round. The supplier decides when its inventory can not be read
+func checkAvailability(ctx context.Context, logger log.Logger, req yanolja.WaitingForOrder) error {
+	demand := make(map[availability.Query]int32)
+	for _, val := range req.SelectVariants {
+		query := availability.Query{VariantId: val.VariantID}
+		if val.Date != nil {
+			query.Date = *val.Date
+		}
+		if val.Time != nil {
+			query.Time = *val.Time
+		}
+		demand[query] += val.Quantity
+	}
+
+	store := inventoryStore(ctx, logger)
+	for query, quantity := range demand {
+		inventory, err := availability.Check(ctx, store, variantInventory, query, time.Now())
+		if err != nil {
+			level.Error(logger).Log("error", "variant inventory not available, left to the supplier", "variantId", query.VariantId, "date", query.Date, "err", err)
+			continue
+		}
+		if !inventory.Available(quantity) {
+			level.Info(logger).Log("info", "variant sold out", "variantId", query.VariantId, "date", query.Date, "time", query.Time, "quantity", inventory.Quantity, "requested", quantity)
+			return customError.NewError(ctx, "leisure-api-00027", fmt.Sprintf("sold out, variantId %d has %d left on %s %s and %d were requested", query.VariantId, max(inventory.Quantity, 0), query.Date, query.Time, quantity), "PreOrderCreationValidate")
+		}
+	}
+
+	return nil
+}
+
+// variantInventory live inventory of the variant from the supplier
+func variantInventory(ctx context.Context, query availability.Query) (availability.Inventory, error) {
+	adapter, err := supplierAdapter(ctx, constant.SUPPLIERYANOLJA)
+	if err != nil {
+		return availability.Inventory{}, err
+	}
+
+	res, err := adapter.Inventory(ctx, supplier.InventoryQuery{
+		VariantId: strconv.FormatInt(query.VariantId, 10),
+		DateStart: query.Date,
+		Time:      query.Time,
+	})
+	if err != nil {
+		return availability.Inventory{}, err
+	}
+	bodyBytes, err := json.Marshal(res.Body)
+	if err != nil {
+		return availability.Inventory{}, err
+	}
+	var variant yanolja.VariantInventoryResp
+	if err = json.Unmarshal(bodyBytes, &variant); err != nil {
+		return availability.Inventory{}, err
+	}
+	// an error answer of the supplier has no inventory, it must not read as sold out
+	if variant.VariantId != query.VariantId {
+		return availability.Inventory{}, fmt.Errorf("supplier answered code %s without inventory of variantId %d", res.Code, query.VariantId)
+	}
+
+	return availability.Inventory{
+		Quantity:            variant.Quantity,
+		QuantityPerPerson:   variant.QuantityPerPerson,
+		QuantityPerPurchase: variant.QuantityPerPurchase,
+	}, nil
+}
+
+// invalidateInventory drop the cached inventories of the variants, they are read from the
+// supplier again on the next check
+func invalidateInventory(ctx context.Context, logger log.Logger, variantIds []int64) {
+	store := inventoryStore(ctx, logger)
+	if store == nil || len(variantIds) == 0 {
+		return
+	}
+
+	if err := availability.Invalidate(ctx, store, variantIds); err != nil {
+		level.Error(logger).Log("error", "invalidating cached variant inventories", "variantIds", fmt.Sprint(variantIds), "err", err)
+	}
+}
+
+// productVariantIds ids of every variant of the product
+func productVariantIds(product domain.Product) []int64 {
+	ids := make([]int64, 0)
+	for _, group := range product.ProductOptionGroups {
+		for _, variant := range group.Variants {
+			ids = append(ids, variant.VariantID)
+		}
+	}
+	return ids
+}
+
+// inventoryStore cache layer keeping the inventories, nil when it is not available
+func inventoryStore(ctx context.Context, logger log.Logger) availability.Store {
+	cacheLayer, err := cache.New(config.Instance().CacheName)
+	if err != nil {
+		level.Error(logger).Log("error", fmt.Sprintf("Error initializing cache layer: %s", err))
+		return nil
+	}
+	return cacheLayer
+}
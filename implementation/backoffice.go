@@ -10,19 +10,23 @@ import (
 
 	"swallow-supplier/config"
 	customError "swallow-supplier/error"
+	"swallow-supplier/mongo/domain/money"
 	"swallow-supplier/mongo/domain/odoo"
+	pricingDomain "swallow-supplier/mongo/domain/pricing"
 	domain "swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/request_response/common"
 	"swallow-supplier/request_response/yanolja"
 	"swallow-supplier/utils"
 	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/fx"
+	"swallow-supplier/utils/pricing"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// GetProductViewSync  to sync product_view to odoo
+// GetProductViewSync  to sync product_view to odoo, superseded by the cursor based GetOdooSyncChanges
 func (s *service) GetProductSync(ctx context.Context) (resp common.Response, err error) {
 	requestID := utils.GenerateUUID("GGT", true)
 
@@ -64,83 +68,7 @@ func (s *service) GetProductSync(ctx context.Context) (resp common.Response, err
 	var odooProducts = make([]odoo.Product, 0)
 
 	for _, product := range products {
-		var addressDetail odoo.FacilityLocationDetail
-		var productVariant odoo.ProductVariant
-		var odooproduct odoo.Product
-
-		odooproduct.ProductID = product.ProductID
-		odooproduct.ProductName = product.ProductName
-		odooproduct.ProductVersion = product.ProductVersion
-		odooproduct.ProductStatusCode = product.ProductStatusCode
-		odooproduct.ProductTypeCode = product.ProductTypeCode
-		if product.ProductStatusCode == "IN_SALE" && product.IsUsed {
-			odooproduct.ProductValidity = "InValid"
-		} else {
-			odooproduct.ProductValidity = "Not_Valid"
-		}
-
-		facilityInfo := product.ProductInfo.FacilityInfos
-		addressDetails := make([]odoo.FacilityLocationDetail, 0)
-
-		if len(facilityInfo) > 0 {
-			for _, facility := range facilityInfo {
-				addressDetail.Latitude = facility.Location.Latitude
-				addressDetail.Longitude = facility.Location.Longitude
-				addressDetail.Address = facility.Location.Address
-				addressDetails = append(addressDetails, addressDetail)
-			}
-		}
-		odooproduct.FacilityAddress = addressDetails
-
-		var regionInfos []odoo.Region
-		if len(product.Regions) > 0 {
-			regionInfos = FlattenRegions(product.Regions)
-		}
-
-		if len(regionInfos) > 0 {
-			odooproduct.Regions = regionInfos
-		}
-
-		odooproduct.IsIntegratedVoucher = product.IsIntegratedVoucher
-
-		productVariants := make([]odoo.ProductVariant, 0)
-		for _, optiongrp := range product.ProductOptionGroups {
-
-			for _, variant := range optiongrp.Variants {
-				productVariant.VariantID = variant.VariantID
-				productVariant.ProductID = variant.ProductID
-				if !strings.EqualFold(variant.VariantName, "Adult") || !strings.EqualFold(variant.VariantName, "Youth") ||
-					!strings.EqualFold(variant.VariantName, "Child") || !strings.EqualFold(variant.VariantName, "children") {
-					productVariant.VariantName = "N/A"
-				}
-				productVariant.VariantName = variant.VariantName
-				productVariant.VariantDescription = variant.VariantDescription
-				productVariant.RefundApprovalTypeCode = variant.RefundApprovalTypeCode
-				productVariant.IsRefundableAfterExpiration = variant.IsRefundableAfterExpiration
-				productVariant.RefundInfo = variant.RefundInfo
-				productVariant.VariantStatusCode = variant.VariantStatusCode
-				productVariant.OrderExpirationUsageProcessTypeCode = variant.OrderExpirationUsageProcessTypeCode
-				productVariant.OrderExpirationDateTypeCode = variant.OrderExpirationDateTypeCode
-				productVariant.ValidityStartDate = variant.SalePeriod.StartDateTime
-				productVariant.ValidityEndDate = variant.SalePeriod.EndDateTime
-				productVariant.Currency = variant.Price.Currency
-				productVariant.SupplierCostPrice = variant.Price.CostPrice
-				productVariant.SalePrice = variant.Price.SalePrice
-				productVariant.RetailPrice = variant.Price.RetailPrice
-				productVariant.DiscountSalePrice = variant.Price.DiscountSalePrice
-				productVariant.IsRound = optiongrp.IsRound
-				productVariant.IsSchedule = optiongrp.IsSchedule
-				voucherdisplaycode := make([]string, 0)
-				for _, item := range variant.VariantItems {
-					voucherdisplaycode = append(voucherdisplaycode, item.VoucherDisplayTypeCode)
-				}
-				productVariant.VoucherDisplayCode = voucherdisplaycode
-				productVariants = append(productVariants, productVariant)
-			}
-		}
-		odooproduct.Variants = productVariants
-		odooProducts = append(odooProducts, odooproduct)
-
+		odooProducts = append(odooProducts, productDataForOdooSync(product))
 	}
 
 	upsertedProducts, err := s.mongoRepository[config.Instance().MongoDBName].UpsertOdooProduct(ctx, odooProducts)
@@ -157,7 +85,7 @@ func (s *service) GetProductSync(ctx context.Context) (resp common.Response, err
 	return resp, nil
 }
 
-// GetOrderSync to sync order to odoo
+// GetOrderSync to sync order to odoo, superseded by the cursor based GetOdooSyncChanges
 func (s *service) GetOrderSync(ctx context.Context) (resp common.Response, err error) {
 	requestID := utils.GenerateUUID("GGT", true)
 
@@ -394,6 +322,89 @@ func (s *service) PostChangeOdooSyncStatusToFalse(ctx context.Context) (resp com
 	return resp, nil
 }
 
+// productDataForOdooSync odoo record of the product
+func productDataForOdooSync(product domain.Product) odoo.Product {
+	var addressDetail odoo.FacilityLocationDetail
+	var productVariant odoo.ProductVariant
+	var odooproduct odoo.Product
+
+	odooproduct.ProductID = product.ProductID
+	odooproduct.OdooTemplateId = product.OdooTemplateId
+	odooproduct.OdooProductId = product.OdooProductId
+	odooproduct.ProductName = product.ProductName
+	odooproduct.ProductVersion = product.ProductVersion
+	odooproduct.ProductStatusCode = product.ProductStatusCode
+	odooproduct.ProductTypeCode = product.ProductTypeCode
+	if product.ProductStatusCode == "IN_SALE" && product.IsUsed {
+		odooproduct.ProductValidity = "InValid"
+	} else {
+		odooproduct.ProductValidity = "Not_Valid"
+	}
+
+	facilityInfo := product.ProductInfo.FacilityInfos
+	addressDetails := make([]odoo.FacilityLocationDetail, 0)
+
+	if len(facilityInfo) > 0 {
+		for _, facility := range facilityInfo {
+			addressDetail.Latitude = facility.Location.Latitude
+			addressDetail.Longitude = facility.Location.Longitude
+			addressDetail.Address = facility.Location.Address
+			addressDetails = append(addressDetails, addressDetail)
+		}
+	}
+	odooproduct.FacilityAddress = addressDetails
+
+	var regionInfos []odoo.Region
+	if len(product.Regions) > 0 {
+		regionInfos = FlattenRegions(product.Regions)
+	}
+
+	if len(regionInfos) > 0 {
+		odooproduct.Regions = regionInfos
+	}
+
+	odooproduct.IsIntegratedVoucher = product.IsIntegratedVoucher
+
+	productVariants := make([]odoo.ProductVariant, 0)
+	for _, optiongrp := range product.ProductOptionGroups {
+
+		for _, variant := range optiongrp.Variants {
+			productVariant.VariantID = variant.VariantID
+			productVariant.ProductID = variant.ProductID
+			if !strings.EqualFold(variant.VariantName, "Adult") || !strings.EqualFold(variant.VariantName, "Youth") ||
+				!strings.EqualFold(variant.VariantName, "Child") || !strings.EqualFold(variant.VariantName, "children") {
+				productVariant.VariantName = "N/A"
+			}
+			productVariant.VariantName = variant.VariantName
+			productVariant.VariantDescription = variant.VariantDescription
+			productVariant.RefundApprovalTypeCode = variant.RefundApprovalTypeCode
+			productVariant.IsRefundableAfterExpiration = variant.IsRefundableAfterExpiration
+			productVariant.RefundInfo = variant.RefundInfo
+			productVariant.VariantStatusCode = variant.VariantStatusCode
+			productVariant.OrderExpirationUsageProcessTypeCode = variant.OrderExpirationUsageProcessTypeCode
+			productVariant.OrderExpirationDateTypeCode = variant.OrderExpirationDateTypeCode
+			productVariant.ValidityStartDate = variant.SalePeriod.StartDateTime
+			productVariant.ValidityEndDate = variant.SalePeriod.EndDateTime
+			productVariant.Currency = variant.Price.Currency
+			productVariant.SupplierCostPrice = variant.Price.CostPrice
+			productVariant.SalePrice = variant.Price.SalePrice
+			productVariant.RetailPrice = variant.Price.RetailPrice
+			productVariant.DiscountSalePrice = variant.Price.DiscountSalePrice
+			productVariant.IsRound = optiongrp.IsRound
+			productVariant.IsSchedule = optiongrp.IsSchedule
+			voucherdisplaycode := make([]string, 0)
+			for _, item := range variant.VariantItems {
+				voucherdisplaycode = append(voucherdisplaycode, item.VoucherDisplayTypeCode)
+			}
+			productVariant.VoucherDisplayCode = voucherdisplaycode
+			productVariants = append(productVariants, productVariant)
+		}
+	}
+	odooproduct.Variants = productVariants
+
+	return odooproduct
+}
+
 func FlattenRegions(regions []domain.Regional) []odoo.Region {
 	var result = make([]odoo.Region, 0)
 	for _, region := range regions {
@@ -435,6 +446,7 @@ func oderDataForOdooSync(ctx context.Context, s *service, logger log.Logger, ord
 	var odooRec odoo.Order
 
 	odooRec.OrderID = order.OrderId
+	odooRec.OdooId = order.OdooId
 	odooRec.Supplier = order.Suppliers
 	odooRec.Channel = order.PartnerOrderChannelCode
 	if order.OrderExpired {
@@ -459,8 +471,20 @@ func oderDataForOdooSync(ctx context.Context, s *service, logger log.Logger, ord
 
 	variantlist := make([]odoo.OrderVariant, 0)
 
-	netInfo, markuplist := FindCurrencyCodeAndMarkup(order.SelectVariants, odooRec.Channel)
+	scope := pricing.Scope{
+		Supplier:    order.Suppliers,
+		ChannelCode: odooRec.Channel,
+	}
+	if scope.Supplier == "" {
+		scope.Supplier = constant.SUPPLIERYANOLJA
+	}
+	if bookedAt, err := time.Parse(time.RFC3339, order.CreatedAt); err == nil {
+		scope.At = bookedAt
+	}
+	netInfo, markuplist := FindCurrencyCodeAndMarkup(ctx, s.mongoRepository[config.Instance().MongoDBName], logger, order.SelectVariants, scope)
 	odooRec.SupplierCurrency = netInfo.Currency
+	odooRec.SettlementCurrency = constant.DEFAULTCURRENCY
+	odooRec.SaleAmount, odooRec.CostAmount = SettlementTotals(order.SelectVariants, odooRec.SettlementCurrency)
 
 	for _, ov := range order.OrderVariants {
 
@@ -572,7 +596,62 @@ func oderDataForOdooSync(ctx context.Context, s *service, logger log.Logger, ord
 	return odooRec
 }
 
-func FindCurrencyCodeAndMarkup(selectVariants []domain.SelectVariant, channelCode string) (netPriceInfo odoo.NetPriceDetail, markuplst []odoo.MarkupDetail) {
+// SettlementTotals total sale price and channel cost of the order in the channel currency and in the
+// settlement currency. The original side stays empty when the variants were priced in different
+// currencies, orders booked before conversions were recorded count their sale price at rate 1
+func SettlementTotals(selectVariants []domain.SelectVariant, settlementCurrency string) (sale money.Conversion, cost money.Conversion) {
+	sale.Settlement.Currency = settlementCurrency
+	cost.Settlement.Currency = settlementCurrency
+
+	var saleMixed, costMixed bool
+	add := func(total *money.Conversion, mixed *bool, conversion *money.Conversion, quantity int32) {
+		if conversion == nil {
+			return
+		}
+		total.Settlement.Amount += conversion.Settlement.Amount * float64(quantity)
+		switch {
+		case *mixed:
+		case total.Original.Currency == "":
+			total.Original = money.Money{Amount: conversion.Original.Amount * float64(quantity), Currency: conversion.Original.Currency}
+			total.Rate = conversion.Rate
+			total.RateAsOf = conversion.RateAsOf
+		case total.Original.Currency == conversion.Original.Currency:
+			total.Original.Amount += conversion.Original.Amount * float64(quantity)
+		default:
+			// mixed channel currencies have no meaningful original total
+			*mixed = true
+			total.Original = money.Money{}
+			total.Rate = 0
+			total.RateAsOf = ""
+		}
+	}
+
+	for _, selectvariant := range selectVariants {
+		saleAmount := selectvariant.SaleAmount
+		if saleAmount == nil {
+			currency := selectvariant.Currency
+			if currency == "" {
+				currency = settlementCurrency
+			}
+			saleAmount = &money.Conversion{
+				Original:   money.Money{Amount: float64(selectvariant.PartnerSalePrice), Currency: currency},
+				Settlement: money.Money{Amount: float64(selectvariant.PartnerSalePrice), Currency: currency},
+				Rate:       1,
+			}
+		}
+		add(&sale, &saleMixed, saleAmount, selectvariant.Quantity)
+		add(&cost, &costMixed, selectvariant.CostAmount, selectvariant.Quantity)
+	}
+
+	sale.Settlement.Amount = fx.Round(sale.Settlement.Amount, settlementCurrency)
+	cost.Settlement.Amount = fx.Round(cost.Settlement.Amount, settlementCurrency)
+
+	return sale, cost
+}
+
+// FindCurrencyCodeAndMarkup currency, net price and markup of every selected variant, the markup
+// comes from the pricing rule of the variant within the scope of the order
+func FindCurrencyCodeAndMarkup(ctx context.Context, rules pricing.RuleSource, logger log.Logger, selectVariants []domain.SelectVariant, scope pricing.Scope) (netPriceInfo odoo.NetPriceDetail, markuplst []odoo.MarkupDetail) {
 	var totalcostprice float64 = 0.0
 	var totalsaleprice float64 = 0.0
 	var orderQuantity int = 0
@@ -600,31 +679,22 @@ func FindCurrencyCodeAndMarkup(selectVariants []domain.SelectVariant, channelCod
 		markupstruct.SalePrice = float64(selectvariant.PartnerSalePrice)
 		markupstruct.CostPrice = float64(selectvariant.CostPrice)
 		markupstruct.VariantId = selectvariant.VariantID
-		if channelCode == "GGT_EVERLAND" {
-			price, markup, markuptype := MargineAndNetPriceDetail(selectvariant.ProductID, totalcostprice, selectvariant.Quantity)
-			fmt.Println("========GGT_EVERLAND ======== ", price, markup, markuptype)
-			markupstruct.MarkupType = markuptype
-			markupstruct.MarkupValue = float32(markup)
-			markupstruct.TotalCostPrice = float64(price)
-			markupstruct.Quantity = selectvariant.Quantity
-			markupstruct.TotalSalePrice = totalsaleprice
-			markupstruct.ProductId = selectvariant.ProductID
 
-		} else {
-			markupstruct.MarkupType = constant.PERCENTAGE
-			markupstruct.Quantity = selectvariant.Quantity
-			markupstruct.MarkupValue = constant.MARKUPPERCENTAGE
-			for _, margine := range constant.MARGINEDETAIL {
-				if margine.ProductId == selectvariant.ProductID && margine.MargineType == constant.PERCENTAGE {
-					markupstruct.MarkupValue = margine.Value
-					break
-				}
-			}
-			markupstruct.TotalCostPrice = totalcostprice + (totalcostprice * (float64(markupstruct.MarkupValue / 100)))
-			markupstruct.TotalSalePrice = totalsaleprice
-			markupstruct.ProductId = selectvariant.ProductID
+		scope.ProductId = selectvariant.ProductID
+		scope.VariantId = selectvariant.VariantID
+		rule, err := pricing.Evaluate(ctx, rules, scope)
+		if err != nil {
+			level.Error(logger).Log("error", "pricing rules could not be loaded, default markup used", "err", err)
 		}
 
+		price, markup, markuptype := MargineAndNetPriceDetail(rule, totalcostprice, selectvariant.Quantity)
+		markupstruct.MarkupType = markuptype
+		markupstruct.MarkupValue = markup
+		markupstruct.TotalCostPrice = price
+		markupstruct.Quantity = selectvariant.Quantity
+		markupstruct.TotalSalePrice = totalsaleprice
+		markupstruct.ProductId = selectvariant.ProductID
+
 		orderQuantity += int(selectvariant.Quantity)
 		MarkupList = append(MarkupList, markupstruct)
 	}
@@ -647,23 +717,9 @@ func FindCurrencyCodeAndMarkup(selectVariants []domain.SelectVariant, channelCod
 	return netPriceInfo, MarkupList
 }
 
-func MargineAndNetPriceDetail(productId int64, cost float64, quantity int32) (netPrice float64, margineVal int, margineType string) {
-	for _, margine := range constant.MARGINEDETAIL {
-		margineVal = int(margine.Value)
-		margineType = margine.MargineType
-		if margine.ProductId == productId {
-			switch margine.MargineType {
-			case "FLATVALUE":
-				netPrice = cost + float64(int(margine.Value)*int(quantity))
-			case "PERCENTAGE":
-				mergineval := margine.Value
-				netPrice = cost + (cost * (float64(mergineval / 100)))
-			}
-			break
-		}
-	}
-
-	return netPrice, margineVal, margineType
+// MargineAndNetPriceDetail net price of quantity units costing cost in total with the markup of the rule added
+func MargineAndNetPriceDetail(rule pricingDomain.Rule, cost float64, quantity int32) (netPrice float64, margineVal float32, margineType string) {
+	return pricing.AddMarkup(rule, cost, quantity), float32(rule.Value), rule.MarkupType
 }
 
 func FindMarkupTypeAndValue(data []odoo.MarkupDetail) (markuptype string, markupval float32) {
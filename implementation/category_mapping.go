@@ -0,0 +1,468 @@
+package implementation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	"swallow-supplier/iface"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/categorymap"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/pagination"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// yanoljaCategoryKeys keys of the stored yanolja categories, mappings may only point at those
+func yanoljaCategoryKeys(ctx context.Context, mrepo iface.MongoRepository) (map[string]bool, error) {
+	categories, _, _, err := mrepo.FindCategory(ctx, pagination.Query{})
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		code, err := strconv.Atoi(category.CategoryCode)
+		if err != nil {
+			continue
+		}
+		keys[domain.CategoryKey(code, int(category.CategoryLevel))] = true
+	}
+	return keys, nil
+}
+
+// categoryMappingProblems problems of the mapping which keep it from being stored, the channel
+// of the mapping is filled in when it has none
+func categoryMappingProblems(ctx context.Context, mrepo iface.MongoRepository, mapping *domain.CategoryMapping) ([]string, error) {
+	yanolja, err := yanoljaCategoryKeys(ctx, mrepo)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.SupplierGGTChannel == "" {
+		mapping.SupplierGGTChannel = constant.YANOLJAGGTTRIP
+	}
+	return categorymap.Validate(*mapping, yanolja), nil
+}
+
+// categoryMappingNotFound error for a yanolja category without mapping
+func categoryMappingNotFound(ctx context.Context, code int, categoryLevel int, source string) error {
+	return customError.NewErrorCustom(ctx, "404", fmt.Sprintf("no mapping for yanolja category %d at level %d", code, categoryLevel), "category mapping not found", http.StatusNotFound, source)
+}
+
+// GetCategoryMappings yanolja category mappings, filtered by level and trip category
+func (s *service) GetCategoryMappings(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetCategoryMappings",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	mappings, err := s.mongoRepository[config.Instance().MongoDBName].GetCategoryMappings(ctx, req.Level, req.TripCategory)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching category mappings", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching category mappings, %v", err), "GetCategoryMappings")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = mappings
+
+	return resp, nil
+}
+
+// GetCategoryMapping mapping of one yanolja category
+func (s *service) GetCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetCategoryMapping",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	mapping, err := s.mongoRepository[config.Instance().MongoDBName].GetCategoryMapping(ctx, req.Code, req.Level)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		resp.Code = "404"
+		return resp, categoryMappingNotFound(ctx, req.Code, req.Level, "GetCategoryMapping")
+	}
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching category mapping", "code", req.Code, "level", req.Level, "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching category mapping, %v", err), "GetCategoryMapping")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = mapping
+
+	return resp, nil
+}
+
+// CreateCategoryMapping map a yanolja category which has no mapping yet
+func (s *service) CreateCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "CreateCategoryMapping",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	mapping := req.Mapping
+	problems, err := categoryMappingProblems(ctx, mrepo, &mapping)
+	if err != nil {
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching yanolja categories, %v", err), "FindCategory")
+	}
+	if len(problems) > 0 {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-1016", strings.Join(problems, ", "), "CreateCategoryMapping")
+	}
+
+	inserted, err := mrepo.InsertCategoryMapping(ctx, mapping)
+	if err != nil {
+		level.Error(logger).Log("repository error", "inserting category mapping", "key", mapping.Key(), "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting category mapping, %v", err), "InsertCategoryMapping")
+	}
+	if !inserted {
+		resp.Code = "409"
+		return resp, customError.NewErrorCustom(ctx, "409", fmt.Sprintf("yanolja category %d at level %d is already mapped", mapping.YanoljaCategoryCode, mapping.YanoljaCategoryLevel), "category mapping exists", http.StatusConflict, "CreateCategoryMapping")
+	}
+	level.Info(logger).Log("info", "category mapping created", "key", mapping.Key(), "trip", mapping.TripCategoryName)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = mapping
+
+	return resp, nil
+}
+
+// UpdateCategoryMapping replace the mapping of a yanolja category
+func (s *service) UpdateCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "UpdateCategoryMapping",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	// the path names the category, the body only carries the mapping
+	mapping := req.Mapping
+	mapping.YanoljaCategoryCode, mapping.YanoljaCategoryLevel = req.Code, req.Level
+	problems, err := categoryMappingProblems(ctx, mrepo, &mapping)
+	if err != nil {
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching yanolja categories, %v", err), "FindCategory")
+	}
+	if len(problems) > 0 {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-1016", strings.Join(problems, ", "), "UpdateCategoryMapping")
+	}
+
+	updated, err := mrepo.UpdateCategoryMapping(ctx, mapping)
+	if err != nil {
+		level.Error(logger).Log("repository error", "updating category mapping", "key", mapping.Key(), "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on updating category mapping, %v", err), "UpdateCategoryMapping")
+	}
+	if !updated {
+		resp.Code = "404"
+		return resp, categoryMappingNotFound(ctx, req.Code, req.Level, "UpdateCategoryMapping")
+	}
+	level.Info(logger).Log("info", "category mapping updated", "key", mapping.Key(), "trip", mapping.TripCategoryName)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = mapping
+
+	return resp, nil
+}
+
+// DeleteCategoryMapping remove the mapping of a yanolja category, products of the category reach
+// trip without it afterwards
+func (s *service) DeleteCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "DeleteCategoryMapping",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	deleted, err := s.mongoRepository[config.Instance().MongoDBName].DeleteCategoryMapping(ctx, req.Code, req.Level)
+	if err != nil {
+		level.Error(logger).Log("repository error", "deleting category mapping", "code", req.Code, "level", req.Level, "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on deleting category mapping, %v", err), "DeleteCategoryMapping")
+	}
+	if !deleted {
+		resp.Code = "404"
+		return resp, categoryMappingNotFound(ctx, req.Code, req.Level, "DeleteCategoryMapping")
+	}
+	level.Info(logger).Log("info", "category mapping deleted", "code", req.Code, "level", req.Level)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = map[string]any{"code": req.Code, "level": req.Level, "deleted": true}
+
+	return resp, nil
+}
+
+// UploadCategoryMappings read a mapping sheet and diff it against the stored mappings. Unless it
+// is a dry run the added and changed mappings are stored, but only when every row is valid.
+// Mappings missing from the sheet are kept
+func (s *service) UploadCategoryMappings(ctx context.Context, req common.CategoryMappingUploadRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "UploadCategoryMappings",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	rows, err := categorymap.ReadRows(req.FileName, req.Content)
+	if err != nil {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-1016", err.Error(), "UploadCategoryMappings")
+	}
+	entries, unreadable, err := categorymap.Parse(rows)
+	if err != nil {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-1016", err.Error(), "UploadCategoryMappings")
+	}
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	yanolja, err := yanoljaCategoryKeys(ctx, mrepo)
+	if err != nil {
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching yanolja categories, %v", err), "FindCategory")
+	}
+	stored, err := mrepo.GetCategoryMappings(ctx, 0, "")
+	if err != nil {
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching category mappings, %v", err), "GetCategoryMappings")
+	}
+
+	diff := categorymap.Compare(stored, entries, yanolja)
+	diff.Invalid = append(unreadable, diff.Invalid...)
+
+	result := common.CategoryMappingUploadResult{DryRun: req.DryRun, Diff: diff}
+	if !req.DryRun && len(diff.Invalid) == 0 {
+		changes := make([]domain.CategoryMapping, 0, len(diff.Added)+len(diff.Changed))
+		changes = append(changes, diff.Added...)
+		for _, change := range diff.Changed {
+			changes = append(changes, change.After)
+		}
+
+		result.Counts, err = mrepo.UpsertCategoryMappings(ctx, changes)
+		if err != nil {
+			level.Error(logger).Log("repository error", "storing category mappings", "error", err)
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on storing category mappings, %v", err), "UpsertCategoryMappings")
+		}
+		result.Applied = true
+	}
+	level.Info(logger).Log("info", "category mapping upload", "file", req.FileName, "dryRun", req.DryRun, "applied", result.Applied,
+		"added", len(diff.Added), "changed", len(diff.Changed), "invalid", len(diff.Invalid), "missing", len(diff.Missing))
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = result
+
+	return resp, nil
+}
+
+// ExportCategoryMappings stored mappings as a mapping sheet, xlsx unless csv is asked for
+func (s *service) ExportCategoryMappings(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "ExportCategoryMappings",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = "xlsx"
+	}
+	if format != "xlsx" && format != "csv" {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-1016", "format must be xlsx or csv", "ExportCategoryMappings")
+	}
+
+	mappings, err := s.mongoRepository[config.Instance().MongoDBName].GetCategoryMappings(ctx, req.Level, req.TripCategory)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching category mappings", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching category mappings, %v", err), "GetCategoryMappings")
+	}
+
+	file := common.File{Name: "category-mapping." + format}
+	var buf bytes.Buffer
+	if format == "csv" {
+		file.ContentType = "text/csv; charset=utf-8"
+		err = categorymap.WriteCSV(&buf, mappings)
+	} else {
+		file.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = categorymap.WriteXLSX(&buf, mappings)
+	}
+	if err != nil {
+		resp.Code = "500"
+		return resp, err
+	}
+	file.Content = buf.Bytes()
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = file
+
+	return resp, nil
+}
+
+// GetUnmappedCategoryProducts products with categories which have no trip mapping
+func (s *service) GetUnmappedCategoryProducts(ctx context.Context) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetUnmappedCategoryProducts",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	products, err := s.mongoRepository[config.Instance().MongoDBName].GetUnmappedCategoryProducts(ctx)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching products without category mapping", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching products without category mapping, %v", err), "GetUnmappedCategoryProducts")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = products
+
+	return resp, nil
+}
@@ -0,0 +1,130 @@
+package implementation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	babel "swallow-supplier/Babel"
+	customError "swallow-supplier/error"
+	"swallow-supplier/request_response/common"
+	tripservice "swallow-supplier/services/distributors/trip"
+	"swallow-supplier/services/pdfvoucher"
+	travolutionsvc "swallow-supplier/services/suppliers/travolution"
+	yanoljasvc "swallow-supplier/services/suppliers/yanolja"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/client"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// breakerServices upstream services guarded by a circuit breaker
+var breakerServices = []string{
+	yanoljasvc.ServiceName,
+	travolutionsvc.ServiceName,
+	tripservice.ServiceName,
+	babel.ServiceName,
+	pdfvoucher.ServiceName,
+}
+
+// GetCircuitBreakers state and counts of the breaker of every upstream service
+func (s *service) GetCircuitBreakers(ctx context.Context) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetCircuitBreakers",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	manager := client.GetBreakerManager()
+
+	states := make([]client.BreakerState, 0, len(breakerServices))
+	known := make(map[string]bool)
+	for _, name := range breakerServices {
+		known[name] = true
+		states = append(states, manager.State(name))
+	}
+	// breakers of services outside the list, e.g. created by ad hoc clients
+	for _, state := range manager.States() {
+		if !known[state.Name] {
+			states = append(states, state)
+		}
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = states
+
+	return resp, nil
+}
+
+// UpdateCircuitBreaker force the breaker of a service open or closed during supplier incidents, the
+// mode is shared with every replica through the cache
+func (s *service) UpdateCircuitBreaker(ctx context.Context, req common.CircuitBreakerRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "UpdateCircuitBreaker",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	serviceName := ""
+	for _, name := range breakerServices {
+		if strings.EqualFold(name, req.Service) {
+			serviceName = name
+		}
+	}
+	if serviceName == "" {
+		resp.Code = "400"
+		resp.Status = http.StatusBadRequest
+		return resp, customError.NewError(ctx, "leisure-api-1018", fmt.Sprintf("no circuit breaker for service %q", req.Service), "UpdateCircuitBreaker")
+	}
+
+	state, err := client.GetBreakerManager().Force(ctx, serviceName, strings.ToUpper(req.Mode))
+	if errors.Is(err, client.ErrInvalidBreakerMode) {
+		resp.Code = "400"
+		resp.Status = http.StatusBadRequest
+		return resp, customError.NewError(ctx, "leisure-api-1016", err.Error(), "UpdateCircuitBreaker")
+	}
+	if err != nil {
+		level.Error(logger).Log("error", "storing circuit breaker mode", "service", serviceName, "err", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-0005", err.Error(), "UpdateCircuitBreaker")
+	}
+
+	level.Info(logger).Log("info", "circuit breaker mode changed", "service", serviceName, "mode", req.Mode)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = state
+
+	return resp, nil
+}
@@ -94,6 +94,10 @@ func (s *service) ProductSyncToTrip(ctx context.Context) (resp yanolja.Response,
 			resp.Code = "500"
 			return resp, fmt.Errorf("repository error: %v", err)
 		}
+		if len(categoryFilters) > 0 && len(tripCategories) == 0 {
+			// listed by GET /v1/category-mappings/unmapped until its categories are mapped
+			level.Warn(logger).Log("msg", "no trip category mapped for the product categories", "productId", productId, "categories", len(categoryFilters))
+		}
 
 		var categories []trip.ProductCategory
 		for _, name := range tripCategories {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"swallow-supplier/caches/cache"
@@ -15,6 +17,7 @@ import (
 	"swallow-supplier/request_response/yanolja"
 	"swallow-supplier/scheduler/cronjob"
 	"swallow-supplier/utils"
+	"swallow-supplier/utils/pagination"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -31,8 +34,43 @@ var PLUMap = map[string]string{
 	"h4G8gI59sq5fBK7": "10177758|43|11961178||",
 }
 
-// to manual test redis issue
-func (s *service) GetRedisData(ctx context.Context) (resp yanolja.Response, err error) {
+// redisEntry cached value of a key
+type redisEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// redisKeyPage keys of the page of the query from the sorted keys and the cursor of the next page
+func redisKeyPage(keys []string, q pagination.Query) ([]string, string, error) {
+	start := int(q.Skip())
+	if after, ok := q.After.(string); ok && q.CursorMode {
+		start = sort.Search(len(keys), func(i int) bool {
+			if q.Descending() {
+				return keys[i] < after
+			}
+			return keys[i] > after
+		})
+	}
+	if start > len(keys) {
+		start = len(keys)
+	}
+	end := len(keys)
+	if q.RecordsPerPage > 0 && start+int(q.RecordsPerPage) < end {
+		end = start + int(q.RecordsPerPage)
+	}
+
+	var next string
+	if q.CursorMode && end < len(keys) {
+		var err error
+		if next, err = pagination.EncodeCursor(keys[end-1]); err != nil {
+			return nil, "", err
+		}
+	}
+	return keys[start:end], next, nil
+}
+
+// to manual test redis issue, one page of the cached keys matching the pattern filter
+func (s *service) GetRedisData(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error) {
 
 	var requestID = utils.GenerateUUID("GGT", true)
 
@@ -62,15 +100,30 @@ func (s *service) GetRedisData(ctx context.Context) (resp yanolja.Response, err
 		resp.Code = "500"
 		return resp, err
 	}
+	pattern := "*"
+	if p, ok := q.Filters["pattern"].(string); ok {
+		pattern = p
+	}
 	var keyary []string
-	keyary, err = cacheLayer.Keys(ctx, "*")
+	keyary, err = cacheLayer.Keys(ctx, pattern)
 	if err != nil {
 		resp.Code = "500"
 		return resp, err
 	}
 
-	redisdata := make(map[string]string, len(keyary))
-	for _, key := range keyary {
+	// keys are sorted here, redis returns them in no order
+	sort.Strings(keyary)
+	if q.Descending() {
+		slices.Reverse(keyary)
+	}
+	page, next, err := redisKeyPage(keyary, q)
+	if err != nil {
+		resp.Code = "500"
+		return resp, err
+	}
+
+	redisdata := make([]redisEntry, 0, len(page))
+	for _, key := range page {
 		fmt.Println("--------------------------key----------------------- ", key)
 		strd, err := cacheLayer.Get(ctx, key)
 		fmt.Println("**************************************** : ", strd)
@@ -79,10 +132,10 @@ func (s *service) GetRedisData(ctx context.Context) (resp yanolja.Response, err
 			level.Error(logger).Log("error", fmt.Sprintf("Error in accessing get function of cache: %s", err))
 			continue
 		}
-		redisdata[key] = strd
+		redisdata = append(redisdata, redisEntry{Key: key, Value: strd})
 	}
 	resp.Code = "200"
-	resp.Body = redisdata
+	resp.Body = listBody(ctx, q, redisdata, int64(len(keyary)), next)
 	return resp, nil
 
 }
@@ -230,7 +283,7 @@ func (s *service) MonitorProductUpdateSvc(ctx context.Context) (resp yanolja.Res
 
 	}(ctx)
 
-	err = cronjob.MonitorProductUpdates(ctx, s.mongoRepository[config.Instance().MongoDBName], logger)
+	_, err = cronjob.MonitorProductUpdates(ctx, s.mongoRepository[config.Instance().MongoDBName], logger)
 	if err != nil {
 		level.Error(logger).Log("error", fmt.Sprintf(" issue in running monitor product updates cron job : %s", err))
 		resp.Code = "500"
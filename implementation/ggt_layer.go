@@ -16,8 +16,10 @@ import (
 	"swallow-supplier/request_response/common"
 	"swallow-supplier/request_response/trip"
 	"swallow-supplier/request_response/yanolja"
-	"swallow-supplier/services/pdfvoucher"
+	"swallow-supplier/utils/cancellation"
 	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
+	"swallow-supplier/utils/pagination"
 
 	"time"
 
@@ -28,6 +30,8 @@ import (
 
 // PostRequestFromGGT request all request from trip
 func (s *service) PostRequestFromGGT(ctx context.Context, req trip.SwallowRequest) (resp yanolja.Response, err error) {
+	// status changes made for the request are recorded as coming from trip
+	ctx = orderstate.WithSource(ctx, orderstate.SourceTrip)
 
 	logger := log.With(
 		s.logger,
@@ -51,6 +55,15 @@ func (s *service) PostRequestFromGGT(ctx context.Context, req trip.SwallowReques
 	mrepo := s.mongoRepository[config.Instance().MongoDBName]
 	level.Info(logger).Log("serviceName", serviceName)
 
+	supplierName, err := s.resolveTripSupplier(ctx, logger, cacheLayer, req.DecryptedBody)
+	if err != nil {
+		resp.Code = "500"
+		return resp, err
+	}
+	if supplierName == constant.SUPPLIERTRAVOLUTION {
+		return s.postTravolutionRequestFromGGT(ctx, logger, serviceName, req)
+	}
+
 	switch serviceName {
 	case "CreatePreOrder":
 		var preorderRequest trip.PreorderRequest
@@ -239,6 +252,16 @@ func (s *service) PostRequestFromGGT(ctx context.Context, req trip.SwallowReques
 			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by orderId, %v", err), "GetOrderbyPartnerOrderId")
 		}
 
+		// the booked variants are quoted, a pre order has no order variants yet
+		quote, err := quoteRefund(ctx, s, record, nil)
+		if err != nil {
+			level.Error(logger).Log("error", "quoting refund of pre order ", err)
+		} else if err = s.mongoRepository[config.Instance().MongoDBName].UpdateRefundQuote(ctx, record.OrderId, quote); err != nil {
+			level.Error(logger).Log("repository error", "storing refund quote of pre order ", err)
+		} else {
+			record.RefundQuote = &quote
+		}
+
 		resp.Code = "200"
 		resp.Body = record
 
@@ -301,8 +324,9 @@ func (s *service) PostRequestFromGGT(ctx context.Context, req trip.SwallowReques
 		}
 		fmt.Println("CancelOrder 74")
 
-		for i, item := range fullCancelOrder.Items {
-			variantId, productversion, productid, err := GetVariantIdFromPlu(ctx, logger, item.PLU)
+		units := make([]cancellation.Unit, 0, len(fullCancelOrder.Items))
+		for _, item := range fullCancelOrder.Items {
+			variantId, _, productid, err := GetVariantIdFromPlu(ctx, logger, item.PLU)
 			if err != nil {
 				resp.Code = "500" // 500
 				resp.Body = err.Error()
@@ -310,18 +334,28 @@ func (s *service) PostRequestFromGGT(ctx context.Context, req trip.SwallowReques
 			}
 			fmt.Println("CancelOrder 75")
 
-			if order.SelectVariants[i].VariantID == variantId &&
-				order.SelectVariants[i].ProductVersion == productversion &&
-				order.SelectVariants[i].ProductID == productid &&
-				order.SelectVariants[i].Quantity != int32(item.Quantity) {
+			units = append(units, cancellation.Unit{ProductID: productid, VariantID: variantId, Quantity: item.Quantity})
+		}
+
+		// items naming fewer units than the order has cancel only those variants
+		var orderVariantIds []int64
+		if len(order.OrderVariants) > 0 {
+			orderVariantIds, err = cancellation.Select(order, units)
+			if err != nil {
 				resp.Code = "400" //400
-				resp.Body = fmt.Errorf("order quantity and cancel quantity donot match ")
-				return resp, customError.NewError(ctx, "leisure-api-00023", fmt.Sprintf("requested cancel quantity %d not match with order quantity %d", int32(item.Quantity), order.SelectVariants[i].Quantity), "PostRequestFromGGT")
+				resp.Body = err.Error()
+				return resp, customError.NewError(ctx, "leisure-api-00023", err.Error(), "PostRequestFromGGT")
 			}
+		}
 
+		var detail yanolja.Response
+		if orderVariantIds == nil || cancellation.Full(order, orderVariantIds) {
+			level.Info(logger).Log("method call", "PostCancelOrderEntirly")
+			detail, err = s.PostCancelOrderEntirly(ctx, orderId)
+		} else {
+			level.Info(logger).Log("method call", "PostCancelOrderVariants")
+			detail, err = s.PostCancelOrderVariants(ctx, orderId, orderVariantIds)
 		}
-		level.Info(logger).Log("method call", "PostCancelOrderEntirly")
-		detail, err := s.PostCancelOrderEntirly(ctx, orderId)
 		fmt.Println("CancelOrder 76")
 
 		if err != nil {
@@ -332,11 +366,21 @@ func (s *service) PostRequestFromGGT(ctx context.Context, req trip.SwallowReques
 		}
 		fmt.Println("CancelOrder 77")
 
-		err = s.mongoRepository[config.Instance().MongoDBName].InsertFullCancelOrderRequestFromTrip(ctx, fullCancelOrder)
+		if orderVariantIds == nil || cancellation.Full(order, orderVariantIds) {
+			err = s.mongoRepository[config.Instance().MongoDBName].InsertFullCancelOrderRequestFromTrip(ctx, fullCancelOrder)
+		} else {
+			err = s.mongoRepository[config.Instance().MongoDBName].InsertPartialCancelOrderRequestFromTrip(ctx, fullCancelOrder)
+			if err == nil {
+				// an order may be partially canceled more than once, the callback answers the latest request
+				if cerr := cacheLayer.Set(ctx, fullCancelOrder.OTAOrderID+"-"+constant.TRIPPARTIALCANCELREQUEST, fullCancelOrder.SequenceID); cerr != nil {
+					level.Error(logger).Log("cache error", "setting partial cancel sequence id", "err", cerr)
+				}
+			}
+		}
 		if err != nil {
-			level.Error(logger).Log("repository error", "inserting trip full cancel order request ")
+			level.Error(logger).Log("repository error", "inserting trip cancel order request ")
 			resp.Code = "500"
-			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting trip full cancel order request, %v", err), "InsertPaymentRequestFromTrip")
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting trip cancel order request, %v", err), "InsertPaymentRequestFromTrip")
 		}
 
 		resp.Body = detail.Body
@@ -404,8 +448,9 @@ func (s *service) PostRequestFromGGT(ctx context.Context, req trip.SwallowReques
 	return resp, nil
 }
 
-// GetProductsFromGGT  return  All product to channel based on listed productId
-func (s *service) GetProductsFromGGT(ctx context.Context) (resp yanolja.Response, err error) {
+// GetProductsFromGGT  return All product to channel, or one page of them with the total and page links
+// when a page is asked for
+func (s *service) GetProductsFromGGT(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error) {
 	logger := log.With(
 		s.logger,
 		"method", "GetProductsFromGGT",
@@ -423,14 +468,14 @@ func (s *service) GetProductsFromGGT(ctx context.Context) (resp yanolja.Response
 
 	}(ctx)
 
-	products, err := s.mongoRepository[config.Instance().MongoDBName].GetAllProductViews(ctx)
+	products, total, next, err := s.mongoRepository[config.Instance().MongoDBName].GetAllProductViews(ctx, q)
 	if err != nil {
 		level.Error(logger).Log("repository error", "no product exist based on productId ", "error ", err)
 		resp.Code = "500"
 		return resp, customError.NewError(ctx, resp.Code, fmt.Sprintf("repository error on fetching product by productId, %v", err), "GetProductByProductId")
 
 	}
-	resp.Body = products
+	resp.Body = unpagedListBody(ctx, q, products, total, next)
 	resp.Code = "200"
 
 	return resp, nil
@@ -617,22 +662,23 @@ func VoucherDataCreator(ctx context.Context, logger log.Logger, s *service, data
 func UpdateVoucherPdf(ctx context.Context, logger log.Logger, s *service, voucherReq []common.PdfVoucherRequest, partnerOrderId string) {
 	level.Info(logger).Log("request data UpdateVoucherPdf", voucherReq)
 
-	pdfVoucherSvc, _ := pdfvoucher.New(ctx)
-	res, err := pdfVoucherSvc.NotifyToVoucherPdfUpdate(ctx, voucherReq)
-	level.Info(logger).Log("error in pdf voucher ", err)
-	if err != nil {
-		level.Error(logger).Log("error", "pdf voucher call error ")
+	// the voucher is generated by the outbox drain, the callback does not wait for it
+	if _, err := enqueueNotification(ctx, logger, s, constant.OutboxKindPdfVoucher, "PdfVoucherCreate", partnerOrderId, "", voucherReq); err != nil {
+		level.Error(logger).Log("error", "pdf voucher outbox error ")
 		_, err := s.PostForcelyCancelOrder(ctx, partnerOrderId)
 		if err != nil {
 			level.Error(logger).Log("error", "yanolja forced cancellation error")
-			return
 		}
-		return
 	}
+}
+
+// handleVoucherPdfResponse notify trip once the voucher generator stored the voucher in the order
+func handleVoucherPdfResponse(ctx context.Context, logger log.Logger, s *service, res yanolja.Response, partnerOrderId string) {
 	level.Info(logger).Log("response from pdfVoucher generator", res.Body, reflect.TypeOf(res.Body))
 
 	var respBody map[string]interface{}
-	if err := json.Unmarshal([]byte(res.Body.(string)), &respBody); err != nil {
+	body, _ := res.Body.(string)
+	if err := json.Unmarshal([]byte(body), &respBody); err != nil {
 		level.Error(logger).Log("Error decoding JSON:", err)
 
 		_, err := s.PostForcelyCancelOrder(ctx, partnerOrderId)
@@ -663,10 +709,8 @@ func UpdateVoucherPdf(ctx context.Context, logger log.Logger, s *service, vouche
 
 		level.Info(logger).Log("order data ", order)
 
-		level.Info(logger).Log("info ", "call to trip")
-
 		// call to trip
-		level.Info(logger).Log("info ", "$$$$$$$$$$$$$$$$$$$$$ call to trip to process voucher #########################")
+		level.Info(logger).Log("info ", "call to trip to process voucher")
 		processTripNotification(ctx, logger, s, "VoucherUpdateNotify", order, constant.TRIPPAYMENTREQUEST)
 	}
 
@@ -0,0 +1,392 @@
+package implementation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"swallow-supplier/caches/cache"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	travolutionMapper "swallow-supplier/mapper/trip_to_travolution"
+	travolutionDomain "swallow-supplier/mongo/domain/travolution"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/trip"
+	"swallow-supplier/request_response/yanolja"
+	"swallow-supplier/utils/constant"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// tripOrderReference fields shared by all trip request bodies which identify the owning supplier
+type tripOrderReference struct {
+	OtaOrderID string `json:"otaOrderId"`
+	Items      []struct {
+		PLU string `json:"PLU"`
+	} `json:"items"`
+}
+
+// resolveTripSupplier finds the supplier of a trip request from the plu of its items, requests
+// without a resolvable plu use the supplier stored for the otaOrderId when the pre order was made
+func (s *service) resolveTripSupplier(ctx context.Context, logger log.Logger, cacheLayer cache.Cache, body string) (string, error) {
+	var ref tripOrderReference
+	if err := json.Unmarshal([]byte(body), &ref); err != nil {
+		return "", customError.NewError(ctx, "leisure-api-1019", fmt.Sprintf("unmarshaling error %v", err), "resolveTripSupplier")
+	}
+
+	var supplierName string
+	for _, item := range ref.Items {
+		pluVal, err := cacheLayer.Get(ctx, item.PLU)
+		if err != nil || pluVal == "" {
+			continue
+		}
+
+		itemSupplier := constant.SUPPLIERYANOLJA
+		if travolutionMapper.IsTravolutionPlu(pluVal) {
+			itemSupplier = constant.SUPPLIERTRAVOLUTION
+		}
+
+		if supplierName != "" && supplierName != itemSupplier {
+			level.Error(logger).Log("error", "items of the order belong to different suppliers")
+			return "", customError.NewError(ctx, "leisure-api-0006", "items of one order cannot belong to different suppliers", "resolveTripSupplier")
+		}
+		supplierName = itemSupplier
+	}
+
+	if supplierName != "" {
+		return supplierName, nil
+	}
+
+	if ref.OtaOrderID != "" {
+		stored, err := cacheLayer.Get(ctx, tripOrderSupplierKey(ref.OtaOrderID))
+		if err == nil && stored != "" {
+			return stored, nil
+		}
+	}
+
+	return constant.SUPPLIERYANOLJA, nil
+}
+
+// tripOrderSupplierKey cache key of the supplier of the trip order, only orders of suppliers other
+// than yanolja have one
+func tripOrderSupplierKey(otaOrderId string) string {
+	return otaOrderId + "-" + constant.TRIPORDERSUPPLIER
+}
+
+// postTravolutionRequestFromGGT handles trip requests of travolution products, responses keep the trip response shape
+func (s *service) postTravolutionRequestFromGGT(ctx context.Context, logger log.Logger, serviceName string, req trip.SwallowRequest) (resp yanolja.Response, err error) {
+	logger = log.With(logger, "supplier", constant.SUPPLIERTRAVOLUTION)
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	switch serviceName {
+	case "CreatePreOrder":
+		var preorderRequest trip.PreorderRequest
+		if err := json.Unmarshal([]byte(req.DecryptedBody), &preorderRequest); err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1019", fmt.Sprintf("unmarshaling error %v", err), "PostRequestFromGGT")
+		}
+
+		orderReq, _, plus, err := travolutionMapper.PreOrderMapper(ctx, mrepo, logger, preorderRequest)
+		if err != nil {
+			resp.Code = "500"
+			return resp, err
+		}
+
+		// For checking duplicate request
+		sequenceIdExist, _ := mrepo.GetSequenceIDByOtaOrderIDAndRequestCategory(ctx, preorderRequest.OtaOrderID, serviceName)
+		if sequenceIdExist {
+			order, err := s.travolutionOrderOfTrip(ctx, logger, preorderRequest.OtaOrderID)
+			if err != nil {
+				resp.Code = "500"
+				return resp, err
+			}
+			resp.Code = "200"
+			resp.Body = trip.CreatePreOrder{
+				PLU:   plus,
+				Order: travolutionOrderToModel(order),
+			}
+			return resp, nil
+		}
+
+		created, err := s.CreateTravolutionOrder(ctx, orderReq)
+		if err != nil {
+			resp.Code = "500"
+			resp.Body = err.Error()
+			return resp, err
+		}
+
+		err = mrepo.InsertPreorderRequestFromTrip(ctx, preorderRequest)
+		if err != nil {
+			level.Error(logger).Log("repository error", "inserting trip priorder request ")
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting trip priorder request, %v", err), "InsertPreorderRequestFromTrip")
+		}
+
+		order, _ := created.Body.(travolutionDomain.Order)
+
+		// travolution issues the order on creation, it is held until trip pays and canceled by
+		// ExpireHeldOrders when trip never does
+		order.HeldUntil = time.Now().UTC().Add(config.Instance().HoldWindow(constant.SUPPLIERTRAVOLUTION)).Format(time.RFC3339)
+		if err = mrepo.UpdateOrderByOrderNumber(ctx, order.OrderNumber, map[string]any{"heldUntil": order.HeldUntil}); err != nil {
+			level.Error(logger).Log("repository error", "holding travolution order", "err", err)
+		}
+
+		cacheLayer, err := cache.New(config.Instance().CacheName)
+		if err == nil {
+			err = cacheLayer.Set(ctx, tripOrderSupplierKey(preorderRequest.OtaOrderID), constant.SUPPLIERTRAVOLUTION)
+		}
+		if err != nil {
+			level.Error(logger).Log("error", "storing supplier of the trip order", "err", err)
+		}
+
+		resp.Body = trip.CreatePreOrder{
+			PLU:   plus,
+			Order: travolutionOrderToModel(order),
+		}
+
+	case "PayPreOrder":
+		// travolution orders are issued on creation, payment only acknowledges the items
+		var confirmTicket trip.PreOrderPaymentRequest
+		if err := json.Unmarshal([]byte(req.DecryptedBody), &confirmTicket); err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1019", fmt.Sprintf("unmarshaling error %v", err), "PostRequestFromGGT")
+		}
+
+		order, err := s.travolutionOrderOfTrip(ctx, logger, confirmTicket.OtaOrderId)
+		if err != nil {
+			resp.Code = "404"
+			return resp, err
+		}
+
+		if isTravolutionOrderCanceled(order.Status) {
+			resp.Code = "400"
+			resp.Body = fmt.Sprintf("orderNumber %s is already canceled", order.OrderNumber)
+			return resp, customError.NewError(ctx, "leisure-api-0006", fmt.Sprintf("orderNumber %s is already canceled", order.OrderNumber), "PostRequestFromGGT")
+		}
+
+		model := travolutionOrderToModel(order)
+		itemAry := make([]domain.Item, 0, len(confirmTicket.Items))
+		for _, item := range confirmTicket.Items {
+			itemAry = append(itemAry, domain.Item{
+				ItemId: item.ItemId,
+				PLU:    item.PLU,
+			})
+		}
+
+		err = mrepo.InsertPaymentRequestFromTrip(ctx, confirmTicket)
+		if err != nil {
+			level.Error(logger).Log("repository error", "inserting trip payment confirm request ")
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting trip payment confirm request, %v", err), "InsertPaymentRequestFromTrip")
+		}
+
+		if err = mrepo.ReleaseTravolutionHold(ctx, order.OrderNumber); err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on releasing travolution hold, %v", err), "ReleaseTravolutionHold")
+		}
+
+		resp.Body = trip.TripResponse{
+			Items: domain.ItemIdDetails{
+				OrderId: model.OrderId,
+				Items:   itemAry,
+			},
+			Order: model,
+		}
+
+	case "CancelPreOrder":
+		var timeoutCancel trip.PreOrderTimeoutCancellation
+		if err := json.Unmarshal([]byte(req.DecryptedBody), &timeoutCancel); err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1019", fmt.Sprintf("unmarshaling error %v", err), "PostRequestFromGGT")
+		}
+
+		resp.Body, err = s.cancelTravolutionOrderFromGGT(ctx, logger, timeoutCancel.OtaOrderId)
+		if err != nil {
+			resp.Code = "500"
+			return resp, err
+		}
+
+	case "ForcedCancelOrder":
+		var forcedCancel trip.CancellationRequest
+		if err := json.Unmarshal([]byte(req.DecryptedBody), &forcedCancel); err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1019", fmt.Sprintf("unmarshaling error %v", err), "PostRequestFromGGT")
+		}
+
+		resp.Body, err = s.cancelTravolutionOrderFromGGT(ctx, logger, forcedCancel.OTAOrderID)
+		if err != nil {
+			resp.Code = "500"
+			return resp, err
+		}
+
+	case "CancelOrder":
+		var fullCancelOrder trip.CancellationRequest
+		if err := json.Unmarshal([]byte(req.DecryptedBody), &fullCancelOrder); err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1019", fmt.Sprintf("unmarshaling error %v", err), "PostRequestFromGGT")
+		}
+
+		order, err := s.travolutionOrderOfTrip(ctx, logger, fullCancelOrder.OTAOrderID)
+		if err != nil {
+			resp.Code = "404"
+			return resp, err
+		}
+
+		// travolution only cancels the whole order
+		var orderQuantity, cancelQuantity int
+		for _, unit := range order.UnitAmounts {
+			orderQuantity += unit.Amount
+		}
+		for _, item := range fullCancelOrder.Items {
+			cancelQuantity += item.Quantity
+		}
+		if orderQuantity != cancelQuantity {
+			resp.Code = "400"
+			resp.Body = "order quantity and cancel quantity donot match"
+			return resp, customError.NewError(ctx, "leisure-api-00023", fmt.Sprintf("requested cancel quantity %d not match with order quantity %d", cancelQuantity, orderQuantity), "PostRequestFromGGT")
+		}
+
+		resp.Body, err = s.cancelTravolutionOrderFromGGT(ctx, logger, fullCancelOrder.OTAOrderID)
+		if err != nil {
+			resp.Code = "500"
+			return resp, err
+		}
+
+		err = mrepo.InsertFullCancelOrderRequestFromTrip(ctx, fullCancelOrder)
+		if err != nil {
+			level.Error(logger).Log("repository error", "inserting trip full cancel order request ")
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting trip full cancel order request, %v", err), "InsertFullCancelOrderRequestFromTrip")
+		}
+
+	case "QueryOrder":
+		var orderInquiry trip.OrderInquiry
+		if err := json.Unmarshal([]byte(req.DecryptedBody), &orderInquiry); err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1019", fmt.Sprintf("unmarshaling error %v", err), "PostRequestFromGGT")
+		}
+
+		order, err := s.travolutionOrderOfTrip(ctx, logger, orderInquiry.OTAOrderID)
+		if err != nil {
+			resp.Code = "404"
+			return resp, err
+		}
+
+		resp.Body = trip.TripResponse{
+			Order: travolutionOrderToModel(order),
+		}
+
+	default:
+		level.Error(logger).Log("Unknown serviceName", serviceName)
+		return resp, nil
+	}
+
+	level.Info(logger).Log("response to trip ", resp)
+	resp.Code = "200"
+	return resp, nil
+}
+
+// cancelTravolutionOrderFromGGT cancels the travolution order of the otaOrderId, an already canceled order is returned as it is
+func (s *service) cancelTravolutionOrderFromGGT(ctx context.Context, logger log.Logger, otaOrderId string) (tripresp trip.TripResponse, err error) {
+	order, err := s.travolutionOrderOfTrip(ctx, logger, otaOrderId)
+	if err != nil {
+		return tripresp, err
+	}
+
+	if !isTravolutionOrderCanceled(order.Status) {
+		canceled, err := s.CancelTravolutionOrder(ctx, order.OrderNumber)
+		if err != nil {
+			level.Error(logger).Log("error", "travolution cancellation failed ", err)
+			return tripresp, err
+		}
+		if canceledOrder, ok := canceled.Body.(travolutionDomain.Order); ok {
+			order = canceledOrder
+		}
+	}
+
+	tripresp.Order = travolutionOrderToModel(order)
+	return tripresp, nil
+}
+
+// travolutionOrderOfTrip fetch travolution order by trip otaOrderId
+func (s *service) travolutionOrderOfTrip(ctx context.Context, logger log.Logger, otaOrderId string) (order travolutionDomain.Order, err error) {
+	order, err = s.mongoRepository[config.Instance().MongoDBName].GetOrderByReferenceNumber(ctx, otaOrderId)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			level.Error(logger).Log("repository error", "no travolution order exist based on otaOrderId ", otaOrderId)
+			return order, customError.NewErrorCustom(ctx, "404", fmt.Sprintf("no travolution order exist with otaOrderId %s", otaOrderId), "", http.StatusNotFound, "GetOrderByReferenceNumber")
+		}
+		level.Error(logger).Log("repository error", "fetching travolution order by otaOrderId ", err)
+		return order, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching travolution order by otaOrderId, %v", err), "GetOrderByReferenceNumber")
+	}
+	return order, nil
+}
+
+// isTravolutionOrderCanceled order status is either cancel requested or canceled
+func isTravolutionOrderCanceled(status string) bool {
+	switch status {
+	case constant.ORDERCANCELREQUEST, constant.ORDERCANCELED, constant.OrderStatusCancelRequest, constant.OrderStatusCanceled:
+		return true
+	}
+	return false
+}
+
+// travolutionOrderStatusCode maps travolution order status into the order status codes used towards trip
+func travolutionOrderStatusCode(status string) string {
+	switch status {
+	case constant.ORDERAVAILABLE, constant.OrderStatusAvailable, constant.BOOKINGPENDING:
+		return constant.ORDERCOMPLETE
+	case constant.ORDERAPPROVED, constant.OrderStatusApproved:
+		return constant.ORDERDONE
+	case constant.ORDERCANCELREQUEST, constant.OrderStatusCancelRequest:
+		return constant.ORDERVARIANTCANCELINGSTATUS
+	case constant.ORDERCANCELED, constant.OrderStatusCanceled, constant.OrderStatusRejected:
+		return constant.ORDERVARIANTCANCELEDSTATUS
+	case constant.ORDEREXPIRED, constant.OrderStatusExpired:
+		return constant.EXPIREDSTAUS
+	}
+	return status
+}
+
+// travolutionOrderToModel projects a travolution order onto the order model returned to trip
+func travolutionOrderToModel(order travolutionDomain.Order) domain.Model {
+	customer := domain.Customer{
+		Name:  order.TravelerName,
+		Tel:   order.TravelerContactNumber,
+		Email: order.TravelerContactEmail,
+	}
+
+	model := domain.Model{
+		Id:                  order.ID,
+		Suppliers:           strings.ToUpper(constant.SUPPLIERTRAVOLUTION),
+		PartnerOrderID:      order.ReferenceNumber,
+		SupplierOrderNumber: order.OrderNumber,
+		OrderStatusCode:     travolutionOrderStatusCode(order.Status),
+		Customer:            customer,
+		ActualCustomer:      customer,
+		SelectVariants:      make([]domain.SelectVariant, 0, len(order.UnitAmounts)),
+		OrderExpired:        order.Status == constant.ORDEREXPIRED || order.Status == constant.OrderStatusExpired,
+		CreatedAt:           order.CreatedAt,
+		UpdatedAt:           order.UpdatedAt,
+	}
+	model.OrderId, _ = strconv.ParseInt(order.OrderNumber, 10, 64)
+
+	for _, unit := range order.UnitAmounts {
+		unitId, _ := strconv.ParseInt(fmt.Sprintf("%v", unit.Unit), 10, 64)
+		model.TotalSelectedVariantsQuantity += int32(unit.Amount)
+		model.SelectVariants = append(model.SelectVariants, domain.SelectVariant{
+			ProductID: int64(order.Product),
+			VariantID: unitId,
+			Date:      order.BookingDate,
+			Time:      order.BookingTime,
+			Quantity:  int32(unit.Amount),
+		})
+	}
+
+	return model
+}
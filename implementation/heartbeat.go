@@ -6,6 +6,7 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 
+	"swallow-supplier/config"
 	customError "swallow-supplier/error"
 	"swallow-supplier/request_response/yanolja"
 	"swallow-supplier/utils"
@@ -24,7 +25,7 @@ func (s *service) HeartBeat(ctx context.Context) (res yanolja.Response, err erro
 		"Trace ID", res.TraceID,
 	)
 
-	resrepo, err := s.mongoRepository[cf.MongoDBName].GetHeartBeatFromMongo(ctx)
+	resrepo, err := s.mongoRepository[config.Instance().MongoDBName].GetHeartBeatFromMongo(ctx)
 	if err != nil {
 		level.Error(logger).Log("err", err)
 		res.Code = "503"
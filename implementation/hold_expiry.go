@@ -0,0 +1,166 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	"swallow-supplier/iface"
+	"swallow-supplier/mongo/domain/travolution"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// ExpireHeldOrders cancel the pre orders trip did not pay within the hold window of their
+// supplier, so their inventory is released. Expired orders are marked expired and trip is told
+// through the forced cancel callback, orders failing to expire are tried again on the next runs
+func (s *service) ExpireHeldOrders(ctx context.Context) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "ExpireHeldOrders",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	ctx = orderstate.WithSource(ctx, orderstate.SourceScheduler)
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	// yanolja holds the pre order until it is completed, travolution issues it on creation and the
+	// order is held on our side until trip pays
+	supplierName := constant.SUPPLIERYANOLJA
+	createdBefore := time.Now().UTC().Add(-config.Instance().HoldWindow(supplierName)).Format(time.RFC3339)
+
+	counts := map[string]int{
+		constant.HoldExpiryOutcomeExpired: 0,
+		constant.HoldExpiryOutcomeFailed:  0,
+	}
+	var after int64
+	for ctx.Err() == nil {
+		orders, err := mrepo.GetExpiredHolds(ctx, supplierName, createdBefore, after, constant.HoldExpiryBatchSize)
+		if err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching expired holds, %v", err), "GetExpiredHolds")
+		}
+		if len(orders) == 0 {
+			break
+		}
+
+		for _, order := range orders {
+			counts[s.expireHold(ctx, logger, mrepo, order)]++
+		}
+		after = orders[len(orders)-1].OrderId
+	}
+
+	heldBefore := time.Now().UTC().Format(time.RFC3339)
+	var afterOrderNumber string
+	for ctx.Err() == nil {
+		orders, err := mrepo.GetExpiredTravolutionHolds(ctx, heldBefore, afterOrderNumber, constant.HoldExpiryBatchSize)
+		if err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching expired travolution holds, %v", err), "GetExpiredTravolutionHolds")
+		}
+		if len(orders) == 0 {
+			break
+		}
+
+		for _, order := range orders {
+			counts[s.expireTravolutionHold(ctx, logger, mrepo, order)]++
+		}
+		afterOrderNumber = orders[len(orders)-1].OrderNumber
+	}
+	level.Info(logger).Log("info", "held orders expired", "expired", counts[constant.HoldExpiryOutcomeExpired], "failed", counts[constant.HoldExpiryOutcomeFailed])
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = counts
+	return resp, nil
+}
+
+// expireHold cancel the pre order at the supplier and record the outcome on the order
+func (s *service) expireHold(ctx context.Context, logger log.Logger, mrepo iface.MongoRepository, order domain.Model) string {
+	logger = log.With(logger, "orderId", order.OrderId, "partnerOrderId", order.PartnerOrderID)
+
+	failed := func(err error) string {
+		level.Error(logger).Log("error", "held order not expired", "err", err)
+		expiry := domain.HoldExpiry{Outcome: constant.HoldExpiryOutcomeFailed, Error: err.Error(), At: time.Now().UTC().Format(time.RFC3339)}
+		if err := mrepo.UpdateHoldExpiry(ctx, order.OrderId, expiry, nil); err != nil {
+			level.Error(logger).Log("repository error", "recording hold expiry failure", "err", err)
+		}
+		return constant.HoldExpiryOutcomeFailed
+	}
+
+	transition, err := orderstate.OrderTransition(order, orderstate.OrderCanceled, orderstate.EventTimeoutCanceled, orderstate.SourceScheduler, time.Now())
+	if err != nil {
+		return failed(err)
+	}
+
+	if _, err = s.PostCancelOrderByReqTimeOut(ctx, order.PartnerOrderID); err != nil {
+		return failed(err)
+	}
+
+	transitions := make([]domain.StatusTransition, 0, 1)
+	if transition != nil {
+		transitions = append(transitions, *transition)
+	}
+	expiry := domain.HoldExpiry{Outcome: constant.HoldExpiryOutcomeExpired, At: time.Now().UTC().Format(time.RFC3339)}
+	if err = mrepo.UpdateHoldExpiry(ctx, order.OrderId, expiry, transitions); err != nil {
+		return failed(err)
+	}
+
+	record, err := mrepo.GetOrderbyOrderId(ctx, order.OrderId)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching expired order", "err", err)
+		return constant.HoldExpiryOutcomeExpired
+	}
+	processTripNotification(ctx, logger, s, "ForcedCancelNotify", record, "")
+
+	return constant.HoldExpiryOutcomeExpired
+}
+
+// expireTravolutionHold cancel the unpaid travolution order and record the outcome on it
+func (s *service) expireTravolutionHold(ctx context.Context, logger log.Logger, mrepo iface.MongoRepository, order travolution.Order) string {
+	logger = log.With(logger, "orderNumber", order.OrderNumber, "referenceNumber", order.ReferenceNumber)
+
+	expiry := travolution.HoldExpiry{Outcome: constant.HoldExpiryOutcomeExpired, At: time.Now().UTC().Format(time.RFC3339)}
+	canceled, err := s.CancelTravolutionOrder(ctx, order.OrderNumber)
+	if err != nil {
+		level.Error(logger).Log("error", "held travolution order not expired", "err", err)
+		expiry.Outcome, expiry.Error = constant.HoldExpiryOutcomeFailed, err.Error()
+	}
+	if err := mrepo.UpdateTravolutionHoldExpiry(ctx, order.OrderNumber, expiry); err != nil {
+		level.Error(logger).Log("repository error", "recording travolution hold expiry", "err", err)
+	}
+	if expiry.Outcome != constant.HoldExpiryOutcomeExpired {
+		return expiry.Outcome
+	}
+
+	if canceledOrder, ok := canceled.Body.(travolution.Order); ok {
+		order = canceledOrder
+	}
+	processTripNotification(ctx, logger, s, "ForcedCancelNotify", travolutionOrderToModel(order), "")
+
+	return constant.HoldExpiryOutcomeExpired
+}
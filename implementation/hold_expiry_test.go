@@ -0,0 +1,78 @@
+package implementation_test
+
+import (
+	"context"
+	"testing"
+
+	boilerplate "swallow-supplier/iface"
+	"swallow-supplier/implementation"
+	"swallow-supplier/mongo/domain/travolution"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/services/suppliers"
+	"swallow-supplier/utils/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// holdRepository travolution orders held for trip's payment, keyed by orderNumber
+type holdRepository struct {
+	stubRepository
+	orders  map[string]travolution.Order
+	expired map[string]travolution.HoldExpiry
+}
+
+func (r *holdRepository) GetExpiredHolds(context.Context, string, string, int64, int64) ([]domain.Model, error) {
+	return nil, nil
+}
+
+func (r *holdRepository) GetExpiredTravolutionHolds(_ context.Context, _ string, afterOrderNumber string, _ int64) ([]travolution.Order, error) {
+	orders := make([]travolution.Order, 0)
+	for _, order := range r.orders {
+		if order.OrderNumber > afterOrderNumber && order.Status == constant.ORDERAVAILABLE {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *holdRepository) GetOrderByOrderNumber(_ context.Context, orderNumber string) (travolution.Order, error) {
+	return r.orders[orderNumber], nil
+}
+
+func (r *holdRepository) UpdateOrderByOrderNumber(_ context.Context, orderNumber string, update map[string]any) error {
+	order := r.orders[orderNumber]
+	if status, ok := update["status"].(string); ok {
+		order.Status = status
+	}
+	r.orders[orderNumber] = order
+	return nil
+}
+
+func (r *holdRepository) UpdateTravolutionHoldExpiry(_ context.Context, orderNumber string, expiry travolution.HoldExpiry) error {
+	r.expired[orderNumber] = expiry
+	return nil
+}
+
+func TestExpireHeldTravolutionOrders(t *testing.T) {
+	mrepo := &holdRepository{
+		orders: map[string]travolution.Order{
+			"TRV-1": {OrderNumber: "TRV-1", ReferenceNumber: "OTA-1", Status: constant.ORDERAVAILABLE},
+		},
+		expired: make(map[string]travolution.HoldExpiry),
+	}
+	adapter := &cancelAdapter{}
+	suppliers.Register(constant.SUPPLIERTRAVOLUTION, func(context.Context) (suppliers.SupplierAdapter, error) {
+		return adapter, nil
+	})
+	s := implementation.NewService(map[string]boilerplate.MongoRepository{cf.MongoDBName: mrepo}, logger)
+
+	// an unpaid travolution order past its hold is canceled at travolution
+	resp, err := s.ExpireHeldOrders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Body.(map[string]int)[constant.HoldExpiryOutcomeExpired])
+	require.Len(t, adapter.requests, 1)
+	assert.Equal(t, "TRV-1", adapter.requests[0].OrderId)
+	assert.Equal(t, constant.HoldExpiryOutcomeExpired, mrepo.expired["TRV-1"].Outcome)
+	assert.Equal(t, constant.ORDERCANCELREQUEST, mrepo.orders["TRV-1"].Status)
+}
@@ -72,6 +72,9 @@ func (s *service) InventorySync(ctx context.Context) (resp yanolja.Response, err
 		}
 		level.Info(logger).Log("Info for inventryresp body ", inventryresp.Body)
 
+		// the synced inventory is the latest one, cached variant inventories are read again
+		invalidateInventory(ctx, logger, productVariantIds(product))
+
 		// Assuming inventryresp.Body contains a JSON object with an array `variantInventories`
 		var inventories yanolja.Inventories
 
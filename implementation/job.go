@@ -0,0 +1,249 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	svc "swallow-supplier/iface"
+	"swallow-supplier/mongo/domain/job"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/scheduler"
+	"swallow-supplier/utils"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// defaultJobRunLimit runs returned when the request has no limit
+const defaultJobRunLimit = 20
+
+// GetJobs list the registered jobs with their effective schedule, pause state and last run
+func (s *service) GetJobs(ctx context.Context) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetJobs",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	settings, err := jobSettings(ctx, mrepo)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching job settings", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching job settings, %v", err), "GetJobs")
+	}
+
+	jobs := make([]common.JobStatus, 0, len(scheduler.Definitions))
+	for _, def := range scheduler.Definitions {
+		status, err := jobStatus(ctx, mrepo, def, settings[def.Name])
+		if err != nil {
+			level.Error(logger).Log("repository error", "fetching job runs", "job", def.Name, "error", err)
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching job runs, %v", err), "GetJobs")
+		}
+		jobs = append(jobs, status)
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = jobs
+
+	return resp, nil
+}
+
+// GetJobRuns latest runs of a job, newest first
+func (s *service) GetJobRuns(ctx context.Context, req common.JobRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetJobRuns",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	if _, ok := scheduler.Lookup(req.Name); !ok {
+		return jobNotFound(ctx, resp, req.Name, "GetJobRuns")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultJobRunLimit
+	}
+
+	runs, err := s.mongoRepository[config.Instance().MongoDBName].GetJobRuns(ctx, req.Name, limit)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching job runs", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching job runs, %v", err), "GetJobRuns")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = runs
+
+	return resp, nil
+}
+
+// TriggerJob request a run of the job now, the replica running the scheduler picks it up within
+// a few seconds. Paused jobs run as well
+func (s *service) TriggerJob(ctx context.Context, req common.JobRequest) (resp common.Response, err error) {
+	return s.updateJob(ctx, req, "TriggerJob", map[string]any{
+		"triggerRequestedAt": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// SetJobPaused pause or resume the scheduled runs of a job
+func (s *service) SetJobPaused(ctx context.Context, req common.JobRequest) (resp common.Response, err error) {
+	return s.updateJob(ctx, req, "SetJobPaused", map[string]any{
+		"paused": req.Paused,
+	})
+}
+
+// UpdateJobSchedule change the schedule of a job at runtime, an empty schedule restores the default
+func (s *service) UpdateJobSchedule(ctx context.Context, req common.JobRequest) (resp common.Response, err error) {
+	if req.Schedule != "" {
+		if err = scheduler.ValidateSchedule(req.Schedule); err != nil {
+			resp.Code = "400"
+			resp.Status = http.StatusBadRequest
+			return resp, customError.NewError(ctx, "leisure-api-1016", fmt.Sprintf("invalid schedule %q, %v", req.Schedule, err), "UpdateJobSchedule")
+		}
+	}
+
+	return s.updateJob(ctx, req, "UpdateJobSchedule", map[string]any{
+		"schedule": req.Schedule,
+	})
+}
+
+// updateJob store the setting change and return the job status
+func (s *service) updateJob(ctx context.Context, req common.JobRequest, method string, update map[string]any) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", method,
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	def, ok := scheduler.Lookup(req.Name)
+	if !ok {
+		return jobNotFound(ctx, resp, req.Name, method)
+	}
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	if err = mrepo.UpdateJobSetting(ctx, def.Name, update); err != nil {
+		level.Error(logger).Log("repository error", "updating job setting", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on updating job setting, %v", err), method)
+	}
+	level.Info(logger).Log("info", "job setting changed", "job", def.Name, "update", fmt.Sprintf("%v", update))
+
+	settings, err := jobSettings(ctx, mrepo)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching job settings", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching job settings, %v", err), method)
+	}
+	status, err := jobStatus(ctx, mrepo, def, settings[def.Name])
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching job runs", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching job runs, %v", err), method)
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = status
+
+	return resp, nil
+}
+
+// jobSettings stored job settings by job name
+func jobSettings(ctx context.Context, mrepo svc.MongoRepository) (map[string]job.Setting, error) {
+	settings, err := mrepo.GetJobSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]job.Setting, len(settings))
+	for _, setting := range settings {
+		byName[setting.Name] = setting
+	}
+	return byName, nil
+}
+
+// jobStatus job definition merged with its stored setting and last run
+func jobStatus(ctx context.Context, mrepo svc.MongoRepository, def scheduler.Definition, setting job.Setting) (common.JobStatus, error) {
+	status := common.JobStatus{
+		Name:               def.Name,
+		Description:        def.Description,
+		Schedule:           def.Schedule,
+		DefaultSchedule:    def.Schedule,
+		Timeout:            def.Timeout.String(),
+		Concurrency:        def.Concurrency,
+		Paused:             def.Paused,
+		TriggerRequestedAt: setting.TriggerRequestedAt,
+	}
+	if setting.Schedule != "" {
+		status.Schedule = setting.Schedule
+	}
+	if setting.Paused != nil {
+		status.Paused = *setting.Paused
+	}
+
+	runs, err := mrepo.GetJobRuns(ctx, def.Name, 1)
+	if err != nil {
+		return status, err
+	}
+	if len(runs) != 0 {
+		status.LastRun = &runs[0]
+	}
+
+	return status, nil
+}
+
+func jobNotFound(ctx context.Context, resp common.Response, name, source string) (common.Response, error) {
+	resp.Code = "404"
+	resp.Status = http.StatusNotFound
+	return resp, customError.NewErrorCustom(ctx, resp.Code, fmt.Sprintf("job %s not found", name), "", http.StatusNotFound, source)
+}
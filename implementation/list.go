@@ -0,0 +1,24 @@
+package implementation
+
+import (
+	"context"
+
+	"swallow-supplier/utils/pagination"
+)
+
+// listBody body of a list response, the records with their pagination
+func listBody(ctx context.Context, q pagination.Query, data interface{}, total int64, next string) pagination.Reports {
+	if q.CursorMode {
+		return pagination.FormatCursorResponse(ctx, data, total, next)
+	}
+	return pagination.FormatResponse(ctx, data, total)
+}
+
+// unpagedListBody body of a list endpoint answering a bare array before it was paginated, the
+// array is kept for requests not asking for a page
+func unpagedListBody(ctx context.Context, q pagination.Query, data interface{}, total int64, next string) interface{} {
+	if !q.Paged {
+		return data
+	}
+	return listBody(ctx, q, data, total, next)
+}
@@ -0,0 +1,345 @@
+package implementation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	trip_domain "swallow-supplier/mongo/domain/trip"
+	"swallow-supplier/request_response/common"
+	tripservice "swallow-supplier/services/distributors/trip"
+	"swallow-supplier/services/pdfvoucher"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/client"
+	"swallow-supplier/utils/constant"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ProcessNotificationOutbox deliver due notifications of the outbox, called by the scheduler
+func (s *service) ProcessNotificationOutbox(ctx context.Context) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "ProcessNotificationOutbox",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	run := common.NotificationOutboxRun{}
+
+	for run.Processed < constant.OutboxBatchSize {
+		msg, err := mrepo.ClaimDueNotificationOutbox(ctx, constant.OutboxLeaseSeconds*time.Second)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				break
+			}
+			level.Error(logger).Log("repository error", "claiming notification outbox", "error", err)
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on claiming notification outbox, %v", err), "ProcessNotificationOutbox")
+		}
+
+		run.Processed++
+		switch sendNotification(ctx, logger, s, msg) {
+		case constant.OutboxStatusSent:
+			run.Sent++
+		case constant.OutboxStatusDead:
+			run.Dead++
+		default:
+			run.Retried++
+		}
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = run
+
+	return resp, nil
+}
+
+// GetNotificationOutbox list notifications of the outbox
+func (s *service) GetNotificationOutbox(ctx context.Context, req common.NotificationOutboxRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetNotificationOutbox",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = 20
+	}
+
+	msgs, total, err := s.mongoRepository[config.Instance().MongoDBName].GetNotificationOutbox(ctx, req.Status, (req.Page-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching notification outbox", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching notification outbox, %v", err), "GetNotificationOutbox")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = common.NotificationOutboxList{
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Items:    msgs,
+	}
+
+	return resp, nil
+}
+
+// ReplayNotificationOutbox reset a notification to pending and deliver it again
+func (s *service) ReplayNotificationOutbox(ctx context.Context, id string) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "ReplayNotificationOutbox",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	msg, err := mrepo.GetNotificationOutboxById(ctx, id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			level.Error(logger).Log("repository error", "no notification exist for id", "id", id)
+			resp.Code = "404"
+			resp.Status = http.StatusNotFound
+			return resp, customError.NewErrorCustom(ctx, resp.Code, err.Error(), "", http.StatusNotFound, "ReplayNotificationOutbox")
+		}
+
+		level.Error(logger).Log("repository error", "fetching notification outbox by id", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching notification outbox, %v", err), "ReplayNotificationOutbox")
+	}
+
+	// the lease keeps the scheduler away while the replay is in flight
+	msg.Status = constant.OutboxStatusPending
+	msg.Attempts = 0
+	msg.NextRetry = time.Now().UTC().Add(constant.OutboxLeaseSeconds * time.Second)
+	err = mrepo.UpdateNotificationOutbox(ctx, msg.Id, map[string]any{
+		"status":    msg.Status,
+		"attempts":  msg.Attempts,
+		"nextRetry": msg.NextRetry,
+	})
+	if err != nil {
+		level.Error(logger).Log("repository error", "resetting notification outbox", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on resetting notification outbox, %v", err), "ReplayNotificationOutbox")
+	}
+
+	sendNotification(ctx, logger, s, msg)
+
+	msg, err = mrepo.GetNotificationOutboxById(ctx, id)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching replayed notification", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching notification outbox, %v", err), "ReplayNotificationOutbox")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = msg
+
+	return resp, nil
+}
+
+// enqueueNotification store the notification in the outbox, it is due at once and delivered by
+// the next ProcessNotificationOutbox run so the caller never waits for the delivery
+func enqueueNotification(ctx context.Context, logger log.Logger, svc *service, kind, message, partnerOrderId, callBackId string, payload interface{}) (msg trip_domain.NotificationOutbox, err error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		level.Error(logger).Log("error", "marshal outbox payload", "error", err)
+		return msg, err
+	}
+
+	msg = trip_domain.NotificationOutbox{
+		Kind:           kind,
+		Message:        message,
+		PartnerOrderId: partnerOrderId,
+		CallBackId:     callBackId,
+		Payload:        string(body),
+		Status:         constant.OutboxStatusPending,
+		NextRetry:      time.Now().UTC(),
+	}
+
+	msg.Id, err = svc.mongoRepository[config.Instance().MongoDBName].InsertNotificationOutbox(ctx, msg)
+	if err != nil {
+		level.Error(logger).Log("repository error", "notification outbox insertion error", "error", err)
+		return msg, err
+	}
+
+	return msg, nil
+}
+
+// sendNotification one delivery attempt, returns the resulting outbox status
+func sendNotification(ctx context.Context, logger log.Logger, svc *service, msg trip_domain.NotificationOutbox) string {
+	mrepo := svc.mongoRepository[config.Instance().MongoDBName]
+	msg.Attempts++
+
+	deliveryErr := deliverNotification(ctx, logger, svc, msg)
+	if deliveryErr == nil {
+		level.Info(logger).Log("info", "outbox notification delivered", "id", msg.Id, "kind", msg.Kind, "partnerOrderId", msg.PartnerOrderId)
+		err := mrepo.UpdateNotificationOutbox(ctx, msg.Id, map[string]any{
+			"status":    constant.OutboxStatusSent,
+			"attempts":  msg.Attempts,
+			"lastError": "",
+		})
+		if err != nil {
+			level.Error(logger).Log("repository error", "marking outbox notification sent", "id", msg.Id, "error", err)
+		}
+		return constant.OutboxStatusSent
+	}
+
+	level.Error(logger).Log("error", "outbox notification delivery failed", "id", msg.Id, "kind", msg.Kind, "attempt", msg.Attempts, "error", deliveryErr)
+
+	update := map[string]any{
+		"attempts":  msg.Attempts,
+		"lastError": deliveryErr.Error(),
+	}
+	status := constant.OutboxStatusPending
+	if msg.Attempts >= constant.OutboxMaxAttempts {
+		status = constant.OutboxStatusDead
+	} else {
+		update["nextRetry"] = time.Now().UTC().Add(notificationBackoff(msg.Attempts))
+	}
+	update["status"] = status
+
+	if err := mrepo.UpdateNotificationOutbox(ctx, msg.Id, update); err != nil {
+		level.Error(logger).Log("repository error", "updating outbox notification", "id", msg.Id, "error", err)
+	}
+
+	if status == constant.OutboxStatusDead {
+		deadLetterNotification(ctx, logger, svc, msg)
+	}
+
+	return status
+}
+
+// notificationBackoff exponential delay before the next attempt, capped at OutboxMaxBackoffSeconds
+func notificationBackoff(attempts int) time.Duration {
+	seconds := float64(constant.OutboxBaseBackoffSeconds) * math.Pow(2, float64(attempts-1))
+	if seconds > constant.OutboxMaxBackoffSeconds {
+		seconds = constant.OutboxMaxBackoffSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// deliverNotification send the notification to trip or the pdf voucher generator
+func deliverNotification(ctx context.Context, logger log.Logger, svc *service, msg trip_domain.NotificationOutbox) error {
+	// every attempt of the same outbox entry is sent with the same idempotency key. The delivery
+	// and its retries end before the lease does, so no other run claims the entry while it is sent
+	sendCtx, cancel := context.WithTimeout(client.WithIdempotencyKey(ctx, msg.Id), constant.OutboxDeliverySeconds*time.Second)
+	defer cancel()
+
+	switch msg.Kind {
+	case constant.OutboxKindTripNotify:
+		var tripRequest trip_domain.CallBackRequest
+		if err := json.Unmarshal([]byte(msg.Payload), &tripRequest); err != nil {
+			return fmt.Errorf("invalid trip notification payload: %w", err)
+		}
+
+		tripsvc, err := tripservice.New(sendCtx)
+		if err != nil {
+			return err
+		}
+
+		resp, err := tripsvc.NotifyToTrip(sendCtx, tripRequest)
+		if err == nil && resp.Code != "200" {
+			err = fmt.Errorf("trip notification returned code %s", resp.Code)
+		}
+
+		status := "SUCCESS"
+		if err != nil {
+			status = "FAILED"
+		}
+		if msg.CallBackId != "" {
+			if uerr := svc.mongoRepository[config.Instance().MongoDBName].UpdateCallBackStatus(ctx, msg.CallBackId, status); uerr != nil {
+				level.Error(logger).Log("repository error", "updating callback status", "error", uerr)
+			}
+		}
+		return err
+
+	case constant.OutboxKindPdfVoucher:
+		var voucherReq []common.PdfVoucherRequest
+		if err := json.Unmarshal([]byte(msg.Payload), &voucherReq); err != nil {
+			return fmt.Errorf("invalid pdf voucher payload: %w", err)
+		}
+
+		pdfVoucherSvc, err := pdfvoucher.New(sendCtx)
+		if err != nil {
+			return err
+		}
+
+		res, err := pdfVoucherSvc.NotifyToVoucherPdfUpdate(sendCtx, voucherReq)
+		if err != nil {
+			return err
+		}
+
+		// the voucher is generated, failures after this point are not retried
+		handleVoucherPdfResponse(ctx, logger, svc, res, msg.PartnerOrderId)
+		return nil
+	}
+
+	return fmt.Errorf("unknown outbox notification kind %q", msg.Kind)
+}
+
+// deadLetterNotification final handling once a notification ran out of attempts
+func deadLetterNotification(ctx context.Context, logger log.Logger, svc *service, msg trip_domain.NotificationOutbox) {
+	level.Error(logger).Log("error", "outbox notification moved to dead letter", "id", msg.Id, "kind", msg.Kind, "partnerOrderId", msg.PartnerOrderId)
+
+	// an order without voucher can not be used by the customer, an operator replays the voucher
+	// or cancels the order
+	if msg.Kind == constant.OutboxKindPdfVoucher {
+		level.Error(logger).Log("error", "pdf voucher not generated, order needs an operator decision", "partnerOrderId", msg.PartnerOrderId)
+	}
+}
@@ -0,0 +1,147 @@
+package implementation_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	boilerplate "swallow-supplier/iface"
+	"swallow-supplier/implementation"
+	trip_domain "swallow-supplier/mongo/domain/trip"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/utils/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// outboxRepository hands out the queued notifications and keeps the updates per id
+type outboxRepository struct {
+	stubRepository
+	queue   []trip_domain.NotificationOutbox
+	updates map[string]map[string]any
+}
+
+func (r *outboxRepository) ClaimDueNotificationOutbox(context.Context, time.Duration) (trip_domain.NotificationOutbox, error) {
+	if len(r.queue) == 0 {
+		return trip_domain.NotificationOutbox{}, mongo.ErrNoDocuments
+	}
+	msg := r.queue[0]
+	r.queue = r.queue[1:]
+	return msg, nil
+}
+
+func (r *outboxRepository) UpdateNotificationOutbox(_ context.Context, id string, update map[string]any) error {
+	r.updates[id] = update
+	return nil
+}
+
+func drainOutbox(t *testing.T, msgs ...trip_domain.NotificationOutbox) (common.NotificationOutboxRun, *outboxRepository) {
+	t.Helper()
+	mrepo := &outboxRepository{queue: msgs, updates: make(map[string]map[string]any)}
+	s := implementation.NewService(map[string]boilerplate.MongoRepository{cf.MongoDBName: mrepo}, logger)
+
+	resp, err := s.ProcessNotificationOutbox(ctx)
+	require.NoError(t, err)
+	return resp.Body.(common.NotificationOutboxRun), mrepo
+}
+
+func TestNotificationOutboxRetry(t *testing.T) {
+	// a payload trip can not be sent fails the delivery without reaching trip
+	msgs := make([]trip_domain.NotificationOutbox, 0)
+	for attempts := 0; attempts < constant.OutboxMaxAttempts-1; attempts++ {
+		msgs = append(msgs, trip_domain.NotificationOutbox{
+			Id:       fmt.Sprintf("attempt-%d", attempts),
+			Kind:     constant.OutboxKindTripNotify,
+			Payload:  "not json",
+			Status:   constant.OutboxStatusPending,
+			Attempts: attempts,
+		})
+	}
+
+	before := time.Now().UTC()
+	run, mrepo := drainOutbox(t, msgs...)
+	assert.Equal(t, len(msgs), run.Processed)
+	assert.Equal(t, len(msgs), run.Retried)
+	assert.Zero(t, run.Dead)
+
+	// the delay doubles from OutboxBaseBackoffSeconds with every failed attempt
+	for _, msg := range msgs {
+		update := mrepo.updates[msg.Id]
+		require.NotNil(t, update, msg.Id)
+		assert.Equal(t, constant.OutboxStatusPending, update["status"])
+		assert.Equal(t, msg.Attempts+1, update["attempts"])
+		assert.Contains(t, update["lastError"], "invalid trip notification payload")
+
+		delay := time.Duration(constant.OutboxBaseBackoffSeconds<<msg.Attempts) * time.Second
+		if delay > constant.OutboxMaxBackoffSeconds*time.Second {
+			delay = constant.OutboxMaxBackoffSeconds * time.Second
+		}
+		nextRetry := update["nextRetry"].(time.Time)
+		assert.WithinDuration(t, before.Add(delay), nextRetry, 5*time.Second, msg.Id)
+	}
+}
+
+func TestNotificationOutboxDeadLetter(t *testing.T) {
+	msg := trip_domain.NotificationOutbox{
+		Id:       "last",
+		Kind:     constant.OutboxKindTripNotify,
+		Payload:  "not json",
+		Status:   constant.OutboxStatusPending,
+		Attempts: constant.OutboxMaxAttempts - 1,
+	}
+
+	// the last allowed attempt failing moves the notification to the dead letters
+	run, mrepo := drainOutbox(t, msg)
+	assert.Equal(t, 1, run.Dead)
+	assert.Zero(t, run.Retried)
+	update := mrepo.updates["last"]
+	assert.Equal(t, constant.OutboxStatusDead, update["status"])
+	assert.Equal(t, constant.OutboxMaxAttempts, update["attempts"])
+	assert.NotContains(t, update, "nextRetry")
+}
+
+func TestNotificationOutboxVoucherDeadLetter(t *testing.T) {
+	msg := trip_domain.NotificationOutbox{
+		Id:             "voucher",
+		Kind:           constant.OutboxKindPdfVoucher,
+		PartnerOrderId: "P-1001",
+		Payload:        "not json",
+		Status:         constant.OutboxStatusPending,
+		Attempts:       constant.OutboxMaxAttempts - 1,
+	}
+
+	// a voucher which could not be generated is left to an operator, the order is not canceled
+	// (the stub repository panics on any order lookup a cancellation would make)
+	run, mrepo := drainOutbox(t, msg)
+	assert.Equal(t, 1, run.Dead)
+	assert.Equal(t, constant.OutboxStatusDead, mrepo.updates["voucher"]["status"])
+}
+
+func TestNotificationOutboxSent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"200","body":{}}`))
+	}))
+	defer server.Close()
+	cf.Trip = server.URL
+	defer func() { cf.Trip = "" }()
+
+	msg := trip_domain.NotificationOutbox{
+		Id:       "sent",
+		Kind:     constant.OutboxKindTripNotify,
+		Payload:  `{"sequenceId":"1"}`,
+		Status:   constant.OutboxStatusPending,
+		Attempts: 3,
+	}
+
+	run, mrepo := drainOutbox(t, msg)
+	assert.Equal(t, 1, run.Sent)
+	update := mrepo.updates["sent"]
+	assert.Equal(t, constant.OutboxStatusSent, update["status"])
+	assert.Equal(t, 4, update["attempts"])
+	assert.Equal(t, "", update["lastError"])
+}
@@ -0,0 +1,204 @@
+package implementation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	svc "swallow-supplier/iface"
+	"swallow-supplier/mongo/domain/odoo"
+	"swallow-supplier/request_response/common"
+	odooSvc "swallow-supplier/services/odoo"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/constant"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// odooPushConsumer prefix of the push cursors, odoo's own pulls acknowledge under the bare kind
+const odooPushConsumer = "push:"
+
+// PushOdooChanges push the order and product changes since the last push to odoo, called by the
+// scheduler. A record odoo refuses is marked with the error and skipped, when odoo cannot be
+// reached the push stops and the next run starts from the same change
+func (s *service) PushOdooChanges(ctx context.Context) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "PushOdooChanges",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	client, err := odooSvc.New(ctx)
+	if err != nil {
+		level.Error(logger).Log("error", "odoo client is not configured", "err", err)
+		resp.Code = "500"
+		return resp, err
+	}
+
+	pushed := make(map[string]int)
+	// products first, the order lines refer to them
+	for _, kind := range []string{odoo.SyncKindProduct, odoo.SyncKindOrder} {
+		pushed[kind], err = s.pushOdooKind(ctx, logger, client, kind)
+		if err != nil {
+			level.Error(logger).Log("error", "odoo push stopped", "kind", kind, "err", err)
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-0004", fmt.Sprintf("odoo push error, %v", err), "PushOdooChanges")
+		}
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = pushed
+
+	return resp, nil
+}
+
+// pushOdooKind push one page of changes of the kind and move its push cursor past them
+func (s *service) pushOdooKind(ctx context.Context, logger log.Logger, client *odooSvc.Odoo, kind string) (pushed int, err error) {
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	consumer := odooPushConsumer + kind
+
+	cursor, err := mrepo.GetOdooSyncCursor(ctx, consumer)
+	if err != nil {
+		return 0, err
+	}
+
+	events, err := mrepo.GetOdooSyncEvents(ctx, kind, cursor.Cursor, constant.OdooSyncPageSize)
+	if err != nil {
+		return 0, err
+	}
+
+	latest := make(map[int64]int64, len(events))
+	for _, event := range events {
+		latest[event.RefId] = event.Seq
+	}
+
+	acked := cursor.Cursor
+	defer func() {
+		if acked > cursor.Cursor {
+			mrepo.AckOdooSyncCursor(ctx, consumer, acked)
+		}
+	}()
+
+	for _, event := range events {
+		if latest[event.RefId] == event.Seq {
+			change, ok, err := s.odooSyncChange(ctx, logger, event)
+			if err != nil {
+				return pushed, err
+			}
+
+			if ok {
+				err = s.pushOdooChange(ctx, mrepo, client, change)
+				if err != nil && !odooSvc.IsRecordError(err) {
+					return pushed, err
+				}
+				if err != nil {
+					level.Error(logger).Log("error", "odoo refused the record", "kind", kind, "refId", event.RefId, "err", err)
+					mrepo.UpdateOdooPushState(ctx, kind, event.RefId, map[string]any{
+						"odooPushError": err.Error(),
+						"odooPushedAt":  time.Now().UTC().Format(time.RFC3339),
+					})
+				} else {
+					pushed++
+				}
+			}
+		}
+		acked = event.Seq
+	}
+
+	return pushed, nil
+}
+
+// pushOdooChange write the change to odoo and keep the odoo ids on our document
+func (s *service) pushOdooChange(ctx context.Context, mrepo svc.MongoRepository, client *odooSvc.Odoo, change odoo.SyncChange) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	switch {
+	case change.Product != nil:
+		_, err := s.pushOdooProduct(ctx, mrepo, client, *change.Product)
+		return err
+
+	case change.Order != nil:
+		productIds := make(map[int64]int64)
+		for _, variant := range change.Order.Variants {
+			if _, done := productIds[variant.ProductId]; done {
+				continue
+			}
+			product, err := mrepo.FetchProductByProductId(ctx, variant.ProductId)
+			if err != nil {
+				return err
+			}
+			productIds[variant.ProductId] = product.OdooProductId
+			// products are pushed ahead of the orders, unless their push failed
+			if product.OdooProductId == 0 {
+				if productIds[variant.ProductId], err = s.pushOdooProduct(ctx, mrepo, client, productDataForOdooSync(product)); err != nil {
+					return err
+				}
+			}
+		}
+
+		id, err := client.UpsertOrder(ctx, *change.Order, productIds)
+		if err != nil {
+			return err
+		}
+		return mrepo.UpdateOdooPushState(ctx, odoo.SyncKindOrder, change.RefId, map[string]any{
+			"odooId":        id,
+			"odooPushedAt":  now,
+			"odooPushError": "",
+		})
+
+	case change.Kind == odoo.SyncKindProduct:
+		_, err := client.ArchiveProduct(ctx, change.RefId, 0)
+		return err
+
+	default:
+		order, err := mrepo.GetOrderbyOrderId(ctx, change.RefId)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		id, err := client.CancelOrder(ctx, change.RefId, order.OdooId)
+		if err != nil || order.OrderId == 0 {
+			return err
+		}
+		return mrepo.UpdateOdooPushState(ctx, odoo.SyncKindOrder, change.RefId, map[string]any{
+			"odooId":        id,
+			"odooPushedAt":  now,
+			"odooPushError": "",
+		})
+	}
+}
+
+// pushOdooProduct create or update the product template and keep its ids on the product
+func (s *service) pushOdooProduct(ctx context.Context, mrepo svc.MongoRepository, client *odooSvc.Odoo, product odoo.Product) (productId int64, err error) {
+	templateId, productId, err := client.UpsertProduct(ctx, product)
+	if err != nil {
+		return 0, err
+	}
+
+	return productId, mrepo.UpdateOdooPushState(ctx, odoo.SyncKindProduct, product.ProductID, map[string]any{
+		"odooTemplateId": templateId,
+		"odooProductId":  productId,
+		"odooPushedAt":   time.Now().UTC().Format(time.RFC3339),
+		"odooPushError":  "",
+	})
+}
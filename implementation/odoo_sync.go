@@ -0,0 +1,251 @@
+package implementation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	"swallow-supplier/mongo/domain/odoo"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetOdooSyncChanges page of order or product changes after the cursor. Every record shows up
+// once per page in its latest state, deleted and canceled ones as tombstones
+func (s *service) GetOdooSyncChanges(ctx context.Context, req common.OdooSyncRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetOdooSyncChanges",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	var since int64
+	if req.Since != nil {
+		since = *req.Since
+	} else {
+		cursor, err := mrepo.GetOdooSyncCursor(ctx, req.Kind)
+		if err != nil {
+			level.Error(logger).Log("repository error", "fetching odoo sync cursor", "kind", req.Kind, "error", err)
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching odoo sync cursor, %v", err), "GetOdooSyncCursor")
+		}
+		since = cursor.Cursor
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = constant.OdooSyncPageSize
+	}
+	if limit > constant.OdooSyncMaxPageSize {
+		limit = constant.OdooSyncMaxPageSize
+	}
+
+	events, err := mrepo.GetOdooSyncEvents(ctx, req.Kind, since, limit+1)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching odoo sync events", "kind", req.Kind, "since", since, "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching odoo sync events, %v", err), "GetOdooSyncEvents")
+	}
+
+	page := odoo.SyncPage{
+		Since:      since,
+		NextCursor: since,
+		HasMore:    int64(len(events)) > limit,
+		Changes:    make([]odoo.SyncChange, 0),
+	}
+	if page.HasMore {
+		events = events[:limit]
+	}
+	if len(events) > 0 {
+		page.NextCursor = events[len(events)-1].Seq
+	}
+
+	// only the latest event of a record is resolved, the earlier ones are covered by its state
+	latest := make(map[int64]int64, len(events))
+	for _, event := range events {
+		latest[event.RefId] = event.Seq
+	}
+
+	odooOrders := make([]odoo.Order, 0)
+	odooProducts := make([]odoo.Product, 0)
+	for _, event := range events {
+		if latest[event.RefId] != event.Seq {
+			continue
+		}
+
+		change, ok, err := s.odooSyncChange(ctx, logger, event)
+		if err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching changed %s, %v", event.Kind, err), "GetOdooSyncChanges")
+		}
+		if !ok {
+			continue
+		}
+		if change.Order != nil {
+			odooOrders = append(odooOrders, *change.Order)
+		}
+		if change.Product != nil {
+			odooProducts = append(odooProducts, *change.Product)
+		}
+
+		page.Changes = append(page.Changes, change)
+	}
+
+	// keep the odoo collections in step with what odoo receives
+	if len(odooOrders) > 0 {
+		if _, err = mrepo.UpsertOdooOrder(ctx, odooOrders); err != nil {
+			level.Error(logger).Log("repository error", "upsert record of odoo order", "error", err)
+		}
+	}
+	if len(odooProducts) > 0 {
+		if _, err = mrepo.UpsertOdooProduct(ctx, odooProducts); err != nil {
+			level.Error(logger).Log("repository error", "upsert record of odoo product", "error", err)
+		}
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = page
+
+	return resp, nil
+}
+
+// PostOdooSyncAck acknowledge the changes up to the cursor, the next pull without since
+// starts after it. An older cursor than the acknowledged one is ignored
+func (s *service) PostOdooSyncAck(ctx context.Context, req common.OdooSyncRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "PostOdooSyncAck",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	if req.Cursor == nil || *req.Cursor < 0 {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-1016", "cursor is required and must not be negative", "PostOdooSyncAck")
+	}
+
+	seq, err := mrepo.GetOdooSyncSeq(ctx)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching odoo sync sequence", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching odoo sync sequence, %v", err), "GetOdooSyncSeq")
+	}
+	if *req.Cursor > seq {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-1016", fmt.Sprintf("cursor %d is ahead of the latest change %d", *req.Cursor, seq), "PostOdooSyncAck")
+	}
+
+	cursor, err := mrepo.AckOdooSyncCursor(ctx, req.Kind, *req.Cursor)
+	if err != nil {
+		level.Error(logger).Log("repository error", "acknowledging odoo sync cursor", "kind", req.Kind, "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on acknowledging odoo sync cursor, %v", err), "AckOdooSyncCursor")
+	}
+	level.Info(logger).Log("info", "odoo sync cursor acknowledged", "kind", req.Kind, "cursor", cursor.Cursor)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = cursor
+
+	return resp, nil
+}
+
+// odooSyncChange latest state of the record of the event, a tombstone when it is deleted or
+// canceled. ok is false for orders which are not booked yet, they reach odoo with a later change
+func (s *service) odooSyncChange(ctx context.Context, logger log.Logger, event odoo.SyncEvent) (change odoo.SyncChange, ok bool, err error) {
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	change = odoo.SyncChange{Seq: event.Seq, Op: odoo.SyncOpTombstone, Kind: event.Kind, RefId: event.RefId}
+	switch event.Kind {
+	case odoo.SyncKindOrder:
+		order, err := mrepo.GetOrderbyOrderId(ctx, event.RefId)
+		if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && orderCanceled(order)) {
+			return change, true, nil
+		}
+		if err != nil {
+			level.Error(logger).Log("repository error", "fetching changed order", "orderId", event.RefId, "error", err)
+			return change, false, err
+		}
+		if order.OrderStatusCode != constant.ORDERDONE {
+			return change, false, nil
+		}
+		// orders of products missing in our catalogue cannot be converted, they are left out
+		odooRecord := oderDataForOdooSync(ctx, s, logger, order)
+		if odooRecord.OrderID == 0 {
+			level.Error(logger).Log("error", "order could not be converted for odoo", "orderId", event.RefId)
+			return change, false, nil
+		}
+		change.Op = odoo.SyncOpUpsert
+		change.Order = &odooRecord
+
+	case odoo.SyncKindProduct:
+		product, err := mrepo.FetchProductByProductId(ctx, event.RefId)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return change, true, nil
+		}
+		if err != nil {
+			level.Error(logger).Log("repository error", "fetching changed product", "productId", event.RefId, "error", err)
+			return change, false, err
+		}
+		odooRecord := productDataForOdooSync(product)
+		change.Op = odoo.SyncOpUpsert
+		change.Product = &odooRecord
+	}
+
+	return change, true, nil
+}
+
+// orderCanceled true once every variant of the order is canceled
+func orderCanceled(order domain.Model) bool {
+	if len(order.OrderVariants) == 0 {
+		return false
+	}
+	for _, variant := range order.OrderVariants {
+		if variant.OrderVariantStatusTypeCode != constant.ORDERVARIANTCANCELEDSTATUS {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package implementation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// orderTransitionError error of a status change which was not made: a conflict when the order
+// may not move or moved in the meantime, a repository error otherwise
+func orderTransitionError(ctx context.Context, err error, source string) error {
+	if errors.Is(err, orderstate.ErrIllegalTransition) || errors.Is(err, orderstate.ErrStaleTransition) || errors.Is(err, orderstate.ErrUnknownVariant) {
+		return customError.NewErrorCustom(ctx, "409", err.Error(), "", http.StatusConflict, source)
+	}
+	return customError.NewError(ctx, "leisure-api-1015", err.Error(), source)
+}
+
+// forcedCancelTransitions transitions of the variants of the order to CANCELED for a cancellation
+// which does not wait for the channel. An order which is not stored yet has none
+func forcedCancelTransitions(ctx context.Context, s *service, partnerOrderId string, event string) ([]domain.StatusTransition, error) {
+	order, err := s.mongoRepository[config.Instance().MongoDBName].GetOrderbyPartnerOrderId(ctx, partnerOrderId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return orderstate.VariantTransitions(order, nil, constant.ORDERVARIANTCANCELEDSTATUS, event, orderstate.Source(ctx, orderstate.SourceAdmin), time.Now())
+}
@@ -0,0 +1,173 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/supplier"
+	"swallow-supplier/request_response/yanolja"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// PostCancelOrderVariants cancel some variants of an order, the rest of the order stays usable
+func (s *service) PostCancelOrderVariants(ctx context.Context, orderId int64, orderVariantIds []int64) (resp yanolja.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "PostCancelOrderVariants",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	if !config.Instance().PartialCancelEnabled {
+		level.Error(logger).Log("error", "partial cancellation is disabled", "orderId", orderId)
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-0006", "partial cancellation is not enabled, the whole order must be canceled", "PostCancelOrderVariants")
+	}
+
+	if len(orderVariantIds) == 0 {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-0001", "no order variant to cancel", "PostCancelOrderVariants")
+	}
+
+	record, err := s.mongoRepository[config.Instance().MongoDBName].GetOrderbyOrderId(ctx, orderId)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching order based on orderId ", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by orderId, %v", err), "GetOrderbyOrderId")
+	}
+
+	// the variants asked for must be allowed to move to CANCELING before the supplier is asked
+	transitions, err := orderstate.VariantTransitions(record, orderVariantIds, constant.ORDERVARIANTCANCELINGSTATUS, orderstate.EventCancelRequested, orderstate.Source(ctx, orderstate.SourceAdmin), time.Now())
+	if err != nil {
+		level.Error(logger).Log("error", "order variants may not be canceled", "err", err)
+		resp.Code = "409"
+		return resp, orderTransitionError(ctx, err, "PostCancelOrderVariants")
+	}
+
+	// the refund is quoted before the supplier is asked, under the policies in force at request time.
+	// The cancellation does not depend on the quote, it goes on unquoted when quoting fails
+	quote, err := quoteRefund(ctx, s, record, orderVariantIds)
+	if err != nil {
+		level.Error(logger).Log("error", "quoting refund of order, canceling unquoted", "orderId", orderId, "err", err)
+	}
+
+	adapter, err := supplierAdapter(ctx, supplierOfOrder(record))
+	if err != nil {
+		level.Error(logger).Log("error", "supplier adapter not available ", err)
+		resp.Code = "500"
+		return resp, err
+	}
+	canceled, err := adapter.Cancel(ctx, supplier.CancelRequest{
+		OrderId:         strconv.FormatInt(orderId, 10),
+		PartnerOrderId:  record.PartnerOrderID,
+		Type:            supplier.CancelPartial,
+		OrderVariantIds: orderVariantIds,
+	})
+	resp = toYanoljaResponse(canceled)
+	if err != nil {
+		level.Error(logger).Log("error", "supplier raised error during partial cancellation of order ", err)
+		return resp, err
+	}
+
+	level.Info(logger).Log("response", resp)
+
+	err = s.mongoRepository[config.Instance().MongoDBName].UpdateCancelRequest(ctx, orderId, constant.TRIPPARTIALCANCELREQUEST, orderVariantIds, quote, transitions)
+	if err != nil {
+		level.Error(logger).Log("repository error", "error in updating order variant status ", err)
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("error in updating order variant status by orderId %d, %v", orderId, err), "UpdateCancelRequest")
+	}
+
+	return recordCancelStatus(ctx, logger, s, record, orderVariantIds, withRefundQuote(resp, quote))
+}
+
+// recordCancelStatus keep the cancel status the supplier answered on each canceled variant
+func recordCancelStatus(ctx context.Context, logger log.Logger, s *service, record domain.Model, orderVariantIds []int64, resp yanolja.Response) (yanolja.Response, error) {
+	orderResp, _ := resp.Body.(map[string]interface{})
+	cancelStatusCode := fmt.Sprintf("%v", orderResp["cancelStatusCode"])
+	cancelFailReasonCode := fmt.Sprintf("%v", orderResp["cancelFailReasonCode"])
+
+	if cancelStatusCode != "FAIL" && cancelStatusCode != "DIRECT" && cancelStatusCode != "ADMIN" {
+		level.Error(logger).Log("error", "cancelStatusCode status is incorrect", "cancelStatusCode", cancelStatusCode)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-0009", "cancelStatusCode is wrong", "recordCancelStatus")
+	}
+
+	level.Info(logger).Log("info", "cancelStatusCode", cancelStatusCode)
+	if cancelStatusCode != "FAIL" {
+		cancelFailReasonCode = ""
+	}
+
+	for _, ov := range record.OrderVariants {
+		if !slices.Contains(orderVariantIds, ov.OrderVariantID) {
+			continue
+		}
+		err := s.mongoRepository[config.Instance().MongoDBName].UpdateCancelDetailsForVariants(ctx, record.OrderId, ov.ProductID, ov.OrderVariantID, cancelFailReasonCode, cancelStatusCode)
+		if err != nil {
+			level.Error(logger).Log("repository error", "updating cancel details of variant ", err)
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on order update by orderId, %v", err), "UpdateCancelDetailsForVariants")
+		}
+	}
+
+	return resp, nil
+}
+
+// orderVariantIds ids of every variant of the order
+func orderVariantIds(record domain.Model) []int64 {
+	ids := make([]int64, 0, len(record.OrderVariants))
+	for _, ov := range record.OrderVariants {
+		ids = append(ids, ov.OrderVariantID)
+	}
+	return ids
+}
+
+// canceledVariantIds variants a cancellation acknowledgement is for, nil when it is for the whole
+// order
+func canceledVariantIds(record domain.Model) []int64 {
+	if record.CancelRequestCategory != constant.TRIPPARTIALCANCELREQUEST {
+		return nil
+	}
+
+	ids := make([]int64, 0)
+	for _, ov := range record.OrderVariants {
+		if ov.OrderVariantStatusTypeCode == constant.ORDERVARIANTCANCELINGSTATUS {
+			ids = append(ids, ov.OrderVariantID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+// cancelRequestType trip request the cancellation of the order answers
+func cancelRequestType(record domain.Model) string {
+	if record.CancelRequestCategory == constant.TRIPPARTIALCANCELREQUEST {
+		return constant.TRIPPARTIALCANCELREQUEST
+	}
+	return constant.TRIPFULLORDERCANCELREQUEST
+}
@@ -0,0 +1,140 @@
+package implementation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	boilerplate "swallow-supplier/iface"
+	"swallow-supplier/implementation"
+	"swallow-supplier/mongo/domain/refund"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/supplier"
+	"swallow-supplier/services/suppliers"
+	"swallow-supplier/utils/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stubSupplier = "StubSupplier"
+
+// cancelRepository keeps what the cancellation wrote on the order
+type cancelRepository struct {
+	stubRepository
+	order       domain.Model
+	canceled    []int64
+	transitions []domain.StatusTransition
+	updated     map[int64]string
+}
+
+func (r *cancelRepository) GetOrderbyOrderId(context.Context, int64) (domain.Model, error) {
+	return r.order, nil
+}
+
+func (r *cancelRepository) GetRefundPolicies(context.Context, []int64) ([]refund.Policy, error) {
+	return nil, errors.New("refund policies not available")
+}
+
+func (r *cancelRepository) UpdateCancelRequest(_ context.Context, _ int64, _ string, orderVariantIds []int64, _ refund.Quote, transitions []domain.StatusTransition) error {
+	r.canceled = orderVariantIds
+	r.transitions = transitions
+	return nil
+}
+
+func (r *cancelRepository) UpdateCancelDetailsForVariants(_ context.Context, _ int64, _ int64, orderVariantId int64, _ string, cancelStatusCode string) error {
+	r.updated[orderVariantId] = cancelStatusCode
+	return nil
+}
+
+// cancelAdapter supplier accepting every cancellation
+type cancelAdapter struct {
+	requests []supplier.CancelRequest
+}
+
+func (a *cancelAdapter) Name() string { return stubSupplier }
+
+func (a *cancelAdapter) Products(context.Context, supplier.ProductQuery) (supplier.Response, error) {
+	return supplier.Response{}, nil
+}
+
+func (a *cancelAdapter) Inventory(context.Context, supplier.InventoryQuery) (supplier.Response, error) {
+	return supplier.Response{}, nil
+}
+
+func (a *cancelAdapter) Prepare(context.Context, supplier.PrepareRequest) (supplier.Response, error) {
+	return supplier.Response{}, nil
+}
+
+func (a *cancelAdapter) Confirm(context.Context, supplier.ConfirmRequest) (supplier.Response, error) {
+	return supplier.Response{}, nil
+}
+
+func (a *cancelAdapter) Cancel(_ context.Context, req supplier.CancelRequest) (supplier.Response, error) {
+	a.requests = append(a.requests, req)
+	return supplier.Response{Supplier: stubSupplier, Code: "200", TraceID: "trace-cancel", Body: map[string]interface{}{"cancelStatusCode": "DIRECT"}}, nil
+}
+
+func (a *cancelAdapter) Status(context.Context, supplier.StatusRequest) (supplier.Response, error) {
+	return supplier.Response{}, nil
+}
+
+func newCancelService(t *testing.T) (boilerplate.Service, *cancelRepository, *cancelAdapter) {
+	t.Helper()
+	mrepo := &cancelRepository{
+		order: domain.Model{
+			OrderId:        1001,
+			PartnerOrderID: "P-1001",
+			Suppliers:      stubSupplier,
+			OrderVariants: []domain.OrderVariant{
+				{OrderVariantID: 1, ProductID: 10, VariantID: 100, OrderVariantStatusTypeCode: constant.ORDERVARIANTNOTUSEDSTATUS},
+				{OrderVariantID: 2, ProductID: 10, VariantID: 100, OrderVariantStatusTypeCode: constant.ORDERVARIANTNOTUSEDSTATUS},
+				{OrderVariantID: 3, ProductID: 10, VariantID: 101, OrderVariantStatusTypeCode: constant.ORDERVARIANTNOTUSEDSTATUS},
+			},
+		},
+		updated: make(map[int64]string),
+	}
+	adapter := &cancelAdapter{}
+	suppliers.Register(stubSupplier, func(context.Context) (suppliers.SupplierAdapter, error) {
+		return adapter, nil
+	})
+
+	return implementation.NewService(map[string]boilerplate.MongoRepository{cf.MongoDBName: mrepo}, logger), mrepo, adapter
+}
+
+func TestPostCancelOrderVariants(t *testing.T) {
+	cf.PartialCancelEnabled = true
+	defer func() { cf.PartialCancelEnabled = false }()
+	s, mrepo, adapter := newCancelService(t)
+
+	resp, err := s.PostCancelOrderVariants(ctx, 1001, []int64{1, 3})
+	require.NoError(t, err)
+	assert.Equal(t, "200", resp.Code)
+	assert.Equal(t, "trace-cancel", resp.TraceID)
+
+	// only the selected variants are sent to the supplier and move to CANCELING
+	require.Len(t, adapter.requests, 1)
+	assert.Equal(t, supplier.CancelPartial, adapter.requests[0].Type)
+	assert.Equal(t, []int64{1, 3}, adapter.requests[0].OrderVariantIds)
+	assert.Equal(t, []int64{1, 3}, mrepo.canceled)
+	require.Len(t, mrepo.transitions, 2)
+	for i, id := range []int64{1, 3} {
+		assert.Equal(t, id, mrepo.transitions[i].OrderVariantId)
+		assert.Equal(t, constant.ORDERVARIANTNOTUSEDSTATUS, mrepo.transitions[i].From)
+		assert.Equal(t, constant.ORDERVARIANTCANCELINGSTATUS, mrepo.transitions[i].To)
+	}
+	assert.Equal(t, map[int64]string{1: "DIRECT", 3: "DIRECT"}, mrepo.updated)
+
+	// the refund could not be quoted, the cancellation goes on unquoted
+	assert.NotContains(t, resp.Body, "refundQuote")
+}
+
+func TestPostCancelOrderVariantsDisabled(t *testing.T) {
+	s, mrepo, adapter := newCancelService(t)
+
+	resp, err := s.PostCancelOrderVariants(ctx, 1001, []int64{1})
+	assert.Error(t, err)
+	assert.Equal(t, "400", resp.Code)
+	assert.Empty(t, adapter.requests)
+	assert.Nil(t, mrepo.canceled)
+}
@@ -0,0 +1,284 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	pricingDomain "swallow-supplier/mongo/domain/pricing"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/pricing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetPricingRules list pricing rules, optionally narrowed to a supplier, product or channel
+func (s *service) GetPricingRules(ctx context.Context, req common.PricingRuleRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetPricingRules",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	rules, err := s.mongoRepository[config.Instance().MongoDBName].GetPricingRules(ctx)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching pricing rules", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching pricing rules, %v", err), "GetPricingRules")
+	}
+
+	filtered := make([]pricingDomain.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if req.Supplier != "" && rule.Supplier != req.Supplier {
+			continue
+		}
+		if req.ProductId != 0 && rule.ProductId != req.ProductId {
+			continue
+		}
+		if req.ChannelCode != "" && rule.ChannelCode != req.ChannelCode {
+			continue
+		}
+		filtered = append(filtered, rule)
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = filtered
+
+	return resp, nil
+}
+
+// GetPricingRule fetch a pricing rule by id
+func (s *service) GetPricingRule(ctx context.Context, id string) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetPricingRule",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	rule, err := s.mongoRepository[config.Instance().MongoDBName].GetPricingRuleById(ctx, id)
+	if err != nil {
+		return pricingRuleLookupError(ctx, logger, resp, err, "GetPricingRule")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = rule
+
+	return resp, nil
+}
+
+// CreatePricingRule store a new pricing rule, it applies to new prices once the loaded rules are refreshed
+func (s *service) CreatePricingRule(ctx context.Context, rule pricingDomain.Rule) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "CreatePricingRule",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	if rule.Rounding == "" {
+		rule.Rounding = constant.ROUNDINGNONE
+	}
+	if err = pricing.Validate(rule); err != nil {
+		resp.Code = "400"
+		resp.Status = http.StatusBadRequest
+		return resp, customError.NewError(ctx, "leisure-api-1016", err.Error(), "CreatePricingRule")
+	}
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	id, err := mrepo.InsertPricingRule(ctx, rule)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return pricingRuleNameTaken(ctx, resp, rule.Name, "CreatePricingRule")
+		}
+		level.Error(logger).Log("repository error", "inserting pricing rule", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting pricing rule, %v", err), "CreatePricingRule")
+	}
+	pricing.Invalidate()
+
+	level.Info(logger).Log("info", "pricing rule created", "id", id, "name", rule.Name)
+
+	rule, err = mrepo.GetPricingRuleById(ctx, id)
+	if err != nil {
+		return pricingRuleLookupError(ctx, logger, resp, err, "CreatePricingRule")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = rule
+
+	return resp, nil
+}
+
+// UpdatePricingRule replace a pricing rule
+func (s *service) UpdatePricingRule(ctx context.Context, rule pricingDomain.Rule) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "UpdatePricingRule",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	if rule.Rounding == "" {
+		rule.Rounding = constant.ROUNDINGNONE
+	}
+	if err = pricing.Validate(rule); err != nil {
+		resp.Code = "400"
+		resp.Status = http.StatusBadRequest
+		return resp, customError.NewError(ctx, "leisure-api-1016", err.Error(), "UpdatePricingRule")
+	}
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	existing, err := mrepo.GetPricingRuleById(ctx, rule.Id)
+	if err != nil {
+		return pricingRuleLookupError(ctx, logger, resp, err, "UpdatePricingRule")
+	}
+
+	rule.CreatedAt = existing.CreatedAt
+	if err = mrepo.ReplacePricingRule(ctx, rule); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return pricingRuleNameTaken(ctx, resp, rule.Name, "UpdatePricingRule")
+		}
+		level.Error(logger).Log("repository error", "replacing pricing rule", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on updating pricing rule, %v", err), "UpdatePricingRule")
+	}
+	pricing.Invalidate()
+
+	level.Info(logger).Log("info", "pricing rule updated", "id", rule.Id, "markupType", rule.MarkupType, "value", rule.Value)
+
+	rule, err = mrepo.GetPricingRuleById(ctx, rule.Id)
+	if err != nil {
+		return pricingRuleLookupError(ctx, logger, resp, err, "UpdatePricingRule")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = rule
+
+	return resp, nil
+}
+
+// DeletePricingRule delete a pricing rule
+func (s *service) DeletePricingRule(ctx context.Context, id string) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "DeletePricingRule",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	deleted, err := s.mongoRepository[config.Instance().MongoDBName].DeletePricingRule(ctx, id)
+	if err != nil {
+		level.Error(logger).Log("repository error", "deleting pricing rule", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on deleting pricing rule, %v", err), "DeletePricingRule")
+	}
+	if !deleted {
+		return pricingRuleLookupError(ctx, logger, resp, mongo.ErrNoDocuments, "DeletePricingRule")
+	}
+	pricing.Invalidate()
+
+	level.Info(logger).Log("info", "pricing rule deleted", "id", id)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = id
+
+	return resp, nil
+}
+
+// pricingRuleLookupError maps a failed pricing rule lookup to 404 or 500
+func pricingRuleLookupError(ctx context.Context, logger log.Logger, resp common.Response, err error, source string) (common.Response, error) {
+	if err == mongo.ErrNoDocuments {
+		level.Error(logger).Log("repository error", "no pricing rule exist for id")
+		resp.Code = "404"
+		resp.Status = http.StatusNotFound
+		return resp, customError.NewErrorCustom(ctx, resp.Code, "pricing rule not found", "", http.StatusNotFound, source)
+	}
+
+	level.Error(logger).Log("repository error", "fetching pricing rule", "error", err)
+	resp.Code = "500"
+	return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching pricing rule, %v", err), source)
+}
+
+// pricingRuleNameTaken rule names are unique, the seed rules are upserted by name
+func pricingRuleNameTaken(ctx context.Context, resp common.Response, name string, source string) (common.Response, error) {
+	resp.Code = "409"
+	resp.Status = http.StatusConflict
+	return resp, customError.NewErrorCustom(ctx, resp.Code, fmt.Sprintf("pricing rule %s already exists", name), "pricing rule exists", http.StatusConflict, source)
+}
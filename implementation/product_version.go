@@ -0,0 +1,211 @@
+package implementation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	"swallow-supplier/iface"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/productdiff"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// recordProductVersion store the product as its version with the changes since the version
+// stored before it. stored is the product document the upsert replaces, the diff base for
+// products stored before their history was kept. A version received again unchanged is skipped
+func recordProductVersion(ctx context.Context, mrepo iface.MongoRepository, product domain.Product, stored *domain.Product) error {
+	existing, err := mrepo.GetProductVersion(ctx, product.ProductID, product.ProductVersion)
+	switch {
+	case err == nil && existing.Product != nil:
+		changes, err := productdiff.Compare(*existing.Product, product)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+	case err != nil && !errors.Is(err, mongo.ErrNoDocuments):
+		return err
+	}
+
+	version := domain.ProductVersion{
+		ProductId: product.ProductID,
+		Version:   product.ProductVersion,
+		Areas:     make([]string, 0),
+		Changes:   make([]domain.ProductChange, 0),
+		Product:   &product,
+	}
+
+	var base *domain.Product
+	previous, err := mrepo.GetPreviousProductVersion(ctx, product.ProductID, product.ProductVersion)
+	switch {
+	case err == nil:
+		base, version.PreviousVersion = previous.Product, previous.Version
+	case errors.Is(err, mongo.ErrNoDocuments):
+		if stored != nil && stored.ProductID == product.ProductID && stored.ProductVersion < product.ProductVersion {
+			base, version.PreviousVersion = stored, stored.ProductVersion
+		}
+	default:
+		return err
+	}
+
+	if base != nil {
+		version.Changes, err = productdiff.Compare(*base, product)
+		if err != nil {
+			return err
+		}
+		version.Areas = productdiff.Areas(version.Changes)
+	}
+
+	return mrepo.UpsertProductVersion(ctx, version)
+}
+
+// productVersionNotFound error for a version of the product which is not stored
+func productVersionNotFound(ctx context.Context, productId int64, version int32, source string) error {
+	return customError.NewErrorCustom(ctx, "404", fmt.Sprintf("no version %d of product %d", version, productId), "product version not found", http.StatusNotFound, source)
+}
+
+// GetProductVersions versions of the product newest first with what changed in each
+func (s *service) GetProductVersions(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetProductVersions",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	versions, err := s.mongoRepository[config.Instance().MongoDBName].GetProductVersions(ctx, req.ProductId)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching product versions", "productId", req.ProductId, "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching product versions, %v", err), "GetProductVersions")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = versions
+
+	return resp, nil
+}
+
+// GetProductVersion product as it was in the version
+func (s *service) GetProductVersion(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetProductVersion",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	version, err := s.mongoRepository[config.Instance().MongoDBName].GetProductVersion(ctx, req.ProductId, req.Version)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		resp.Code = "404"
+		return resp, productVersionNotFound(ctx, req.ProductId, req.Version, "GetProductVersion")
+	}
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching product version", "productId", req.ProductId, "version", req.Version, "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching product version, %v", err), "GetProductVersion")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = version
+
+	return resp, nil
+}
+
+// DiffProductVersions changes of the product from one stored version to another
+func (s *service) DiffProductVersions(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "DiffProductVersions",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	products := make([]domain.Product, 0, 2)
+	for _, number := range []int32{req.From, req.To} {
+		version, err := mrepo.GetProductVersion(ctx, req.ProductId, number)
+		if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && version.Product == nil) {
+			resp.Code = "404"
+			return resp, productVersionNotFound(ctx, req.ProductId, number, "DiffProductVersions")
+		}
+		if err != nil {
+			level.Error(logger).Log("repository error", "fetching product version", "productId", req.ProductId, "version", number, "error", err)
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching product version, %v", err), "GetProductVersion")
+		}
+		products = append(products, *version.Product)
+	}
+
+	changes, err := productdiff.Compare(products[0], products[1])
+	if err != nil {
+		resp.Code = "500"
+		return resp, err
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = domain.ProductVersionDiff{
+		ProductId: req.ProductId,
+		From:      req.From,
+		To:        req.To,
+		Areas:     productdiff.Areas(changes),
+		Changes:   changes,
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,289 @@
+package implementation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	"swallow-supplier/mongo/domain/reconciliation"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/reconcile"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GenerateReconciliationReport compare the orders of the day with yanolja's reconciliation status
+// and the callbacks sent to trip, and store the mismatches as the report of the day. The day
+// defaults to yesterday
+func (s *service) GenerateReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GenerateReconciliationReport",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	if req.Date == "" {
+		req.Date = time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	if _, err = time.Parse("2006-01-02", req.Date); err != nil {
+		resp.Code = "400"
+		return resp, customError.NewError(ctx, "leisure-api-1016", "date must be in YYYY-MM-DD format", "GenerateReconciliationReport")
+	}
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+
+	report := reconciliation.Report{Date: req.Date, Mismatches: make([]reconciliation.Mismatch, 0)}
+	checked := make(map[int64]bool)
+
+	var after int64
+	for {
+		orders, err := mrepo.GetReconciliationOrders(ctx, req.Date, after, constant.ReconcileBatchSize)
+		if err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching orders to reconcile, %v", err), "GetReconciliationOrders")
+		}
+		if len(orders) == 0 {
+			break
+		}
+
+		orderIds := make([]int64, 0, len(orders))
+		for _, order := range orders {
+			orderIds = append(orderIds, order.OrderId)
+		}
+		callbacks, err := mrepo.GetTripCallbacksByOrderIds(ctx, orderIds)
+		if err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching trip callbacks, %v", err), "GetTripCallbacksByOrderIds")
+		}
+
+		for _, order := range orders {
+			checked[order.OrderId] = true
+			report.OrdersChecked++
+			report.VariantsChecked += int64(len(order.OrderVariants))
+			report.Mismatches = append(report.Mismatches, reconcile.Compare(order, callbacks[order.OrderId])...)
+		}
+		after = orders[len(orders)-1].OrderId
+	}
+
+	// callbacks of the day for orders we no longer have
+	tripOrderIds, err := mrepo.GetTripCallbackOrderIds(ctx, req.Date)
+	if err != nil {
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching trip callbacks, %v", err), "GetTripCallbackOrderIds")
+	}
+	unchecked := make([]int64, 0)
+	for _, orderId := range tripOrderIds {
+		if !checked[orderId] {
+			unchecked = append(unchecked, orderId)
+		}
+	}
+	if len(unchecked) > 0 {
+		existing, err := mrepo.GetExistingOrderIds(ctx, unchecked)
+		if err != nil {
+			resp.Code = "500"
+			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching orders, %v", err), "GetExistingOrderIds")
+		}
+		missing := make([]int64, 0)
+		for _, orderId := range unchecked {
+			if !existing[orderId] {
+				missing = append(missing, orderId)
+			}
+		}
+		if len(missing) > 0 {
+			callbacks, err := mrepo.GetTripCallbacksByOrderIds(ctx, missing)
+			if err != nil {
+				resp.Code = "500"
+				return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching trip callbacks, %v", err), "GetTripCallbacksByOrderIds")
+			}
+			for _, orderId := range missing {
+				report.Mismatches = append(report.Mismatches, reconcile.Orphans(orderId, callbacks[orderId])...)
+			}
+		}
+	}
+
+	report.Counts = reconcile.Count(report.Mismatches)
+	report.Total = int64(len(report.Mismatches))
+	report.GeneratedAt = time.Now().UTC().Format(time.RFC3339)
+
+	if err = mrepo.UpsertReconciliationReport(ctx, report); err != nil {
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on storing reconciliation report, %v", err), "UpsertReconciliationReport")
+	}
+	level.Info(logger).Log("info", "reconciliation report generated", "date", report.Date, "orders", report.OrdersChecked, "mismatches", report.Total)
+
+	report.Mismatches = nil
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = report
+
+	return resp, nil
+}
+
+// GetReconciliationReports latest reconciliation reports with their counts
+func (s *service) GetReconciliationReports(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetReconciliationReports",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	if req.Limit < 1 {
+		req.Limit = 30
+	}
+
+	reports, err := s.mongoRepository[config.Instance().MongoDBName].GetReconciliationReports(ctx, req.Limit)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching reconciliation reports", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching reconciliation reports, %v", err), "GetReconciliationReports")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = reports
+
+	return resp, nil
+}
+
+// GetReconciliationReport report of the day with a page of its mismatches
+func (s *service) GetReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetReconciliationReport",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = constant.ReconcilePageSize
+	}
+
+	report, total, err := s.mongoRepository[config.Instance().MongoDBName].GetReconciliationReport(ctx, req.Date, req.Type, (req.Page-1)*req.PageSize, req.PageSize)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		resp.Code = "404"
+		return resp, customError.NewErrorCustom(ctx, "404", fmt.Sprintf("no reconciliation report for %s", req.Date), "report not found", http.StatusNotFound, "GetReconciliationReport")
+	}
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching reconciliation report", "date", req.Date, "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching reconciliation report, %v", err), "GetReconciliationReport")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = common.ReconciliationReportPage{
+		Report:        report,
+		Type:          req.Type,
+		MismatchTotal: total,
+		Page:          req.Page,
+		PageSize:      req.PageSize,
+	}
+
+	return resp, nil
+}
+
+// ExportReconciliationReport mismatches of the report of the day as csv
+func (s *service) ExportReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "ExportReconciliationReport",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+
+	}(ctx)
+
+	report, _, err := s.mongoRepository[config.Instance().MongoDBName].GetReconciliationReport(ctx, req.Date, req.Type, 0, 0)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		resp.Code = "404"
+		return resp, customError.NewErrorCustom(ctx, "404", fmt.Sprintf("no reconciliation report for %s", req.Date), "report not found", http.StatusNotFound, "ExportReconciliationReport")
+	}
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching reconciliation report", "date", req.Date, "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching reconciliation report, %v", err), "ExportReconciliationReport")
+	}
+
+	var buf bytes.Buffer
+	if err = reconcile.WriteCSV(&buf, report.Date, report.Mismatches); err != nil {
+		resp.Code = "500"
+		return resp, err
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = common.File{
+		Name:        fmt.Sprintf("reconciliation-%s.csv", report.Date),
+		ContentType: "text/csv; charset=utf-8",
+		Content:     buf.Bytes(),
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,322 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	"swallow-supplier/iface"
+	"swallow-supplier/mongo/domain/money"
+	refundDomain "swallow-supplier/mongo/domain/refund"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/common"
+	"swallow-supplier/request_response/yanolja"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/cancellation"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/refund"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// GetRefundPolicy refund policy applied to a variant: the curated one, else the one parsed from
+// the refund text of its product, else the default full refund
+func (s *service) GetRefundPolicy(ctx context.Context, req common.RefundPolicyRequest) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetRefundPolicy",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	policies, err := refundPolicies(ctx, mrepo, []refund.Line{{ProductId: req.ProductId, VariantId: req.VariantId}}, nil)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching refund policy", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching refund policy, %v", err), "GetRefundPolicy")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = policies[req.VariantId]
+
+	return resp, nil
+}
+
+// PutRefundPolicy store the curated refund policy of a variant, it applies to the next quotes
+func (s *service) PutRefundPolicy(ctx context.Context, policy refundDomain.Policy) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "PutRefundPolicy",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	if err = refund.Validate(policy); err != nil {
+		resp.Code = "400"
+		resp.Status = http.StatusBadRequest
+		return resp, customError.NewError(ctx, "leisure-api-1016", err.Error(), "PutRefundPolicy")
+	}
+
+	mrepo := s.mongoRepository[config.Instance().MongoDBName]
+	if err = mrepo.UpsertRefundPolicy(ctx, policy); err != nil {
+		level.Error(logger).Log("repository error", "storing refund policy", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on storing refund policy, %v", err), "PutRefundPolicy")
+	}
+
+	level.Info(logger).Log("info", "refund policy stored", "variantId", policy.VariantId, "tiers", len(policy.Tiers))
+
+	policy, err = mrepo.GetRefundPolicy(ctx, policy.VariantId)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching refund policy", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching refund policy, %v", err), "PutRefundPolicy")
+	}
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = policy
+
+	return resp, nil
+}
+
+// DeleteRefundPolicy delete the curated refund policy of a variant, it falls back to the parsed one
+func (s *service) DeleteRefundPolicy(ctx context.Context, variantId int64) (resp common.Response, err error) {
+	requestID := utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "DeleteRefundPolicy",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Info(logger).Log("info", "processing request went into panic mode")
+		resp.Code = "500"
+
+	}(ctx)
+
+	deleted, err := s.mongoRepository[config.Instance().MongoDBName].DeleteRefundPolicy(ctx, variantId)
+	if err != nil {
+		level.Error(logger).Log("repository error", "deleting refund policy", "error", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on deleting refund policy, %v", err), "DeleteRefundPolicy")
+	}
+	if !deleted {
+		level.Error(logger).Log("repository error", "no refund policy exist for variant", "variantId", variantId)
+		resp.Code = "404"
+		resp.Status = http.StatusNotFound
+		return resp, customError.NewErrorCustom(ctx, resp.Code, "refund policy not found", "", http.StatusNotFound, "DeleteRefundPolicy")
+	}
+
+	level.Info(logger).Log("info", "refund policy deleted", "variantId", variantId)
+
+	resp.Code = "200"
+	resp.Status = http.StatusOK
+	resp.Body = variantId
+
+	return resp, nil
+}
+
+// quoteRefund refund owed for canceling the order variants, or every booked variant when the order
+// is not confirmed yet. The quote is empty when quoting fails
+func quoteRefund(ctx context.Context, s *service, record domain.Model, orderVariantIds []int64) (refundDomain.Quote, error) {
+	lines := make([]refund.Line, 0)
+	refundInfo := make(map[int64]string)
+
+	if len(orderVariantIds) > 0 {
+		paid := cancellation.Paid(record, orderVariantIds)
+		for _, ov := range record.OrderVariants {
+			if !slices.Contains(orderVariantIds, ov.OrderVariantID) {
+				continue
+			}
+			price, ok := paid[ov.OrderVariantID]
+			if !ok {
+				price = money.Money{Currency: orderCurrency(record)}
+			}
+			lines = append(lines, refund.Line{
+				OrderVariantId: ov.OrderVariantID,
+				ProductId:      ov.ProductID,
+				VariantId:      ov.VariantID,
+				Quantity:       1,
+				VisitDate:      visitDate(record, ov),
+				Paid:           price,
+			})
+			refundInfo[ov.VariantID] = ov.RefundInfo
+		}
+	} else {
+		for _, selected := range record.SelectVariants {
+			price, _ := cancellation.UnitPrice(record, selected.ProductID, selected.VariantID)
+			if price.Currency == "" {
+				price.Currency = orderCurrency(record)
+			}
+			price.Amount *= float64(selected.Quantity)
+			lines = append(lines, refund.Line{
+				ProductId: selected.ProductID,
+				VariantId: selected.VariantID,
+				Quantity:  int(selected.Quantity),
+				VisitDate: selected.Date,
+				Paid:      price,
+			})
+		}
+	}
+
+	policies, err := refundPolicies(ctx, s.mongoRepository[config.Instance().MongoDBName], lines, refundInfo)
+	if err != nil {
+		return refundDomain.Quote{}, err
+	}
+
+	loc, err := time.LoadLocation(constant.RefundPolicyTimezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	quote, err := refund.Quote(policies, lines, time.Now().In(loc))
+	if err != nil {
+		return refundDomain.Quote{}, err
+	}
+	return quote, nil
+}
+
+// refundPolicies policy of each variant of the lines. Variants without a curated policy get the
+// one parsed from their refund text, the order variant one first and the product one next
+func refundPolicies(ctx context.Context, mrepo iface.MongoRepository, lines []refund.Line, refundInfo map[int64]string) (map[int64]refundDomain.Policy, error) {
+	variantIds := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		variantIds = append(variantIds, line.VariantId)
+	}
+
+	curated, err := mrepo.GetRefundPolicies(ctx, variantIds)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[int64]refundDomain.Policy, len(lines))
+	for _, policy := range curated {
+		policies[policy.VariantId] = policy
+	}
+
+	views := make(map[int64]*domain.ProductView)
+	for _, line := range lines {
+		if _, ok := policies[line.VariantId]; ok {
+			continue
+		}
+
+		policy, ok := refund.Parse(refundInfo[line.VariantId])
+		if view := productView(ctx, mrepo, views, line.ProductId); view != nil {
+			for _, variant := range view.Variants {
+				if variant.VariantID != line.VariantId {
+					continue
+				}
+				if !ok {
+					policy, ok = refund.Parse(variant.RefundInfo)
+				}
+				policy.RefundableAfterExpiration = variant.IsRefundableAfterExpiration
+			}
+			if !ok {
+				policy, ok = refund.Parse(view.ProductInfo.RefundInfo)
+				policy.RefundableAfterExpiration = view.IsRefundableAfterExpiration
+			}
+		}
+		if !ok {
+			policy = refund.Default(line.ProductId, line.VariantId)
+		}
+
+		policy.ProductId, policy.VariantId = line.ProductId, line.VariantId
+		policies[line.VariantId] = policy
+	}
+
+	return policies, nil
+}
+
+// productView product view of the product, fetched once per quote. Products no longer in sale
+// have none
+func productView(ctx context.Context, mrepo iface.MongoRepository, views map[int64]*domain.ProductView, productId int64) *domain.ProductView {
+	if view, ok := views[productId]; ok {
+		return view
+	}
+
+	view, err := mrepo.GetProductViewByProductId(ctx, productId)
+	if err != nil {
+		views[productId] = nil
+		return nil
+	}
+	views[productId] = &view
+	return &view
+}
+
+// visitDate date the order variant is used on, the booked date when the supplier did not set one.
+// Open dated vouchers are counted down to the end of their validity, empty when it is unknown
+func visitDate(record domain.Model, ov domain.OrderVariant) string {
+	if ov.Date != "" {
+		return ov.Date
+	}
+	for _, selected := range record.SelectVariants {
+		if selected.ProductID == ov.ProductID && selected.VariantID == ov.VariantID && selected.Date != "" {
+			return selected.Date
+		}
+	}
+	if ov.ValidityPeriod.EndDateTime.IsZero() {
+		return ""
+	}
+	return ov.ValidityPeriod.EndDateTime.Format(time.DateOnly)
+}
+
+// orderCurrency currency the order was booked in
+func orderCurrency(record domain.Model) string {
+	for _, selected := range record.SelectVariants {
+		if selected.Currency != "" {
+			return selected.Currency
+		}
+	}
+	return ""
+}
+
+// withRefundQuote expose the quote next to the supplier answer of the cancellation, unquoted
+// cancellations have none
+func withRefundQuote(resp yanolja.Response, quote refundDomain.Quote) yanolja.Response {
+	if quote.QuotedAt == "" {
+		return resp
+	}
+	if body, ok := resp.Body.(map[string]interface{}); ok {
+		body["refundQuote"] = quote
+	}
+	return resp
+}
@@ -1,16 +1,11 @@
 package implementation
 
 import (
-	"swallow-supplier/config"
 	svc "swallow-supplier/iface"
 
 	"github.com/go-kit/kit/log"
 )
 
-var (
-	cf = config.Instance()
-)
-
 // service implements the Order Service
 type service struct {
 	mongoRepository map[string]svc.MongoRepository
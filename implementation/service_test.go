@@ -3,10 +3,14 @@ package implementation_test
 import (
 	"context"
 	"net/http/httptest"
+	"os"
+	"testing"
 
 	"swallow-supplier/config"
 	boilerplate "swallow-supplier/iface"
 	service "swallow-supplier/iface"
+	"swallow-supplier/implementation"
+	"swallow-supplier/request_response/heartbeat"
 
 	"github.com/go-kit/kit/log"
 )
@@ -20,12 +24,20 @@ var (
 	svc    service.Service
 )
 
-/*func TestMain(m *testing.M) {
-	cf = *config.Instance()
+// stubRepository repository of the tests, methods not overridden panic when called
+type stubRepository struct {
+	boilerplate.MongoRepository
+}
+
+func (stubRepository) GetHeartBeatFromMongo(context.Context) (heartbeat.MongoResponse, error) {
+	return heartbeat.MongoResponse{Timestamp: "2026-10-17T00:00:00Z", AppMongoDb: "Connection alive"}, nil
+}
+
+func TestMain(m *testing.M) {
+	cf = config.AppConfig{MongoDBName: "test"}
+	config.SetInstance(&cf)
 	logger = log.NewNopLogger()
-	_, repo = test.GetRepository(cf.DatabaseDriverPostgres, cf.DefaultDBName)
-	srv = httptest.NewServer(test.GetHandlers())
+	repo = map[string]boilerplate.MongoRepository{cf.MongoDBName: stubRepository{}}
 	svc = implementation.NewService(repo, logger)
-	defer srv.Close()
 	os.Exit(m.Run())
-}*/
+}
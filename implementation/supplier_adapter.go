@@ -0,0 +1,47 @@
+package implementation
+
+import (
+	"context"
+	"strings"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/request_response/supplier"
+	"swallow-supplier/request_response/travolution"
+	"swallow-supplier/request_response/yanolja"
+	"swallow-supplier/services/suppliers"
+	"swallow-supplier/utils/constant"
+)
+
+// supplierOfOrder supplier which fulfils the order, orders created before the supplier was stored belong to yanolja
+func supplierOfOrder(order domain.Model) string {
+	if strings.TrimSpace(order.Suppliers) == "" {
+		return constant.SUPPLIERYANOLJA
+	}
+	return order.Suppliers
+}
+
+// supplierAdapter returns the booking adapter of the supplier
+func supplierAdapter(ctx context.Context, supplierName string) (suppliers.SupplierAdapter, error) {
+	return suppliers.New(ctx, supplierName)
+}
+
+// toYanoljaResponse converts the supplier neutral response into the response used by the GGT layer
+func toYanoljaResponse(res supplier.Response) yanolja.Response {
+	return yanolja.Response{
+		Code:        res.Code,
+		TraceID:     res.TraceID,
+		Body:        res.Body,
+		Page:        res.Page,
+		Collection:  res.Collection,
+		ContentType: res.ContentType,
+	}
+}
+
+// toTravolutionResponse converts the supplier neutral response into the travolution response
+func toTravolutionResponse(res supplier.Response) travolution.Response {
+	return travolution.Response{
+		Code:            res.Code,
+		Body:            res.Body,
+		Contents:        res.Contents,
+		HtmlTypeContent: res.ContentType,
+	}
+}
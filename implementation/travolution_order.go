@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"swallow-supplier/config"
 	customError "swallow-supplier/error"
+	"swallow-supplier/request_response/supplier"
 	"swallow-supplier/request_response/travolution"
 	travolutionSvc "swallow-supplier/services/suppliers/travolution"
 
@@ -75,8 +76,14 @@ func (s *service) CreateTravolutionOrder(ctx context.Context, req travolution.Or
 	}
 	fmt.Println("---------3----------")
 
-	var tsvc, _ = travolutionSvc.New(ctx)
-	resp, err = tsvc.TravolutionOrder(ctx, req, product.Type)
+	adapter, err := supplierAdapter(ctx, constant.SUPPLIERTRAVOLUTION)
+	if err != nil {
+		level.Error(logger).Log(" travolution error ", err)
+		resp.Code = "500"
+		return resp, err
+	}
+	prepared, err := adapter.Prepare(ctx, supplier.PrepareRequest{PartnerOrderId: req.ReferenceNumber, Travolution: req, BookingType: product.Type})
+	resp = toTravolutionResponse(prepared)
 	if err != nil {
 		level.Error(logger).Log(" travolution error ", err)
 		resp.Code = "500"
@@ -234,8 +241,14 @@ func (s *service) CancelTravolutionOrder(ctx context.Context, orderNumber string
 	level.Info(logger).Log(" info ", "travolution service call")
 
 	// update cancel_request
-	var tsvc, _ = travolutionSvc.New(ctx)
-	resp, err = tsvc.TravolutionCancelOrder(ctx, orderNumber)
+	adapter, err := supplierAdapter(ctx, constant.SUPPLIERTRAVOLUTION)
+	if err != nil {
+		level.Error(logger).Log(" travolution error ", err)
+		resp.Code = "500"
+		return resp, err
+	}
+	canceled, err := adapter.Cancel(ctx, supplier.CancelRequest{OrderId: orderNumber, PartnerOrderId: order.ReferenceNumber, Type: supplier.CancelFull})
+	resp = toTravolutionResponse(canceled)
 	if err != nil {
 		level.Error(logger).Log(" travolution error ", err)
 		resp.Code = "500"
@@ -285,7 +298,7 @@ func (s *service) OrderWebhookUpdate(ctx context.Context, payload domain.Webhook
 	// insert event call to mongo
 	_, err = s.mongoRepository[config.Instance().MongoDBName].UpsertTravolutionWebhook(ctx, payload)
 	if err != nil {
-		level.Error(logger).Log(" InsertTravolutionWebhook reposotory error ", fmt.Sprintf("%w", err))
+		level.Error(logger).Log(" InsertTravolutionWebhook reposotory error ", fmt.Sprintf("%v", err))
 		resp.Code = "500"
 		resp.Body = fmt.Errorf("InsertTravolutionWebhook error in repository %w ", err)
 		return resp, err
@@ -311,7 +324,7 @@ func (s *service) OrderWebhookUpdate(ctx context.Context, payload domain.Webhook
 		}
 		_, err = s.mongoRepository[config.Instance().MongoDBName].UpsertWebhookToOrder(ctx, payload, update)
 		if err != nil {
-			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%w", err))
+			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%v", err))
 			resp.Code = "500"
 			resp.Body = fmt.Errorf("UpsertWebhookToOrder error in repository %w ", err)
 			return resp, err
@@ -330,7 +343,7 @@ func (s *service) OrderWebhookUpdate(ctx context.Context, payload domain.Webhook
 		//status = "AP" // used
 		_, err = s.mongoRepository[config.Instance().MongoDBName].UpsertWebhookToOrder(ctx, payload, update)
 		if err != nil {
-			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%w", err))
+			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%v", err))
 			resp.Code = "500"
 			resp.Body = fmt.Errorf("UpsertWebhookToOrder error in repository %w ", err)
 			return resp, err
@@ -348,7 +361,7 @@ func (s *service) OrderWebhookUpdate(ctx context.Context, payload domain.Webhook
 		//status = "AV" // available
 		_, err = s.mongoRepository[config.Instance().MongoDBName].UpsertWebhookToOrder(ctx, payload, update)
 		if err != nil {
-			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%w", err))
+			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%v", err))
 			resp.Code = "500"
 			resp.Body = fmt.Errorf("UpsertWebhookToOrder error in repository %w ", err)
 			return resp, err
@@ -367,7 +380,7 @@ func (s *service) OrderWebhookUpdate(ctx context.Context, payload domain.Webhook
 		//status = "CL"
 		_, err = s.mongoRepository[config.Instance().MongoDBName].UpsertWebhookToOrder(ctx, payload, update)
 		if err != nil {
-			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%w", err))
+			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%v", err))
 			resp.Code = "500"
 			resp.Body = fmt.Errorf("UpsertWebhookToOrder error in repository %w ", err)
 			return resp, err
@@ -386,7 +399,7 @@ func (s *service) OrderWebhookUpdate(ctx context.Context, payload domain.Webhook
 		//status = "RJ"
 		_, err = s.mongoRepository[config.Instance().MongoDBName].UpsertWebhookToOrder(ctx, payload, update)
 		if err != nil {
-			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%w", err))
+			level.Error(logger).Log(" UpsertWebhookToOrder reposotory error  ", fmt.Sprintf("%v", err))
 			resp.Code = "500"
 			resp.Body = fmt.Errorf("UpsertWebhookToOrder error in repository %w ", err)
 			return resp, err
@@ -396,7 +409,7 @@ func (s *service) OrderWebhookUpdate(ctx context.Context, payload domain.Webhook
 		// BOOKING_REJECTED event, then immediately proceed with cancellation of order
 		resp, err = s.CancelTravolutionOrder(ctx, payload.Data.OrderNumber)
 		if err != nil {
-			level.Error(logger).Log(" CancelTravolutionOrder service error  ", fmt.Sprintf("%w", err))
+			level.Error(logger).Log(" CancelTravolutionOrder service error  ", fmt.Sprintf("%v", err))
 			resp.Code = "500"
 			resp.Body = fmt.Errorf("CancelTravolutionOrder service error %w ", err)
 			return resp, err
@@ -7,8 +7,10 @@ import (
 	"reflect"
 	"runtime/debug"
 	"strconv"
+	"swallow-supplier/caches/cache"
 	"swallow-supplier/config"
 	customError "swallow-supplier/error"
+	travolutionMapper "swallow-supplier/mapper/trip_to_travolution"
 	domain "swallow-supplier/mongo/domain/travolution"
 	"swallow-supplier/request_response/travolution"
 	travolutionSvc "swallow-supplier/services/suppliers/travolution"
@@ -514,6 +516,19 @@ func (s *service) PostCreateAllProduct(ctx context.Context) (resp travolution.Re
 			return resp, customError.NewError(ctx, "repository error", fmt.Sprint(customError.ErrDatabase.Error(), "InsertTravolutionProduct"), nil)
 		}
 
+		// publish plu of the product so trip requests can be resolved to travolution
+		cacheLayer, err := cache.New(config.Instance().CacheName)
+		if err != nil {
+			level.Error(logger).Log("error", fmt.Sprintf("Error initializing cache layer: %s", err))
+			resp.Code = "500"
+			return resp, err
+		}
+		for key, plu := range travolutionMapper.GeneratePlus(rawProductDetail) {
+			if err := cacheLayer.Set(ctx, key, plu); err != nil {
+				level.Error(logger).Log("cache error", "publishing travolution plu", "plu", plu, "error", err)
+			}
+		}
+
 		insertedIDs = append(insertedIDs, insertedID)
 	}
 
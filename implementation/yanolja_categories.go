@@ -15,6 +15,7 @@ import (
 	yanoljasvc "swallow-supplier/services/suppliers/yanolja"
 	"swallow-supplier/utils"
 	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/pagination"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -81,6 +82,40 @@ func (s *service) GetCategories(ctx context.Context) (resp yanolja.Response, err
 
 }
 
+// GetStoredCategories one page of the categories stored in ggt with the total and page links
+func (s *service) GetStoredCategories(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error) {
+	var requestID = utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetStoredCategories",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+	}(ctx)
+
+	records, total, next, err := s.mongoRepository[config.Instance().MongoDBName].FindCategory(ctx, q)
+	if err != nil {
+		level.Error(logger).Log("error", "request to FindCategory raised error ", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error from FindCategory, %v", err), "FindCategory")
+	}
+
+	resp.Code = "200"
+	resp.Body = listBody(ctx, q, records, total, next)
+	return resp, nil
+}
+
 // InsertAllCategories insert all product's categories from yanolja to ggt
 // This api reads from yanolja serve and write it to GGT
 func (s *service) InsertAllCategories(ctx context.Context) (resp yanolja.Response, err error) {
@@ -148,6 +183,8 @@ func (s *service) InsertAllCategories(ctx context.Context) (resp yanolja.Respons
 
 // UpsertCategoryMapping upsert all  products categories from yanolja- ggt - trip mapping
 // reading from excel provided by @james
+// Deprecated: reads the sheet from YGTFilePath without validation, upload the sheet to
+// POST /v1/category-mappings/upload instead
 func (s *service) UpsertCategoryMapping(ctx context.Context) (resp yanolja.Response, err error) {
 	var requestID = utils.GenerateUUID("GGT", true)
 
@@ -12,10 +12,11 @@ import (
 	customError "swallow-supplier/error"
 	domain "swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/request_response/common"
+	"swallow-supplier/request_response/supplier"
 	"swallow-supplier/request_response/yanolja"
-	yanoljasvc "swallow-supplier/services/suppliers/yanolja"
 	"swallow-supplier/utils"
 	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
 	"swallow-supplier/utils/validator"
 	"time"
 
@@ -64,8 +65,14 @@ func (s *service) PostWaitForOrder(ctx context.Context, req yanolja.WaitingForOr
 		return resp, err
 	}
 
-	var ordersvc, _ = yanoljasvc.New(ctx)
-	resp, err = ordersvc.WaitingForOrder(ctx, req)
+	adapter, err := supplierAdapter(ctx, constant.SUPPLIERYANOLJA)
+	if err != nil {
+		level.Error(logger).Log("error", "supplier adapter not available ", err)
+		resp.Code = "500"
+		return resp, err
+	}
+	prepared, err := adapter.Prepare(ctx, supplier.PrepareRequest{PartnerOrderId: req.PartnerOrderID, Yanolja: req})
+	resp = toYanoljaResponse(prepared)
 	if err != nil {
 		level.Error(logger).Log("error", "request to yanolja client raise error ", err)
 		resp.Code = "500"
@@ -196,8 +203,27 @@ func (s *service) PostOrderCompletion(ctx context.Context, req yanolja.OrderConf
 
 	}(ctx)
 
-	var getsvc, _ = yanoljasvc.New(ctx)
-	resp, err = getsvc.OrderComplete(ctx, req)
+	record, err := s.mongoRepository[config.Instance().MongoDBName].GetOrderbyOrderId(ctx, req.OrderId)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching record based on orderId ", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by orderId, %v", err), "GetOrderbyOrderId")
+	}
+	// an order which may not be confirmed any more is not sent to yanolja
+	if _, err = orderstate.OrderTransition(record, constant.ORDERDONE, orderstate.EventConfirmed, orderstate.SourceTrip, time.Now()); err != nil {
+		level.Error(logger).Log("error", "order may not be confirmed", "err", err)
+		resp.Code = "409"
+		return resp, orderTransitionError(ctx, err, "postOrderCompletion")
+	}
+
+	adapter, err := supplierAdapter(ctx, constant.SUPPLIERYANOLJA)
+	if err != nil {
+		level.Error(logger).Log("error", "supplier adapter not available ", err)
+		resp.Code = "500"
+		return resp, err
+	}
+	confirmed, err := adapter.Confirm(ctx, supplier.ConfirmRequest{OrderId: strconv.FormatInt(req.OrderId, 10), PartnerOrderId: req.PartnerOrderId})
+	resp = toYanoljaResponse(confirmed)
 
 	if err != nil {
 		level.Error(logger).Log("error", "request to yanolja client raise error ", err)
@@ -215,7 +241,16 @@ func (s *service) PostOrderCompletion(ctx context.Context, req yanolja.OrderConf
 		"updatedAt":       time.Now().UTC().Format(time.RFC3339),
 	}
 
-	id, err := s.mongoRepository[config.Instance().MongoDBName].UpdateOrderByOrderId(ctx, req.OrderId, update)
+	transitions := make([]domain.StatusTransition, 0, 1)
+	transition, err := orderstate.OrderTransition(record, fmt.Sprintf("%v", orderResp["orderStatusCode"]), orderstate.EventConfirmed, orderstate.SourceTrip, time.Now())
+	if err != nil {
+		// yanolja has the last word on the status, it is stored without a history entry
+		level.Error(logger).Log("error", "confirmed status is not a legal transition", "err", err)
+	} else if transition != nil {
+		transitions = append(transitions, *transition)
+	}
+
+	id, err := s.mongoRepository[config.Instance().MongoDBName].UpdateOrderByOrderId(ctx, req.OrderId, update, transitions...)
 	if err != nil {
 		level.Error(logger).Log("error", "repository error on order update ", err)
 		cancellationresp, err := s.PostForcelyCancelOrder(ctx, req.PartnerOrderId)
@@ -344,7 +379,6 @@ func (s *service) PostOrderCompletion(ctx context.Context, req yanolja.OrderConf
 		}
 	}
 
-	var record domain.Model
 	record, err = s.mongoRepository[config.Instance().MongoDBName].GetOrderbyOrderId(ctx, req.OrderId)
 	if err != nil {
 		level.Error(logger).Log("repository error", "fetching record based on orderId ", err)
@@ -394,8 +428,14 @@ func (s *service) GetOrderByOrderId(ctx context.Context, req yanolja.OrderConfir
 
 	}(ctx)
 
-	var getsvc, _ = yanoljasvc.New(ctx)
-	resp, err = getsvc.GetOrderbyId(ctx, req)
+	adapter, err := supplierAdapter(ctx, constant.SUPPLIERYANOLJA)
+	if err != nil {
+		level.Error(logger).Log("error", "supplier adapter not available ", err)
+		resp.Code = "500"
+		return resp, err
+	}
+	status, err := adapter.Status(ctx, supplier.StatusRequest{OrderId: strconv.FormatInt(req.OrderId, 10), PartnerOrderId: req.PartnerOrderId})
+	resp = toYanoljaResponse(status)
 
 	if err != nil {
 		level.Error(logger).Log("error", "request to yanolja client raise error ", err)
@@ -431,6 +471,9 @@ func (s *service) GetOrderByOrderId(ctx context.Context, req yanolja.OrderConfir
 		resp.Code = "500"
 		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by orderId, %v", err), "GetOrderbyOrderId")
 	}
+	for i := range rec.SelectVariants {
+		rec.SelectVariants[i].ProductVersionLink = domain.ProductVersionLink(rec.SelectVariants[i].ProductID, rec.SelectVariants[i].ProductVersion)
+	}
 	resp.Code = "200"
 	resp.Body = rec
 	level.Info(logger).Log("response")
@@ -490,61 +533,45 @@ func (s *service) PostCancelOrderEntirly(ctx context.Context, orderId int64) (re
 
 	// product.isCancelPenalty == false then cancellation is allowed
 
-	var getsvc, _ = yanoljasvc.New(ctx)
-	resp, err = getsvc.CancelFullOrder(ctx, orderId)
-
+	// every variant must be allowed to move to CANCELING before yanolja is asked
+	transitions, err := orderstate.VariantTransitions(record, nil, constant.ORDERVARIANTCANCELINGSTATUS, orderstate.EventCancelRequested, orderstate.Source(ctx, orderstate.SourceAdmin), time.Now())
 	if err != nil {
-		level.Error(logger).Log("error", "request to yanolja client raise error during full cancellation order ", err)
-		return resp, err //customError.NewError(ctx, "leisure-api-0004", fmt.Sprintf(" full cancellation order yanolja server %v", err), "yanolja")
+		level.Error(logger).Log("error", "order may not be canceled", "err", err)
+		resp.Code = "409"
+		return resp, orderTransitionError(ctx, err, "postCancelOrderEntirly")
 	}
 
-	level.Info(logger).Log("response", resp)
-
-	err = s.mongoRepository[config.Instance().MongoDBName].UpdateOrderVariantStatusByOrderId(ctx, orderId, "CANCELING")
+	ids := orderVariantIds(record)
+	// the refund is quoted before the supplier is asked, under the policies in force at request time.
+	// The cancellation does not depend on the quote, it goes on unquoted when quoting fails
+	quote, err := quoteRefund(ctx, s, record, ids)
 	if err != nil {
-		level.Error(logger).Log("repository error", "error in updaing order variant status ", err)
-		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("error in updaing order variant status by  orderId %d, %v", orderId, err), "UpdateOrderVariantStatusByOrderId")
+		level.Error(logger).Log("error", "quoting refund of order, canceling unquoted", "orderId", orderId, "err", err)
 	}
 
-	orderResp := resp.Body.(map[string]interface{})
-	cancelStatusCode := fmt.Sprintf("%v", orderResp["cancelStatusCode"])
-	cancelFailReasonCode := fmt.Sprintf("%v", orderResp["cancelFailReasonCode"])
-
-	if cancelStatusCode != "FAIL" && cancelStatusCode != "DIRECT" && cancelStatusCode != "ADMIN" {
-		level.Error(logger).Log("error", "cancelStatusCode status is incorrect", err)
+	adapter, err := supplierAdapter(ctx, supplierOfOrder(record))
+	if err != nil {
+		level.Error(logger).Log("error", "supplier adapter not available ", err)
 		resp.Code = "500"
-		return resp, customError.NewError(ctx, "leisure-api-0009", fmt.Sprintf("cancelStatusCode is wrong"), "PostCancelOrderEntirly")
+		return resp, err
 	}
+	canceled, err := adapter.Cancel(ctx, supplier.CancelRequest{OrderId: strconv.FormatInt(orderId, 10), PartnerOrderId: record.PartnerOrderID, Type: supplier.CancelFull})
+	resp = toYanoljaResponse(canceled)
 
-	level.Info(logger).Log("info", "cancelStatusCode", cancelStatusCode)
-	if cancelStatusCode == "FAIL" {
-		for _, ov := range record.OrderVariants {
-			err = s.mongoRepository[config.Instance().MongoDBName].UpdateCancelDetailsForVariants(ctx, orderId, ov.ProductID, ov.OrderVariantID, cancelFailReasonCode, cancelStatusCode)
-			if err != nil {
-				resp.Code = "500"
-				level.Error(logger).Log("error", "request to yanolja client raise error ", err)
-				return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on order update by orderId, %v", err), "PostCancelOrderEntirly")
-			}
-
-		}
-	} else if cancelStatusCode == "DIRECT" || cancelStatusCode == "ADMIN" {
-		level.Info(logger).Log("response", resp)
-
-		//partnerOrderId := record.PartnerOrderID
+	if err != nil {
+		level.Error(logger).Log("error", "request to yanolja client raise error during full cancellation order ", err)
+		return resp, err //customError.NewError(ctx, "leisure-api-0004", fmt.Sprintf(" full cancellation order yanolja server %v", err), "yanolja")
+	}
 
-		for _, ov := range record.OrderVariants {
-			err = s.mongoRepository[config.Instance().MongoDBName].UpdateCancelDetailsForVariants(ctx, orderId,
-				ov.ProductID, ov.OrderVariantID, "", cancelStatusCode)
+	level.Info(logger).Log("response", resp)
 
-			if err != nil {
-				resp.Code = "500"
-				resp.Body = record
-				return resp, fmt.Errorf("error in updating failed reason and status code")
-			}
-		}
+	err = s.mongoRepository[config.Instance().MongoDBName].UpdateCancelRequest(ctx, orderId, constant.TRIPFULLORDERCANCELREQUEST, ids, quote, transitions)
+	if err != nil {
+		level.Error(logger).Log("repository error", "error in updaing order variant status ", err)
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("error in updaing order variant status by  orderId %d, %v", orderId, err), "UpdateCancelRequest")
 	}
 
-	return resp, nil
+	return recordCancelStatus(ctx, logger, s, record, ids, withRefundQuote(resp, quote))
 }
 
 // PostCancelOrderByReqTimeOut cancel order when request timeout
@@ -570,8 +597,21 @@ func (s *service) PostCancelOrderByReqTimeOut(ctx context.Context, partnerOrderI
 
 	}(ctx)
 
-	var getsvc, _ = yanoljasvc.New(ctx)
-	resp, err = getsvc.TimeoutAndForcedOrderCancellation(ctx, partnerOrderId)
+	adapter, err := supplierAdapter(ctx, constant.SUPPLIERYANOLJA)
+	if err != nil {
+		level.Error(logger).Log("error", "supplier adapter not available ", err)
+		resp.Code = "500"
+		return resp, err
+	}
+	transitions, err := forcedCancelTransitions(ctx, s, partnerOrderId, orderstate.EventTimeoutCanceled)
+	if err != nil {
+		level.Error(logger).Log("error", "order may not be canceled", "err", err)
+		resp.Code = "409"
+		return resp, orderTransitionError(ctx, err, "UpdateForcedCancelOrderDetail")
+	}
+
+	canceled, err := adapter.Cancel(ctx, supplier.CancelRequest{PartnerOrderId: partnerOrderId, Type: supplier.CancelTimeout})
+	resp = toYanoljaResponse(canceled)
 
 	if err != nil {
 		level.Error(logger).Log("error", "request to yanolja client raise error ", err)
@@ -580,7 +620,7 @@ func (s *service) PostCancelOrderByReqTimeOut(ctx context.Context, partnerOrderI
 
 	level.Info(logger).Log("response from yanolja", resp)
 
-	_, err = s.mongoRepository[config.Instance().MongoDBName].UpdateForcedCancelOrderDetail(ctx, partnerOrderId, constant.ORDERVARIANTCANCELEDSTATUS)
+	_, err = s.mongoRepository[config.Instance().MongoDBName].UpdateForcedCancelOrderDetail(ctx, partnerOrderId, constant.ORDERVARIANTCANCELEDSTATUS, transitions)
 	if err != nil {
 		level.Error(logger).Log("error", "timeout cancel order update issue ", err)
 		resp.Code = "500"
@@ -615,8 +655,21 @@ func (s *service) PostForcelyCancelOrder(ctx context.Context, partnerOrderId str
 
 	}(ctx)
 
-	var getsvc, _ = yanoljasvc.New(ctx)
-	resp, err = getsvc.ForcedOrderCancellation(ctx, partnerOrderId)
+	adapter, err := supplierAdapter(ctx, constant.SUPPLIERYANOLJA)
+	if err != nil {
+		level.Error(logger).Log("error", "supplier adapter not available ", err)
+		resp.Code = "500"
+		return resp, err
+	}
+	transitions, err := forcedCancelTransitions(ctx, s, partnerOrderId, orderstate.EventForcedCanceled)
+	if err != nil {
+		level.Error(logger).Log("error", "order may not be canceled", "err", err)
+		resp.Code = "409"
+		return resp, orderTransitionError(ctx, err, "UpdateForcedCancelOrderDetail")
+	}
+
+	canceled, err := adapter.Cancel(ctx, supplier.CancelRequest{PartnerOrderId: partnerOrderId, Type: supplier.CancelForced})
+	resp = toYanoljaResponse(canceled)
 
 	if err != nil {
 		level.Error(logger).Log("error", "request to yanolja client raise error ", err)
@@ -625,7 +678,7 @@ func (s *service) PostForcelyCancelOrder(ctx context.Context, partnerOrderId str
 
 	level.Info(logger).Log("response from yanolja", resp)
 
-	_, err = s.mongoRepository[config.Instance().MongoDBName].UpdateForcedCancelOrderDetail(ctx, partnerOrderId, constant.ORDERVARIANTCANCELEDSTATUS)
+	_, err = s.mongoRepository[config.Instance().MongoDBName].UpdateForcedCancelOrderDetail(ctx, partnerOrderId, constant.ORDERVARIANTCANCELEDSTATUS, transitions)
 	if err != nil {
 		level.Error(logger).Log("error", "forced cancel order update issue ", err)
 		resp.Code = "500"
@@ -838,6 +891,11 @@ func PreOrderCreationValidate(ctx context.Context, s *service, req yanolja.Waiti
 		return e
 	}
 
+	// sold out dates are refused here rather than by the supplier
+	if err = checkAvailability(ctx, s.logger, req); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -862,11 +920,13 @@ func UpdateReconcilationDetail(ctx context.Context, s *service, logger log.Logge
 	level.Info(logger).Log("function name", "UpdateReconciliationDetail")
 
 	// Update MongoDB with the modified reconciliation details array
+	// keyed on the order variant so a partial cancellation leaves the other units alone
 	filter := map[string]any{
 		"orderId":        orderId,
 		"partnerOrderId": partnerOrderId,
 		"productId":      ov.ProductID,
 		"variantId":      ov.VariantID,
+		"orderVariantId": ov.OrderVariantID,
 	}
 	// Fetch existing reconciliation details
 	reconDetails, err := s.mongoRepository[config.Instance().MongoDBName].GetReconciliationDetailsByOrderAndVariant(
@@ -930,7 +990,7 @@ func (s *service) GetEverlandOrders(ctx context.Context, req yanolja.EverlandGet
 
 	}(ctx)
 
-	rec, err := s.mongoRepository[config.Instance().MongoDBName].GetOrdersByChannelCodeAndCustomerEmail(ctx, req.ChannelCode, req.CustomerEmail)
+	rec, total, next, err := s.mongoRepository[config.Instance().MongoDBName].GetOrdersByChannelCodeAndCustomerEmail(ctx, req.ChannelCode, req.CustomerEmail, req.Query)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			level.Error(logger).Log("repository error", "no record exist based on cuatomer email and channelcode  ", err)
@@ -945,7 +1005,7 @@ func (s *service) GetEverlandOrders(ctx context.Context, req yanolja.EverlandGet
 	}
 
 	resp.Code = "200"
-	resp.Body = rec
+	resp.Body = unpagedListBody(ctx, req.Query, rec, total, next)
 
 	return resp, nil
 
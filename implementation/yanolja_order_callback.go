@@ -1,8 +1,10 @@
 package implementation
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"swallow-supplier/caches/cache"
 	"swallow-supplier/config"
@@ -10,8 +12,9 @@ import (
 	trip_domain "swallow-supplier/mongo/domain/trip"
 	domain "swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/request_response/yanolja"
-	tripservice "swallow-supplier/services/distributors/trip"
 	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
+	"time"
 
 	"swallow-supplier/utils"
 
@@ -53,7 +56,19 @@ func (s *service) CancellationAckClbk(ctx context.Context, ackreq yanolja.Cancel
 		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by orderId, %v", err), "GetOrderbyOrderId")
 	}
 
+	// a partial cancellation only acknowledges the variants asked to cancel
+	ackedIds := canceledVariantIds(record)
+	transitions, err := orderstate.VariantTransitions(record, ackedIds, constant.ORDERVARIANTCANCELEDSTATUS, orderstate.EventCancelAcked, orderstate.SourceYanolja, time.Now())
+	if err != nil {
+		resp.Code = "409"
+		level.Error(logger).Log("error", "cancellation may not be acknowledged", "err", err)
+		return resp, orderTransitionError(ctx, err, "CancellationAckClbk")
+	}
+
 	for _, variant := range record.OrderVariants {
+		if ackedIds != nil && !slices.Contains(ackedIds, variant.OrderVariantID) {
+			continue
+		}
 		err = UpdateReconcilationDetail(ctx, s, logger, ackreq.OrderId, ackreq.PartnerOrderId, variant)
 		if err != nil {
 			resp.Code = "500"
@@ -62,10 +77,14 @@ func (s *service) CancellationAckClbk(ctx context.Context, ackreq yanolja.Cancel
 		}
 	}
 
-	err = s.mongoRepository[config.Instance().MongoDBName].UpdateOrderCancelAck(ctx, ackreq.OrderId, ackreq.PartnerOrderId, ackreq.OrderCancelTypeCode, "CANCELED")
+	err = s.mongoRepository[config.Instance().MongoDBName].UpdateOrderCancelAck(ctx, ackreq.OrderId, ackreq.PartnerOrderId, ackreq.OrderCancelTypeCode, "CANCELED", ackedIds, transitions)
 	if err != nil {
 		resp.Code = "500"
 		level.Error(logger).Log("repository_error", "UpdateOrderCancelAck  throws error ", err)
+		if errors.Is(err, orderstate.ErrStaleTransition) {
+			resp.Code = "409"
+			return resp, orderTransitionError(ctx, err, "CancellationAckClbk")
+		}
 		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on update order by orderId and partnerOrderId, %v", err), "CancellationAckClbk")
 	}
 	level.Info(logger).Log("info", "document update with orderId ", ackreq.OrderId)
@@ -79,7 +98,7 @@ func (s *service) CancellationAckClbk(ctx context.Context, ackreq yanolja.Cancel
 
 	level.Info(logger).Log("info", "updated document with OrderId ", ackreq.OrderId)
 
-	processTripNotification(ctx, logger, s, "CancelAckNotify", record, constant.TRIPFULLORDERCANCELREQUEST)
+	processTripNotification(ctx, logger, s, "CancelAckNotify", record, cancelRequestType(record))
 
 	resp.Body = record
 	resp.Code = "200"
@@ -109,15 +128,34 @@ func (s *service) RefusalToCancelClbk(ctx context.Context, refusaltocancel yanol
 
 	}(ctx)
 
-	err = s.mongoRepository[config.Instance().MongoDBName].UpdateRefusalToCancelInfo(ctx, refusaltocancel.OrderId, refusaltocancel.PartnerOrderId, refusaltocancel.OrderVariantID, refusaltocancel.CancelRejectTypeCode, refusaltocancel.Message)
+	record, err := s.mongoRepository[config.Instance().MongoDBName].GetOrderbyOrderId(ctx, refusaltocancel.OrderId)
+	if err != nil {
+		resp.Code = "500"
+		level.Error(logger).Log("repository_error", "GetOrderbyOrderId  throws error ", err)
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by orderId, %v", err), "GetOrderbyOrderId")
+	}
+
+	// a refusal arriving after the variant was canceled must not bring it back
+	transitions, err := orderstate.VariantTransitions(record, []int64{refusaltocancel.OrderVariantID}, constant.ORDERVARIANTNOTUSEDSTATUS, orderstate.EventCancelRefused, orderstate.SourceYanolja, time.Now())
+	if err != nil {
+		resp.Code = "409"
+		level.Error(logger).Log("error", "cancellation may not be refused", "err", err)
+		return resp, orderTransitionError(ctx, err, "RefusalToCancelClbk")
+	}
+
+	err = s.mongoRepository[config.Instance().MongoDBName].UpdateRefusalToCancelInfo(ctx, refusaltocancel.OrderId, refusaltocancel.PartnerOrderId, refusaltocancel.OrderVariantID, refusaltocancel.CancelRejectTypeCode, refusaltocancel.Message, transitions)
 	if err != nil {
 		resp.Code = "500"
 		level.Error(logger).Log("repository_error", "UpdateRefusalToCancelInfo  throws error ", err)
+		if errors.Is(err, orderstate.ErrStaleTransition) {
+			resp.Code = "409"
+			return resp, orderTransitionError(ctx, err, "RefusalToCancelClbk")
+		}
 		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on update order by orderId and partnerOrderId, %v", err), "RefusalToCancelClbk")
 	}
 	level.Info(logger).Log("info", "document update with orderId ", refusaltocancel.OrderId)
 
-	record, err := s.mongoRepository[config.Instance().MongoDBName].GetOrderbyOrderId(ctx, refusaltocancel.OrderId)
+	record, err = s.mongoRepository[config.Instance().MongoDBName].GetOrderbyOrderId(ctx, refusaltocancel.OrderId)
 	if err != nil {
 		level.Error(logger).Log("repository error", "fetching record based on orderId ", err)
 		resp.Code = "500"
@@ -126,7 +164,7 @@ func (s *service) RefusalToCancelClbk(ctx context.Context, refusaltocancel yanol
 	level.Info(logger).Log("info", "updated document with OrderId ", refusaltocancel.OrderId)
 
 	if IsAllVariantCancilationDone(record) {
-		processTripNotification(ctx, logger, s, "CancelAckNotify", record, constant.TRIPFULLORDERCANCELREQUEST)
+		processTripNotification(ctx, logger, s, "CancelAckNotify", record, cancelRequestType(record))
 	}
 
 	resp.Body = record
@@ -213,6 +251,17 @@ func (s *service) ForcedOrderCancellationClbk(ctx context.Context, forcecancella
 		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by orderId, %v", err), "GetOrderbyOrderId")
 	}
 
+	canceledIds := make([]int64, 0, len(forcecancellation.ForceCancelVariants))
+	for _, variant := range forcecancellation.ForceCancelVariants {
+		canceledIds = append(canceledIds, variant.OrderVariantID)
+	}
+	transitions, err := orderstate.VariantTransitions(record, canceledIds, constant.ORDERVARIANTCANCELEDSTATUS, orderstate.EventForcedCanceled, orderstate.SourceYanolja, time.Now())
+	if err != nil {
+		resp.Code = "409"
+		level.Error(logger).Log("error", "variants may not be canceled", "err", err)
+		return resp, orderTransitionError(ctx, err, "ForcedOrderCancellationClbk")
+	}
+
 	var incrmnt int8 = 0
 	for _, ordervariant := range record.OrderVariants {
 		if ordervariant.OrderVariantID == forcecancellation.ForceCancelVariants[incrmnt].OrderVariantID {
@@ -225,10 +274,15 @@ func (s *service) ForcedOrderCancellationClbk(ctx context.Context, forcecancella
 
 			err = s.mongoRepository[config.Instance().MongoDBName].ForcedCancellationReasonUpdate(ctx, forcecancellation.OrderId,
 				forcecancellation.PartnerOrderId, forcecancellation.ForceCancelVariants[incrmnt].OrderVariantID,
-				forcecancellation.ForceCancelVariants[incrmnt].ForceCancelTypeCode)
+				forcecancellation.ForceCancelVariants[incrmnt].ForceCancelTypeCode,
+				orderstate.ForVariant(transitions, forcecancellation.ForceCancelVariants[incrmnt].OrderVariantID))
 			if err != nil {
 				resp.Code = "500"
 				level.Error(logger).Log("repository_error", "ForcedCancellationReasonUpdate  throws error ", err)
+				if errors.Is(err, orderstate.ErrStaleTransition) {
+					resp.Code = "409"
+					return resp, orderTransitionError(ctx, err, "ForcedOrderCancellationClbk")
+				}
 				return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on update order by orderId and partnerOrderId, %v", err), "CancellationAckClbk")
 			}
 			incrmnt = incrmnt + 1
@@ -440,6 +494,25 @@ func (s *service) ProcessingOrRestoringClbk(ctx context.Context, req yanolja.Pro
 		return resp, customError.NewError(ctx, "leisure-api-0006", fmt.Sprintf("eventType value is not CONSUME|RESTORED, %v", err), "ProcessingOrRestoringClbk")
 	}
 	level.Info(logger).Log("event_type : ", req.EventType)
+
+	order, err := s.mongoRepository[config.Instance().MongoDBName].GetOrderbyOrderId(ctx, req.OrderId)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching order based on orderId ", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by orderId, %v", err), "GetOrderbyOrderId")
+	}
+
+	to, event := constant.ORDERVARIANTUSEDSTATUS, orderstate.EventUsed
+	if req.EventType == constant.CONSTANTRESTORE {
+		to, event = constant.ORDERVARIANTNOTUSEDSTATUS, orderstate.EventRestored
+	}
+	transitions, err := orderstate.VariantTransitions(order, []int64{req.OrderVariantId}, to, event, orderstate.SourceYanolja, time.Now())
+	if err != nil {
+		level.Error(logger).Log("error", "voucher may not be "+strings.ToLower(event), "err", err)
+		resp.Code = "409"
+		return resp, orderTransitionError(ctx, err, "ProcessingOrRestoringClbk")
+	}
+
 	update := make(map[string]any)
 	if req.EventType == constant.CONSTANTCONSUME {
 		update = map[string]any{
@@ -462,9 +535,13 @@ func (s *service) ProcessingOrRestoringClbk(ctx context.Context, req yanolja.Pro
 	}
 
 	level.Info(logger).Log("Info ", "record to update ", " update ", update)
-	err = s.mongoRepository[config.Instance().MongoDBName].UpdateProcessingRestoringOfOrder(ctx, req.OrderId, update)
+	err = s.mongoRepository[config.Instance().MongoDBName].UpdateProcessingRestoringOfOrder(ctx, req.OrderId, update, transitions)
 	if err != nil {
 		level.Error(logger).Log("error", "request to yanolja client raise error from database ", err)
+		if errors.Is(err, orderstate.ErrStaleTransition) {
+			resp.Code = "409"
+			return resp, orderTransitionError(ctx, err, "ProcessingOrRestoringClbk")
+		}
 		resp.Body = "Database error in updating used or restoring detail"
 		resp.Body = "500"
 		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on fetching order by partnerOrderId, %v", err), "UpdateProcessingRestoringOfOrder")
@@ -497,7 +574,7 @@ func SayHello(logger log.Logger, record domain.Model) {
 
 }
 
-// processTripNotification handles Trip response in the background with retries.
+// processTripNotification builds the Trip notification and hands it to the outbox.
 func processTripNotification(ctx context.Context, logger log.Logger, svc *service, message string, record domain.Model, requestType string) {
 	level.Info(logger).Log("method", fmt.Sprintf("processTripNotification for message %s", message))
 
@@ -596,32 +673,13 @@ func processTripNotification(ctx context.Context, logger log.Logger, svc *servic
 		return
 	}
 
-	level.Info(logger).Log("info ", "call to trip service", record.OrderId)
-	tripsvc, _ := tripservice.New(ctx)
-	success := false // Track success status
-
-	resp, err := tripsvc.NotifyToTrip(ctx, tripRequest)
-	if err == nil {
-		level.Info(logger).Log("info", "Successfully notified trip service", "orderId", record.OrderId)
-		success = true
-	}
-
-	if !success && resp.Code != "200" {
-		level.Error(logger).Log("error", "Unsuccessful trip notification after retries", "orderId", record.OrderId)
-		// add repository call "FAILED"
-		err = svc.mongoRepository[config.Instance().MongoDBName].UpdateCallBackStatus(ctx, id, "FAILED")
-		if err != nil {
-			return
-		}
-	} else {
-		level.Info(logger).Log("info", "Trip notification was successful", "orderId", record.OrderId)
-		// Add repository call for SUCCESS
-		err = svc.mongoRepository[config.Instance().MongoDBName].UpdateCallBackStatus(ctx, id, "SUCCESS")
-		if err != nil {
-			return
-		}
+	// delivery goes through the outbox, the scheduler sends it and retries a failed call
+	if _, err = enqueueNotification(ctx, logger, svc, constant.OutboxKindTripNotify, message, record.PartnerOrderID, id, tripRequest); err != nil {
+		level.Error(logger).Log("error", "Unable to queue trip notification", "orderId", record.OrderId)
+		_ = svc.mongoRepository[config.Instance().MongoDBName].UpdateCallBackStatus(ctx, id, "FAILED")
+		return
 	}
-
+	level.Info(logger).Log("info ", "trip notification queued", record.OrderId)
 }
 
 // checking for all voucher available for a order
@@ -648,17 +706,7 @@ func IsAllVoucherDataAvailable(order domain.Model) bool {
 
 // check for cancelling order
 func IsAllVariantCancilationDone(order domain.Model) bool {
-	if len(order.OrderVariants) == 0 {
-		return false
-	}
-
-	for _, variant := range order.OrderVariants {
-		if variant.CancelRejectTypeCode == "" && variant.OrderVariantStatusTypeCode == "CANCELING" {
-			return false
-		}
-	}
-
-	return true
+	return orderstate.CancellationSettled(order)
 }
 
 func IsAllVariantUsed(order domain.Model) bool {
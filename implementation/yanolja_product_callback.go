@@ -63,6 +63,14 @@ func (s *service) InsertProductClbk(ctx context.Context, product yanolja.Upsert_
 			return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error on inserting product into the database, %v", err), "UpsertProduct")
 
 		}
+
+		// the product is stored, a missing history entry does not fail the callback
+		if err = recordProductVersion(ctx, s.mongoRepository[config.Instance().MongoDBName], product.Body, &record); err != nil {
+			level.Error(logger).Log("repository error", "recording product version", "productId", product.Body.ProductID, "version", product.Body.ProductVersion, "error", err)
+		}
+
+		// the new version may change the sale of its variants, their inventories are read again
+		invalidateInventory(ctx, logger, productVariantIds(product.Body))
 	} else {
 		resp.Body = product.Body
 		resp.Code = "400"
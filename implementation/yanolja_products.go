@@ -349,6 +349,9 @@ func (s *service) InsertAllProduct(ctx context.Context) (resp yanolja.Response,
 				resp.Code = "500"
 				return resp, fmt.Errorf("mongo insert error: %w", err)
 			}
+			if err = recordProductVersion(ctx, s.mongoRepository[config.Instance().MongoDBName], product, nil); err != nil {
+				level.Error(logger).Log("error", "failed to record product version", "productId", product.ProductID, "err", err)
+			}
 		}
 	}
 
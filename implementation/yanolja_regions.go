@@ -10,6 +10,7 @@ import (
 	"swallow-supplier/request_response/yanolja"
 	yanoljasvc "swallow-supplier/services/suppliers/yanolja"
 	"swallow-supplier/utils"
+	"swallow-supplier/utils/pagination"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -51,6 +52,40 @@ func (s *service) GetRegions(ctx context.Context) (resp yanolja.Response, err er
 	return resp, nil
 }
 
+// GetStoredRegions one page of the regions stored in ggt with the total and page links
+func (s *service) GetStoredRegions(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error) {
+	var requestID = utils.GenerateUUID("GGT", true)
+
+	logger := log.With(
+		s.logger,
+		"method", "GetStoredRegions",
+		"Request ID", requestID,
+	)
+
+	// Defer when panic
+	defer func(context.Context) {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		level.Error(logger).Log("error", "processing request went into panic mode", "panic", r)
+		resp.Code = "500"
+		err = fmt.Errorf("panic occurred: %v", r)
+	}(ctx)
+
+	records, total, next, err := s.mongoRepository[config.Instance().MongoDBName].FindRegion(ctx, q)
+	if err != nil {
+		level.Error(logger).Log("error", "request to FindRegion raised error ", err)
+		resp.Code = "500"
+		return resp, customError.NewError(ctx, "leisure-api-1015", fmt.Sprintf("repository error from FindRegion, %v", err), "FindRegion")
+	}
+
+	resp.Code = "200"
+	resp.Body = listBody(ctx, q, records, total, next)
+	return resp, nil
+}
+
 // InsertAllRegions insert all regions from yanolja
 func (s *service) InsertAllRegions(ctx context.Context) (resp yanolja.Response, err error) {
 	var requestID = utils.GenerateUUID("GGT", true)
@@ -20,6 +20,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"swallow-supplier/caches"
+	"swallow-supplier/caches/cache"
 	"swallow-supplier/config"
 	svc "swallow-supplier/iface"
 	repomongo "swallow-supplier/mongo/repository"
@@ -28,6 +29,8 @@ import (
 	"swallow-supplier/middleware"
 	"swallow-supplier/transport"
 	httptransport "swallow-supplier/transport/http"
+	"swallow-supplier/utils/client"
+	"swallow-supplier/utils/pricing"
 	"swallow-supplier/utils/validator"
 )
 
@@ -64,6 +67,13 @@ func main() {
 
 	level.Info(logger).Log("redis ", "redis initialized successfully")
 
+	// breakers forced open or closed are followed by every replica
+	if cacheLayer, err := cache.New(c.CacheName); err != nil {
+		level.Error(logger).Log("error", "circuit breaker modes are not shared", "err", err)
+	} else {
+		client.GetBreakerManager().SetStore(cacheLayer)
+	}
+
 	//fmt.Println("Connection String:", c.DatabaseConnectionMongo)
 
 	level.Info(logger).Log("info", "MongoDB Connection  started")
@@ -104,6 +114,18 @@ func main() {
 
 	}
 
+	// the margins which used to be hardcoded become the first pricing rules
+	if err := mongorepo[c.MongoDBName].EnsurePricingRuleIndexes(context.Background()); err != nil {
+		level.Error(logger).Log("Pricing rule indexes error: %v", err)
+	}
+	if _, err := mongorepo[c.MongoDBName].SeedPricingRules(context.Background(), pricing.SeedRules); err != nil {
+		level.Error(logger).Log("Pricing rules seeding error: %v", err)
+	}
+
+	if err := mongorepo[c.MongoDBName].EnsureOdooSyncIndexes(context.Background()); err != nil {
+		level.Error(logger).Log("Odoo sync indexes error: %v", err)
+	}
+
 	/*
 				 ctx := context.Background()
 
@@ -0,0 +1,179 @@
+package trip_to_travolution
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"swallow-supplier/caches/cache"
+	"swallow-supplier/config"
+	svc "swallow-supplier/iface"
+	domain "swallow-supplier/mongo/domain/travolution"
+	"swallow-supplier/request_response/travolution"
+	"swallow-supplier/request_response/trip"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// DefaultNationality used when trip does not send the nationality of the passenger
+const DefaultNationality = "KR"
+
+// Plu parsed travolution plu value "TRV|productUid|optionUid|unitUid|date|time"
+type Plu struct {
+	ProductUid int
+	OptionUid  string
+	UnitUid    string
+	Date       string
+	Time       string
+}
+
+// IsTravolutionPlu reports whether the plu value belongs to a travolution product
+func IsTravolutionPlu(pluVal string) bool {
+	return strings.HasPrefix(pluVal, constant.TRAVOLUTIONPLUPREFIX+"|")
+}
+
+// FormatPlu builds the plu value stored in redis for a travolution unit
+func FormatPlu(productUid int, optionUid string, unitUid string, date string, time string) string {
+	return fmt.Sprintf("%s|%d|%s|%s|%s|%s", constant.TRAVOLUTIONPLUPREFIX, productUid, optionUid, unitUid, date, time)
+}
+
+// GeneratePlus plu hash and value for every unit of every option, booking products get one plu per schedule
+func GeneratePlus(product travolution.RawProduct) map[string]string {
+	pluHash := make(map[string]string)
+
+	for _, option := range product.Options {
+		for _, unit := range option.UnitsPrice {
+			if product.Type == constant.ProductTypeBooking && len(option.BookingSchedules) > 0 {
+				for _, schedule := range option.BookingSchedules {
+					plu := FormatPlu(product.UID, option.UID, unit.UID, schedule.Date, schedule.Time)
+					pluHash[utils.GenerateDeterministicCode(plu, 15)] = plu
+				}
+				continue
+			}
+
+			plu := FormatPlu(product.UID, option.UID, unit.UID, "", "")
+			pluHash[utils.GenerateDeterministicCode(plu, 15)] = plu
+		}
+	}
+
+	return pluHash
+}
+
+// ParsePlu parse travolution plu value
+func ParsePlu(pluVal string) (plu Plu, err error) {
+	detail := strings.Split(pluVal, "|")
+	if len(detail) != 6 || detail[0] != constant.TRAVOLUTIONPLUPREFIX {
+		return plu, fmt.Errorf("invalid travolution plu %q", pluVal)
+	}
+
+	plu.ProductUid, err = strconv.Atoi(detail[1])
+	if err != nil {
+		return plu, fmt.Errorf("invalid productUid in plu %q: %w", pluVal, err)
+	}
+	plu.OptionUid = detail[2]
+	plu.UnitUid = detail[3]
+	plu.Date = detail[4]
+	plu.Time = detail[5]
+
+	return plu, nil
+}
+
+// PreOrderMapper maps trip pre order request into a travolution order, all items must be units of the same product option
+func PreOrderMapper(ctx context.Context, mrepo svc.MongoRepository, logger log.Logger, tripReq trip.PreorderRequest) (req travolution.OrderRequest, product domain.Product, plus map[string]string, err error) {
+	level.Info(logger).Log(
+		"function name", "trip_to_travolution.PreOrderMapper",
+	)
+
+	if len(tripReq.Items) == 0 {
+		return req, product, nil, fmt.Errorf("items cannot be empty")
+	}
+
+	cacheLayer, err := cache.New(config.Instance().CacheName)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error initializing cache layer", "error", err)
+		return req, product, nil, fmt.Errorf("error initializing cache layer: %w", err)
+	}
+
+	plus = make(map[string]string)
+	var first Plu
+
+	for i, item := range tripReq.Items {
+		pluVal, err := cacheLayer.Get(ctx, item.PLU)
+		if err != nil || pluVal == "" {
+			level.Error(logger).Log("error", "plu not in redis", "plu", item.PLU)
+			return req, product, nil, fmt.Errorf("plu %s not in redis", item.PLU)
+		}
+
+		plu, err := ParsePlu(pluVal)
+		if err != nil {
+			level.Error(logger).Log("error", err)
+			return req, product, nil, err
+		}
+		plus[item.PLU] = pluVal
+
+		if i == 0 {
+			first = plu
+		} else if plu.ProductUid != first.ProductUid || plu.OptionUid != first.OptionUid || plu.Date != first.Date || plu.Time != first.Time {
+			return req, product, nil, fmt.Errorf("all items of a travolution order must belong to the same product option and schedule")
+		}
+
+		req.UnitAmounts = append(req.UnitAmounts, domain.UnitAmount{
+			Unit:   plu.UnitUid,
+			Amount: item.Quantity,
+		})
+	}
+
+	product, err = mrepo.GetProductByProductUid(ctx, first.ProductUid)
+	if err != nil {
+		level.Error(logger).Log("repository error", "fetching travolution product ", err)
+		return req, product, nil, err
+	}
+
+	req.Product = first.ProductUid
+	switch product.Type {
+	case constant.ProductTypePass, constant.ProductTypePackage:
+		req.Option = first.OptionUid
+	default:
+		// TK and BK options are numeric, TravolutionOrder expects the json number type
+		optionUid, err := strconv.Atoi(first.OptionUid)
+		if err != nil {
+			return req, product, nil, fmt.Errorf("invalid optionUid %s for product type %s", first.OptionUid, product.Type)
+		}
+		req.Option = float64(optionUid)
+	}
+
+	if product.Type == constant.ProductTypeBooking {
+		req.BookingDate = first.Date
+		req.BookingTime = first.Time
+	}
+
+	req.ReferenceNumber = tripReq.OtaOrderID
+	req.VoucherSendType = travolution.DoNotSend
+	req.TravelerNationality = DefaultNationality
+
+	if len(tripReq.Contacts) > 0 {
+		req.TravelerName = tripReq.Contacts[0].Name
+		req.TravelerContactEmail = strings.ToLower(tripReq.Contacts[0].Email)
+		req.TravelerContactNumber = tripReq.Contacts[0].Mobile
+	}
+
+	if passengers := tripReq.Items[0].Passengers; len(passengers) > 0 {
+		req.TravelerName = passengers[0].Name
+		if passengers[0].Mobile != "" {
+			req.TravelerContactNumber = passengers[0].Mobile
+		}
+		if len(passengers[0].NationalityCode) == 2 {
+			req.TravelerNationality = strings.ToUpper(passengers[0].NationalityCode)
+		}
+	}
+
+	if req.TravelerName == "" {
+		level.Error(logger).Log("error", "Passengers names are empty")
+		return req, product, nil, fmt.Errorf("passengers names cannot be empty")
+	}
+
+	return req, product, plus, nil
+}
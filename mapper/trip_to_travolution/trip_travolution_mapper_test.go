@@ -0,0 +1,61 @@
+package trip_to_travolution_test
+
+import (
+	"testing"
+
+	mapper "swallow-supplier/mapper/trip_to_travolution"
+	domain "swallow-supplier/mongo/domain/travolution"
+	"swallow-supplier/request_response/travolution"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlu(t *testing.T) {
+	// Test case 1: booking plu with schedule
+	plu, err := mapper.ParsePlu("TRV|1200|34|56|2025-10-01|10:00")
+	assert.Nil(t, err)
+	assert.Equal(t, 1200, plu.ProductUid)
+	assert.Equal(t, "34", plu.OptionUid)
+	assert.Equal(t, "56", plu.UnitUid)
+	assert.Equal(t, "2025-10-01", plu.Date)
+	assert.Equal(t, "10:00", plu.Time)
+
+	// Test case 2: yanolja plu is rejected
+	assert.False(t, mapper.IsTravolutionPlu("10141711|39|11538777||"))
+	_, err = mapper.ParsePlu("10141711|39|11538777||")
+	assert.NotNil(t, err)
+
+	// Test case 3: round trip with FormatPlu
+	value := mapper.FormatPlu(7, "PAS", "9", "", "")
+	assert.True(t, mapper.IsTravolutionPlu(value))
+	plu, err = mapper.ParsePlu(value)
+	assert.Nil(t, err)
+	assert.Equal(t, "PAS", plu.OptionUid)
+}
+
+func TestGeneratePlus(t *testing.T) {
+	product := travolution.RawProduct{
+		UID:  1200,
+		Type: "BK",
+		Options: []travolution.RawProductOption{{
+			UID:        "34",
+			UnitsPrice: []travolution.RawOptionUnitPrice{{UID: "1"}, {UID: "2"}},
+			BookingSchedules: []domain.BookingSchedule{
+				{Date: "2025-10-01", Time: "10:00"},
+				{Date: "2025-10-02", Time: "10:00"},
+			},
+		}},
+	}
+
+	// Test case 1: one plu per unit and schedule
+	plus := mapper.GeneratePlus(product)
+	assert.Len(t, plus, 4)
+	for key, value := range plus {
+		assert.Len(t, key, 15)
+		assert.True(t, mapper.IsTravolutionPlu(value))
+	}
+
+	// Test case 2: ticket products ignore schedules
+	product.Type = "TK"
+	assert.Len(t, mapper.GeneratePlus(product), 2)
+}
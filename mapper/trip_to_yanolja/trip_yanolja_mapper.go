@@ -2,18 +2,21 @@ package trip_to_yanolja
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
 	"math/rand"
 	"strconv"
 	"strings"
 	"swallow-supplier/caches/cache"
 	"swallow-supplier/config"
 	svc "swallow-supplier/iface"
+	"swallow-supplier/mongo/domain/money"
 	"swallow-supplier/request_response/trip"
 	"swallow-supplier/request_response/yanolja"
 	"swallow-supplier/utils"
 	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/fx"
+	"swallow-supplier/utils/pricing"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -98,36 +101,39 @@ func PreOrderMapper(ctx context.Context, mrepo svc.MongoRepository, logger log.L
 				variantInfo.Time = &detail[4]
 			}
 			variantInfo.Quantity = int32(val.Quantity)
-			variantInfo.Currency = "KRW" // need to be checked with subham
-			variantInfo.PartnerSalePrice = float32(val.SalePrice)
-			if val.DistributionChannel == "GGT_EVERLAND" {
-				variantInfo.CostPrice = utils.RemoveMargineFromProductCostPrice(variantInfo.ProductID, val.Cost)
-
-				/* for _, margine := range constant.MARGINEDETAIL {
-					if margine.ProductId == variantInfo.ProductID {
-						switch margine.MargineType {
-						case "flat":
-							variantInfo.CostPrice = float32(val.Cost + float64(margine.Value))
-						case "percent":
-							mergineval := float64(1 + margine.Value/100)
-							variantInfo.CostPrice = float32(math.Floor(val.Cost) / mergineval)
-						}
-
-					}
-				} */
-
-			} else {
-				margineVal := constant.MARKUPPERCENTAGE
-
-				for _, margine := range constant.MARGINEDETAIL {
-					if margine.ProductId == variantInfo.ProductID && margine.MargineType == constant.PERCENTAGE {
-						margineVal = margine.Value
-						break
-					}
-				}
+			// trip may price in another currency, yanolja settles in KRW
+			bookedAt := time.Now()
+			saleAmount, err := settle(ctx, mrepo, logger, money.Money{Amount: val.SalePrice, Currency: val.SalePriceCurrency}, bookedAt)
+			if err != nil {
+				level.Error(logger).Log("error", "sale price could not be converted", "currency", val.SalePriceCurrency, "err", err)
+				return req, nil, err
+			}
+			costCurrency := val.CostCurrency
+			if costCurrency == "" {
+				costCurrency = val.PriceCurrency
+			}
+			costAmount, err := settle(ctx, mrepo, logger, money.Money{Amount: val.Cost, Currency: costCurrency}, bookedAt)
+			if err != nil {
+				level.Error(logger).Log("error", "cost could not be converted", "currency", costCurrency, "err", err)
+				return req, nil, err
+			}
 
-				variantInfo.CostPrice = float32(math.Floor(val.Cost / float64(1+(margineVal/100)))) // now changes
+			variantInfo.Currency = constant.DEFAULTCURRENCY
+			variantInfo.PartnerSalePrice = float32(saleAmount.Settlement.Amount)
+			variantInfo.SaleAmount = &saleAmount
+			variantInfo.CostAmount = &costAmount
+			rule, err := pricing.Evaluate(ctx, mrepo, pricing.Scope{
+				Supplier:    constant.SUPPLIERYANOLJA,
+				ProductId:   variantInfo.ProductID,
+				VariantId:   variantInfo.VariantID,
+				ChannelCode: val.DistributionChannel,
+				At:          bookedAt,
+			})
+			if err != nil {
+				level.Error(logger).Log("error", "pricing rules could not be loaded", "err", err)
+				return req, nil, err
 			}
+			variantInfo.CostPrice = utils.RemoveMargineFromProductCostPrice(rule, costAmount.Settlement.Amount)
 
 			selectVariant = append(selectVariant, variantInfo)
 			req.TotalSelectedVariantsQuantity = req.TotalSelectedVariantsQuantity + int32(val.Quantity)
@@ -161,3 +167,16 @@ func PreOrderMapper(ctx context.Context, mrepo svc.MongoRepository, logger log.L
 
 	return req, plus, nil
 }
+
+// settle converts the amount into KRW. Until a rate of the currency is imported the amount is taken
+// as KRW like before fx rates were known, the zero rate recorded on the order marks it for review
+func settle(ctx context.Context, mrepo svc.MongoRepository, logger log.Logger, amount money.Money, at time.Time) (money.Conversion, error) {
+	conversion, err := fx.Convert(ctx, mrepo, amount, constant.DEFAULTCURRENCY, at)
+	if errors.Is(err, fx.ErrRateNotFound) {
+		level.Warn(logger).Log("msg", "no fx rate imported, amount kept unconverted", "currency", amount.Currency, "err", err)
+		conversion.Settlement = money.Money{Amount: amount.Amount, Currency: constant.DEFAULTCURRENCY}
+		conversion.Rate = 0
+		return conversion, nil
+	}
+	return conversion, err
+}
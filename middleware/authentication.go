@@ -2,22 +2,29 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	httptransport "github.com/go-kit/kit/transport/http"
 	kitlog "github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"go.mongodb.org/mongo-driver/mongo"
 	"gopkg.in/yaml.v3"
 
 	customContext "swallow-supplier/context"
 	customError "swallow-supplier/error"
 	repo "swallow-supplier/iface"
+	"swallow-supplier/mongo/domain/apiclient"
 	"swallow-supplier/utils"
+	"swallow-supplier/utils/apikey"
+	"swallow-supplier/utils/constant"
 
 	"swallow-supplier/config"
 )
@@ -95,17 +102,78 @@ func AuthMiddleWare(repository map[string]repo.MongoRepository) endpoint.Middlew
 				return nil, err
 			}
 
-			if c.AuthorizationKey != authToken {
-				err = customError.NewErrorCustom(ctx, "401", "Authentication failed", customError.ErrApiKeyRequired.Error(), http.StatusUnauthorized, "auth_middleware")
+			client, err := ResolveClient(ctx, repository[c.MongoDBName], authToken)
+			if err != nil {
 				level.Error(logger).Log("err", err)
 				return nil, err
 			}
 
+			// the caller scopes must include one of the scopes of the route method
+			if !sc.Verify(client.Scopes, strings.ToUpper(method)) {
+				err = customError.NewErrorCustom(ctx, "403", "access denied", customError.ErrAccessDenied.Error(), http.StatusForbidden, "auth_middleware")
+				level.Error(logger).Log("err", err, "client", client.Name, "scopes", fmt.Sprint(client.Scopes))
+				return nil, err
+			}
+
+			// a client bound to a channel can not act for another one
+			channelCode := customContext.GetCtxHeader(ctx, customContext.CtxLabelChannelCode)
+			if client.ChannelCode != "" && channelCode != "" && !strings.EqualFold(client.ChannelCode, channelCode) {
+				err = customError.NewErrorCustom(ctx, "403", "access denied", customError.ErrChannelInvalid.Error(), http.StatusForbidden, "auth_middleware")
+				level.Error(logger).Log("err", err, "client", client.Name, "channelCode", channelCode)
+				return nil, err
+			}
+
+			ctx = customContext.CtxWithClient(ctx, client.Id, client.Name, client.ChannelCode)
 			return next(ctx, request)
 		}
 	}
 }
 
+// ResolveClient api client of the key. The shared AUTHORIZATION_KEY still resolves to a super user
+// unless AUTH_LEGACY_KEY_ENABLED is "false"
+func ResolveClient(ctx context.Context, repository repo.MongoRepository, key string) (client apiclient.ApiClient, err error) {
+	c := config.Instance()
+	if c.AuthorizationKey != "" && c.AuthLegacyKeyEnabled != "false" &&
+		subtle.ConstantTimeCompare([]byte(c.AuthorizationKey), []byte(key)) == 1 {
+		return legacyClient, nil
+	}
+
+	hash := apikey.Hash(key)
+	if apikey.Unknown(hash) {
+		return client, customError.NewErrorCustom(ctx, "401", "Authentication failed", customError.ErrApiKeyInvalid.Error(), http.StatusUnauthorized, "auth_middleware")
+	}
+
+	client, ok := apikey.Cached(hash)
+	if !ok {
+		client, err = repository.GetApiClientByKeyHash(ctx, hash)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				apikey.CacheUnknown(hash)
+				return client, customError.NewErrorCustom(ctx, "401", "Authentication failed", customError.ErrApiKeyInvalid.Error(), http.StatusUnauthorized, "auth_middleware")
+			}
+			return client, customError.NewErrorCustom(ctx, "500", "Authentication failed", err.Error(), http.StatusInternalServerError, "auth_middleware")
+		}
+		apikey.Cache(hash, client)
+	}
+
+	if !client.Enabled {
+		return client, customError.NewErrorCustom(ctx, "401", "api key revoked", customError.ErrApiKeyInvalid.Error(), http.StatusUnauthorized, "auth_middleware")
+	}
+	if client.ExpiresAt != nil && !time.Now().Before(*client.ExpiresAt) {
+		return client, customError.NewErrorCustom(ctx, "401", "api key expired", customError.ErrApiKeyInvalid.Error(), http.StatusUnauthorized, "auth_middleware")
+	}
+
+	return client, nil
+}
+
+// legacyClient identity of callers using the shared AUTHORIZATION_KEY
+var legacyClient = apiclient.ApiClient{
+	Id:      "legacy",
+	Name:    "legacy",
+	Scopes:  []int{constant.ScopeSuperUser},
+	Enabled: true,
+}
+
 // ReadScopeDefinition read the auth.yml
 func ReadScopeDefinition() (conf AuthConfig, err error) {
 	var (
@@ -8,12 +8,15 @@ import (
 	customContext "swallow-supplier/context"
 	customError "swallow-supplier/error"
 	svc "swallow-supplier/iface"
+	pricing_domain "swallow-supplier/mongo/domain/pricing"
+	refund_domain "swallow-supplier/mongo/domain/refund"
 	travolution_domain "swallow-supplier/mongo/domain/travolution"
 	domain "swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/request_response/common"
 	"swallow-supplier/request_response/travolution"
 	"swallow-supplier/request_response/trip"
 	"swallow-supplier/request_response/yanolja"
+	"swallow-supplier/utils/pagination"
 
 	kitlog "github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -103,6 +106,15 @@ func (mw loggingMiddleware) GetCategories(ctx context.Context) (resp yanolja.Res
 	return mw.next.GetCategories(ctx)
 }
 
+// GetStoredCategories one page of the categories stored in ggt
+func (mw loggingMiddleware) GetStoredCategories(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, q, resp, err)
+	}(time.Now())
+
+	return mw.next.GetStoredCategories(ctx, q)
+}
+
 func (mw loggingMiddleware) InsertAllCategories(ctx context.Context) (resp yanolja.Response, err error) {
 	defer func(startTime time.Time) {
 		logRequest(ctx, mw.logger, startTime, nil, resp, err)
@@ -120,6 +132,15 @@ func (mw loggingMiddleware) GetRegions(ctx context.Context) (resp yanolja.Respon
 	return mw.next.GetRegions(ctx)
 }
 
+// GetStoredRegions one page of the regions stored in ggt
+func (mw loggingMiddleware) GetStoredRegions(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, q, resp, err)
+	}(time.Now())
+
+	return mw.next.GetStoredRegions(ctx, q)
+}
+
 // InsertAllRegions for storing regions detail
 func (mw loggingMiddleware) InsertAllRegions(ctx context.Context) (resp yanolja.Response, err error) {
 	defer func(startTime time.Time) {
@@ -165,6 +186,15 @@ func (mw loggingMiddleware) PostCancelOrderEntirly(ctx context.Context, orderId
 	return mw.next.PostCancelOrderEntirly(ctx, orderId)
 }
 
+// PostCancelOrderVariants cancel some variants of an order
+func (mw loggingMiddleware) PostCancelOrderVariants(ctx context.Context, orderId int64, orderVariantIds []int64) (resp yanolja.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, orderVariantIds, resp, err)
+	}(time.Now())
+
+	return mw.next.PostCancelOrderVariants(ctx, orderId, orderVariantIds)
+}
+
 // PostCancelOrderByReqTimeOut cancel order when request timeout
 func (mw loggingMiddleware) PostCancelOrderByReqTimeOut(ctx context.Context, partnerOrderId string) (resp yanolja.Response, err error) {
 	defer func(startTime time.Time) {
@@ -330,12 +360,12 @@ func (mw loggingMiddleware) PostRequestFromGGT(ctx context.Context, req trip.Swa
 	return mw.next.PostRequestFromGGT(ctx, req)
 }
 
-func (mw loggingMiddleware) GetRedisData(ctx context.Context) (resp yanolja.Response, err error) {
+func (mw loggingMiddleware) GetRedisData(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error) {
 	defer func(startTime time.Time) {
-		logRequest(ctx, mw.logger, startTime, "", resp, err)
+		logRequest(ctx, mw.logger, startTime, q, resp, err)
 	}(time.Now())
 
-	return mw.next.GetRedisData(ctx)
+	return mw.next.GetRedisData(ctx, q)
 }
 
 func (mw loggingMiddleware) GetImageSyncToTrip(ctx context.Context) (resp yanolja.Response, err error) {
@@ -572,12 +602,12 @@ func (mw loggingMiddleware) PostChangeOdooSyncStatusToFalse(ctx context.Context)
 	return mw.next.PostChangeOdooSyncStatusToFalse(ctx)
 }
 
-func (mw loggingMiddleware) GetProductsFromGGT(ctx context.Context) (resp yanolja.Response, err error) {
+func (mw loggingMiddleware) GetProductsFromGGT(ctx context.Context, q pagination.Query) (resp yanolja.Response, err error) {
 	defer func(startTime time.Time) {
-		logRequest(ctx, mw.logger, startTime, "", resp, err)
+		logRequest(ctx, mw.logger, startTime, q, resp, err)
 	}(time.Now())
 
-	return mw.next.GetProductsFromGGT(ctx)
+	return mw.next.GetProductsFromGGT(ctx, q)
 }
 
 func (mw loggingMiddleware) GetProductByIdFromGGT(ctx context.Context, productId int64) (resp yanolja.Response, err error) {
@@ -620,6 +650,357 @@ func (mw loggingMiddleware) OrderWebhookUpdate(ctx context.Context, payload trav
 	return mw.next.OrderWebhookUpdate(ctx, payload)
 }
 
+// Notification outbox
+
+func (mw loggingMiddleware) ProcessNotificationOutbox(ctx context.Context) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, nil, resp, err)
+	}(time.Now())
+
+	return mw.next.ProcessNotificationOutbox(ctx)
+}
+
+func (mw loggingMiddleware) GetNotificationOutbox(ctx context.Context, req common.NotificationOutboxRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetNotificationOutbox(ctx, req)
+}
+
+func (mw loggingMiddleware) ReplayNotificationOutbox(ctx context.Context, id string) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, id, resp, err)
+	}(time.Now())
+
+	return mw.next.ReplayNotificationOutbox(ctx, id)
+}
+
+// Circuit breaker
+
+func (mw loggingMiddleware) GetCircuitBreakers(ctx context.Context) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, nil, resp, err)
+	}(time.Now())
+
+	return mw.next.GetCircuitBreakers(ctx)
+}
+
+func (mw loggingMiddleware) UpdateCircuitBreaker(ctx context.Context, req common.CircuitBreakerRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.UpdateCircuitBreaker(ctx, req)
+}
+
+// Pricing rules
+
+func (mw loggingMiddleware) GetPricingRules(ctx context.Context, req common.PricingRuleRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetPricingRules(ctx, req)
+}
+
+func (mw loggingMiddleware) GetPricingRule(ctx context.Context, id string) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, id, resp, err)
+	}(time.Now())
+
+	return mw.next.GetPricingRule(ctx, id)
+}
+
+func (mw loggingMiddleware) CreatePricingRule(ctx context.Context, rule pricing_domain.Rule) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, rule, resp, err)
+	}(time.Now())
+
+	return mw.next.CreatePricingRule(ctx, rule)
+}
+
+func (mw loggingMiddleware) UpdatePricingRule(ctx context.Context, rule pricing_domain.Rule) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, rule, resp, err)
+	}(time.Now())
+
+	return mw.next.UpdatePricingRule(ctx, rule)
+}
+
+func (mw loggingMiddleware) DeletePricingRule(ctx context.Context, id string) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, id, resp, err)
+	}(time.Now())
+
+	return mw.next.DeletePricingRule(ctx, id)
+}
+
+// Refund policies
+func (mw loggingMiddleware) GetRefundPolicy(ctx context.Context, req common.RefundPolicyRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetRefundPolicy(ctx, req)
+}
+
+func (mw loggingMiddleware) PutRefundPolicy(ctx context.Context, policy refund_domain.Policy) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, policy, resp, err)
+	}(time.Now())
+
+	return mw.next.PutRefundPolicy(ctx, policy)
+}
+
+func (mw loggingMiddleware) DeleteRefundPolicy(ctx context.Context, variantId int64) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, variantId, resp, err)
+	}(time.Now())
+
+	return mw.next.DeleteRefundPolicy(ctx, variantId)
+}
+
+// Availability
+func (mw loggingMiddleware) GetVariantAvailability(ctx context.Context, req common.AvailabilityRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetVariantAvailability(ctx, req)
+}
+
+// Api clients
+
+func (mw loggingMiddleware) GetApiClients(ctx context.Context) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, nil, resp, err)
+	}(time.Now())
+
+	return mw.next.GetApiClients(ctx)
+}
+
+func (mw loggingMiddleware) IssueApiClient(ctx context.Context, req common.ApiClientRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		// the response carries the api key
+		logRequest(ctx, mw.logger, startTime, req, nil, err)
+	}(time.Now())
+
+	return mw.next.IssueApiClient(ctx, req)
+}
+
+func (mw loggingMiddleware) RotateApiClientKey(ctx context.Context, req common.ApiClientRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		// the response carries the api key
+		logRequest(ctx, mw.logger, startTime, req, nil, err)
+	}(time.Now())
+
+	return mw.next.RotateApiClientKey(ctx, req)
+}
+
+func (mw loggingMiddleware) RevokeApiClient(ctx context.Context, id string) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, id, resp, err)
+	}(time.Now())
+
+	return mw.next.RevokeApiClient(ctx, id)
+}
+
+func (mw loggingMiddleware) GetJobs(ctx context.Context) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, nil, resp, err)
+	}(time.Now())
+
+	return mw.next.GetJobs(ctx)
+}
+
+func (mw loggingMiddleware) GetJobRuns(ctx context.Context, req common.JobRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetJobRuns(ctx, req)
+}
+
+func (mw loggingMiddleware) TriggerJob(ctx context.Context, req common.JobRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.TriggerJob(ctx, req)
+}
+
+func (mw loggingMiddleware) SetJobPaused(ctx context.Context, req common.JobRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.SetJobPaused(ctx, req)
+}
+
+func (mw loggingMiddleware) UpdateJobSchedule(ctx context.Context, req common.JobRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.UpdateJobSchedule(ctx, req)
+}
+
+func (mw loggingMiddleware) GetOdooSyncChanges(ctx context.Context, req common.OdooSyncRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetOdooSyncChanges(ctx, req)
+}
+
+func (mw loggingMiddleware) PostOdooSyncAck(ctx context.Context, req common.OdooSyncRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.PostOdooSyncAck(ctx, req)
+}
+
+func (mw loggingMiddleware) PushOdooChanges(ctx context.Context) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, nil, resp, err)
+	}(time.Now())
+
+	return mw.next.PushOdooChanges(ctx)
+}
+
+func (mw loggingMiddleware) ExpireHeldOrders(ctx context.Context) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, nil, resp, err)
+	}(time.Now())
+
+	return mw.next.ExpireHeldOrders(ctx)
+}
+
+func (mw loggingMiddleware) GenerateReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GenerateReconciliationReport(ctx, req)
+}
+
+func (mw loggingMiddleware) GetReconciliationReports(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetReconciliationReports(ctx, req)
+}
+
+func (mw loggingMiddleware) GetReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetReconciliationReport(ctx, req)
+}
+
+func (mw loggingMiddleware) ExportReconciliationReport(ctx context.Context, req common.ReconciliationReportRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		// the csv itself is not logged
+		logRequest(ctx, mw.logger, startTime, req, nil, err)
+	}(time.Now())
+
+	return mw.next.ExportReconciliationReport(ctx, req)
+}
+
+func (mw loggingMiddleware) GetCategoryMappings(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetCategoryMappings(ctx, req)
+}
+
+func (mw loggingMiddleware) GetCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetCategoryMapping(ctx, req)
+}
+
+func (mw loggingMiddleware) CreateCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.CreateCategoryMapping(ctx, req)
+}
+
+func (mw loggingMiddleware) UpdateCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.UpdateCategoryMapping(ctx, req)
+}
+
+func (mw loggingMiddleware) DeleteCategoryMapping(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.DeleteCategoryMapping(ctx, req)
+}
+
+func (mw loggingMiddleware) UploadCategoryMappings(ctx context.Context, req common.CategoryMappingUploadRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		// the sheet itself is not logged
+		logRequest(ctx, mw.logger, startTime, map[string]any{"fileName": req.FileName, "size": len(req.Content), "dryRun": req.DryRun}, resp, err)
+	}(time.Now())
+
+	return mw.next.UploadCategoryMappings(ctx, req)
+}
+
+func (mw loggingMiddleware) ExportCategoryMappings(ctx context.Context, req common.CategoryMappingRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		// the file itself is not logged
+		logRequest(ctx, mw.logger, startTime, req, nil, err)
+	}(time.Now())
+
+	return mw.next.ExportCategoryMappings(ctx, req)
+}
+
+func (mw loggingMiddleware) GetUnmappedCategoryProducts(ctx context.Context) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, nil, resp, err)
+	}(time.Now())
+
+	return mw.next.GetUnmappedCategoryProducts(ctx)
+}
+
+func (mw loggingMiddleware) GetProductVersions(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetProductVersions(ctx, req)
+}
+
+func (mw loggingMiddleware) GetProductVersion(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.GetProductVersion(ctx, req)
+}
+
+func (mw loggingMiddleware) DiffProductVersions(ctx context.Context, req common.ProductVersionRequest) (resp common.Response, err error) {
+	defer func(startTime time.Time) {
+		logRequest(ctx, mw.logger, startTime, req, resp, err)
+	}(time.Now())
+
+	return mw.next.DiffProductVersions(ctx, req)
+}
+
 // logRequest log the requests
 func logRequest(ctx context.Context, logger kitlog.Logger, startTime time.Time, req interface{}, res interface{}, err error) {
 	if err == nil {
@@ -629,6 +1010,7 @@ func logRequest(ctx context.Context, logger kitlog.Logger, startTime time.Time,
 			customContext.CtxLabelTraceID, customContext.CtxTraceID(ctx),
 			customContext.CtxLabelRequestID, customContext.GetCtxHeader(ctx, customContext.CtxLabelRequestID),
 			customContext.CtxLabelChannelCode, customContext.GetCtxHeader(ctx, customContext.CtxLabelChannelCode),
+			customContext.CtxLabelClientName, customContext.GetCtxHeader(ctx, customContext.CtxLabelClientName),
 			requestKey, req,
 			responseKey, res)
 		return
@@ -647,6 +1029,7 @@ func logRequest(ctx context.Context, logger kitlog.Logger, startTime time.Time,
 		customContext.CtxLabelTraceID, customContext.CtxTraceID(ctx),
 		customContext.CtxLabelRequestID, customContext.GetCtxHeader(ctx, customContext.CtxLabelRequestID),
 		customContext.CtxLabelChannelCode, customContext.GetCtxHeader(ctx, customContext.CtxLabelChannelCode),
+		customContext.CtxLabelClientName, customContext.GetCtxHeader(ctx, customContext.CtxLabelClientName),
 		requestKey, req,
 		responseKey, res,
 		errorKey, err)
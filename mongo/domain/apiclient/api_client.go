@@ -0,0 +1,19 @@
+package apiclient
+
+import "time"
+
+// ApiClient caller of the api, only the sha256 of its key is stored
+type ApiClient struct {
+	Id          string     `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string     `bson:"name" json:"name"`
+	KeyPrefix   string     `bson:"keyPrefix" json:"keyPrefix"` // first characters of the key to recognise it
+	KeyHash     string     `bson:"keyHash" json:"-"`
+	ChannelCode string     `bson:"channelCode,omitempty" json:"channelCode,omitempty"`
+	Scopes      []int      `bson:"scopes" json:"scopes"`
+	Enabled     bool       `bson:"enabled" json:"enabled"`
+	ExpiresAt   *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	RotatedAt   string     `bson:"rotatedAt,omitempty" json:"rotatedAt,omitempty"`
+	RevokedAt   string     `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	CreatedAt   string     `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   string     `bson:"updatedAt" json:"updatedAt"`
+}
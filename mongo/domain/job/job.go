@@ -0,0 +1,28 @@
+package job
+
+import "time"
+
+// Setting runtime settings of a registered job, changed through the admin api and picked up by
+// every replica. Empty fields keep the registered defaults
+type Setting struct {
+	Name               string `bson:"_id" json:"name"`
+	Schedule           string `bson:"schedule,omitempty" json:"schedule,omitempty"`
+	Paused             *bool  `bson:"paused,omitempty" json:"paused,omitempty"`
+	TriggerRequestedAt string `bson:"triggerRequestedAt,omitempty" json:"triggerRequestedAt,omitempty"`
+	UpdatedAt          string `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Run one execution of a job
+type Run struct {
+	Id         string    `bson:"_id,omitempty" json:"id"`
+	Job        string    `bson:"job" json:"job"`
+	Trigger    string    `bson:"trigger" json:"trigger" oneof:"'SCHEDULE' 'MANUAL'"`
+	Holder     string    `bson:"holder" json:"holder"`
+	StartedAt  string    `bson:"startedAt" json:"startedAt"`
+	EndedAt    string    `bson:"endedAt" json:"endedAt"`
+	DurationMs int64     `bson:"durationMs" json:"durationMs"`
+	Outcome    string    `bson:"outcome" json:"outcome" oneof:"'SUCCESS' 'FAILED' 'TIMEOUT'"`
+	Items      int       `bson:"items" json:"items"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	ExpireAt   time.Time `bson:"expireAt" json:"-"`
+}
@@ -0,0 +1,13 @@
+package money
+
+// FxRate exchange rate of a day, one Base unit is worth Rate Quote units
+type FxRate struct {
+	Id        string  `bson:"_id,omitempty" json:"id,omitempty"`
+	Base      string  `bson:"base" json:"base"`
+	Quote     string  `bson:"quote" json:"quote"`
+	Rate      float64 `bson:"rate" json:"rate"`
+	AsOf      string  `bson:"asOf" json:"asOf"` // 2006-01-02
+	Source    string  `bson:"source" json:"source"`
+	CreatedAt string  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt string  `bson:"updatedAt" json:"updatedAt"`
+}
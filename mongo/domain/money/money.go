@@ -0,0 +1,15 @@
+package money
+
+// Money amount in a currency
+type Money struct {
+	Amount   float64 `bson:"amount" json:"amount"`
+	Currency string  `bson:"currency" json:"currency"` // 3-character currency code
+}
+
+// Conversion amount received in the original currency and what it settles to
+type Conversion struct {
+	Original   Money   `bson:"original" json:"original"`
+	Settlement Money   `bson:"settlement" json:"settlement"`
+	Rate       float64 `bson:"rate" json:"rate"`                             // settlement units per original unit
+	RateAsOf   string  `bson:"rateAsOf,omitempty" json:"rateAsOf,omitempty"` // date of the rate used, empty when no conversion was needed
+}
@@ -1,31 +1,37 @@
 package odoo
 
+import "swallow-supplier/mongo/domain/money"
+
 // Order represents the MongoDB model for the given dataset
 type Order struct {
-	Id                     string         `bson:"_id" json:"id,omitempty"`
-	Channel                string         `bson:"channel" json:"channel"`
-	Supplier               string         `bson:"supplier" json:"supplier"`
-	OrderID                int64          `bson:"orderId" json:"orderId"`
-	PartnerOrderID         string         `bson:"partnerOrderId" json:"partnerOrderId"`
-	BookingStatus          string         `bson:"bookingStatus" json:"bookingStatus"`
-	CustomerName           string         `bson:"customerName" json:"customerName"`
-	BookedAt               string         `bson:"bookedAt" json:"bookedAt" default:""` ///createdAt
-	ExpirationStatus       string         `bson:"expirationStatus" json:"expirationStatus"`
-	TotalQuantity          int            `bson:"totalQuantity" json:"totalQuantity"`
-	SupplierCostPrice      float32        `bson:"supplierCostPrice" json:"supplierCostPrice"` // sum of all cost price of ord
-	SupplierSalePrice      float32        `bson:"supplierSalePrice" json:"supplierSalePrice"`
-	Margin                 float32        `bson:"margin" json:"margin"`
-	MarkupType             string         `bson:"markupType" json:"markupType"`
-	MarkupValue            float32        `bson:"markupValue" json:"markupValue"`
-	ChannelNetPriceFormula string         `bson:"channelNetPriceFormula" json:"channelNetPriceFormula"`
-	ChannelNetPrice        float32        `bson:"channelNetPrice" json:"channelNetPrice"`
-	SupplierCurrency       string         `bson:"supplierCurrency" json:"supplierCurrency"`
-	AppliedMarkup          float32        `bson:"appliedMarkup" json:"appliedMarkup"`
-	Variants               []OrderVariant `bson:"variants" json:"variants"`
-	MarkupInfo             []MarkupDetail `bson:"markupInfo" json:"markupInfo"`
-	NetPriceInfo           NetPriceDetail `bson:"netPriceInfo" json:"netPriceInfo"`
-	CreatedAt              string         `bson:"createdAt" json:"createdAt" validate:"required"`
-	UpdatedAt              string         `bson:"updatedAt" json:"updatedAt"`
+	Id                     string           `bson:"_id" json:"id,omitempty"`
+	Channel                string           `bson:"channel" json:"channel"`
+	Supplier               string           `bson:"supplier" json:"supplier"`
+	OrderID                int64            `bson:"orderId" json:"orderId"`
+	PartnerOrderID         string           `bson:"partnerOrderId" json:"partnerOrderId"`
+	BookingStatus          string           `bson:"bookingStatus" json:"bookingStatus"`
+	CustomerName           string           `bson:"customerName" json:"customerName"`
+	BookedAt               string           `bson:"bookedAt" json:"bookedAt" default:""` ///createdAt
+	ExpirationStatus       string           `bson:"expirationStatus" json:"expirationStatus"`
+	TotalQuantity          int              `bson:"totalQuantity" json:"totalQuantity"`
+	SupplierCostPrice      float32          `bson:"supplierCostPrice" json:"supplierCostPrice"` // sum of all cost price of ord
+	SupplierSalePrice      float32          `bson:"supplierSalePrice" json:"supplierSalePrice"`
+	Margin                 float32          `bson:"margin" json:"margin"`
+	MarkupType             string           `bson:"markupType" json:"markupType"`
+	MarkupValue            float32          `bson:"markupValue" json:"markupValue"`
+	ChannelNetPriceFormula string           `bson:"channelNetPriceFormula" json:"channelNetPriceFormula"`
+	ChannelNetPrice        float32          `bson:"channelNetPrice" json:"channelNetPrice"`
+	SupplierCurrency       string           `bson:"supplierCurrency" json:"supplierCurrency"`
+	SettlementCurrency     string           `bson:"settlementCurrency" json:"settlementCurrency"`
+	SaleAmount             money.Conversion `bson:"saleAmount" json:"saleAmount"` // total sale price in the channel and the settlement currency
+	CostAmount             money.Conversion `bson:"costAmount" json:"costAmount"` // total channel cost in the channel and the settlement currency
+	AppliedMarkup          float32          `bson:"appliedMarkup" json:"appliedMarkup"`
+	Variants               []OrderVariant   `bson:"variants" json:"variants"`
+	MarkupInfo             []MarkupDetail   `bson:"markupInfo" json:"markupInfo"`
+	NetPriceInfo           NetPriceDetail   `bson:"netPriceInfo" json:"netPriceInfo"`
+	OdooId                 int64            `bson:"odooId,omitempty" json:"odooId,omitempty"` // sale.order id once pushed
+	CreatedAt              string           `bson:"createdAt" json:"createdAt" validate:"required"`
+	UpdatedAt              string           `bson:"updatedAt" json:"updatedAt"`
 }
 
 type OrderVariant struct {
@@ -13,6 +13,8 @@ type Product struct {
 	Regions             []Region                 `bson:"regions" json:"regions"`
 	IsIntegratedVoucher bool                     `bson:"isIntegratedVoucher" json:"isIntegratedVoucher"`
 	Variants            []ProductVariant         `json:"variants" json:"variants"`
+	OdooTemplateId      int64                    `bson:"odooTemplateId,omitempty" json:"odooTemplateId,omitempty"` // product.template id once pushed
+	OdooProductId       int64                    `bson:"odooProductId,omitempty" json:"odooProductId,omitempty"`   // product.product id order lines refer to
 	CreatedAt           string                   `bson:"createdAt" json:"createdAt" validate:"required"`
 	UpdatedAt           string                   `bson:"updatedAt" json:"updatedAt"`
 }
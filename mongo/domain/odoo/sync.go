@@ -0,0 +1,47 @@
+package odoo
+
+// sync event kinds
+const (
+	SyncKindOrder   = "order"
+	SyncKindProduct = "product"
+)
+
+// sync event operations, a tombstone tells odoo the record was deleted or canceled
+const (
+	SyncOpUpsert    = "UPSERT"
+	SyncOpTombstone = "TOMBSTONE"
+)
+
+// SyncEvent change of an order or product, Seq grows with every change over all kinds
+type SyncEvent struct {
+	Seq       int64  `bson:"_id" json:"seq"`
+	Kind      string `bson:"kind" json:"kind"`
+	RefId     int64  `bson:"refId" json:"refId"`
+	CreatedAt string `bson:"createdAt" json:"createdAt"`
+}
+
+// SyncCursor last sequence acknowledged by a consumer of the events. Odoo pulls acknowledge under
+// the kind, the push keeps its own cursors
+type SyncCursor struct {
+	Consumer string `bson:"_id" json:"consumer"`
+	Cursor   int64  `bson:"cursor" json:"cursor"`
+	AckedAt  string `bson:"ackedAt" json:"ackedAt"`
+}
+
+// SyncChange state of a changed record when odoo pulls it, Order or Product is empty for tombstones
+type SyncChange struct {
+	Seq     int64    `json:"seq"`
+	Op      string   `json:"op"`
+	Kind    string   `json:"kind"`
+	RefId   int64    `json:"refId"`
+	Order   *Order   `json:"order,omitempty"`
+	Product *Product `json:"product,omitempty"`
+}
+
+// SyncPage page of changes after Since, NextCursor is acknowledged once the page is imported
+type SyncPage struct {
+	Since      int64        `json:"since"`
+	NextCursor int64        `json:"nextCursor"`
+	HasMore    bool         `json:"hasMore"`
+	Changes    []SyncChange `json:"changes"`
+}
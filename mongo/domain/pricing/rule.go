@@ -0,0 +1,23 @@
+package pricing
+
+import "time"
+
+// Rule markup applied between the supplier cost and the channel price.
+// Empty scope fields match everything, the most specific enabled rule wins.
+type Rule struct {
+	Id          string     `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string     `bson:"name" json:"name"`
+	Supplier    string     `bson:"supplier,omitempty" json:"supplier,omitempty"`
+	ProductId   int64      `bson:"productId,omitempty" json:"productId,omitempty"`
+	VariantId   int64      `bson:"variantId,omitempty" json:"variantId,omitempty"`
+	ChannelCode string     `bson:"channelCode,omitempty" json:"channelCode,omitempty"`
+	ValidFrom   *time.Time `bson:"validFrom,omitempty" json:"validFrom,omitempty"`
+	ValidTo     *time.Time `bson:"validTo,omitempty" json:"validTo,omitempty"`
+	MarkupType  string     `bson:"markupType" json:"markupType" oneof:"'PERCENTAGE' 'FLATVALUE'"`
+	Value       float64    `bson:"value" json:"value"`
+	Rounding    string     `bson:"rounding" json:"rounding" oneof:"'NONE' 'FLOOR' 'CEIL' 'ROUND'"`
+	Priority    int        `bson:"priority" json:"priority"`
+	Enabled     bool       `bson:"enabled" json:"enabled"`
+	CreatedAt   string     `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   string     `bson:"updatedAt" json:"updatedAt"`
+}
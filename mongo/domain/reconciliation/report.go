@@ -0,0 +1,37 @@
+package reconciliation
+
+// mismatch types of the report
+const (
+	MismatchStatus             = "STATUS_MISMATCH"
+	MismatchMissingVoucher     = "MISSING_VOUCHER"
+	MismatchPriceDrift         = "PRICE_DRIFT"
+	MismatchOrphanCancellation = "ORPHAN_CANCELLATION"
+)
+
+// Report daily comparison of our orders, yanolja's reconciliation status and what trip was told
+// through the callbacks. One report per day, running it again replaces it
+type Report struct {
+	Date            string           `bson:"_id" json:"date"`
+	GeneratedAt     string           `bson:"generatedAt" json:"generatedAt"`
+	OrdersChecked   int64            `bson:"ordersChecked" json:"ordersChecked"`
+	VariantsChecked int64            `bson:"variantsChecked" json:"variantsChecked"`
+	Counts          map[string]int64 `bson:"counts" json:"counts"`
+	Total           int64            `bson:"total" json:"total"`
+	Mismatches      []Mismatch       `bson:"mismatches,omitempty" json:"mismatches,omitempty"`
+}
+
+// Mismatch disagreement on one order variant. Ours, Yanolja and Trip hold the compared value of
+// each side, empty when the side has no record of it
+type Mismatch struct {
+	Type           string `bson:"type" json:"type"`
+	OrderId        int64  `bson:"orderId" json:"orderId"`
+	PartnerOrderId string `bson:"partnerOrderId" json:"partnerOrderId"`
+	Channel        string `bson:"channel" json:"channel"`
+	OrderVariantId int64  `bson:"orderVariantId" json:"orderVariantId"`
+	ProductId      int64  `bson:"productId" json:"productId"`
+	VariantId      int64  `bson:"variantId" json:"variantId"`
+	Ours           string `bson:"ours" json:"ours"`
+	Yanolja        string `bson:"yanolja" json:"yanolja"`
+	Trip           string `bson:"trip" json:"trip"`
+	Detail         string `bson:"detail" json:"detail"`
+}
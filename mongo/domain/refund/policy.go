@@ -0,0 +1,57 @@
+package refund
+
+import "swallow-supplier/mongo/domain/money"
+
+// Policy refund rules of a variant. The tier with the largest MinDaysBefore not above the days
+// left before the visit applies, nothing is refunded inside a non refundable window
+type Policy struct {
+	Id                        string   `bson:"_id,omitempty" json:"id,omitempty"`
+	VariantId                 int64    `bson:"variantId" json:"variantId"`
+	ProductId                 int64    `bson:"productId,omitempty" json:"productId,omitempty"`
+	Source                    string   `bson:"source" json:"source" oneof:"'CURATED' 'PARSED' 'DEFAULT'"`
+	Tiers                     []Tier   `bson:"tiers" json:"tiers"`
+	NonRefundableWindows      []Window `bson:"nonRefundableWindows,omitempty" json:"nonRefundableWindows,omitempty"`
+	RefundableAfterExpiration bool     `bson:"refundableAfterExpiration" json:"refundableAfterExpiration"`
+	CreatedAt                 string   `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+	UpdatedAt                 string   `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
+
+// Tier cancellation fee from MinDaysBefore days before the visit on, a percentage of the paid
+// amount or a flat amount per unit in the paid currency
+type Tier struct {
+	MinDaysBefore int     `bson:"minDaysBefore" json:"minDaysBefore"`
+	FeeType       string  `bson:"feeType" json:"feeType" oneof:"'PERCENTAGE' 'FLATVALUE'"`
+	Fee           float64 `bson:"fee" json:"fee"`
+}
+
+// Window days before the visit, both ends included, in which nothing is refunded
+type Window struct {
+	FromDaysBefore int `bson:"fromDaysBefore" json:"fromDaysBefore"`
+	ToDaysBefore   int `bson:"toDaysBefore" json:"toDaysBefore"`
+}
+
+// Quote refund owed to the channel for the canceled units of an order
+type Quote struct {
+	Paid       money.Money `bson:"paid" json:"paid"`
+	Fee        money.Money `bson:"fee" json:"fee"`
+	Refund     money.Money `bson:"refund" json:"refund"`
+	Refundable bool        `bson:"refundable" json:"refundable"`
+	Lines      []QuoteLine `bson:"lines" json:"lines"`
+	QuotedAt   string      `bson:"quotedAt" json:"quotedAt"`
+}
+
+// QuoteLine refund of one order variant, or of the booked quantity of a variant when the order
+// was not confirmed yet
+type QuoteLine struct {
+	OrderVariantId int64       `bson:"orderVariantId,omitempty" json:"orderVariantId,omitempty"`
+	ProductId      int64       `bson:"productId" json:"productId"`
+	VariantId      int64       `bson:"variantId" json:"variantId"`
+	Quantity       int         `bson:"quantity" json:"quantity"`
+	VisitDate      string      `bson:"visitDate" json:"visitDate"`
+	DaysBefore     int         `bson:"daysBefore" json:"daysBefore"`
+	PolicySource   string      `bson:"policySource" json:"policySource"`
+	Paid           money.Money `bson:"paid" json:"paid"`
+	Fee            money.Money `bson:"fee" json:"fee"`
+	Refund         money.Money `bson:"refund" json:"refund"`
+	Refundable     bool        `bson:"refundable" json:"refundable"`
+}
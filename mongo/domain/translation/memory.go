@@ -0,0 +1,17 @@
+package translation
+
+// Memory translation of a source text into one language, stored once per source hash and
+// language so unchanged text is not sent to babel again
+type Memory struct {
+	Id         string `bson:"_id" json:"id"`
+	SourceHash string `bson:"sourceHash" json:"sourceHash"`
+	TargetLang string `bson:"targetLang" json:"targetLang"`
+	Source     string `bson:"source" json:"source"`
+	Text       string `bson:"text" json:"text"`
+	CreatedAt  string `bson:"createdAt" json:"createdAt"`
+}
+
+// MemoryId id of the translation of the source hash into the language
+func MemoryId(sourceHash string, targetLang string) string {
+	return sourceHash + ":" + targetLang
+}
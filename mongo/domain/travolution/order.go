@@ -33,11 +33,23 @@ type Order struct {
 	CancelRequestedAt string `bson:"cancelRequestedAt,omitempty" json:"cancelRequestedAt,omitempty"`
 	CanceledAt        string `bson:"canceledAt,omitempty" json:"canceledAt,omitempty"`
 
+	// Pre orders of trip are held until trip pays, an order still held after HeldUntil is canceled
+	HeldUntil  string      `bson:"heldUntil,omitempty" json:"heldUntil,omitempty"`
+	HoldExpiry *HoldExpiry `bson:"holdExpiry,omitempty" json:"holdExpiry,omitempty"`
+
 	// Audit fields
 	CreatedAt string `bson:"createdAt" json:"createdAt" validate:"required"`
 	UpdatedAt string `bson:"updatedAt" json:"updatedAt" validate:"required"`
 }
 
+// HoldExpiry outcome of the last attempt to cancel the unpaid pre order
+type HoldExpiry struct {
+	Outcome  string `bson:"outcome" json:"outcome"`
+	Attempts int    `bson:"attempts" json:"attempts"`
+	Error    string `bson:"error,omitempty" json:"error,omitempty"`
+	At       string `bson:"at" json:"at"`
+}
+
 // UnitAmount represents the pricing unit and quantity.
 type UnitAmount struct {
 	Unit   interface{} `bson:"unit" json:"unit" validate:"required"`
@@ -1,5 +1,7 @@
 package travolution
 
+import "swallow-supplier/mongo/domain/webhook"
+
 // WebhookEventData represents the order data in the webhook
 type WebhookEventData struct {
 	OrderNumber     string `bson:"orderNumber" json:"orderNumber" validate:"required"`
@@ -14,4 +16,6 @@ type Webhook struct {
 	Data      WebhookEventData `bson:"data" json:"data" validate:"required,dive"`
 	CreatedAt string           `bson:"createdAt" json:"createdAt" validate:"required"`
 	UpdatedAt string           `bson:"updatedAt" json:"updatedAt"`
+	// Verification signature check of the request, set by the transport and never read from the body
+	Verification *webhook.Verification `bson:"verification,omitempty" json:"-"`
 }
@@ -0,0 +1,19 @@
+package trip
+
+import "time"
+
+// NotificationOutbox outbound trip / pdf voucher notification waiting for delivery
+type NotificationOutbox struct {
+	Id             string    `bson:"_id,omitempty" json:"id,omitempty"`
+	Kind           string    `bson:"kind" json:"kind" oneof:"'TRIP_NOTIFY' 'PDF_VOUCHER'"`
+	Message        string    `bson:"message" json:"message"`
+	PartnerOrderId string    `bson:"partnerOrderId" json:"partnerOrderId"`
+	CallBackId     string    `bson:"callBackId,omitempty" json:"callBackId,omitempty"`
+	Payload        string    `bson:"payload" json:"payload"`
+	Status         string    `bson:"status" json:"status" oneof:"'PENDING' 'SENT' 'DEAD'"`
+	Attempts       int       `bson:"attempts" json:"attempts"`
+	NextRetry      time.Time `bson:"nextRetry" json:"nextRetry"`
+	LastError      string    `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	CreatedAt      string    `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      string    `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,22 @@
+package webhook
+
+// Verification outcome of the signature check of a supplier callback
+type Verification struct {
+	Verified   bool   `bson:"verified" json:"verified"`
+	Enforced   bool   `bson:"enforced" json:"enforced"`
+	Reason     string `bson:"reason,omitempty" json:"reason,omitempty"`
+	Timestamp  string `bson:"timestamp,omitempty" json:"timestamp,omitempty"`
+	VerifiedAt string `bson:"verifiedAt" json:"verifiedAt"`
+}
+
+// Record raw supplier callback with its verification, rejected callbacks are stored as well
+type Record struct {
+	Id            string       `bson:"_id,omitempty" json:"id,omitempty"`
+	Supplier      string       `bson:"supplier" json:"supplier"`
+	Path          string       `bson:"path" json:"path"`
+	RemoteAddr    string       `bson:"remoteAddr,omitempty" json:"remoteAddr,omitempty"`
+	Body          string       `bson:"body" json:"body"`
+	BodyTruncated bool         `bson:"bodyTruncated,omitempty" json:"bodyTruncated,omitempty"`
+	Verification  Verification `bson:"verification" json:"verification"`
+	CreatedAt     string       `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,42 @@
+package yanolja
+
+import "fmt"
+
+// CategoryMapping yanolja category mapped to the ggt and trip categories, one per yanolja
+// category code and level. The field names are the snake case headers of the mapping sheet
+// the collection was first imported from
+type CategoryMapping struct {
+	YanoljaCategoryId         int64  `bson:"yanolja_category_id" json:"yanoljaCategoryId"`
+	YanoljaCategoryCode       int    `bson:"yanolja_category_code" json:"yanoljaCategoryCode" validate:"required,gt=0"`
+	YanoljaCategoryLevel      int    `bson:"yanolja_category_level" json:"yanoljaCategoryLevel" validate:"required,gt=0"`
+	YanoljaCategoryKName      string `bson:"yanolja_category_k_name" json:"yanoljaCategoryKName"`
+	YanoljaCategoryEName      string `bson:"yanolja_category_e_name" json:"yanoljaCategoryEName"`
+	YanoljaCategoryStatusCode string `bson:"yanolja_category_status_code" json:"yanoljaCategoryStatusCode"`
+	GGTCategoryId             int    `bson:"ggtcategory_id" json:"GGTCategoryId"`
+	GGTCategoryName           string `bson:"ggtcategory_name" json:"GGTCategoryName"`
+	GGTSubCategoryId          int    `bson:"ggtsub_category_id" json:"GGTSubCategoryId"`
+	GGTSubCategoryName        string `bson:"ggtsub_category_name" json:"GGTSubCategoryName"`
+	TripCategoryName          string `bson:"trip_category_name" json:"tripCategoryName" validate:"required"`
+	SupplierGGTChannel        string `bson:"supplier_ggt_channel" json:"supplierGGTChannel"`
+	CreatedAt                 string `bson:"created_at,omitempty" json:"createdAt,omitempty"`
+	UpdatedAt                 string `bson:"updated_at,omitempty" json:"updatedAt,omitempty"`
+}
+
+// Key yanolja category code and level the mapping is stored under
+func (m CategoryMapping) Key() string {
+	return CategoryKey(m.YanoljaCategoryCode, m.YanoljaCategoryLevel)
+}
+
+// CategoryKey key of a yanolja category code and level
+func CategoryKey(code int, level int) string {
+	return fmt.Sprintf("%d|%d", code, level)
+}
+
+// UnmappedCategoryProduct product with categories which have no trip mapping, its content
+// reaches trip without those categories
+type UnmappedCategoryProduct struct {
+	ProductId         int64             `bson:"_id" json:"productId"`
+	ProductName       string            `bson:"productName" json:"productName"`
+	ProductStatusCode string            `bson:"productStatusCode" json:"productStatusCode"`
+	Categories        []ProductCategory `bson:"categories" json:"categories"`
+}
@@ -0,0 +1,27 @@
+package yanolja
+
+// scopes of a status transition
+const (
+	TransitionScopeOrder   = "ORDER"
+	TransitionScopeVariant = "VARIANT"
+)
+
+// StatusTransition one change of the order status or of the status of one of its variants, kept
+// in the status history of the order
+type StatusTransition struct {
+	Scope          string `bson:"scope" json:"scope"`
+	OrderVariantId int64  `bson:"orderVariantId,omitempty" json:"orderVariantId,omitempty"`
+	From           string `bson:"from" json:"from"`
+	To             string `bson:"to" json:"to"`
+	Event          string `bson:"event" json:"event"`
+	Source         string `bson:"source" json:"source"`
+	At             string `bson:"at" json:"at"`
+}
+
+// HoldExpiry outcome of canceling a pre order trip did not pay within the hold window
+type HoldExpiry struct {
+	Outcome  string `bson:"outcome" json:"outcome"`
+	Attempts int    `bson:"attempts" json:"attempts"`
+	Error    string `bson:"error,omitempty" json:"error,omitempty"`
+	At       string `bson:"at" json:"at"`
+}
@@ -1,6 +1,8 @@
 package yanolja
 
 import (
+	"swallow-supplier/mongo/domain/money"
+	"swallow-supplier/mongo/domain/refund"
 	"time"
 )
 
@@ -23,6 +25,13 @@ type SelectVariant struct {
 	Currency         string  `bson:"currency" json:"currency" validate:"required"` // 3-character currency code
 	PartnerSalePrice float32 `bson:"partnerSalePrice" json:"partnerSalePrice" validate:"required,gt=0"`
 	CostPrice        float32 `bson:"costPrice" json:"costPrice" validate:"required,gt=0"`
+
+	// prices as sent by the channel and the rate used to settle them in Currency
+	SaleAmount *money.Conversion `bson:"saleAmount,omitempty" json:"saleAmount,omitempty"`
+	CostAmount *money.Conversion `bson:"costAmount,omitempty" json:"costAmount,omitempty"`
+
+	// product version the variant was booked against, set on the order view
+	ProductVersionLink string `bson:"-" json:"productVersionLink,omitempty"`
 }
 
 // ValidityPeriod represents the validity period of an order variant.
@@ -79,6 +88,7 @@ type OrderVariant struct {
 	ReconciliationByDate        []ReconcilationDetail `bson:"reconciliationByDate" json:"reconciliationByDate"validate:"required"`
 	ForceCancelTypeCode         string                `bson:"forceCancelTypeCode" json:"forceCancelTypeCode" validate:"required"`
 	CancelVariantSync           bool                  `bson:"cancelVariantSync" json:"cancelVariantSync" validate:"required" default:"false""`
+	Refund                      *money.Money          `bson:"refund,omitempty" json:"refund,omitempty"` // refunded to the channel once the variant is canceled
 }
 
 type ReconcilationDetail struct {
@@ -88,21 +98,29 @@ type ReconcilationDetail struct {
 
 // OrderResponse represents the full order response.
 type Model struct {
-	Id                            string          `bson:"_id,omitempty" json:"id"`
-	Suppliers                     string          `bson:"suppliers,omitempty" json:"suppliers,omitempty"`
-	PartnerOrderID                string          `bson:"partnerOrderId" json:"partnerOrderId" validate:"required, min=0, max <=50"`
-	PartnerOrderGroupID           string          `bson:"partnerOrderGroupId" json:"partnerOrderGroupId"`
-	OrderId                       int64           `bson:"orderId" json:"orderId" validate:"required"`
-	PartnerOrderChannelCode       string          `bson:"partnerOrderChannelCode" json:"partnerOrderChannelCode"`
-	PartnerOrderChannelName       string          `bson:"partnerOrderChannelName" json:"partnerOrderChannelName" validate:"min=0, max=255"`
-	TotalSelectedVariantsQuantity int32           `bson:"totalSelectedVariantsQuantity" json:"totalSelectedVariantsQuantity" validate:"gt=0"`
-	OrderStatusCode               string          `bson:"orderStatusCode" json:"orderStatusCode" validate:"required"`
-	Customer                      Customer        `bson:"customer" json:"customer" validate:"required,dive"`
-	ActualCustomer                Customer        `bson:"actualCustomer" json:"actualCustomer" validate:"required,dive"`
-	SelectVariants                []SelectVariant `bson:"selectVariants" json:"selectVariants" validate:"required,dive"`
-	OrderVariants                 []OrderVariant  `bson:"orderVariants" json:"orderVariants"`
-	OrderExpired                  bool            `bson:"orderExpired" json:"orderExpired" default:"false"`
-	OodoSyncStatus                bool            `bson:"oodoSyncStatus" json:"oodoSyncStatus" default:"false"`
-	CreatedAt                     string          `bson:"createdAt" json:"createdAt" validate:"required"`
-	UpdatedAt                     string          `bson:"updatedAt" json:"updatedAt"`
+	Id                            string             `bson:"_id,omitempty" json:"id"`
+	Suppliers                     string             `bson:"suppliers,omitempty" json:"suppliers,omitempty"`
+	PartnerOrderID                string             `bson:"partnerOrderId" json:"partnerOrderId" validate:"required, min=0, max <=50"`
+	PartnerOrderGroupID           string             `bson:"partnerOrderGroupId" json:"partnerOrderGroupId"`
+	OrderId                       int64              `bson:"orderId" json:"orderId" validate:"required"`
+	SupplierOrderNumber           string             `bson:"supplierOrderNumber,omitempty" json:"supplierOrderNumber,omitempty"` // order number at suppliers without numeric order id
+	PartnerOrderChannelCode       string             `bson:"partnerOrderChannelCode" json:"partnerOrderChannelCode"`
+	PartnerOrderChannelName       string             `bson:"partnerOrderChannelName" json:"partnerOrderChannelName" validate:"min=0, max=255"`
+	TotalSelectedVariantsQuantity int32              `bson:"totalSelectedVariantsQuantity" json:"totalSelectedVariantsQuantity" validate:"gt=0"`
+	OrderStatusCode               string             `bson:"orderStatusCode" json:"orderStatusCode" validate:"required"`
+	Customer                      Customer           `bson:"customer" json:"customer" validate:"required,dive"`
+	ActualCustomer                Customer           `bson:"actualCustomer" json:"actualCustomer" validate:"required,dive"`
+	SelectVariants                []SelectVariant    `bson:"selectVariants" json:"selectVariants" validate:"required,dive"`
+	OrderVariants                 []OrderVariant     `bson:"orderVariants" json:"orderVariants"`
+	OrderExpired                  bool               `bson:"orderExpired" json:"orderExpired" default:"false"`
+	HoldExpiry                    *HoldExpiry        `bson:"holdExpiry,omitempty" json:"holdExpiry,omitempty"`                       // set by the hold expiry job
+	CancelRequestCategory         string             `bson:"cancelRequestCategory,omitempty" json:"cancelRequestCategory,omitempty"` // FullCancel or PartialCancel, of the latest cancellation asked for
+	RefundQuote                   *refund.Quote      `bson:"refundQuote,omitempty" json:"refundQuote,omitempty"`                     // quoted for the latest cancellation
+	OodoSyncStatus                bool               `bson:"oodoSyncStatus" json:"oodoSyncStatus" default:"false"`
+	OdooId                        int64              `bson:"odooId,omitempty" json:"odooId,omitempty"` // sale.order id, set by the odoo push
+	OdooPushedAt                  string             `bson:"odooPushedAt,omitempty" json:"odooPushedAt,omitempty"`
+	OdooPushError                 string             `bson:"odooPushError,omitempty" json:"odooPushError,omitempty"`
+	StatusHistory                 []StatusTransition `bson:"statusHistory,omitempty" json:"statusHistory,omitempty"` // status changes of the order and its variants, oldest first
+	CreatedAt                     string             `bson:"createdAt" json:"createdAt" validate:"required"`
+	UpdatedAt                     string             `bson:"updatedAt" json:"updatedAt"`
 }
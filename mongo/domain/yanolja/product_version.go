@@ -0,0 +1,62 @@
+package yanolja
+
+import "fmt"
+
+// kinds of product changes
+const (
+	ProductChangeAdded   = "ADDED"
+	ProductChangeRemoved = "REMOVED"
+	ProductChangeChanged = "CHANGED"
+)
+
+// areas of the product a change falls in
+const (
+	ProductAreaPrice      = "price"
+	ProductAreaStatus     = "status"
+	ProductAreaSalePeriod = "salePeriod"
+	ProductAreaVariants   = "variants"
+	ProductAreaContent    = "content"
+)
+
+// ProductVersion product as received for one version, with what changed since the version
+// stored before it
+type ProductVersion struct {
+	Id              string          `bson:"_id" json:"id"`
+	ProductId       int64           `bson:"productId" json:"productId"`
+	Version         int32           `bson:"version" json:"version"`
+	PreviousVersion int32           `bson:"previousVersion" json:"previousVersion"`
+	Areas           []string        `bson:"areas" json:"areas"`
+	Changes         []ProductChange `bson:"changes" json:"changes"`
+	Product         *Product        `bson:"product,omitempty" json:"product,omitempty"`
+	CreatedAt       string          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       string          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ProductChange one changed field of the product, Path names it by the ids of the elements
+// holding it
+type ProductChange struct {
+	Path   string `bson:"path" json:"path"`
+	Area   string `bson:"area" json:"area"`
+	Kind   string `bson:"kind" json:"kind"`
+	Before any    `bson:"before,omitempty" json:"before,omitempty"`
+	After  any    `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// ProductVersionDiff changes between two stored versions of a product
+type ProductVersionDiff struct {
+	ProductId int64           `json:"productId"`
+	From      int32           `json:"from"`
+	To        int32           `json:"to"`
+	Areas     []string        `json:"areas"`
+	Changes   []ProductChange `json:"changes"`
+}
+
+// ProductVersionId id of the version of the product
+func ProductVersionId(productId int64, version int32) string {
+	return fmt.Sprintf("%d:%d", productId, version)
+}
+
+// ProductVersionLink path of the version of the product in the api
+func ProductVersionLink(productId int64, version int32) string {
+	return fmt.Sprintf("/v1/products/%d/versions/%d", productId, version)
+}
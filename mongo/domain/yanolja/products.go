@@ -34,6 +34,10 @@ type Product struct {
 	ViewScheduleStatus          bool                 `bson:"viewScheduleStatus" json:"viewScheduleStatus"`
 	ContentScheduleStatus       bool                 `bson:"contentScheduleStatus" json:"contentScheduleStatus"`
 	OodoSyncStatus              bool                 `bson:"oodoSyncStatus" json:"oodoSyncStatus"`
+	OdooTemplateId              int64                `bson:"odooTemplateId,omitempty" json:"odooTemplateId,omitempty"` // product.template id, set by the odoo push
+	OdooProductId               int64                `bson:"odooProductId,omitempty" json:"odooProductId,omitempty"`
+	OdooPushedAt                string               `bson:"odooPushedAt,omitempty" json:"odooPushedAt,omitempty"`
+	OdooPushError               string               `bson:"odooPushError,omitempty" json:"odooPushError,omitempty"`
 	CreatedAt                   string               `bson:"createdAt" json:"createdAt" validate:"required"`
 	UpdatedAt                   string               `bson:"updatedAt" json:"updatedAt"`
 }
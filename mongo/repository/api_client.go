@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"swallow-supplier/mongo/domain/apiclient"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertApiClient insert api client
+func (r *mongoRepository) InsertApiClient(ctx context.Context, client apiclient.ApiClient) (id string, err error) {
+	level.Info(r.logger).Log("repo-method", "InsertApiClient")
+
+	collection := r.db.Collection("api_clients")
+
+	currentTime := time.Now().UTC().Format(time.RFC3339)
+	client.Id = primitive.NewObjectID().Hex()
+	client.CreatedAt = currentTime
+	client.UpdatedAt = currentTime
+
+	_, err = collection.InsertOne(ctx, client)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to insert api client", "name", client.Name, "err", err)
+		return "", err
+	}
+
+	return client.Id, nil
+}
+
+// GetApiClients fetch all api clients
+func (r *mongoRepository) GetApiClients(ctx context.Context) (clients []apiclient.ApiClient, err error) {
+	collection := r.db.Collection("api_clients")
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch api clients", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	clients = make([]apiclient.ApiClient, 0)
+	if err = cursor.All(ctx, &clients); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+// GetApiClientById fetch api client by id
+func (r *mongoRepository) GetApiClientById(ctx context.Context, id string) (client apiclient.ApiClient, err error) {
+	collection := r.db.Collection("api_clients")
+
+	err = collection.FindOne(ctx, bson.M{"_id": id}).Decode(&client)
+	return client, err
+}
+
+// GetApiClientByKeyHash fetch api client by the hash of its key
+func (r *mongoRepository) GetApiClientByKeyHash(ctx context.Context, keyHash string) (client apiclient.ApiClient, err error) {
+	collection := r.db.Collection("api_clients")
+
+	err = collection.FindOne(ctx, bson.M{"keyHash": keyHash}).Decode(&client)
+	return client, err
+}
+
+// UpdateApiClient update fields of an api client
+func (r *mongoRepository) UpdateApiClient(ctx context.Context, id string, update map[string]any) error {
+	collection := r.db.Collection("api_clients")
+
+	if update == nil {
+		update = make(map[string]any)
+	}
+	update["updatedAt"] = time.Now().UTC().Format(time.RFC3339)
+
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": update})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to update api client", "id", id, "err", err)
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("api client %s not found", id)
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/pagination"
 	"time"
 
 	"github.com/go-kit/kit/log/level"
@@ -70,30 +71,15 @@ func (r *mongoRepository) UpdateCategoriesByCategoryId(ctx context.Context, cate
 	return result.UpsertedID.(string), nil
 }
 
-// fetch all the category record
-func (r *mongoRepository) FindCategory(ctx context.Context) (record []yanolja.Category, err error) {
-	filter := bson.M{} // Empty filter to match all documents
-
-	collection := r.db.Collection("categories")
-	cursor, err := collection.Find(ctx, filter)
+// FindCategory one page of the stored categories with the total matching the query, a zero
+// query returns them all
+func (r *mongoRepository) FindCategory(ctx context.Context, q pagination.Query) (record []yanolja.Category, total int64, next string, err error) {
+	record, total, next, err = findPage[yanolja.Category](ctx, r.db.Collection("categories"), bson.M{}, q)
 	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	for cursor.Next(ctx) {
-		var category yanolja.Category
-		if err := cursor.Decode(&category); err != nil {
-			return nil, err
-		}
-		record = append(record, category)
+		level.Error(r.logger).Log("error", "failed to fetch categories", "err", err)
+		return nil, 0, "", err
 	}
-
-	if err := cursor.Err(); err != nil {
-		return nil, err
-	}
-
-	return record, nil
+	return record, total, next, nil
 }
 
 // FindCategoryByCategoryId filter category based on categoryId
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"swallow-supplier/mongo/domain/yanolja"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// categoryMappingFilter filter of the mapping of the yanolja category
+func categoryMappingFilter(code int, categoryLevel int) bson.M {
+	return bson.M{"yanolja_category_code": code, "yanolja_category_level": categoryLevel}
+}
+
+// GetCategoryMappings mappings ordered by yanolja category level and code, categoryLevel zero
+// and an empty trip category match every mapping
+func (r *mongoRepository) GetCategoryMappings(ctx context.Context, categoryLevel int, tripCategory string) (mappings []yanolja.CategoryMapping, err error) {
+	collection := r.db.Collection("category_mapping")
+
+	filter := bson.M{}
+	if categoryLevel > 0 {
+		filter["yanolja_category_level"] = categoryLevel
+	}
+	if tripCategory != "" {
+		filter["trip_category_name"] = bson.M{"$regex": "^" + regexp.QuoteMeta(tripCategory) + "$", "$options": "i"}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "yanolja_category_level", Value: 1}, {Key: "yanolja_category_code", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch category mappings", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	mappings = make([]yanolja.CategoryMapping, 0)
+	if err = cursor.All(ctx, &mappings); err != nil {
+		return nil, err
+	}
+	return mappings, nil
+}
+
+// GetCategoryMapping mapping of the yanolja category, mongo.ErrNoDocuments when it has none
+func (r *mongoRepository) GetCategoryMapping(ctx context.Context, code int, categoryLevel int) (mapping yanolja.CategoryMapping, err error) {
+	err = r.db.Collection("category_mapping").FindOne(ctx, categoryMappingFilter(code, categoryLevel)).Decode(&mapping)
+	return mapping, err
+}
+
+// InsertCategoryMapping store a new mapping, false when the category is already mapped
+func (r *mongoRepository) InsertCategoryMapping(ctx context.Context, mapping yanolja.CategoryMapping) (inserted bool, err error) {
+	collection := r.db.Collection("category_mapping")
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	mapping.CreatedAt, mapping.UpdatedAt = now, now
+
+	// the filter keeps a concurrent insert of the same category from adding a second mapping
+	opts := options.Update().SetUpsert(true)
+	result, err := collection.UpdateOne(ctx, categoryMappingFilter(mapping.YanoljaCategoryCode, mapping.YanoljaCategoryLevel), bson.M{"$setOnInsert": mapping}, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to insert category mapping", "key", mapping.Key(), "err", err)
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// UpdateCategoryMapping replace the fields of the mapping of the yanolja category, false when
+// the category has no mapping
+func (r *mongoRepository) UpdateCategoryMapping(ctx context.Context, mapping yanolja.CategoryMapping) (updated bool, err error) {
+	collection := r.db.Collection("category_mapping")
+
+	fields, err := categoryMappingFields(mapping)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(ctx, categoryMappingFilter(mapping.YanoljaCategoryCode, mapping.YanoljaCategoryLevel), bson.M{"$set": fields})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to update category mapping", "key", mapping.Key(), "err", err)
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// DeleteCategoryMapping delete the mapping of the yanolja category, false when it had none
+func (r *mongoRepository) DeleteCategoryMapping(ctx context.Context, code int, categoryLevel int) (deleted bool, err error) {
+	result, err := r.db.Collection("category_mapping").DeleteOne(ctx, categoryMappingFilter(code, categoryLevel))
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to delete category mapping", "code", code, "level", categoryLevel, "err", err)
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+// UpsertCategoryMappings store the mappings of an upload in one bulk write
+func (r *mongoRepository) UpsertCategoryMappings(ctx context.Context, mappings []yanolja.CategoryMapping) (map[string]int64, error) {
+	collection := r.db.Collection("category_mapping")
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	operations := make([]mongo.WriteModel, 0, len(mappings))
+	for _, mapping := range mappings {
+		fields, err := categoryMappingFields(mapping)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, mongo.NewUpdateOneModel().
+			SetFilter(categoryMappingFilter(mapping.YanoljaCategoryCode, mapping.YanoljaCategoryLevel)).
+			SetUpdate(bson.M{"$set": fields, "$setOnInsert": bson.M{"created_at": now}}).
+			SetUpsert(true))
+	}
+	if len(operations) == 0 {
+		return map[string]int64{"modified": 0, "upserted": 0}, nil
+	}
+
+	result, err := collection.BulkWrite(ctx, operations, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to upsert category mappings", "err", err)
+		return nil, err
+	}
+	return map[string]int64{"modified": result.ModifiedCount, "upserted": result.UpsertedCount}, nil
+}
+
+// categoryMappingFields fields of the mapping to set, created_at is kept from the insert
+func categoryMappingFields(mapping yanolja.CategoryMapping) (bson.M, error) {
+	mapping.CreatedAt = ""
+	mapping.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	raw, err := bson.Marshal(mapping)
+	if err != nil {
+		return nil, err
+	}
+	fields := bson.M{}
+	if err = bson.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// GetUnmappedCategoryProducts products with a third level category which has no trip mapping,
+// GetTripsByCategory finds nothing for those and their content goes to trip without them
+func (r *mongoRepository) GetUnmappedCategoryProducts(ctx context.Context) (products []yanolja.UnmappedCategoryProduct, err error) {
+	collection := r.db.Collection("products")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{"productId": 1, "productName": 1, "productStatusCode": 1, "categories": 1}}},
+		{{Key: "$unwind", Value: "$categories"}},
+		{{Key: "$unwind", Value: "$categories.subCategories"}},
+		{{Key: "$unwind", Value: "$categories.subCategories.subCategories"}},
+		{{Key: "$project", Value: bson.M{
+			"productId":         1,
+			"productName":       1,
+			"productStatusCode": 1,
+			"category":          "$categories.subCategories.subCategories",
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "category_mapping",
+			"let": bson.M{
+				"code":  bson.M{"$convert": bson.M{"input": "$category.categoryCode", "to": "int", "onError": nil, "onNull": nil}},
+				"level": "$category.categoryLevel",
+			},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$yanolja_category_code", "$$code"}},
+					bson.M{"$eq": bson.A{"$yanolja_category_level", "$$level"}},
+				}}}},
+				bson.M{"$limit": 1},
+			},
+			"as": "mapping",
+		}}},
+		{{Key: "$match", Value: bson.M{"mapping": bson.M{"$size": 0}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":               "$productId",
+			"productName":       bson.M{"$first": "$productName"},
+			"productStatusCode": bson.M{"$first": "$productStatusCode"},
+			"categories": bson.M{"$addToSet": bson.M{
+				"categoryCode":  "$category.categoryCode",
+				"categoryLevel": "$category.categoryLevel",
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch products without category mapping", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products = make([]yanolja.UnmappedCategoryProduct, 0)
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetResumeToken fetch the persisted resume token of a change stream, nil when there is none
+func (r *mongoRepository) GetResumeToken(ctx context.Context, name string) (token bson.Raw, err error) {
+	collection := r.db.Collection("change_stream_tokens")
+
+	var doc struct {
+		Token bson.Raw `bson:"token"`
+	}
+	err = collection.FindOne(ctx, bson.M{"_id": name}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	return doc.Token, err
+}
+
+// SaveResumeToken persist the resume token of a change stream, a nil token drops it
+func (r *mongoRepository) SaveResumeToken(ctx context.Context, name string, token bson.Raw) error {
+	collection := r.db.Collection("change_stream_tokens")
+
+	if token == nil {
+		_, err := collection.DeleteOne(ctx, bson.M{"_id": name})
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"token":     token,
+		"updatedAt": time.Now().UTC().Format(time.RFC3339),
+	}}
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": name}, update, options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"swallow-supplier/mongo/domain/money"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpsertFxRates insert or replace the rates, a rate is identified by base, quote and asOf
+func (r *mongoRepository) UpsertFxRates(ctx context.Context, rates []money.FxRate) (upserted int64, err error) {
+	level.Info(r.logger).Log("repo-method", "UpsertFxRates")
+
+	if len(rates) == 0 {
+		return 0, nil
+	}
+
+	collection := r.db.Collection("fx_rates")
+
+	currentTime := time.Now().UTC().Format(time.RFC3339)
+	models := make([]mongo.WriteModel, 0, len(rates))
+	for _, rate := range rates {
+		filter := bson.M{
+			"base":  strings.ToUpper(rate.Base),
+			"quote": strings.ToUpper(rate.Quote),
+			"asOf":  rate.AsOf,
+		}
+		update := bson.M{
+			"$set": bson.M{
+				"rate":      rate.Rate,
+				"source":    rate.Source,
+				"updatedAt": currentTime,
+			},
+			"$setOnInsert": bson.M{
+				"_id":       primitive.NewObjectID().Hex(),
+				"createdAt": currentTime,
+			},
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	res, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to upsert fx rates", "err", err)
+		return 0, err
+	}
+
+	return res.UpsertedCount + res.ModifiedCount, nil
+}
+
+// GetFxRate latest rate of the pair on or before asOf
+func (r *mongoRepository) GetFxRate(ctx context.Context, base, quote, asOf string) (rate money.FxRate, err error) {
+	collection := r.db.Collection("fx_rates")
+
+	filter := bson.M{
+		"base":  strings.ToUpper(base),
+		"quote": strings.ToUpper(quote),
+		"asOf":  bson.M{"$lte": asOf},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "asOf", Value: -1}})
+
+	err = collection.FindOne(ctx, filter, opts).Decode(&rate)
+	return rate, err
+}
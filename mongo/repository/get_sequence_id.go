@@ -9,6 +9,7 @@ import (
 	"github.com/go-kit/kit/log/level"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func (r *mongoRepository) GetSequenceIDByKey(ctx context.Context, key string, typeOfCollection string) (string, error) {
@@ -27,19 +28,25 @@ func (r *mongoRepository) GetSequenceIDByKey(ctx context.Context, key string, ty
 	if typeOfCollection == constant.TRIPPAYMENTREQUEST {
 		collection = r.db.Collection("trip_payments_request")
 
-	} else if typeOfCollection == constant.TRIPFULLORDERCANCELREQUEST {
+	} else if typeOfCollection == constant.TRIPFULLORDERCANCELREQUEST || typeOfCollection == constant.TRIPPARTIALCANCELREQUEST {
 		collection = r.db.Collection("trip_full_cancel_request")
 	}
 
 	// MongoDB query
 	filter := bson.M{"otaOrderId": key}
+	opts := options.FindOne()
+	if typeOfCollection == constant.TRIPFULLORDERCANCELREQUEST || typeOfCollection == constant.TRIPPARTIALCANCELREQUEST {
+		// an order may be canceled partially several times, the latest request is answered
+		filter["requestCategory"] = typeOfCollection
+		opts.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	}
 
 	// Target result struct
 	var result struct {
 		SequenceID string `bson:"sequenceId"`
 	}
 
-	err := collection.FindOne(ctx, filter).Decode(&result)
+	err := collection.FindOne(ctx, filter, opts).Decode(&result)
 	if err != nil {
 		level.Error(r.logger).Log("error", fmt.Sprintf("document not exist with otaOrderId %s", key))
 		return "", err
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"swallow-supplier/mongo/domain/travolution"
+	"swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetExpiredHolds pre orders of the supplier created before createdBefore which are still waiting
+// for trip's payment, with an orderId above afterOrderId and in orderId order. Orders which ran out
+// of expiry attempts are left out
+func (r *mongoRepository) GetExpiredHolds(ctx context.Context, supplier string, createdBefore string, afterOrderId int64, limit int64) (orders []yanolja.Model, err error) {
+	collection := r.db.Collection("orders")
+
+	suppliers := bson.A{bson.M{"suppliers": bson.M{"$regex": "^" + regexp.QuoteMeta(supplier) + "$", "$options": "i"}}}
+	if strings.EqualFold(supplier, constant.SUPPLIERYANOLJA) {
+		// orders stored before the supplier was recorded are yanolja's
+		suppliers = append(suppliers, bson.M{"suppliers": bson.M{"$in": bson.A{"", nil}}})
+	}
+
+	filter := bson.M{
+		"orderId":             bson.M{"$gt": afterOrderId},
+		"orderStatusCode":     constant.ORDERPREPARE,
+		"orderExpired":        bson.M{"$ne": true},
+		"createdAt":           bson.M{"$lt": createdBefore},
+		"holdExpiry.attempts": bson.M{"$not": bson.M{"$gte": constant.HoldExpiryMaxAttempts}},
+		"$or":                 suppliers,
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "orderId", Value: 1}}).SetLimit(limit)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch expired holds", "supplier", supplier, "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	orders = make([]yanolja.Model, 0)
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// UpdateHoldExpiry record the outcome of expiring the pre order, an expired order is marked
+// expired and canceled with its transitions
+func (r *mongoRepository) UpdateHoldExpiry(ctx context.Context, orderId int64, expiry yanolja.HoldExpiry, transitions []yanolja.StatusTransition) error {
+	collection := r.db.Collection("orders")
+
+	filter := bson.M{"orderId": orderId}
+	set := bson.M{
+		"holdExpiry.outcome": expiry.Outcome,
+		"holdExpiry.error":   expiry.Error,
+		"holdExpiry.at":      expiry.At,
+		"updatedAt":          time.Now().UTC().Format(time.RFC3339),
+	}
+	if expiry.Outcome == constant.HoldExpiryOutcomeExpired {
+		set["orderExpired"] = true
+		set["orderStatusCode"] = orderstate.OrderCanceled
+		set["oodoSyncStatus"] = false
+	}
+	update := bson.M{
+		"$set": set,
+		"$inc": bson.M{"holdExpiry.attempts": 1},
+	}
+
+	result, err := collection.UpdateOne(ctx, guardTransitions(filter, update, transitions), update)
+	if err != nil {
+		level.Error(r.logger).Log("repository-error", "UpdateHoldExpiry", "error", err)
+		return fmt.Errorf("failed to update hold expiry: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		if len(transitions) > 0 {
+			return orderstate.ErrStaleTransition
+		}
+		return fmt.Errorf("order %d not found", orderId)
+	}
+	r.recordOrderChange(ctx, filter)
+
+	return nil
+}
+
+// GetExpiredTravolutionHolds travolution pre orders still held for trip's payment after heldBefore,
+// with an orderNumber above afterOrderNumber and in orderNumber order. Only orders travolution has
+// issued are returned, orders which ran out of expiry attempts are left out
+func (r *mongoRepository) GetExpiredTravolutionHolds(ctx context.Context, heldBefore string, afterOrderNumber string, limit int64) (orders []travolution.Order, err error) {
+	collection := r.db.Collection("travolution_orders")
+
+	filter := bson.M{
+		"orderNumber":         bson.M{"$gt": afterOrderNumber},
+		"status":              constant.ORDERAVAILABLE,
+		"heldUntil":           bson.M{"$gt": "", "$lt": heldBefore},
+		"holdExpiry.attempts": bson.M{"$not": bson.M{"$gte": constant.HoldExpiryMaxAttempts}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "orderNumber", Value: 1}}).SetLimit(limit)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch expired travolution holds", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	orders = make([]travolution.Order, 0)
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// UpdateTravolutionHoldExpiry record the outcome of canceling the unpaid travolution pre order, an
+// expired order is no longer held
+func (r *mongoRepository) UpdateTravolutionHoldExpiry(ctx context.Context, orderNumber string, expiry travolution.HoldExpiry) error {
+	collection := r.db.Collection("travolution_orders")
+
+	set := bson.M{
+		"holdExpiry.outcome": expiry.Outcome,
+		"holdExpiry.error":   expiry.Error,
+		"holdExpiry.at":      expiry.At,
+		"updatedAt":          time.Now().UTC().Format(time.RFC3339),
+	}
+	update := bson.M{
+		"$set": set,
+		"$inc": bson.M{"holdExpiry.attempts": 1},
+	}
+	if expiry.Outcome == constant.HoldExpiryOutcomeExpired {
+		update["$unset"] = bson.M{"heldUntil": ""}
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"orderNumber": orderNumber}, update)
+	if err != nil {
+		level.Error(r.logger).Log("repository-error", "UpdateTravolutionHoldExpiry", "error", err)
+		return fmt.Errorf("failed to update travolution hold expiry: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("travolution order %s not found", orderNumber)
+	}
+
+	return nil
+}
+
+// ReleaseTravolutionHold trip paid the pre order, it is no longer held
+func (r *mongoRepository) ReleaseTravolutionHold(ctx context.Context, orderNumber string) error {
+	collection := r.db.Collection("travolution_orders")
+
+	update := bson.M{
+		"$unset": bson.M{"heldUntil": ""},
+		"$set":   bson.M{"updatedAt": time.Now().UTC().Format(time.RFC3339)},
+	}
+	if _, err := collection.UpdateOne(ctx, bson.M{"orderNumber": orderNumber}, update); err != nil {
+		level.Error(r.logger).Log("repository-error", "ReleaseTravolutionHold", "error", err)
+		return fmt.Errorf("failed to release travolution hold: %w", err)
+	}
+
+	return nil
+}
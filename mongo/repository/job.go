@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"swallow-supplier/mongo/domain/job"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetJobSettings fetch the runtime settings of all jobs
+func (r *mongoRepository) GetJobSettings(ctx context.Context) (settings []job.Setting, err error) {
+	collection := r.db.Collection("jobs")
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch job settings", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	settings = make([]job.Setting, 0)
+	if err = cursor.All(ctx, &settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// UpdateJobSetting set fields of the job settings, creating them on first change
+func (r *mongoRepository) UpdateJobSetting(ctx context.Context, name string, update map[string]any) error {
+	collection := r.db.Collection("jobs")
+
+	update["updatedAt"] = time.Now().UTC().Format(time.RFC3339)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": name}, bson.M{"$set": update}, options.Update().SetUpsert(true))
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to update job setting", "job", name, "err", err)
+	}
+	return err
+}
+
+// ClaimJobTrigger take the pending manual trigger of the job, only one caller gets it
+func (r *mongoRepository) ClaimJobTrigger(ctx context.Context, name string) (claimed bool, err error) {
+	collection := r.db.Collection("jobs")
+
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"_id": name, "triggerRequestedAt": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"triggerRequestedAt": ""}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount > 0, nil
+}
+
+// EnsureJobRunIndexes index job runs by job and expire them once expireAt has passed
+func (r *mongoRepository) EnsureJobRunIndexes(ctx context.Context) error {
+	collection := r.db.Collection("job_runs")
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "job", Value: 1}, {Key: "startedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "expireAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to create job run indexes", "err", err)
+	}
+	return err
+}
+
+// InsertJobRun record a finished job run
+func (r *mongoRepository) InsertJobRun(ctx context.Context, run job.Run) (id string, err error) {
+	collection := r.db.Collection("job_runs")
+
+	run.Id = primitive.NewObjectID().Hex()
+	if _, err = collection.InsertOne(ctx, run); err != nil {
+		level.Error(r.logger).Log("error", "Failed to insert job run", "job", run.Job, "err", err)
+		return "", err
+	}
+
+	return run.Id, nil
+}
+
+// GetJobRuns latest runs of the job, newest first
+func (r *mongoRepository) GetJobRuns(ctx context.Context, name string, limit int64) (runs []job.Run, err error) {
+	collection := r.db.Collection("job_runs")
+
+	opts := options.Find().SetSort(bson.D{{Key: "startedAt", Value: -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, bson.M{"job": name}, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch job runs", "job", name, "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs = make([]job.Run, 0)
+	if err = cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AcquireJobLease take or renew the lease for holder. The lease is granted when it does not exist,
+// is already held by holder or has expired, a live lease of another holder returns false
+func (r *mongoRepository) AcquireJobLease(ctx context.Context, name, holder string, ttl time.Duration) (acquired bool, err error) {
+	collection := r.db.Collection("job_leases")
+
+	now := time.Now().UTC()
+	filter := bson.M{
+		"_id": name,
+		"$or": []bson.M{
+			{"holder": holder},
+			{"expiresAt": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"holder":    holder,
+		"renewedAt": now,
+		"expiresAt": now.Add(ttl),
+	}}
+
+	res, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		// the upsert collides with the live lease of another holder
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		level.Error(r.logger).Log("error", "Failed to acquire job lease", "name", name, "err", err)
+		return false, err
+	}
+
+	return res.MatchedCount > 0 || res.UpsertedCount > 0, nil
+}
+
+// ReleaseJobLease give up the lease if holder still has it, a standby can take it right away
+func (r *mongoRepository) ReleaseJobLease(ctx context.Context, name, holder string) error {
+	collection := r.db.Collection("job_leases")
+
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": name, "holder": holder})
+	return err
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"swallow-supplier/utils/pagination"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// listFilter filter of the query's filters combined by its condition, and-ed with base
+func listFilter(base bson.M, q pagination.Query) bson.M {
+	conditions := make(bson.A, 0, len(q.Filters))
+	for field, value := range q.Filters {
+		if q.List.Filter[field] == pagination.FilterText {
+			value = bson.M{"$regex": regexp.QuoteMeta(fmt.Sprint(value)), "$options": "i"}
+		}
+		conditions = append(conditions, bson.M{field: value})
+	}
+
+	filter := bson.M{}
+	for field, value := range base {
+		filter[field] = value
+	}
+	switch {
+	case len(conditions) == 0:
+	case q.Condition == "OR":
+		filter["$or"] = conditions
+	default:
+		filter["$and"] = conditions
+	}
+	return filter
+}
+
+// findPage one page of the query from the collection and the total matching the filter. Cursor
+// pages return the cursor of the next page, empty on the last one. A zero query returns every
+// record
+func findPage[T any](ctx context.Context, collection *mongo.Collection, base bson.M, q pagination.Query) (records []T, total int64, next string, err error) {
+	filter := listFilter(base, q)
+
+	total, err = collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to count %s: %w", collection.Name(), err)
+	}
+
+	direction := 1
+	if q.Descending() {
+		direction = -1
+	}
+
+	opts := options.Find()
+	if field := q.SortField(); field != "" {
+		sort := bson.D{{Key: field, Value: direction}}
+		if field != q.List.Key {
+			// ties keep one order so pages neither repeat nor miss records
+			sort = append(sort, bson.E{Key: q.List.Key, Value: direction})
+		}
+		opts.SetSort(sort)
+	}
+	if q.RecordsPerPage > 0 {
+		opts.SetLimit(q.RecordsPerPage)
+	}
+	if skip := q.Skip(); skip > 0 {
+		opts.SetSkip(skip)
+	}
+
+	if q.CursorMode && q.After != nil {
+		operator := "$gt"
+		if direction < 0 {
+			operator = "$lt"
+		}
+		filter = bson.M{"$and": bson.A{filter, bson.M{q.List.Key: bson.M{operator: q.After}}}}
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to fetch %s: %w", collection.Name(), err)
+	}
+	defer cursor.Close(ctx)
+
+	records = make([]T, 0)
+	var last bson.RawValue
+	for cursor.Next(ctx) {
+		var record T
+		if err = cursor.Decode(&record); err != nil {
+			return nil, 0, "", fmt.Errorf("failed to decode %s: %w", collection.Name(), err)
+		}
+		records = append(records, record)
+		if q.CursorMode {
+			last = cursor.Current.Lookup(q.List.Key)
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, 0, "", fmt.Errorf("failed to read %s: %w", collection.Name(), err)
+	}
+
+	if q.CursorMode && int64(len(records)) == q.RecordsPerPage && last.Type != 0 {
+		var value any
+		if err = last.Unmarshal(&value); err != nil {
+			return nil, 0, "", fmt.Errorf("failed to read %s cursor: %w", collection.Name(), err)
+		}
+		if next, err = pagination.EncodeCursor(value); err != nil {
+			return nil, 0, "", err
+		}
+	}
+
+	return records, total, next, nil
+}
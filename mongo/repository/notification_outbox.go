@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"swallow-supplier/mongo/domain/trip"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertNotificationOutbox insert notification into the outbox
+func (r *mongoRepository) InsertNotificationOutbox(ctx context.Context, msg trip.NotificationOutbox) (id string, err error) {
+	level.Info(r.logger).Log("repo-method", "InsertNotificationOutbox")
+
+	collection := r.db.Collection("notification_outbox")
+
+	currentTime := time.Now().UTC().Format(time.RFC3339)
+	msg.Id = primitive.NewObjectID().Hex()
+	msg.CreatedAt = currentTime
+	msg.UpdatedAt = currentTime
+	if msg.Status == "" {
+		msg.Status = constant.OutboxStatusPending
+	}
+
+	_, err = collection.InsertOne(ctx, msg)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to insert notification outbox", "partnerOrderId", msg.PartnerOrderId, "err", err)
+		return "", err
+	}
+
+	return msg.Id, nil
+}
+
+// ClaimDueNotificationOutbox picks the oldest pending notification whose retry time passed and
+// pushes its nextRetry by the lease, so a concurrent drain does not pick it again
+func (r *mongoRepository) ClaimDueNotificationOutbox(ctx context.Context, lease time.Duration) (msg trip.NotificationOutbox, err error) {
+	collection := r.db.Collection("notification_outbox")
+
+	now := time.Now().UTC()
+	filter := bson.M{
+		"status":    constant.OutboxStatusPending,
+		"nextRetry": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"nextRetry": now.Add(lease),
+			"updatedAt": now.Format(time.RFC3339),
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextRetry", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&msg)
+	return msg, err
+}
+
+// UpdateNotificationOutbox update fields of an outbox notification
+func (r *mongoRepository) UpdateNotificationOutbox(ctx context.Context, id string, update map[string]any) error {
+	collection := r.db.Collection("notification_outbox")
+
+	if update == nil {
+		update = make(map[string]any)
+	}
+	update["updatedAt"] = time.Now().UTC().Format(time.RFC3339)
+
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": update})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to update notification outbox", "id", id, "err", err)
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("notification outbox %s not found", id)
+	}
+
+	return nil
+}
+
+// GetNotificationOutbox list outbox notifications by status, newest first
+func (r *mongoRepository) GetNotificationOutbox(ctx context.Context, status string, skip, limit int64) (msgs []trip.NotificationOutbox, total int64, err error) {
+	collection := r.db.Collection("notification_outbox")
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err = collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	msgs = make([]trip.NotificationOutbox, 0)
+	if err = cursor.All(ctx, &msgs); err != nil {
+		return nil, 0, err
+	}
+
+	return msgs, total, nil
+}
+
+// GetNotificationOutboxById fetch outbox notification by id
+func (r *mongoRepository) GetNotificationOutboxById(ctx context.Context, id string) (msg trip.NotificationOutbox, err error) {
+	collection := r.db.Collection("notification_outbox")
+
+	err = collection.FindOne(ctx, bson.M{"_id": id}).Decode(&msg)
+	return msg, err
+}
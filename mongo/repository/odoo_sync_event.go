@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"swallow-supplier/mongo/domain/odoo"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const odooSyncCounter = "odoo_sync"
+
+// EnsureOdooSyncIndexes index the sync events by kind and sequence
+func (r *mongoRepository) EnsureOdooSyncIndexes(ctx context.Context) error {
+	collection := r.db.Collection("odoo_sync_events")
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "kind", Value: 1}, {Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to create odoo sync event indexes", "err", err)
+	}
+	return err
+}
+
+// AppendOdooSyncEvent record a change of the order or product under the next sequence
+func (r *mongoRepository) AppendOdooSyncEvent(ctx context.Context, kind string, refId int64) (seq int64, err error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = r.db.Collection("counters").FindOneAndUpdate(ctx,
+		bson.M{"_id": odooSyncCounter},
+		bson.M{"$inc": bson.M{"seq": int64(1)}},
+		opts,
+	).Decode(&counter)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to take odoo sync sequence", "kind", kind, "refId", refId, "err", err)
+		return 0, err
+	}
+
+	event := odoo.SyncEvent{
+		Seq:       counter.Seq,
+		Kind:      kind,
+		RefId:     refId,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if _, err = r.db.Collection("odoo_sync_events").InsertOne(ctx, event); err != nil {
+		level.Error(r.logger).Log("error", "Failed to insert odoo sync event", "kind", kind, "refId", refId, "err", err)
+		return 0, err
+	}
+
+	return event.Seq, nil
+}
+
+// recordOrderChange append a sync event for every order matching the filter which already
+// has an orderId. The write it follows has succeeded, so failures are only logged
+func (r *mongoRepository) recordOrderChange(ctx context.Context, filter bson.M) {
+	opts := options.Find().SetProjection(bson.M{"orderId": 1})
+	cursor, err := r.db.Collection("orders").Find(ctx, filter, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to look up changed order for odoo sync", "err", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var order struct {
+			OrderId int64 `bson:"orderId"`
+		}
+		if err = cursor.Decode(&order); err != nil || order.OrderId == 0 {
+			continue
+		}
+		r.AppendOdooSyncEvent(ctx, odoo.SyncKindOrder, order.OrderId)
+	}
+}
+
+// GetOdooSyncEvents events of the kind after since in sequence order. Events younger than the
+// settle period are left for the next pull
+func (r *mongoRepository) GetOdooSyncEvents(ctx context.Context, kind string, since int64, limit int64) (events []odoo.SyncEvent, err error) {
+	collection := r.db.Collection("odoo_sync_events")
+
+	settled := time.Now().UTC().Add(-constant.OdooSyncSettleSeconds * time.Second).Format(time.RFC3339)
+	filter := bson.M{
+		"kind":      kind,
+		"_id":       bson.M{"$gt": since},
+		"createdAt": bson.M{"$lte": settled},
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch odoo sync events", "kind", kind, "since", since, "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events = make([]odoo.SyncEvent, 0)
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// GetOdooSyncSeq latest sequence handed out
+func (r *mongoRepository) GetOdooSyncSeq(ctx context.Context) (seq int64, err error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+
+	err = r.db.Collection("counters").FindOne(ctx, bson.M{"_id": odooSyncCounter}).Decode(&counter)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return counter.Seq, err
+}
+
+// GetOdooSyncCursor cursor acknowledged by the consumer, zero when it never acknowledged
+func (r *mongoRepository) GetOdooSyncCursor(ctx context.Context, consumer string) (cursor odoo.SyncCursor, err error) {
+	err = r.db.Collection("odoo_sync_cursors").FindOne(ctx, bson.M{"_id": consumer}).Decode(&cursor)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return odoo.SyncCursor{Consumer: consumer}, nil
+	}
+	return cursor, err
+}
+
+// AckOdooSyncCursor move the cursor of the consumer forward, an older cursor leaves it unchanged
+func (r *mongoRepository) AckOdooSyncCursor(ctx context.Context, consumer string, seq int64) (cursor odoo.SyncCursor, err error) {
+	collection := r.db.Collection("odoo_sync_cursors")
+
+	update := bson.M{
+		"$max": bson.M{"cursor": seq},
+		"$set": bson.M{"ackedAt": time.Now().UTC().Format(time.RFC3339)},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err = collection.FindOneAndUpdate(ctx, bson.M{"_id": consumer}, update, opts).Decode(&cursor); err != nil {
+		level.Error(r.logger).Log("error", "Failed to acknowledge odoo sync cursor", "consumer", consumer, "cursor", seq, "err", err)
+		return cursor, err
+	}
+
+	return cursor, nil
+}
+
+// UpdateOdooPushState set the odoo ids and push outcome on the order or product. It does not
+// append a sync event, the push would otherwise follow its own writes
+func (r *mongoRepository) UpdateOdooPushState(ctx context.Context, kind string, refId int64, update map[string]any) error {
+	collection, filter := r.db.Collection("orders"), bson.M{"orderId": refId}
+	if kind == odoo.SyncKindProduct {
+		collection, filter = r.db.Collection("products"), bson.M{"productId": refId}
+	}
+
+	if _, err := collection.UpdateOne(ctx, filter, bson.M{"$set": update}); err != nil {
+		level.Error(r.logger).Log("error", "Failed to update odoo push state", "kind", kind, "refId", refId, "err", err)
+		return err
+	}
+	return nil
+}
@@ -7,10 +7,15 @@ import (
 	"strings"
 	"time"
 
+	"swallow-supplier/mongo/domain/odoo"
+	"swallow-supplier/mongo/domain/refund"
 	"swallow-supplier/mongo/domain/yanolja"
 	domain "swallow-supplier/mongo/domain/yanolja"
 	req_resp "swallow-supplier/request_response/yanolja"
 	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
+	"swallow-supplier/utils/pagination"
+	refundCalc "swallow-supplier/utils/refund"
 
 	"github.com/go-kit/kit/log/level"
 	"go.mongodb.org/mongo-driver/bson"
@@ -56,6 +61,8 @@ func (r *mongoRepository) InsertPreOrder(ctx context.Context, rec req_resp.Waiti
 			Currency:         variant.Currency,
 			PartnerSalePrice: variant.PartnerSalePrice,
 			CostPrice:        variant.CostPrice,
+			SaleAmount:       variant.SaleAmount,
+			CostAmount:       variant.CostAmount,
 		}
 		selectVariant = append(selectVariant, variant)
 	}
@@ -117,6 +124,7 @@ func (r *mongoRepository) UpdatePreOrderById(ctx context.Context, update map[str
 	if err != nil || result.ModifiedCount == 0 {
 		return "", fmt.Errorf("failed to update document: %w", err)
 	}
+	r.recordOrderChange(ctx, filter)
 
 	val := update["_id"]
 
@@ -206,7 +214,7 @@ func (r *mongoRepository) GetOrderbyPartnerOrderId(ctx context.Context, partnerO
 }
 
 // UpdateOrderByOrderId  update single order by orderId
-func (r *mongoRepository) UpdateOrderByOrderId(ctx context.Context, orderid int64, update map[string]any) (id string, err error) {
+func (r *mongoRepository) UpdateOrderByOrderId(ctx context.Context, orderid int64, update map[string]any, transitions ...yanolja.StatusTransition) (id string, err error) {
 	level.Info(r.logger).Log("repository method ", "UpdateOrderByOrderId")
 
 	// Create an empty bson.M map
@@ -226,16 +234,20 @@ func (r *mongoRepository) UpdateOrderByOrderId(ctx context.Context, orderid int6
 
 	opts := options.Update().SetUpsert(false) // Set upsert to true if you want to insert a new document if no match is found
 
-	result, err := collection.UpdateOne(context.TODO(), filter, updateBson, opts)
+	result, err := collection.UpdateOne(context.TODO(), guardTransitions(filter, updateBson, transitions), updateBson, opts)
+	if err == nil && len(transitions) > 0 && result.MatchedCount == 0 {
+		return "", orderstate.ErrStaleTransition
+	}
 	if err != nil || result.ModifiedCount == 0 {
 		return "", fmt.Errorf("failed to update document: %w", err)
 	}
+	r.recordOrderChange(ctx, filter)
 
 	return string(orderid), nil
 }
 
 // UpdateProcessingRestoringOfOrder  for updating orderVariantStatusTypeCode
-func (r *mongoRepository) UpdateProcessingRestoringOfOrder(ctx context.Context, orderId int64, updaterec map[string]any) (err error) {
+func (r *mongoRepository) UpdateProcessingRestoringOfOrder(ctx context.Context, orderId int64, updaterec map[string]any, transitions []yanolja.StatusTransition) (err error) {
 	level.Info(r.logger).Log("repository method ", "UpdateProcessingRestoringOfOrder")
 
 	collection := r.db.Collection("orders")
@@ -264,11 +276,15 @@ func (r *mongoRepository) UpdateProcessingRestoringOfOrder(ctx context.Context,
 	opts := options.Update().SetUpsert(false) // Set upsert to true if you want to insert a new document if no match is found
 
 	// Execute the UpdateOne to match and update a single orderVariant
-	_, err = collection.UpdateOne(ctx, filter, update, opts)
+	result, err := collection.UpdateOne(ctx, guardTransitions(filter, update, transitions), update, opts)
 	if err != nil {
 		level.Error(r.logger).Log("repository-error ", "UpdateProcessingRestoringOfOrder")
 		return fmt.Errorf("failed to update Resusal to cancel info: %w", err)
 	}
+	if len(transitions) > 0 && result.MatchedCount == 0 {
+		return orderstate.ErrStaleTransition
+	}
+	r.recordOrderChange(ctx, bson.M{"orderId": orderId})
 
 	return nil
 }
@@ -327,6 +343,7 @@ func (r *mongoRepository) DeleteOrderByOrderId(ctx context.Context, orderid int6
 		}
 		return "", fmt.Errorf("failed to delete document with id %d with error %v", orderid, err)
 	}
+	r.AppendOdooSyncEvent(ctx, odoo.SyncKindOrder, orderid)
 
 	return deletedDoc.Id, nil
 }
@@ -358,6 +375,7 @@ func (r *mongoRepository) UpdateOrderDueToRefusalToCancel(ctx context.Context, o
 	if err != nil || result.ModifiedCount == 0 {
 		return "", fmt.Errorf("failed to update document: %w", err)
 	}
+	r.recordOrderChange(ctx, bson.M{"orderId": orderid})
 
 	return string(orderid), nil
 }
@@ -512,12 +530,13 @@ func (r *mongoRepository) UpdateOrderVoucherIndividually(ctx context.Context, or
 	if err != nil || result.ModifiedCount == 0 {
 		return fmt.Errorf("failed to update document: %w", err)
 	}
+	r.recordOrderChange(ctx, bson.M{"orderId": orderid})
 
 	return nil
 }
 
 // ForcedCancellationReasonUpdate  callback to update cancele status of ordervariantStatusCode
-func (r *mongoRepository) ForcedCancellationReasonUpdate(ctx context.Context, orderid int64, partnerOrderId string, orderVariantId int64, ForceCancelReason string) (err error) {
+func (r *mongoRepository) ForcedCancellationReasonUpdate(ctx context.Context, orderid int64, partnerOrderId string, orderVariantId int64, ForceCancelReason string, transitions []yanolja.StatusTransition) (err error) {
 
 	level.Info(r.logger).Log("repository method", "ForcedCancellationReasonUpdate")
 
@@ -545,11 +564,15 @@ func (r *mongoRepository) ForcedCancellationReasonUpdate(ctx context.Context, or
 	opts := options.Update().SetUpsert(false) // Set upsert to true if you want to insert a new document if no match is found
 
 	// Execute the UpdateOne to match and update a single orderVariant
-	_, err = collection.UpdateOne(ctx, filter, update, opts)
+	result, err := collection.UpdateOne(ctx, guardTransitions(filter, update, transitions), update, opts)
 	if err != nil {
 		level.Error(r.logger).Log("repository-error ", "ForcedCancellationReasonUpdate")
 		return fmt.Errorf("failed to update Resusal to cancel info: %w", err)
 	}
+	if len(transitions) > 0 && result.MatchedCount == 0 {
+		return orderstate.ErrStaleTransition
+	}
+	r.recordOrderChange(ctx, bson.M{"orderId": orderid})
 
 	return nil
 }
@@ -698,6 +721,7 @@ func (r *mongoRepository) UpdateCancelDetailsForVariants(
 	if err != nil {
 		return fmt.Errorf("failed to update cancel details: %w", err)
 	}
+	r.recordOrderChange(ctx, bson.M{"orderId": orderId})
 
 	// Log the count of matched and modified documents
 	fmt.Printf("Matched %d document(s) and updated %d variant(s)\n", res.MatchedCount, res.ModifiedCount)
@@ -836,6 +860,20 @@ func (r *mongoRepository) UpdateReconciliationDetailByDayInsert(ctx context.Cont
 	return nil
 }
 
+// variantMatch conditions on the order variant of a reconciliation request, the fields are prefixed
+// for array filters. Requests naming the orderVariantId only match that variant, the others every
+// variant of the product variant
+func variantMatch(req map[string]any, prefix string) bson.M {
+	match := bson.M{
+		prefix + "productId": req["productId"],
+		prefix + "variantId": req["variantId"],
+	}
+	if orderVariantId, ok := req["orderVariantId"]; ok {
+		match[prefix+"orderVariantId"] = orderVariantId
+	}
+	return match
+}
+
 // UpdateReconciliationDetailByDay  update record of reconcilation day by day
 func (r *mongoRepository) UpdateReconciliationDetailByDay(ctx context.Context, req map[string]any, updates []domain.ReconcilationDetail) error {
 	level.Info(r.logger).Log("repository_method_name ", "UpdateReconciliationDetailByDay")
@@ -861,10 +899,7 @@ func (r *mongoRepository) UpdateReconciliationDetailByDay(ctx context.Context, r
 		"orderId":        req["orderId"],
 		"partnerOrderId": req["partnerOrderId"],
 		"orderVariants": bson.M{
-			"$elemMatch": bson.M{
-				"productId": req["productId"],
-				"variantId": req["variantId"],
-			},
+			"$elemMatch": variantMatch(req, ""),
 		},
 	}
 
@@ -887,10 +922,7 @@ func (r *mongoRepository) UpdateReconciliationDetailByDay(ctx context.Context, r
 		// Define array filters for updating the reconciliation record with the specified date.
 		arrayFilters := options.ArrayFilters{
 			Filters: []interface{}{
-				bson.M{
-					"variantFilter.productId": req["productId"],
-					"variantFilter.variantId": req["variantId"],
-				},
+				variantMatch(req, "variantFilter."),
 				bson.M{
 					"reconFilter.reconciliationDate": reconciliationDate,
 				},
@@ -918,10 +950,7 @@ func (r *mongoRepository) UpdateReconciliationDetailByDay(ctx context.Context, r
 			// Use array filters for pushing a new entry to the specified variant.
 			insertArrayFilters := options.ArrayFilters{
 				Filters: []interface{}{
-					bson.M{
-						"variantFilter.productId": req["productId"],
-						"variantFilter.variantId": req["variantId"],
-					},
+					variantMatch(req, "variantFilter."),
 				},
 			}
 
@@ -1210,7 +1239,7 @@ func (r *mongoRepository) GetReconciliationDetailsByDateAndStatus(ctx context.Co
 }
 
 // UpdateOrderDueToRefusalToCancel  update cancel status of single order
-func (r *mongoRepository) UpdateForcedCancelOrderDetail(ctx context.Context, partnerOrderId string, newStatus string) (id string, err error) {
+func (r *mongoRepository) UpdateForcedCancelOrderDetail(ctx context.Context, partnerOrderId string, newStatus string, transitions []yanolja.StatusTransition) (id string, err error) {
 	level.Info(r.logger).Log("repository method ", "UpdateForcedCancelOrderDetail")
 
 	collection := r.db.Collection("orders")
@@ -1233,17 +1262,88 @@ func (r *mongoRepository) UpdateForcedCancelOrderDetail(ctx context.Context, par
 	opts := options.Update().SetUpsert(false) // Set upsert to true if you want to insert a new document if no match is found
 
 	// Execute the UpdateOne to match and update the document
-	result, err := collection.UpdateOne(ctx, filter, update, opts)
+	result, err := collection.UpdateOne(ctx, guardTransitions(filter, update, transitions), update, opts)
+	if err == nil && len(transitions) > 0 && result.MatchedCount == 0 {
+		return "", orderstate.ErrStaleTransition
+	}
 	if err != nil || result.ModifiedCount == 0 {
 		level.Error(r.logger).Log("repository-error", "UpdateForcedCancelOrderDetail", "error", err)
 		return "", fmt.Errorf("failed to update document: %w", err)
 	}
+	r.recordOrderChange(ctx, filter)
 
 	return partnerOrderId, nil
 }
 
+// UpdateCancelRequest move the variants to CANCELING with their quoted refund, and keep the quote and
+// the category of the cancellation asked for
+func (r *mongoRepository) UpdateCancelRequest(ctx context.Context, orderid int64, category string, orderVariantIds []int64, quote refund.Quote, transitions []yanolja.StatusTransition) (err error) {
+	level.Info(r.logger).Log("repository method ", "UpdateCancelRequest")
+
+	collection := r.db.Collection("orders")
+
+	filter := bson.M{"orderId": orderid}
+
+	set := bson.M{
+		"cancelRequestCategory": category,
+		"oodoSyncStatus":        false,
+		"updatedAt":             time.Now().UTC().Format(time.RFC3339),
+	}
+	// an unquoted cancellation keeps no quote
+	if quote.QuotedAt != "" {
+		set["refundQuote"] = quote
+	}
+	refunds := refundCalc.Refunds(quote)
+	arrayFilters := make([]interface{}, 0, len(orderVariantIds))
+	for _, orderVariantId := range orderVariantIds {
+		identifier := fmt.Sprintf("v%d", len(arrayFilters))
+		set["orderVariants.$["+identifier+"].orderVariantStatusTypeCode"] = constant.ORDERVARIANTCANCELINGSTATUS
+		if refund, ok := refunds[orderVariantId]; ok {
+			set["orderVariants.$["+identifier+"].refund"] = refund
+		}
+		arrayFilters = append(arrayFilters, bson.M{identifier + ".orderVariantId": orderVariantId})
+	}
+	update := bson.M{"$set": set}
+
+	opts := options.Update().SetUpsert(false).SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+
+	result, err := collection.UpdateOne(ctx, guardTransitions(filter, update, transitions), update, opts)
+	if err == nil && len(transitions) > 0 && result.MatchedCount == 0 {
+		return orderstate.ErrStaleTransition
+	}
+	if err != nil || result.MatchedCount == 0 {
+		level.Error(r.logger).Log("repository-error", "UpdateCancelRequest", "error", err)
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+	r.recordOrderChange(ctx, filter)
+
+	return nil
+}
+
+// UpdateRefundQuote keep the refund quoted for the cancellation of the order
+func (r *mongoRepository) UpdateRefundQuote(ctx context.Context, orderid int64, quote refund.Quote) (err error) {
+	level.Info(r.logger).Log("repository method ", "UpdateRefundQuote")
+
+	collection := r.db.Collection("orders")
+
+	filter := bson.M{"orderId": orderid}
+	update := bson.M{"$set": bson.M{
+		"refundQuote": quote,
+		"updatedAt":   time.Now().UTC().Format(time.RFC3339),
+	}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil || result.MatchedCount == 0 {
+		level.Error(r.logger).Log("repository-error", "UpdateRefundQuote", "error", err)
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+	r.recordOrderChange(ctx, filter)
+
+	return nil
+}
+
 // UpdateOrderVariantStatusByOrderId  to update all variant status
-func (r *mongoRepository) UpdateOrderVariantStatusByOrderId(ctx context.Context, orderid int64, variantStatus string) (err error) {
+func (r *mongoRepository) UpdateOrderVariantStatusByOrderId(ctx context.Context, orderid int64, variantStatus string, transitions []yanolja.StatusTransition) (err error) {
 	level.Info(r.logger).Log("repository method ", "UpdateOrderVariantStatusByOrderId")
 
 	// Create an empty bson.M map
@@ -1265,16 +1365,20 @@ func (r *mongoRepository) UpdateOrderVariantStatusByOrderId(ctx context.Context,
 
 	opts := options.Update().SetUpsert(false) // Set upsert to true if you want to insert a new document if no match is found
 
-	result, err := collection.UpdateOne(context.TODO(), filter, updateBson, opts)
+	result, err := collection.UpdateOne(context.TODO(), guardTransitions(filter, updateBson, transitions), updateBson, opts)
+	if err == nil && len(transitions) > 0 && result.MatchedCount == 0 {
+		return orderstate.ErrStaleTransition
+	}
 	if err != nil || result.ModifiedCount == 0 {
 		return fmt.Errorf("failed to update document: %w", err)
 	}
+	r.recordOrderChange(ctx, filter)
 
 	return nil
 }
 
 // UpdateOrderCancelTypeCode  to update all variant canceltypecode
-func (r *mongoRepository) UpdateOrderCancelAck(ctx context.Context, orderid int64, partnerOrderId string, canceltypecode string, orderstatus string) (err error) {
+func (r *mongoRepository) UpdateOrderCancelAck(ctx context.Context, orderid int64, partnerOrderId string, canceltypecode string, orderstatus string, orderVariantIds []int64, transitions []yanolja.StatusTransition) (err error) {
 	level.Info(r.logger).Log("repository method ", "UpdateOrderCancelAck")
 
 	// Create an empty bson.M map
@@ -1287,30 +1391,42 @@ func (r *mongoRepository) UpdateOrderCancelAck(ctx context.Context, orderid int6
 	filter := bson.M{"orderId": orderid,
 		"partnerOrderId": partnerOrderId}
 
+	// every variant unless the acknowledgement is for some of them
+	variants := "orderVariants.$[]"
+	opts := options.Update().SetUpsert(false) // Set upsert to true if you want to insert a new document if no match is found
+	if len(orderVariantIds) > 0 {
+		variants = "orderVariants.$[acked]"
+		opts.SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+			bson.M{"acked.orderVariantId": bson.M{"$in": orderVariantIds}},
+		}})
+	}
+
 	updateBson = bson.M{
 		"$set": bson.M{
-			"orderVariants.$[].orderCancelTypeCode":                                    canceltypecode,
-			"orderVariants.$[].canceledDateTime":                                       time.Now().UTC().Format(time.RFC3339),
-			"orderVariants.$[].refundInfo":                                             canceltypecode,
-			"orderVariants.$[].orderVariantStatusTypeCode":                             orderstatus,
-			"orderVariants.$[].orderVariantItems.$[].voucher.voucherProvideStatusCode": orderstatus,
-			"oodoSyncStatus": false,
-			"updatedAt":      time.Now().UTC().Format(time.RFC3339),
+			variants + ".orderCancelTypeCode":                                    canceltypecode,
+			variants + ".canceledDateTime":                                       time.Now().UTC().Format(time.RFC3339),
+			variants + ".refundInfo":                                             canceltypecode,
+			variants + ".orderVariantStatusTypeCode":                             orderstatus,
+			variants + ".orderVariantItems.$[].voucher.voucherProvideStatusCode": orderstatus,
+			"oodoSyncStatus":                                                     false,
+			"updatedAt":                                                          time.Now().UTC().Format(time.RFC3339),
 		},
 	}
 
-	opts := options.Update().SetUpsert(false) // Set upsert to true if you want to insert a new document if no match is found
-
-	result, err := collection.UpdateMany(context.TODO(), filter, updateBson, opts)
+	result, err := collection.UpdateMany(context.TODO(), guardTransitions(filter, updateBson, transitions), updateBson, opts)
+	if err == nil && len(transitions) > 0 && result.MatchedCount == 0 {
+		return orderstate.ErrStaleTransition
+	}
 	if err != nil || result.ModifiedCount == 0 {
 		return fmt.Errorf("failed to update document: %w", err)
 	}
+	r.recordOrderChange(ctx, filter)
 
 	return nil
 }
 
 // UpdateRefusalToCancelInfo
-func (r *mongoRepository) UpdateRefusalToCancelInfo(ctx context.Context, orderid int64, partnerOrderId string, ovariantId int64, cancelRejectTypeCode, message string) error {
+func (r *mongoRepository) UpdateRefusalToCancelInfo(ctx context.Context, orderid int64, partnerOrderId string, ovariantId int64, cancelRejectTypeCode, message string, transitions []yanolja.StatusTransition) error {
 	level.Info(r.logger).Log("repo-method", "UpdateRefusalToCancelInfo")
 
 	collection := r.db.Collection("orders")
@@ -1344,11 +1460,14 @@ func (r *mongoRepository) UpdateRefusalToCancelInfo(ctx context.Context, orderid
 	level.Info(r.logger).Log("update", update)
 
 	// Execute the update operation
-	result, err := collection.UpdateOne(ctx, filter, update)
+	result, err := collection.UpdateOne(ctx, guardTransitions(filter, update, transitions), update)
 	if err != nil {
 		level.Error(r.logger).Log("repository-error", "UpdateRefusalToCancelInfo", "error", err)
 		return fmt.Errorf("failed to update refusal to cancel info: %w", err)
 	}
+	if len(transitions) > 0 && result.MatchedCount == 0 {
+		return orderstate.ErrStaleTransition
+	}
 
 	// Log the update result
 	level.Info(r.logger).Log("update-result", result.ModifiedCount)
@@ -1358,6 +1477,7 @@ func (r *mongoRepository) UpdateRefusalToCancelInfo(ctx context.Context, orderid
 		level.Warn(r.logger).Log("warning", "No document updated. Verify the filter matches the target document.")
 		return fmt.Errorf("no document matched the filter criteria")
 	}
+	r.recordOrderChange(ctx, bson.M{"orderId": orderid})
 
 	return nil
 }
@@ -1404,6 +1524,7 @@ func (r *mongoRepository) UpdateForceCancelVariants(ctx context.Context, orderid
 	if err != nil {
 		return fmt.Errorf("failed to update force cancel variants reason: %w", err)
 	}
+	r.recordOrderChange(ctx, filter)
 
 	return nil
 }
@@ -1430,44 +1551,28 @@ func (r *mongoRepository) FindOrderByOrderIdAndPartnerOrderId(ctx context.Contex
 	return record, nil
 }
 
-// GetOrdersByChannelCodeAndCustomerEmail
-func (r *mongoRepository) GetOrdersByChannelCodeAndCustomerEmail(ctx context.Context, channelCode string, customerEmail string) ([]domain.Model, error) {
+// GetOrdersByChannelCodeAndCustomerEmail one page of the orders of the customer on the channel
+// with the total matching the query, mongo.ErrNoDocuments when the customer has none
+func (r *mongoRepository) GetOrdersByChannelCodeAndCustomerEmail(ctx context.Context, channelCode string, customerEmail string, q pagination.Query) ([]domain.Model, int64, string, error) {
 	level.Info(r.logger).Log("repository method", "GetOrdersByChannelCodeAndCustomerEmail")
 
-	fmt.Println(" channelCode :", channelCode, "cutomerEmail : ", customerEmail)
-	collection := r.db.Collection("orders")
-
 	filter := bson.M{
 		"partnerOrderChannelCode": channelCode,
 		"customer.email":          customerEmail,
 	}
 
-	cursor, err := collection.Find(ctx, filter)
+	records, total, next, err := findPage[domain.Model](ctx, r.db.Collection("orders"), filter, q)
 	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	var records []domain.Model
-	for cursor.Next(ctx) {
-		var record domain.Model
-		if err := cursor.Decode(&record); err != nil {
-			return nil, err
-		}
-		records = append(records, record)
-	}
-
-	if err := cursor.Err(); err != nil {
-		return nil, err
+		return nil, 0, "", err
 	}
 
 	// If no records found, return a custom error
-	if len(records) == 0 {
-		level.Error(r.logger).Log("error ", "no documents(order) exist with odooSyncStatus=false from method GetOrdersByOdooSyncStatus")
-		return nil, mongo.ErrNoDocuments
+	if total == 0 {
+		level.Error(r.logger).Log("error ", "no documents(order) exist for the customer email and channel code from method GetOrdersByChannelCodeAndCustomerEmail")
+		return nil, 0, "", mongo.ErrNoDocuments
 	}
 
-	return records, nil
+	return records, total, next, nil
 }
 
 // GetOrderByPartnerIdSuffix
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"swallow-supplier/mongo/domain/yanolja"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// statusCondition condition matching the status, an empty status also matches a missing field
+func statusCondition(status string) any {
+	if status == "" {
+		return bson.M{"$in": bson.A{"", nil}}
+	}
+	return status
+}
+
+// guardTransitions filter which matches the order only while it and its variants still hold the
+// statuses the transitions start from, the update appends the transitions to the status history.
+// A single variant is matched with one $elemMatch so positional $ updates keep pointing at it
+func guardTransitions(filter bson.M, update bson.M, transitions []yanolja.StatusTransition) bson.M {
+	if len(transitions) == 0 {
+		return filter
+	}
+
+	guarded := make(bson.M, len(filter)+1)
+	for key, value := range filter {
+		guarded[key] = value
+	}
+	filter = guarded
+
+	variants := make(bson.A, 0, len(transitions))
+	for _, transition := range transitions {
+		switch transition.Scope {
+		case yanolja.TransitionScopeOrder:
+			filter["orderStatusCode"] = statusCondition(transition.From)
+		case yanolja.TransitionScopeVariant:
+			variants = append(variants, bson.M{"orderVariants": bson.M{"$elemMatch": bson.M{
+				"orderVariantId":             transition.OrderVariantId,
+				"orderVariantStatusTypeCode": statusCondition(transition.From),
+			}}})
+		}
+	}
+
+	switch len(variants) {
+	case 0:
+	case 1:
+		delete(filter, "orderVariants.orderVariantId")
+		filter["orderVariants"] = variants[0].(bson.M)["orderVariants"]
+	default:
+		filter["$and"] = variants
+	}
+
+	update["$push"] = bson.M{"statusHistory": bson.M{"$each": transitions}}
+	return filter
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"swallow-supplier/mongo/domain/pricing"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetPricingRules fetch all pricing rules
+func (r *mongoRepository) GetPricingRules(ctx context.Context) (rules []pricing.Rule, err error) {
+	collection := r.db.Collection("pricing_rules")
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch pricing rules", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules = make([]pricing.Rule, 0)
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetPricingRuleById fetch pricing rule by id
+func (r *mongoRepository) GetPricingRuleById(ctx context.Context, id string) (rule pricing.Rule, err error) {
+	collection := r.db.Collection("pricing_rules")
+
+	err = collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rule)
+	return rule, err
+}
+
+// InsertPricingRule insert pricing rule
+func (r *mongoRepository) InsertPricingRule(ctx context.Context, rule pricing.Rule) (id string, err error) {
+	level.Info(r.logger).Log("repo-method", "InsertPricingRule")
+
+	collection := r.db.Collection("pricing_rules")
+
+	currentTime := time.Now().UTC().Format(time.RFC3339)
+	rule.Id = primitive.NewObjectID().Hex()
+	rule.CreatedAt = currentTime
+	rule.UpdatedAt = currentTime
+
+	_, err = collection.InsertOne(ctx, rule)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to insert pricing rule", "name", rule.Name, "err", err)
+		return "", err
+	}
+
+	return rule.Id, nil
+}
+
+// ReplacePricingRule replace the pricing rule keeping its creation time
+func (r *mongoRepository) ReplacePricingRule(ctx context.Context, rule pricing.Rule) error {
+	collection := r.db.Collection("pricing_rules")
+
+	rule.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	res, err := collection.ReplaceOne(ctx, bson.M{"_id": rule.Id}, rule)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to replace pricing rule", "id", rule.Id, "err", err)
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("pricing rule %s not found", rule.Id)
+	}
+
+	return nil
+}
+
+// DeletePricingRule delete pricing rule by id
+func (r *mongoRepository) DeletePricingRule(ctx context.Context, id string) (deleted bool, err error) {
+	collection := r.db.Collection("pricing_rules")
+
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to delete pricing rule", "id", id, "err", err)
+		return false, err
+	}
+
+	return res.DeletedCount > 0, nil
+}
+
+// EnsurePricingRuleIndexes rule names are unique, so the seed rules can be upserted by name
+func (r *mongoRepository) EnsurePricingRuleIndexes(ctx context.Context) error {
+	collection := r.db.Collection("pricing_rules")
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to create pricing rule indexes", "err", err)
+	}
+	return err
+}
+
+// SeedPricingRules insert the rules when the collection is still empty. Rules are upserted by name
+// so replicas starting together do not insert them twice
+func (r *mongoRepository) SeedPricingRules(ctx context.Context, rules []pricing.Rule) (inserted int, err error) {
+	collection := r.db.Collection("pricing_rules")
+
+	count, err := collection.CountDocuments(ctx, bson.M{})
+	if err != nil || count > 0 {
+		return 0, err
+	}
+
+	currentTime := time.Now().UTC().Format(time.RFC3339)
+	for _, rule := range rules {
+		rule.Id = primitive.NewObjectID().Hex()
+		rule.CreatedAt = currentTime
+		rule.UpdatedAt = currentTime
+
+		res, err := collection.UpdateOne(ctx, bson.M{"name": rule.Name}, bson.M{"$setOnInsert": rule}, options.Update().SetUpsert(true))
+		if mongo.IsDuplicateKeyError(err) {
+			// another replica seeded the rule first
+			continue
+		}
+		if err != nil {
+			level.Error(r.logger).Log("error", "Failed to seed pricing rule", "name", rule.Name, "err", err)
+			return inserted, err
+		}
+		inserted += int(res.UpsertedCount)
+	}
+
+	level.Info(r.logger).Log("info", "pricing rules seeded", "count", inserted)
+	return inserted, nil
+}
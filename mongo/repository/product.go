@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"swallow-supplier/mongo/domain/odoo"
 	"swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/request_response/common"
 	req_resp "swallow-supplier/request_response/yanolja"
@@ -36,6 +37,9 @@ func (r *mongoRepository) InsertProducts(ctx context.Context, products []yanolja
 	if err != nil {
 		return err
 	}
+	for _, product := range products {
+		r.AppendOdooSyncEvent(ctx, odoo.SyncKindProduct, product.ProductID)
+	}
 	return nil
 }
 
@@ -55,6 +59,7 @@ func (r *mongoRepository) InsertOneProduct(ctx context.Context, product yanolja.
 		level.Error(r.logger).Log("database error", err.Error())
 		return err
 	}
+	r.AppendOdooSyncEvent(ctx, odoo.SyncKindProduct, product.ProductID)
 	return nil
 }
 
@@ -142,6 +147,10 @@ func (r *mongoRepository) UpsertProduct(ctx context.Context, product yanolja.Pro
 	rsp["inserted_count"] = result.UpsertedCount
 	rsp["matched_count"] = result.MatchedCount
 
+	if result.ModifiedCount > 0 || result.UpsertedCount > 0 {
+		r.AppendOdooSyncEvent(ctx, odoo.SyncKindProduct, product.ProductID)
+	}
+
 	//fmt.Println("************ Detail***************** : ", rsp)
 	return nil
 }
@@ -279,6 +288,7 @@ func (r *mongoRepository) DeleteProductByProductId(ctx context.Context, productI
 		}
 		return "", fmt.Errorf("failed to delete document with id %d with error %v", productId, err)
 	}
+	r.AppendOdooSyncEvent(ctx, odoo.SyncKindProduct, productId)
 
 	return deletedDoc.Id, nil
 }
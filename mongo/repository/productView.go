@@ -10,6 +10,7 @@ import (
 	"swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/utils"
 	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/pagination"
 
 	"time"
 
@@ -359,9 +360,10 @@ func (r *mongoRepository) GetPLUDetails(ctx context.Context, productID int64) (p
 	return productview, nil
 }
 
-// GetAllProducts  get all products
-func (r *mongoRepository) GetAllProductViews(ctx context.Context) (products []yanolja.ProductView, err error) {
-	level.Info(r.logger).Log("methodName ", "GetAllProducts")
+// GetAllProductViews one page of the product views with the total matching the query, a zero
+// query returns them all
+func (r *mongoRepository) GetAllProductViews(ctx context.Context, q pagination.Query) (products []yanolja.ProductView, total int64, next string, err error) {
+	level.Info(r.logger).Log("methodName ", "GetAllProductViews")
 
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
@@ -374,19 +376,10 @@ func (r *mongoRepository) GetAllProductViews(ctx context.Context) (products []ya
 		}
 	}
 
-	collection := r.db.Collection("productview")
-
-	cursor, err := collection.Find(ctx, filter)
+	products, total, next, err = findPage[yanolja.ProductView](ctx, r.db.Collection("productview"), filter, q)
 	if err != nil {
 		level.Error(r.logger).Log("msg", "Failed to fetch products", "err", err)
-		return nil, fmt.Errorf("failed to fetch products: %w", err)
-	}
-
-	defer cursor.Close(ctx)
-
-	if err := cursor.All(ctx, &products); err != nil {
-		level.Error(r.logger).Log("msg", "Failed to decode products", "err", err)
-		return nil, fmt.Errorf("failed to decode products: %w", err)
+		return nil, 0, "", err
 	}
 
 	// Apply costPrice modification
@@ -397,7 +390,7 @@ func (r *mongoRepository) GetAllProductViews(ctx context.Context) (products []ya
 		}
 	}
 
-	return products, nil
+	return products, total, next, nil
 }
 
 // GetProductByProductId fetches a single product by productId
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"swallow-supplier/mongo/domain/yanolja"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpsertProductVersion store the version of the product, a version received again replaces the
+// stored one but keeps its createdAt
+func (r *mongoRepository) UpsertProductVersion(ctx context.Context, version yanolja.ProductVersion) error {
+	collection := r.db.Collection("product_versions")
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	version.Id = yanolja.ProductVersionId(version.ProductId, version.Version)
+	version.UpdatedAt = now
+
+	update := bson.M{
+		"$set": bson.M{
+			"productId":       version.ProductId,
+			"version":         version.Version,
+			"previousVersion": version.PreviousVersion,
+			"areas":           version.Areas,
+			"changes":         version.Changes,
+			"product":         version.Product,
+			"updatedAt":       version.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"createdAt": now},
+	}
+	opts := options.Update().SetUpsert(true)
+	if _, err := collection.UpdateOne(ctx, bson.M{"_id": version.Id}, update, opts); err != nil {
+		level.Error(r.logger).Log("error", "Failed to store product version", "id", version.Id, "err", err)
+		return err
+	}
+	return nil
+}
+
+// GetProductVersions versions of the product newest first, without their product
+func (r *mongoRepository) GetProductVersions(ctx context.Context, productId int64) (versions []yanolja.ProductVersion, err error) {
+	collection := r.db.Collection("product_versions")
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.M{"product": 0})
+
+	cursor, err := collection.Find(ctx, bson.M{"productId": productId}, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch product versions", "productId", productId, "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions = make([]yanolja.ProductVersion, 0)
+	if err = cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetProductVersion the version of the product, mongo.ErrNoDocuments when it is not stored
+func (r *mongoRepository) GetProductVersion(ctx context.Context, productId int64, version int32) (productVersion yanolja.ProductVersion, err error) {
+	err = r.db.Collection("product_versions").FindOne(ctx, bson.M{"_id": yanolja.ProductVersionId(productId, version)}).Decode(&productVersion)
+	return productVersion, err
+}
+
+// GetPreviousProductVersion latest stored version of the product below version,
+// mongo.ErrNoDocuments when there is none
+func (r *mongoRepository) GetPreviousProductVersion(ctx context.Context, productId int64, version int32) (productVersion yanolja.ProductVersion, err error) {
+	collection := r.db.Collection("product_versions")
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+	err = collection.FindOne(ctx, bson.M{"productId": productId, "version": bson.M{"$lt": version}}, opts).Decode(&productVersion)
+	if err != nil && err != mongo.ErrNoDocuments {
+		level.Error(r.logger).Log("error", "Failed to fetch previous product version", "productId", productId, "version", version, "err", err)
+	}
+	return productVersion, err
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"swallow-supplier/mongo/domain/reconciliation"
+	"swallow-supplier/mongo/domain/trip"
+	"swallow-supplier/mongo/domain/yanolja"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// dayRange bounds of the day for the timestamp and day strings the collections store
+func dayRange(date string) bson.M {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return bson.M{"$gte": date, "$lt": date + "~"}
+	}
+	return bson.M{"$gte": date, "$lt": day.AddDate(0, 0, 1).Format("2006-01-02")}
+}
+
+// GetReconciliationOrders orders created, changed or reconciled on the day with an orderId above
+// afterOrderId, in orderId order so the caller can walk the day in batches
+func (r *mongoRepository) GetReconciliationOrders(ctx context.Context, date string, afterOrderId int64, limit int64) (orders []yanolja.Model, err error) {
+	collection := r.db.Collection("orders")
+
+	filter := bson.M{
+		"orderId": bson.M{"$gt": afterOrderId},
+		"$or": bson.A{
+			bson.M{"createdAt": dayRange(date)},
+			bson.M{"updatedAt": dayRange(date)},
+			bson.M{"orderVariants.reconciliationByDate.reconciliationDate": dayRange(date)},
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "orderId", Value: 1}}).SetLimit(limit)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch orders for reconciliation", "date", date, "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	orders = make([]yanolja.Model, 0)
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetTripCallbacksByOrderIds callbacks sent to trip for the orders keyed by orderId, oldest first
+func (r *mongoRepository) GetTripCallbacksByOrderIds(ctx context.Context, orderIds []int64) (callbacks map[int64][]trip.CallBackDetail, err error) {
+	collection := r.db.Collection("callBack_detail")
+
+	// InsertCallBackDetail stores the request under tripCallBackInfo
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"tripCallBackInfo.channelreq.order.orderId": bson.M{"$in": orderIds}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: 1}}}},
+		{{Key: "$addFields", Value: bson.M{"channelCallBackInfo": "$tripCallBackInfo"}}},
+		{{Key: "$project", Value: bson.M{"tripCallBackInfo": 0}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch trip callbacks", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	callbacks = make(map[int64][]trip.CallBackDetail)
+	for cursor.Next(ctx) {
+		var detail trip.CallBackDetail
+		if err = cursor.Decode(&detail); err != nil {
+			level.Error(r.logger).Log("error", "Failed to decode trip callback", "err", err)
+			continue
+		}
+		orderId := detail.ChannelCallBackInfo.ChannelReq.Order.OrderId
+		callbacks[orderId] = append(callbacks[orderId], detail)
+	}
+	return callbacks, cursor.Err()
+}
+
+// GetTripCallbackOrderIds orders trip received a callback for on the day
+func (r *mongoRepository) GetTripCallbackOrderIds(ctx context.Context, date string) (orderIds []int64, err error) {
+	collection := r.db.Collection("callBack_detail")
+
+	values, err := collection.Distinct(ctx, "tripCallBackInfo.channelreq.order.orderId", bson.M{"createdAt": dayRange(date)})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch trip callback orders", "date", date, "err", err)
+		return nil, err
+	}
+
+	orderIds = make([]int64, 0, len(values))
+	for _, value := range values {
+		switch id := value.(type) {
+		case int64:
+			orderIds = append(orderIds, id)
+		case int32:
+			orderIds = append(orderIds, int64(id))
+		case float64:
+			orderIds = append(orderIds, int64(id))
+		}
+	}
+	return orderIds, nil
+}
+
+// GetExistingOrderIds the orderIds out of orderIds which have an order
+func (r *mongoRepository) GetExistingOrderIds(ctx context.Context, orderIds []int64) (existing map[int64]bool, err error) {
+	collection := r.db.Collection("orders")
+
+	opts := options.Find().SetProjection(bson.M{"orderId": 1})
+	cursor, err := collection.Find(ctx, bson.M{"orderId": bson.M{"$in": orderIds}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	existing = make(map[int64]bool, len(orderIds))
+	for cursor.Next(ctx) {
+		var order struct {
+			OrderId int64 `bson:"orderId"`
+		}
+		if err = cursor.Decode(&order); err == nil {
+			existing[order.OrderId] = true
+		}
+	}
+	return existing, cursor.Err()
+}
+
+// UpsertReconciliationReport store the report of the day, replacing an earlier run
+func (r *mongoRepository) UpsertReconciliationReport(ctx context.Context, report reconciliation.Report) error {
+	collection := r.db.Collection("reconciliation_reports")
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := collection.ReplaceOne(ctx, bson.M{"_id": report.Date}, report, opts); err != nil {
+		level.Error(r.logger).Log("error", "Failed to store reconciliation report", "date", report.Date, "err", err)
+		return err
+	}
+	return nil
+}
+
+// GetReconciliationReports latest reports without their mismatches
+func (r *mongoRepository) GetReconciliationReports(ctx context.Context, limit int64) (reports []reconciliation.Report, err error) {
+	collection := r.db.Collection("reconciliation_reports")
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"mismatches": 0})
+
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch reconciliation reports", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reports = make([]reconciliation.Report, 0)
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetReconciliationReport report of the day with the page of its mismatches of the type, every
+// type when empty. total is the number of mismatches of the type, limit zero returns all
+func (r *mongoRepository) GetReconciliationReport(ctx context.Context, date string, mismatchType string, skip int64, limit int64) (report reconciliation.Report, total int64, err error) {
+	collection := r.db.Collection("reconciliation_reports")
+
+	mismatches := interface{}("$mismatches")
+	if mismatchType != "" {
+		mismatches = bson.M{"$filter": bson.M{
+			"input": "$mismatches",
+			"as":    "mismatch",
+			"cond":  bson.M{"$eq": bson.A{"$$mismatch.type", mismatchType}},
+		}}
+	}
+
+	page := interface{}("$matched")
+	if limit > 0 {
+		page = bson.M{"$slice": bson.A{"$matched", skip, limit}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": date}}},
+		{{Key: "$addFields", Value: bson.M{"matched": bson.M{"$ifNull": bson.A{mismatches, bson.A{}}}}}},
+		{{Key: "$addFields", Value: bson.M{"matchedTotal": bson.M{"$size": "$matched"}, "mismatches": page}}},
+		{{Key: "$project", Value: bson.M{"matched": 0}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch reconciliation report", "date", date, "err", err)
+		return report, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err = cursor.Err(); err != nil {
+			return report, 0, err
+		}
+		return report, 0, mongo.ErrNoDocuments
+	}
+
+	var result struct {
+		reconciliation.Report `bson:",inline"`
+		MatchedTotal          int64 `bson:"matchedTotal"`
+	}
+	if err = cursor.Decode(&result); err != nil {
+		return report, 0, err
+	}
+	if result.Mismatches == nil {
+		result.Mismatches = make([]reconciliation.Mismatch, 0)
+	}
+
+	return result.Report, result.MatchedTotal, nil
+}
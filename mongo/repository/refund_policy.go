@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"swallow-supplier/mongo/domain/refund"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetRefundPolicies fetch the curated refund policies of the variants
+func (r *mongoRepository) GetRefundPolicies(ctx context.Context, variantIds []int64) (policies []refund.Policy, err error) {
+	collection := r.db.Collection("refund_policies")
+
+	cursor, err := collection.Find(ctx, bson.M{"variantId": bson.M{"$in": variantIds}})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch refund policies", "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	policies = make([]refund.Policy, 0)
+	if err = cursor.All(ctx, &policies); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// GetRefundPolicy fetch the curated refund policy of a variant
+func (r *mongoRepository) GetRefundPolicy(ctx context.Context, variantId int64) (policy refund.Policy, err error) {
+	collection := r.db.Collection("refund_policies")
+
+	err = collection.FindOne(ctx, bson.M{"variantId": variantId}).Decode(&policy)
+	return policy, err
+}
+
+// UpsertRefundPolicy store the curated refund policy of a variant, replacing the previous one
+func (r *mongoRepository) UpsertRefundPolicy(ctx context.Context, policy refund.Policy) error {
+	level.Info(r.logger).Log("repo-method", "UpsertRefundPolicy")
+
+	collection := r.db.Collection("refund_policies")
+
+	currentTime := time.Now().UTC().Format(time.RFC3339)
+	policy.Source = constant.RefundPolicyCurated
+	policy.UpdatedAt = currentTime
+
+	update := bson.M{
+		"$set": bson.M{
+			"variantId":                 policy.VariantId,
+			"productId":                 policy.ProductId,
+			"source":                    policy.Source,
+			"tiers":                     policy.Tiers,
+			"nonRefundableWindows":      policy.NonRefundableWindows,
+			"refundableAfterExpiration": policy.RefundableAfterExpiration,
+			"updatedAt":                 policy.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id":       primitive.NewObjectID().Hex(),
+			"createdAt": currentTime,
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"variantId": policy.VariantId}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to upsert refund policy", "variantId", policy.VariantId, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteRefundPolicy delete the curated refund policy of a variant
+func (r *mongoRepository) DeleteRefundPolicy(ctx context.Context, variantId int64) (deleted bool, err error) {
+	collection := r.db.Collection("refund_policies")
+
+	res, err := collection.DeleteOne(ctx, bson.M{"variantId": variantId})
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to delete refund policy", "variantId", variantId, "err", err)
+		return false, err
+	}
+
+	return res.DeletedCount > 0, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/pagination"
 
 	"github.com/go-kit/kit/log/level"
 	"go.mongodb.org/mongo-driver/bson"
@@ -73,30 +74,15 @@ func (r *mongoRepository) UpdateRegionsByRegionId(ctx context.Context, regiondId
 	return result.UpsertedID.(string), nil
 }
 
-// FindRegion fetch all the regions record
-func (r *mongoRepository) FindRegion(ctx context.Context) (records []yanolja.Region, err error) {
-	filter := bson.M{} // Empty filter to match all documents
-
-	collection := r.db.Collection("regions")
-	cursor, err := collection.Find(ctx, filter)
+// FindRegion one page of the stored regions with the total matching the query, a zero query
+// returns them all
+func (r *mongoRepository) FindRegion(ctx context.Context, q pagination.Query) (records []yanolja.Region, total int64, next string, err error) {
+	records, total, next, err = findPage[yanolja.Region](ctx, r.db.Collection("regions"), bson.M{}, q)
 	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	for cursor.Next(ctx) {
-		var region yanolja.Region
-		if err := cursor.Decode(&region); err != nil {
-			return nil, err
-		}
-		records = append(records, region)
-	}
-
-	if err := cursor.Err(); err != nil {
-		return nil, err
+		level.Error(r.logger).Log("error", "failed to fetch regions", "err", err)
+		return nil, 0, "", err
 	}
-
-	return records, nil
+	return records, total, next, nil
 }
 
 // FindRecordByRegionId fetch the record by regionId
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"swallow-supplier/mongo/domain/translation"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetTranslations stored translations into the language keyed by source hash, hashes without a
+// translation are left out
+func (r *mongoRepository) GetTranslations(ctx context.Context, targetLang string, sourceHashes []string) (translations map[string]string, err error) {
+	collection := r.db.Collection("translation_memory")
+
+	ids := make([]string, 0, len(sourceHashes))
+	for _, hash := range sourceHashes {
+		ids = append(ids, translation.MemoryId(hash, targetLang))
+	}
+
+	opts := options.Find().SetProjection(bson.M{"sourceHash": 1, "text": 1})
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		level.Error(r.logger).Log("error", "Failed to fetch translations", "lang", targetLang, "err", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	translations = make(map[string]string, len(sourceHashes))
+	for cursor.Next(ctx) {
+		var memory translation.Memory
+		if err = cursor.Decode(&memory); err != nil {
+			return nil, err
+		}
+		translations[memory.SourceHash] = memory.Text
+	}
+	return translations, cursor.Err()
+}
+
+// InsertTranslations store new translations, a translation already stored for the source hash
+// and language is kept
+func (r *mongoRepository) InsertTranslations(ctx context.Context, memories []translation.Memory) error {
+	if len(memories) == 0 {
+		return nil
+	}
+	collection := r.db.Collection("translation_memory")
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	operations := make([]mongo.WriteModel, 0, len(memories))
+	for _, memory := range memories {
+		memory.Id = translation.MemoryId(memory.SourceHash, memory.TargetLang)
+		memory.CreatedAt = now
+		operations = append(operations, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": memory.Id}).
+			SetUpdate(bson.M{"$setOnInsert": memory}).
+			SetUpsert(true))
+	}
+
+	if _, err := collection.BulkWrite(ctx, operations, options.BulkWrite().SetOrdered(false)); err != nil {
+		level.Error(r.logger).Log("error", "Failed to store translations", "err", err)
+		return err
+	}
+	return nil
+}
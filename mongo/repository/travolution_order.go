@@ -296,6 +296,30 @@ func (r *mongoRepository) GetOrderByOrderNumber(ctx context.Context, orderNumber
 	return order, nil
 }
 
+// GetOrderByReferenceNumber fetches the latest order by referenceNumber (partner order id) from travolution_order
+func (r *mongoRepository) GetOrderByReferenceNumber(ctx context.Context, referenceNumber string) (order domain.Order, err error) {
+	logger := log.With(r.logger, "repository", "GetOrderByReferenceNumber")
+
+	collection := r.db.Collection("travolution_orders")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"referenceNumber": referenceNumber}
+	opts := options.FindOne().SetSort(bson.M{"createdAt": -1})
+
+	err = collection.FindOne(ctx, filter, opts).Decode(&order)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Order{}, mongo.ErrNoDocuments
+		}
+		level.Error(logger).Log("msg", "failed to fetch order", "referenceNumber", referenceNumber, "err", err)
+		return domain.Order{}, err
+	}
+
+	return order, nil
+}
+
 // UpdateOrderStatusOnWebhook
 func (r *mongoRepository) UpdateOrderStatusOnWebhook(ctx context.Context, orderID string, status, eventType string) error {
 	level.Info(r.logger).Log("repository method", "UpdateOrderStatus", "orderID", orderID, "status", status, "eventType", eventType)
@@ -76,12 +76,17 @@ func (r *mongoRepository) UpsertTravolutionWebhook(ctx context.Context, payload
 		"data.dateAt":          payload.Data.DateAt,
 	}
 
+	set := bson.M{
+		"data":      payload.Data, // overwrite the latest payload data
+		"updatedAt": payload.UpdatedAt,
+	}
+	if payload.Verification != nil {
+		set["verification"] = payload.Verification
+	}
+
 	// Update document
 	update := bson.M{
-		"$set": bson.M{
-			"data":      payload.Data, // overwrite the latest payload data
-			"updatedAt": payload.UpdatedAt,
-		},
+		"$set": set,
 		"$setOnInsert": bson.M{
 			"_id":       payload.ID,
 			"createdAt": now,
@@ -113,6 +113,18 @@ func (r *mongoRepository) InsertPaymentRequestFromTrip(ctx context.Context, paym
 func (r *mongoRepository) InsertFullCancelOrderRequestFromTrip(ctx context.Context, cancelRequest trip.CancellationRequest) (err error) {
 	level.Info(r.logger).Log("repository method", "InsertFullCancelOrder")
 
+	return r.insertCancelOrderRequest(ctx, cancelRequest, constant.TRIPFULLORDERCANCELREQUEST)
+}
+
+// InsertPartialCancelOrderRequestFromTrip to insert partial cancel order request from trip
+func (r *mongoRepository) InsertPartialCancelOrderRequestFromTrip(ctx context.Context, cancelRequest trip.CancellationRequest) (err error) {
+	level.Info(r.logger).Log("repository method", "InsertPartialCancelOrder")
+
+	return r.insertCancelOrderRequest(ctx, cancelRequest, constant.TRIPPARTIALCANCELREQUEST)
+}
+
+// insertCancelOrderRequest cancel requests of both categories share the collection
+func (r *mongoRepository) insertCancelOrderRequest(ctx context.Context, cancelRequest trip.CancellationRequest, category string) (err error) {
 	collection := r.db.Collection("trip_full_cancel_request")
 
 	currentTime := time.Now().UTC().Format(time.RFC3339)
@@ -124,14 +136,14 @@ func (r *mongoRepository) InsertFullCancelOrderRequestFromTrip(ctx context.Conte
 		SupplierOrderId: cancelRequest.SupplierOrderID,
 		ConfirmType:     cancelRequest.ConfirmType,
 		Items:           cancelRequest.Items,
-		RequestCategory: constant.TRIPFULLORDERCANCELREQUEST,
+		RequestCategory: category,
 		CreatedAt:       currentTime,
 		UpdatedAt:       currentTime,
 	}
 
 	_, err = collection.InsertOne(ctx, doc)
 	if err != nil {
-		return fmt.Errorf("failed to insert full cancel order: %w", err)
+		return fmt.Errorf("failed to insert %s order: %w", category, err)
 	}
 
 	return nil
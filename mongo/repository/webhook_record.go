@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"swallow-supplier/mongo/domain/webhook"
+
+	"github.com/go-kit/kit/log/level"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InsertWebhookRecord store a received supplier callback with its signature verification
+func (r *mongoRepository) InsertWebhookRecord(ctx context.Context, record webhook.Record) (id string, err error) {
+	collection := r.db.Collection("webhook_records")
+
+	record.Id = primitive.NewObjectID().Hex()
+	record.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	if _, err = collection.InsertOne(ctx, record); err != nil {
+		level.Error(r.logger).Log("error", "Failed to insert webhook record", "path", record.Path, "err", err)
+		return "", err
+	}
+
+	return record.Id, nil
+}
@@ -0,0 +1,22 @@
+package common
+
+import (
+	"time"
+
+	"swallow-supplier/mongo/domain/apiclient"
+)
+
+// ApiClientRequest issue an api client, or rotate the key of the client Id with an optional new expiry
+type ApiClientRequest struct {
+	Id          string     `json:"-"`
+	Name        string     `json:"name"`
+	ChannelCode string     `json:"channelCode"`
+	Scopes      []int      `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+// ApiClientKey api client with its key, the key is only returned when issued or rotated
+type ApiClientKey struct {
+	Client apiclient.ApiClient `json:"client"`
+	Key    string              `json:"key"`
+}
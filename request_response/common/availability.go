@@ -0,0 +1,10 @@
+package common
+
+// AvailabilityRequest variant, visit date and round whose availability is asked for, Quantity is
+// the number of units to book
+type AvailabilityRequest struct {
+	VariantId int64  `json:"variantId"`
+	Date      string `json:"date"`
+	Time      string `json:"time"`
+	Quantity  int32  `json:"quantity"`
+}
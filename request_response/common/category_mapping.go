@@ -0,0 +1,31 @@
+package common
+
+import (
+	"swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/categorymap"
+)
+
+// CategoryMappingRequest mapping of a yanolja category, Code and Level come from the path,
+// Level and TripCategory filter the list and Format picks the export file
+type CategoryMappingRequest struct {
+	Code         int                     `json:"code"`
+	Level        int                     `json:"level"`
+	TripCategory string                  `json:"tripCategory"`
+	Format       string                  `json:"format"`
+	Mapping      yanolja.CategoryMapping `json:"mapping"`
+}
+
+// CategoryMappingUploadRequest uploaded mapping sheet, DryRun only returns the diff
+type CategoryMappingUploadRequest struct {
+	FileName string `json:"fileName"`
+	Content  []byte `json:"-"`
+	DryRun   bool   `json:"dryRun"`
+}
+
+// CategoryMappingUploadResult diff of the upload and, when applied, the upsert counts
+type CategoryMappingUploadResult struct {
+	DryRun  bool             `json:"dryRun"`
+	Applied bool             `json:"applied"`
+	Diff    categorymap.Diff `json:"diff"`
+	Counts  map[string]int64 `json:"counts,omitempty"`
+}
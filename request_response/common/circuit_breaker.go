@@ -0,0 +1,7 @@
+package common
+
+// CircuitBreakerRequest force the breaker of a service open or closed, AUTO releases it
+type CircuitBreakerRequest struct {
+	Service string `json:"service"`
+	Mode    string `json:"mode"`
+}
@@ -0,0 +1,24 @@
+package common
+
+import "swallow-supplier/mongo/domain/job"
+
+// JobRequest job Name of the path with the new schedule, Paused is set by the pause and resume routes
+type JobRequest struct {
+	Name     string `json:"-"`
+	Schedule string `json:"schedule"`
+	Paused   bool   `json:"-"`
+	Limit    int64  `json:"-"`
+}
+
+// JobStatus registered job with its effective settings and its last run
+type JobStatus struct {
+	Name               string   `json:"name"`
+	Description        string   `json:"description"`
+	Schedule           string   `json:"schedule"`
+	DefaultSchedule    string   `json:"defaultSchedule"`
+	Timeout            string   `json:"timeout"`
+	Concurrency        string   `json:"concurrency"`
+	Paused             bool     `json:"paused"`
+	TriggerRequestedAt string   `json:"triggerRequestedAt,omitempty"`
+	LastRun            *job.Run `json:"lastRun,omitempty"`
+}
@@ -0,0 +1,62 @@
+package common
+
+import "swallow-supplier/utils/pagination"
+
+// lists sortable and filterable fields of the list endpoints, the keys are unique fields cursor
+// pages follow
+var (
+	// ProductViewList products for the channels from the productview collection
+	ProductViewList = pagination.List{
+		Key:  "productId",
+		Sort: []string{"productName", "productStatusCode", "createdAt", "updatedAt"},
+		Filter: map[string]string{
+			"productId":         pagination.FilterNumber,
+			"productName":       pagination.FilterText,
+			"productStatusCode": pagination.FilterExact,
+			"productTypeCode":   pagination.FilterExact,
+			"supplierName":      pagination.FilterExact,
+		},
+	}
+
+	// CategoryList stored yanolja categories
+	CategoryList = pagination.List{
+		Key:  "categoryId",
+		Sort: []string{"categoryCode", "categoryLevel", "categoryName"},
+		Filter: map[string]string{
+			"categoryCode":       pagination.FilterExact,
+			"categoryLevel":      pagination.FilterNumber,
+			"categoryName":       pagination.FilterText,
+			"categoryStatusCode": pagination.FilterExact,
+		},
+	}
+
+	// RegionList stored yanolja regions
+	RegionList = pagination.List{
+		Key:  "regionId",
+		Sort: []string{"regionCode", "regionLevel", "regionName"},
+		Filter: map[string]string{
+			"regionCode":     pagination.FilterExact,
+			"regionLevel":    pagination.FilterNumber,
+			"regionName":     pagination.FilterText,
+			"parentRegionId": pagination.FilterNumber,
+			"isUsed":         pagination.FilterBool,
+		},
+	}
+
+	// EverlandOrderList orders of a customer on a channel
+	EverlandOrderList = pagination.List{
+		Key:  "orderId",
+		Sort: []string{"orderStatusCode", "createdAt", "updatedAt"},
+		Filter: map[string]string{
+			"orderStatusCode": pagination.FilterExact,
+			"partnerOrderId":  pagination.FilterExact,
+			"orderExpired":    pagination.FilterBool,
+		},
+	}
+
+	// RedisKeyList keys of the cache, pattern is the redis key pattern
+	RedisKeyList = pagination.List{
+		Key:    "key",
+		Filter: map[string]string{"pattern": pagination.FilterExact},
+	}
+)
@@ -0,0 +1,24 @@
+package common
+
+// NotificationOutboxRequest list notifications of the outbox
+type NotificationOutboxRequest struct {
+	Status   string `json:"status"`
+	Page     int64  `json:"page"`
+	PageSize int64  `json:"pageSize"`
+}
+
+// NotificationOutboxList paged notifications of the outbox
+type NotificationOutboxList struct {
+	Total    int64       `json:"total"`
+	Page     int64       `json:"page"`
+	PageSize int64       `json:"pageSize"`
+	Items    interface{} `json:"items"`
+}
+
+// NotificationOutboxRun summary of one outbox drain
+type NotificationOutboxRun struct {
+	Processed int `json:"processed"`
+	Sent      int `json:"sent"`
+	Retried   int `json:"retried"`
+	Dead      int `json:"dead"`
+}
@@ -0,0 +1,10 @@
+package common
+
+// OdooSyncRequest Kind is set by the route. Since is the cursor the pull reads after, the
+// acknowledged cursor when not given. Cursor is the sequence acknowledged by odoo
+type OdooSyncRequest struct {
+	Kind   string `json:"-"`
+	Since  *int64 `json:"-"`
+	Limit  int64  `json:"-"`
+	Cursor *int64 `json:"cursor"`
+}
@@ -0,0 +1,8 @@
+package common
+
+// PricingRuleRequest filter of the pricing rule listing
+type PricingRuleRequest struct {
+	Supplier    string `json:"supplier"`
+	ProductId   int64  `json:"productId"`
+	ChannelCode string `json:"channelCode"`
+}
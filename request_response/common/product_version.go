@@ -0,0 +1,9 @@
+package common
+
+// ProductVersionRequest product and version from the path, From and To are the versions to diff
+type ProductVersionRequest struct {
+	ProductId int64 `json:"productId"`
+	Version   int32 `json:"version"`
+	From      int32 `json:"from"`
+	To        int32 `json:"to"`
+}
@@ -0,0 +1,22 @@
+package common
+
+import "swallow-supplier/mongo/domain/reconciliation"
+
+// ReconciliationReportRequest day of the report, Type narrows its mismatches
+type ReconciliationReportRequest struct {
+	Date     string `json:"date"`
+	Type     string `json:"type"`
+	Page     int64  `json:"page"`
+	PageSize int64  `json:"pageSize"`
+	Limit    int64  `json:"limit"`
+}
+
+// ReconciliationReportPage report with one page of its mismatches, MismatchTotal counts the
+// mismatches of the requested type
+type ReconciliationReportPage struct {
+	reconciliation.Report
+	Type          string `json:"type,omitempty"`
+	MismatchTotal int64  `json:"mismatchTotal"`
+	Page          int64  `json:"page"`
+	PageSize      int64  `json:"pageSize"`
+}
@@ -0,0 +1,8 @@
+package common
+
+// RefundPolicyRequest variant whose refund policy is looked up, the product is needed to parse the
+// policy of variants without a curated one
+type RefundPolicyRequest struct {
+	VariantId int64 `json:"variantId"`
+	ProductId int64 `json:"productId"`
+}
@@ -12,3 +12,10 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 	Status  int    `json:"status"`
 }
+
+// File body of a download, written as is with its content type instead of the json response
+type File struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
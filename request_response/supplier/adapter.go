@@ -0,0 +1,79 @@
+package supplier
+
+import (
+	"swallow-supplier/request_response/travolution"
+	"swallow-supplier/request_response/yanolja"
+)
+
+// Response supplier neutral response returned by every SupplierAdapter call, it keeps every field
+// of the supplier response. Page and Collection are only set by yanolja, Contents only by travolution
+type Response struct {
+	Supplier    string               `json:"supplier"`
+	Code        string               `json:"code"`
+	TraceID     string               `json:"traceId,omitempty"`
+	Body        interface{}          `json:"body,omitempty"`
+	Page        yanolja.NumberOfPage `json:"page"`
+	Collection  bool                 `json:"collection"`
+	ContentType *string              `json:"contentType"`
+	Contents    travolution.Content  `json:"contents"`
+}
+
+// ProductQuery lookup of products, ProductId empty means list
+type ProductQuery struct {
+	ProductId  string `json:"productId,omitempty"`
+	PageNumber int    `json:"pageNumber,omitempty"`
+	PageSize   int    `json:"pageSize,omitempty"`
+	StatusCode string `json:"statusCode,omitempty"`
+	Lang       string `json:"lang,omitempty"`
+}
+
+// InventoryQuery lookup of inventory for a product or a single variant/option
+type InventoryQuery struct {
+	ProductId string `json:"productId,omitempty"`
+	VariantId string `json:"variantId,omitempty"` // yanolja variantId or travolution optionUid
+	DateStart string `json:"dateStart,omitempty"`
+	DateEnd   string `json:"dateEnd,omitempty"`
+	Time      string `json:"time,omitempty"`
+}
+
+// PrepareRequest holds the supplier specific booking payload, only the one matching the adapter is read
+type PrepareRequest struct {
+	PartnerOrderId string                   `json:"partnerOrderId"`
+	Yanolja        yanolja.WaitingForOrder  `json:"yanolja,omitempty"`
+	Travolution    travolution.OrderRequest `json:"travolution,omitempty"`
+	BookingType    string                   `json:"bookingType,omitempty"` // travolution product type TK, BK, PAS, PKG
+}
+
+// ConfirmRequest confirmation of a prepared order
+type ConfirmRequest struct {
+	OrderId        string `json:"orderId"`
+	PartnerOrderId string `json:"partnerOrderId"`
+}
+
+// CancelType kind of cancellation requested from the supplier
+type CancelType string
+
+const (
+	// CancelFull regular full cancellation of a confirmed order
+	CancelFull CancelType = "FULL"
+	// CancelTimeout cancellation of a pre order after a request timeout
+	CancelTimeout CancelType = "TIMEOUT"
+	// CancelForced forced cancellation requested by the channel
+	CancelForced CancelType = "FORCED"
+	// CancelPartial cancellation of some variants of a confirmed order
+	CancelPartial CancelType = "PARTIAL"
+)
+
+// CancelRequest cancellation of an order, OrderVariantIds are the variants of a partial one
+type CancelRequest struct {
+	OrderId         string     `json:"orderId"`
+	PartnerOrderId  string     `json:"partnerOrderId"`
+	Type            CancelType `json:"type"`
+	OrderVariantIds []int64    `json:"orderVariantIds,omitempty"`
+}
+
+// StatusRequest lookup of the current order state at the supplier
+type StatusRequest struct {
+	OrderId        string `json:"orderId"`
+	PartnerOrderId string `json:"partnerOrderId"`
+}
@@ -1,5 +1,7 @@
 package trip
 
+// SwallowRequest request envelope of trip, in encrypted mode body arrives as ciphertext and
+// is replaced by the plain json once the envelope is opened
 type SwallowRequest struct {
 	Header        SwallowHeader `json:"header" binding:"required"`
 	DecryptedBody string        `json:"body" binding:"required"`
@@ -7,4 +9,6 @@ type SwallowRequest struct {
 type SwallowHeader struct {
 	ServiceName string `json:"serviceName" binding:"required"`
 	RequestTime string `json:"requestTime" binding:"required"`
+	// Sign hex HMAC-SHA256 of serviceName, requestTime and the encrypted body, empty in plaintext mode
+	Sign string `json:"sign,omitempty"`
 }
@@ -1,6 +1,9 @@
 package yanolja
 
+import "swallow-supplier/utils/pagination"
+
 type EverlandGetRequest struct {
-	ChannelCode   string `json:"ChannelCode" binding:"required" validate:"required"`
-	CustomerEmail string `json:"customerEmail" validate:"required"`
+	ChannelCode   string           `json:"ChannelCode" binding:"required" validate:"required"`
+	CustomerEmail string           `json:"customerEmail" validate:"required"`
+	Query         pagination.Query `json:"-"` // page, sort and filters of the orders
 }
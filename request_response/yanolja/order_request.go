@@ -1,5 +1,7 @@
 package yanolja
 
+import "swallow-supplier/mongo/domain/money"
+
 // Blocking of order in advance
 type WaitingForOrder struct {
 	PartnerOrderID                string         `json:"partnerOrderId" binding:"required" validate:"required"`
@@ -28,6 +30,10 @@ type VariantInfo struct {
 	Currency         string  `json:"currency" binding:"required" validate:"required"`
 	PartnerSalePrice float32 `json:"partnerSalePrice" binding:"required" validate:"required"`
 	CostPrice        float32 `json:"costPrice" binding:"required" validate:"required"`
+
+	// channel prices before settlement, stored on the order only
+	SaleAmount *money.Conversion `json:"-"`
+	CostAmount *money.Conversion `json:"-"`
 }
 
 // pre order confirmation request
@@ -48,7 +54,6 @@ type OrderList struct {
 	OrderId []int64 `json:"orderId" binding:"required" validate:"required"`
 }
 
-//
 type OrderReconcileReq struct {
 	ReconciliationDate       string `json:"reconciliationDate" binding:"required" validate:"required,datetime=2006-01-02"`
 	ReconcileOrderStatusCode string `json:"reconcileOrderStatusCode" binding:"required" validate:"required,oneof=UNKNOWN CREATED CANCELING CANCELED"`
@@ -0,0 +1,101 @@
+package cronjob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"swallow-supplier/config"
+	svc "swallow-supplier/iface"
+	"swallow-supplier/mongo/domain/money"
+	"swallow-supplier/utils/fx"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// fxRateFile layout of the rate file, asOf defaults to the day of the import
+type fxRateFile struct {
+	Source string `json:"source"`
+	AsOf   string `json:"asOf"`
+	Rates  []struct {
+		Base  string  `json:"base"`
+		Quote string  `json:"quote"`
+		Rate  float64 `json:"rate"`
+	} `json:"rates"`
+}
+
+// fxRateStubSource source of the placeholder rate file shipped for local runs
+const fxRateStubSource = "stub"
+
+// isLocal whether the service runs on a developer machine
+func isLocal(appEnv string) bool {
+	return appEnv == "" || appEnv == "LOCAL"
+}
+
+// ImportFxRates load the exchange rates of the configured file into the fx_rates collection
+func ImportFxRates(ctx context.Context, mrepo svc.MongoRepository, logger log.Logger) (err error) {
+	path := config.Instance().FxRateFilePath
+	if path == "" {
+		level.Info(logger).Log("msg", "fx rate import skipped, no FX_RATE_FILE_PATH configured")
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error reading fx rate file", "path", path, "error", err)
+		return fmt.Errorf("error reading fx rate file: %w", err)
+	}
+
+	var file fxRateFile
+	if err = json.Unmarshal(content, &file); err != nil {
+		level.Error(logger).Log("msg", "Error parsing fx rate file", "path", path, "error", err)
+		return fmt.Errorf("error parsing fx rate file: %w", err)
+	}
+
+	asOf := file.AsOf
+	if asOf == "" {
+		asOf = time.Now().UTC().Format(fx.DateLayout)
+	} else if _, err = time.Parse(fx.DateLayout, asOf); err != nil {
+		level.Error(logger).Log("msg", "Invalid asOf in fx rate file", "asOf", asOf, "error", err)
+		return fmt.Errorf("invalid asOf %q in fx rate file: %w", asOf, err)
+	}
+
+	// the stub file only carries placeholder rates for local runs, settlements must never use them
+	if strings.EqualFold(file.Source, fxRateStubSource) && !isLocal(config.Instance().AppEnv) {
+		level.Error(logger).Log("msg", "refusing to import stub fx rate file", "path", path, "appEnv", config.Instance().AppEnv)
+		return fmt.Errorf("fx rate file %s is a stub, it is only imported locally", path)
+	}
+
+	source := file.Source
+	if source == "" {
+		source = path
+	}
+
+	rates := make([]money.FxRate, 0, len(file.Rates))
+	for _, rate := range file.Rates {
+		if rate.Base == "" || rate.Quote == "" || rate.Rate <= 0 {
+			level.Error(logger).Log("msg", "skipping invalid fx rate", "base", rate.Base, "quote", rate.Quote, "rate", rate.Rate)
+			continue
+		}
+		rates = append(rates, money.FxRate{
+			Base:   strings.ToUpper(rate.Base),
+			Quote:  strings.ToUpper(rate.Quote),
+			Rate:   rate.Rate,
+			AsOf:   asOf,
+			Source: source,
+		})
+	}
+
+	upserted, err := mrepo.UpsertFxRates(ctx, rates)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error in UpsertFxRates", "error", err)
+		return fmt.Errorf("error in UpsertFxRates: %w", err)
+	}
+
+	level.Info(logger).Log("msg", "fx rates imported", "asOf", asOf, "rates", len(rates), "upserted", upserted)
+	return nil
+}
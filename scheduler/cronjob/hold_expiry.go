@@ -0,0 +1,22 @@
+package cronjob
+
+import (
+	"context"
+	svc "swallow-supplier/iface"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// ExpireHeldOrders cancel the pre orders trip left unpaid past the hold window of their supplier
+func ExpireHeldOrders(ctx context.Context, s svc.Service, logger log.Logger) (int, error) {
+	resp, err := s.ExpireHeldOrders(ctx)
+	if err != nil {
+		level.Error(logger).Log("error", "hold expiry failed", "err", err)
+		return 0, err
+	}
+
+	counts, _ := resp.Body.(map[string]int)
+	return counts[constant.HoldExpiryOutcomeExpired], nil
+}
@@ -0,0 +1,31 @@
+package cronjob
+
+import (
+	"context"
+	svc "swallow-supplier/iface"
+	"sync/atomic"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// outboxRunning skips a tick while the previous drain is still delivering
+var outboxRunning atomic.Bool
+
+// DrainNotificationOutbox deliver due trip / pdf voucher notifications, failed ones are
+// rescheduled with exponential backoff and moved to dead letter after the last attempt
+func DrainNotificationOutbox(ctx context.Context, s svc.Service, logger log.Logger) error {
+	if !outboxRunning.CompareAndSwap(false, true) {
+		return nil
+	}
+	defer outboxRunning.Store(false)
+
+	resp, err := s.ProcessNotificationOutbox(ctx)
+	if err != nil {
+		level.Error(logger).Log("error", "notification outbox drain failed", "err", err)
+		return err
+	}
+
+	level.Info(logger).Log("msg", "notification outbox drained", "result", resp.Body)
+	return nil
+}
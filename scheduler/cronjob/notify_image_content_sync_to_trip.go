@@ -2,7 +2,9 @@ package cronjob
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"swallow-supplier/config"
 	"time"
@@ -11,8 +13,8 @@ import (
 	"github.com/go-kit/log/level"
 )
 
-// notifyTripAPI sends a request to /localhost/trip and retries until success.
-func notifyTripAPI(logger log.Logger) {
+// NotifyTripAPI sends a request to /localhost/trip and retries until success.
+func NotifyTripAPI(ctx context.Context, logger log.Logger) error {
 	cf := config.Instance()
 	tripURL := cf.TripSyncUrl
 	payload := map[string]string{"message": "MonitorProductUpdates completed successfully"}
@@ -24,10 +26,10 @@ func notifyTripAPI(logger log.Logger) {
 	maxRetries := 5 // Maximum number of retries
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		req, err := http.NewRequest("POST", tripURL, bytes.NewBuffer(jsonPayload))
+		req, err := http.NewRequestWithContext(ctx, "POST", tripURL, bytes.NewBuffer(jsonPayload))
 		if err != nil {
 			level.Error(logger).Log("error", "Failed to create request for trip API", "err", err)
-			return
+			return err
 		}
 		req.Header.Set("Content-Type", "application/json")
 
@@ -38,17 +40,22 @@ func notifyTripAPI(logger log.Logger) {
 			defer resp.Body.Close()
 			if resp.StatusCode == http.StatusOK {
 				level.Info(logger).Log("msg", "Successfully notified trip API", "attempt", attempt)
-				return // Exit function on success
+				return nil // Exit function on success
 			}
 			level.Error(logger).Log("msg", "Trip API responded with error", "status", resp.StatusCode, "attempt", attempt)
 		}
 
 		// If not successful, retry after a delay
-		time.Sleep(retryInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryInterval):
+		}
 		if retryInterval < maxRetryInterval {
 			retryInterval *= 2 // Exponential backoff
 		}
 	}
 
 	level.Error(logger).Log("msg", "Failed to notify trip API after multiple attempts")
+	return errors.New("failed to notify trip API after multiple attempts")
 }
@@ -0,0 +1,37 @@
+package cronjob
+
+import (
+	"context"
+	"swallow-supplier/config"
+	svc "swallow-supplier/iface"
+	"sync/atomic"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// odooPushRunning skips a tick while the previous push is still writing to odoo
+var odooPushRunning atomic.Bool
+
+// PushOdooChanges push the changed orders and products to odoo, nothing happens while no odoo
+// is configured
+func PushOdooChanges(ctx context.Context, s svc.Service, logger log.Logger) (int, error) {
+	if config.Instance().OdooUrl == "" || !odooPushRunning.CompareAndSwap(false, true) {
+		return 0, nil
+	}
+	defer odooPushRunning.Store(false)
+
+	resp, err := s.PushOdooChanges(ctx)
+	if err != nil {
+		level.Error(logger).Log("error", "odoo push failed", "err", err)
+		return 0, err
+	}
+
+	pushed := 0
+	if counts, ok := resp.Body.(map[string]int); ok {
+		for _, count := range counts {
+			pushed += count
+		}
+	}
+	return pushed, nil
+}
@@ -2,93 +2,233 @@ package cronjob
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	svc "swallow-supplier/iface"
 	"swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/log/level"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// MonitorProductUpdates polls the product collection for changes and updates/inserts into ProductView.
-func MonitorProductUpdates(ctx context.Context, mrepo svc.MongoRepository, logger log.Logger) (err error) {
-	db := mrepo.GetMongoDb(ctx)
-	productCollection := db.Collection("products")
+// productChangeStream name the resume token of the products change stream is stored under
+const productChangeStream = "products"
 
-	// --- Product_View
-	cursor, err := productCollection.Find(ctx, bson.M{"viewScheduleStatus": false})
+// pendingProductFilter products still waiting for their product view or image processing
+var pendingProductFilter = bson.M{"$or": bson.A{
+	bson.M{"viewScheduleStatus": false},
+	bson.M{"imageScheduleStatus": false},
+}}
+
+// WatchProductUpdates processes products as they change through a change stream on the product
+// collection. The resume token is persisted after every processed batch so a restart, or another
+// replica taking over, continues where the stream stopped. When the token is too old to resume
+// from it is dropped and MonitorProductUpdates sweeps whatever was missed
+func WatchProductUpdates(ctx context.Context, mrepo svc.MongoRepository, logger log.Logger) (err error) {
+	logger = log.With(logger, "method", "WatchProductUpdates")
+
+	err = watchProductUpdates(ctx, mrepo, logger)
+	if err == nil || ctx.Err() != nil || !resumeTokenLost(err) {
+		return err
+	}
+
+	level.Warn(logger).Log("msg", "product change stream can not resume, sweeping instead", "err", err)
+	if err = mrepo.SaveResumeToken(ctx, productChangeStream, nil); err != nil {
+		return err
+	}
+	_, err = MonitorProductUpdates(ctx, mrepo, logger)
+	return err
+}
+
+func watchProductUpdates(ctx context.Context, mrepo svc.MongoRepository, logger log.Logger) error {
+	token, err := mrepo.GetResumeToken(ctx, productChangeStream)
 	if err != nil {
-		level.Error(logger).Log("msg", "Error querying product for viewSchedule", "err", err)
-		return fmt.Errorf("failed to execute find query for viewSchedule: %w", err)
+		return fmt.Errorf("failed to load product resume token: %w", err)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": bson.A{"insert", "update", "replace"}},
+		"$or": bson.A{
+			bson.M{"fullDocument.viewScheduleStatus": false},
+			bson.M{"fullDocument.imageScheduleStatus": false},
+		},
+	}}}}
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetMaxAwaitTime(constant.ProductChangeWindowMillis * time.Millisecond)
+	if token != nil {
+		opts.SetResumeAfter(token)
 	}
-	defer cursor.Close(ctx)
 
-	var products []yanolja.Product
-	err = cursor.All(ctx, &products)
+	stream, err := mrepo.GetMongoDb(ctx).Collection("products").Watch(ctx, pipeline, opts)
 	if err != nil {
-		level.Error(logger).Log("msg", "Error decoding product documents", "err", err)
-		// defer will close cursor
+		return err
 	}
-	// Close current cursor explicitly (optional; defer will handle it)
-	cursor.Close(ctx)
+	defer stream.Close(context.Background())
 
-	if len(products) != 0 {
-		level.Info(logger).Log("info", "UpdateOrInsertProductView proceeds")
-		if err = mrepo.UpdateOrInsertProductView(ctx, products); err != nil {
-			level.Error(logger).Log("msg", "Error updating ProductView", "err", err)
-		} else {
-			level.Info(logger).Log("msg", "Successfully processed productview")
+	level.Info(logger).Log("msg", "watching product changes", "resumed", token != nil)
+	for {
+		products, err := nextProductBatch(ctx, stream)
+		if err != nil {
+			return err
+		}
+
+		if err = processProductUpdates(ctx, mrepo, logger, products); err != nil {
+			// the token is not saved, the batch is delivered again after the restart
+			return err
+		}
+
+		if err = mrepo.SaveResumeToken(ctx, productChangeStream, stream.ResumeToken()); err != nil {
+			level.Error(logger).Log("msg", "failed to save product resume token", "err", err)
 		}
 	}
+}
 
-	// --- productImage
-	cursor, err = productCollection.Find(ctx, bson.M{"imageScheduleStatus": false})
+// nextProductBatch waits for the next change and adds the changes already available behind it,
+// a product changed several times is processed once with its latest document
+func nextProductBatch(ctx context.Context, stream *mongo.ChangeStream) ([]yanolja.Product, error) {
+	if !stream.Next(ctx) {
+		if err := stream.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ctx.Err()
+	}
+
+	latest := make(map[int64]int)
+	products := make([]yanolja.Product, 0)
+	add := func() error {
+		var event struct {
+			FullDocument *yanolja.Product `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			return err
+		}
+		if event.FullDocument == nil {
+			return nil
+		}
+		if i, ok := latest[event.FullDocument.ProductID]; ok {
+			products[i] = *event.FullDocument
+			return nil
+		}
+		latest[event.FullDocument.ProductID] = len(products)
+		products = append(products, *event.FullDocument)
+		return nil
+	}
+
+	if err := add(); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(constant.ProductChangeWindowMillis * time.Millisecond)
+	for len(products) < constant.ProductBatchSize && time.Now().Before(deadline) && stream.TryNext(ctx) {
+		if err := add(); err != nil {
+			return nil, err
+		}
+	}
+
+	return products, stream.Err()
+}
+
+// resumeTokenLost the oplog no longer holds the events after the token
+func resumeTokenLost(err error) bool {
+	var se mongo.ServerError
+	if errors.As(err, &se) {
+		// ChangeStreamHistoryLost, ChangeStreamFatalError
+		return se.HasErrorCode(286) || se.HasErrorCode(280)
+	}
+	return false
+}
+
+// MonitorProductUpdates fallback sweep for changes the stream missed, it walks the products still
+// waiting for their product view or images in batches instead of loading them all at once
+func MonitorProductUpdates(ctx context.Context, mrepo svc.MongoRepository, logger log.Logger) (swept int, err error) {
+	productCollection := mrepo.GetMongoDb(ctx).Collection("products")
+
+	cursor, err := productCollection.Find(ctx, pendingProductFilter, options.Find().SetBatchSize(constant.ProductBatchSize))
 	if err != nil {
-		level.Error(logger).Log("msg", "Error querying product for imageSchedule", "err", err)
-		return fmt.Errorf("failed to execute find query for imageSchedule: %w", err)
+		level.Error(logger).Log("msg", "Error querying pending products", "err", err)
+		return 0, fmt.Errorf("failed to execute find query for pending products: %w", err)
 	}
 	defer cursor.Close(ctx)
 
-	products = nil
-	err = cursor.All(ctx, &products)
-	if err != nil {
-		level.Error(logger).Log("msg", "Error decoding product documents", "err", err)
-		cursor.Close(ctx)
+	products := make([]yanolja.Product, 0, constant.ProductBatchSize)
+	for cursor.Next(ctx) {
+		var product yanolja.Product
+		if err = cursor.Decode(&product); err != nil {
+			level.Error(logger).Log("msg", "Error decoding product document", "err", err)
+			continue
+		}
+		products = append(products, product)
+
+		if len(products) == constant.ProductBatchSize {
+			if err = processProductUpdates(ctx, mrepo, logger, products); err != nil {
+				return swept, err
+			}
+			swept += len(products)
+			products = products[:0]
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return swept, err
 	}
-	cursor.Close(ctx)
 
 	if len(products) != 0 {
-		level.Info(logger).Log("info", "ProductImagesForProcessing proceeds")
-		if err = ProductImagesForProcessing(ctx, logger, mrepo, products); err != nil {
-			level.Error(logger).Log("error", "Failed to schedule ProductImagesForProcessing job", "err", err)
-			return err
+		if err = processProductUpdates(ctx, mrepo, logger, products); err != nil {
+			return swept, err
+		}
+		swept += len(products)
+	}
+	if swept != 0 {
+		level.Info(logger).Log("msg", "product sweep processed missed changes", "count", swept)
+	}
+
+	return swept, nil
+}
+
+// processProductUpdates updates the product view and queues the images of the products still
+// needing them, the content sync to trip follows once new images were queued
+func processProductUpdates(ctx context.Context, mrepo svc.MongoRepository, logger log.Logger, products []yanolja.Product) (err error) {
+	views := make([]yanolja.Product, 0)
+	images := make([]yanolja.Product, 0)
+	contentPending := false
+	for _, product := range products {
+		if !product.ViewScheduleStatus {
+			views = append(views, product)
+		}
+		if !product.ImageScheduleStatus {
+			images = append(images, product)
+			contentPending = contentPending || !product.ContentScheduleStatus
 		}
+	}
 
-		// --- contentSync to trip (requires image + productview updated first)
-		cursor, err = productCollection.Find(ctx, bson.M{"contentScheduleStatus": false})
-		if err != nil {
-			level.Error(logger).Log("msg", "Error querying product for contentSchedule", "err", err)
-			return fmt.Errorf("failed to execute find query for contentSchedule: %w", err)
+	if len(views) != 0 {
+		level.Info(logger).Log("info", "UpdateOrInsertProductView proceeds", "count", len(views))
+		if err = mrepo.UpdateOrInsertProductView(ctx, views); err != nil {
+			level.Error(logger).Log("msg", "Error updating ProductView", "err", err)
+		} else {
+			level.Info(logger).Log("msg", "Successfully processed productview")
 		}
-		defer cursor.Close(ctx)
+	}
 
-		products = nil
-		err = cursor.All(ctx, &products)
-		if err != nil {
-			level.Error(logger).Log("msg", "Error decoding product documents", "err", err)
-			cursor.Close(ctx)
-			return err // <--- FIX: explicit return of the outer err
+	if len(images) != 0 {
+		level.Info(logger).Log("info", "ProductImagesForProcessing proceeds", "count", len(images))
+		if err = ProductImagesForProcessing(ctx, logger, mrepo, images); err != nil {
+			level.Error(logger).Log("error", "Failed to schedule ProductImagesForProcessing job", "err", err)
+			return err
 		}
-		cursor.Close(ctx)
+	}
 
-		if len(products) != 0 {
-			if err = ProductSyncToTrip(ctx, logger, mrepo); err != nil {
-				level.Error(logger).Log("error", "Failed to schedule job ProductSyncToTrip", "err", err)
-				return err
-			}
+	// --- contentSync to trip (requires image + productview updated first)
+	if contentPending {
+		if err = ProductSyncToTrip(ctx, logger, mrepo); err != nil {
+			level.Error(logger).Log("error", "Failed to schedule job ProductSyncToTrip", "err", err)
+			return err
 		}
 	}
 
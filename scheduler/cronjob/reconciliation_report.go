@@ -0,0 +1,25 @@
+package cronjob
+
+import (
+	"context"
+	svc "swallow-supplier/iface"
+	"swallow-supplier/mongo/domain/reconciliation"
+	"swallow-supplier/request_response/common"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// GenerateReconciliationReport reconcile yesterday's orders against yanolja and trip, the
+// report is stored in reconciliation_reports for finance
+func GenerateReconciliationReport(ctx context.Context, s svc.Service, logger log.Logger) (int, error) {
+	resp, err := s.GenerateReconciliationReport(ctx, common.ReconciliationReportRequest{})
+	if err != nil {
+		level.Error(logger).Log("error", "reconciliation report failed", "err", err)
+		return 0, err
+	}
+
+	report, _ := resp.Body.(reconciliation.Report)
+	level.Info(logger).Log("msg", "reconciliation report generated", "date", report.Date, "mismatches", report.Total)
+	return int(report.OrdersChecked), nil
+}
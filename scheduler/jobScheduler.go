@@ -7,6 +7,7 @@ import (
 	//"fmt"
 	svc "swallow-supplier/iface"
 	"swallow-supplier/scheduler/cronjob"
+	"swallow-supplier/utils/constant"
 
 	//"swallow-supplier/scheduler/cronjob"
 
@@ -29,6 +30,10 @@ func Jobs(ctx context.Context, logger log.Logger, s svc.Service, mrepo svc.Mongo
 		ctx = context.Background()
 	}
 
+	// Only the replica holding the lease runs the jobs, the others stand by to take over
+	lease := NewLease(mrepo, "scheduler", constant.JobLeaseSeconds*time.Second, logger)
+	go lease.Run(ctx)
+
 	// Setup cron scheduler
 	job := cron.New()
 
@@ -50,46 +55,76 @@ func Jobs(ctx context.Context, logger log.Logger, s svc.Service, mrepo svc.Mongo
 
 	}) */
 
-	// Setup cron scheduler for every 5 second for product update
-	_, err = job.AddFunc("@every 5s", func() {
-		//level.Info(logger).Log("msg", "Scheduled execution of MonitorProductUpdates every 5 second")
-
-		cronjob.MonitorProductUpdates(ctx, mrepo, logger)
+	// Product view and image processing follow the product change stream on the leader,
+	// a failed stream is started again from its resume token
+	go lease.Lead(ctx, func(ctx context.Context) {
+		if err := cronjob.WatchProductUpdates(ctx, mrepo, logger); err != nil && ctx.Err() == nil {
+			level.Error(logger).Log("error", "WatchProductUpdates stopped", "err", err)
+		}
 	})
-	if err != nil {
-		level.Error(logger).Log("error", "Failed to schedule MonitorProductUpdates job", "err", err)
-		return err
-	}
-
-	// Add jobs to the scheduler to run every 60 seconds  for redis update for plu
-	_, err = job.AddFunc("@every 60s", func() {
-		cronjob.SchedulePluUpsertToRedis(ctx, mrepo, logger)
 
-	})
-	if err != nil {
-		level.Error(logger).Log("error", "Failed to schedule SyncItemIdDetail job", "err", err)
-		return err
+	if err = mrepo.EnsureJobRunIndexes(ctx); err != nil {
+		level.Error(logger).Log("error", "Failed to create job run indexes", "err", err)
 	}
 
-	// Add jobs to the scheduler to run every 2 seconds  always need in order creation
-	_, err = job.AddFunc("@every 2s", func() {
-		cronjob.SyncItemIdDetail(ctx, logger, mrepo)
+	// functions of the registered jobs, see Definitions for their schedule, timeout and concurrency
+	funcs := map[string]Func{
+		"MonitorProductUpdates": func(ctx context.Context) (int, error) {
+			return cronjob.MonitorProductUpdates(ctx, mrepo, logger)
+		},
+		"SchedulePluUpsertToRedis": func(ctx context.Context) (int, error) {
+			return 0, cronjob.SchedulePluUpsertToRedis(ctx, mrepo, logger)
+		},
+		"SyncItemIdDetail": func(ctx context.Context) (int, error) {
+			return 0, cronjob.SyncItemIdDetail(ctx, logger, mrepo)
+		},
+		// below job needs to be removed
+		"SyncTripRequestToredis": func(ctx context.Context) (int, error) {
+			return 0, cronjob.SyncTripRequestToredis(ctx, mrepo, logger)
+		},
+		"DrainNotificationOutbox": func(ctx context.Context) (int, error) {
+			return 0, cronjob.DrainNotificationOutbox(ctx, s, logger)
+		},
+		"ExpireHeldOrders": func(ctx context.Context) (int, error) {
+			return cronjob.ExpireHeldOrders(ctx, s, logger)
+		},
+		"PushOdooChanges": func(ctx context.Context) (int, error) {
+			return cronjob.PushOdooChanges(ctx, s, logger)
+		},
+		"GenerateReconciliationReport": func(ctx context.Context) (int, error) {
+			return cronjob.GenerateReconciliationReport(ctx, s, logger)
+		},
+		"ImportFxRates": func(ctx context.Context) (int, error) {
+			return 0, cronjob.ImportFxRates(ctx, mrepo, logger)
+		},
+		"SyncImageUrlForImageId": func(ctx context.Context) (int, error) {
+			return 0, cronjob.SyncImageUrlForImageId(ctx, logger, mrepo)
+		},
+		"ProductSyncToTrip": func(ctx context.Context) (int, error) {
+			return 0, cronjob.ProductSyncToTrip(ctx, logger, mrepo)
+		},
+		"NotifyTripAPI": func(ctx context.Context) (int, error) {
+			return 0, cronjob.NotifyTripAPI(ctx, logger)
+		},
+	}
 
-	})
-	if err != nil {
-		level.Error(logger).Log("error", "Failed to schedule SyncItemIdDetail job", "err", err)
-		return err
+	registry := NewRegistry(job, lease, mrepo, logger)
+	for _, def := range Definitions {
+		if err = registry.Register(ctx, def, funcs[def.Name]); err != nil {
+			level.Error(logger).Log("error", fmt.Sprintf("Failed to schedule %s job", def.Name), "err", err)
+			return err
+		}
 	}
 
-	// Add jobs to the scheduler to run every 2 seconds  always need in order creation
-	// below code needs to be removed
-	_, err = job.AddFunc("@every 2s", func() {
-		cronjob.SyncTripRequestToredis(ctx, mrepo, logger)
+	// stored schedules, pauses and manual triggers are picked up on every replica
+	if err = registry.Sync(ctx); err != nil {
+		level.Error(logger).Log("error", "Failed to load job settings", "err", err)
+	}
+	go registry.SyncEvery(ctx, constant.JobSyncSeconds*time.Second)
 
-	})
-	if err != nil {
-		level.Error(logger).Log("error", fmt.Sprintf("Failed to schedule SyncTripRequestToredis job %w", err))
-		return err
+	// Import the fx rates at start on the leader, orders convert channel prices with the latest one
+	if err = registry.RunAtStart(ctx, "ImportFxRates"); err != nil {
+		level.Error(logger).Log("error", "Failed to import fx rates at start", "err", err)
 	}
 
 	// Start the cron scheduler
@@ -0,0 +1,159 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"swallow-supplier/utils"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// LeaseStore persists the lease, implemented by the mongo repository
+type LeaseStore interface {
+	AcquireJobLease(ctx context.Context, name, holder string, ttl time.Duration) (acquired bool, err error)
+	ReleaseJobLease(ctx context.Context, name, holder string) error
+}
+
+// Lease leader lease shared by the replicas, jobs wrapped with Guard only run on the holder.
+// The holder renews every third of the ttl, a standby takes over within one ttl after it dies
+type Lease struct {
+	store  LeaseStore
+	name   string
+	holder string
+	ttl    time.Duration
+	logger log.Logger
+
+	mutex      sync.RWMutex
+	validUntil time.Time
+}
+
+// NewLease lease name for this instance, the holder is the hostname (the pod name) with a random suffix
+func NewLease(store LeaseStore, name string, ttl time.Duration, logger log.Logger) *Lease {
+	host, _ := os.Hostname()
+	return &Lease{
+		store:  store,
+		name:   name,
+		holder: fmt.Sprintf("%s-%s", host, utils.GenerateUUID("", true)[:8]),
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Holder identity of this instance
+func (l *Lease) Holder() string {
+	return l.holder
+}
+
+// IsLeader whether this instance holds the lease. Leadership ends at the local expiry of the last
+// successful renewal, so a stalled instance steps down before a standby can take over
+func (l *Lease) IsLeader() bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return time.Now().Before(l.validUntil)
+}
+
+// Run acquire and renew the lease until ctx is done, the lease is released on the way out
+func (l *Lease) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	l.renew(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			l.release()
+			return
+		case <-ticker.C:
+			l.renew(ctx)
+		}
+	}
+}
+
+// Guard wraps a job so it only runs while this instance is the leader
+func (l *Lease) Guard(fn func()) func() {
+	return func() {
+		if !l.IsLeader() {
+			return
+		}
+		fn()
+	}
+}
+
+func (l *Lease) renew(ctx context.Context) {
+	// the expiry is counted from before the call, never later than the stored one
+	attemptedAt := time.Now()
+	wasLeader := l.IsLeader()
+
+	acquired, err := l.store.AcquireJobLease(ctx, l.name, l.holder, l.ttl)
+	if err != nil {
+		level.Error(l.logger).Log("method", "Lease", "lease", l.name, "holder", l.holder, "error", err)
+		return
+	}
+
+	l.mutex.Lock()
+	if acquired {
+		l.validUntil = attemptedAt.Add(l.ttl)
+	} else {
+		l.validUntil = time.Time{}
+	}
+	l.mutex.Unlock()
+
+	if acquired != wasLeader {
+		level.Info(l.logger).Log("method", "Lease", "lease", l.name, "holder", l.holder, "leader", acquired)
+	}
+}
+
+func (l *Lease) release() {
+	l.mutex.Lock()
+	l.validUntil = time.Time{}
+	l.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := l.store.ReleaseJobLease(ctx, l.name, l.holder); err != nil {
+		level.Error(l.logger).Log("method", "Lease", "lease", l.name, "holder", l.holder, "error", err)
+	}
+}
+
+// Lead runs fn while this instance is the leader, fn gets a context which is cancelled as soon
+// as the leadership is lost and is started again once it is regained
+func (l *Lease) Lead(ctx context.Context, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		if l.IsLeader() {
+			leadCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				fn(leadCtx)
+			}()
+
+		running:
+			for l.IsLeader() {
+				select {
+				case <-ticker.C:
+				case <-done:
+					break running
+				case <-ctx.Done():
+					break running
+				}
+			}
+			cancel()
+			<-done
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package scheduler_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"swallow-supplier/scheduler"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryLeases in memory lease store, crashed holders never release
+type memoryLeases struct {
+	mutex   sync.Mutex
+	holder  string
+	expires time.Time
+	crashed map[string]bool
+}
+
+func (m *memoryLeases) AcquireJobLease(_ context.Context, _, holder string, ttl time.Duration) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.holder != holder && time.Now().Before(m.expires) {
+		return false, nil
+	}
+	m.holder, m.expires = holder, time.Now().Add(ttl)
+	return true, nil
+}
+
+func (m *memoryLeases) ReleaseJobLease(_ context.Context, _, holder string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.holder == holder && !m.crashed[holder] {
+		m.holder, m.expires = "", time.Time{}
+	}
+	return nil
+}
+
+func TestLeaseFailover(t *testing.T) {
+	store := &memoryLeases{crashed: map[string]bool{}}
+	ttl := 90 * time.Millisecond
+
+	first := scheduler.NewLease(store, "scheduler", ttl, log.NewNopLogger())
+	second := scheduler.NewLease(store, "scheduler", ttl, log.NewNopLogger())
+	assert.NotEqual(t, first.Holder(), second.Holder())
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	go first.Run(firstCtx)
+	assert.Eventually(t, first.IsLeader, time.Second, 5*time.Millisecond)
+
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	go second.Run(secondCtx)
+
+	runs := 0
+	job := second.Guard(func() { runs++ })
+	time.Sleep(2 * ttl)
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+	job()
+	assert.Equal(t, 0, runs)
+
+	// the leader dies without releasing, the standby takes over once the lease expires
+	store.mutex.Lock()
+	store.crashed[first.Holder()] = true
+	store.mutex.Unlock()
+	stopFirst()
+
+	assert.Eventually(t, second.IsLeader, 2*ttl, 5*time.Millisecond)
+	assert.False(t, first.IsLeader())
+	job()
+	assert.Equal(t, 1, runs)
+}
+
+func TestLeaseLead(t *testing.T) {
+	store := &memoryLeases{crashed: map[string]bool{}}
+	ttl := 60 * time.Millisecond
+	lease := scheduler.NewLease(store, "scheduler", ttl, log.NewNopLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go lease.Run(ctx)
+
+	started := make(chan struct{}, 1)
+	stopped := make(chan struct{}, 1)
+	go lease.Lead(ctx, func(ctx context.Context) {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- struct{}{}
+	})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("lead did not start on the leader")
+	}
+
+	// another holder takes the lease over
+	store.mutex.Lock()
+	store.holder, store.expires = "other", time.Now().Add(time.Hour)
+	store.mutex.Unlock()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("lead was not stopped after the lease was lost")
+	}
+	assert.False(t, lease.IsLeader())
+}
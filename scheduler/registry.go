@@ -0,0 +1,326 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	job_domain "swallow-supplier/mongo/domain/job"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/robfig/cron/v3"
+)
+
+// Func body of a job, items is the number of records it handled
+type Func func(ctx context.Context) (items int, err error)
+
+// Definition registered job with its defaults, the schedule is a cron spec or @every duration
+type Definition struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Schedule    string        `json:"schedule"`
+	Timeout     time.Duration `json:"-"`
+	Concurrency string        `json:"concurrency" oneof:"'FORBID' 'ALLOW'"`
+	Paused      bool          `json:"paused"`
+}
+
+// Definitions every job of the scheduler, jobs the scheduler did not run before are registered
+// paused until they are resumed through the admin api
+var Definitions = []Definition{
+	{Name: "MonitorProductUpdates", Description: "sweep for product changes the change stream missed", Schedule: "@every 5m", Timeout: 4 * time.Minute, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "SchedulePluUpsertToRedis", Description: "upsert plu details to redis", Schedule: "@every 60s", Timeout: 55 * time.Second, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "SyncItemIdDetail", Description: "sync item id details needed by order creation to redis", Schedule: "@every 2s", Timeout: 30 * time.Second, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "SyncTripRequestToredis", Description: "sync trip request data to redis", Schedule: "@every 2s", Timeout: 30 * time.Second, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "DrainNotificationOutbox", Description: "retry outbound trip and pdf voucher notifications", Schedule: "@every 30s", Timeout: 5 * time.Minute, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "ExpireHeldOrders", Description: "cancel pre orders left unpaid past the supplier's hold window", Schedule: "@every 1m", Timeout: 5 * time.Minute, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "PushOdooChanges", Description: "push changed orders and products to odoo", Schedule: "@every 5s", Timeout: 2 * time.Minute, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "GenerateReconciliationReport", Description: "reconcile yesterday's orders against yanolja and trip", Schedule: "30 0 * * *", Timeout: 30 * time.Minute, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "ImportFxRates", Description: "import fx rates from the rate file", Schedule: "@every 6h", Timeout: 5 * time.Minute, Concurrency: constant.JobConcurrencyForbid},
+	{Name: "SyncImageUrlForImageId", Description: "send unsynced product images to trip for their image id", Schedule: "@every 5m", Timeout: 4 * time.Minute, Concurrency: constant.JobConcurrencyForbid, Paused: true},
+	{Name: "ProductSyncToTrip", Description: "sync product content pending for trip", Schedule: "@every 15m", Timeout: 14 * time.Minute, Concurrency: constant.JobConcurrencyForbid, Paused: true},
+	{Name: "NotifyTripAPI", Description: "tell the trip sync service product content is ready", Schedule: "@every 1h", Timeout: 5 * time.Minute, Concurrency: constant.JobConcurrencyForbid, Paused: true},
+}
+
+// Lookup definition of the job name
+func Lookup(name string) (Definition, bool) {
+	for _, def := range Definitions {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// ValidateSchedule checks a cron spec or @every duration
+func ValidateSchedule(schedule string) error {
+	_, err := cron.ParseStandard(schedule)
+	return err
+}
+
+// JobStore persists job settings and runs, implemented by the mongo repository
+type JobStore interface {
+	GetJobSettings(ctx context.Context) (settings []job_domain.Setting, err error)
+	ClaimJobTrigger(ctx context.Context, name string) (claimed bool, err error)
+	InsertJobRun(ctx context.Context, run job_domain.Run) (id string, err error)
+}
+
+type registered struct {
+	def      Definition
+	fn       Func
+	entry    cron.EntryID
+	schedule string
+	paused   bool
+	running  int32
+}
+
+// Registry jobs of the cron scheduler. Scheduled runs only happen on the lease holder and are
+// recorded in the job store, see run for the runs which are not
+type Registry struct {
+	cron   *cron.Cron
+	lease  *Lease
+	store  JobStore
+	logger log.Logger
+
+	mutex sync.Mutex
+	jobs  map[string]*registered
+}
+
+// NewRegistry registry adding its jobs to c
+func NewRegistry(c *cron.Cron, lease *Lease, store JobStore, logger log.Logger) *Registry {
+	return &Registry{
+		cron:   c,
+		lease:  lease,
+		store:  store,
+		logger: logger,
+		jobs:   make(map[string]*registered),
+	}
+}
+
+// Register schedule the job with its default schedule, stored settings are applied by Sync
+func (r *Registry) Register(ctx context.Context, def Definition, fn Func) error {
+	if fn == nil {
+		return fmt.Errorf("job %s has no function", def.Name)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.jobs[def.Name]; exists {
+		return fmt.Errorf("job %s is already registered", def.Name)
+	}
+
+	job := &registered{def: def, fn: fn, schedule: def.Schedule, paused: def.Paused}
+	entry, err := r.cron.AddFunc(def.Schedule, r.scheduled(ctx, job))
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q of job %s: %w", def.Schedule, def.Name, err)
+	}
+	job.entry = entry
+	r.jobs[def.Name] = job
+
+	return nil
+}
+
+// Sync apply the stored schedules and pauses, the leader also starts the pending manual triggers
+func (r *Registry) Sync(ctx context.Context) error {
+	settings, err := r.store.GetJobSettings(ctx)
+	if err != nil {
+		return err
+	}
+	stored := make(map[string]job_domain.Setting, len(settings))
+	for _, setting := range settings {
+		stored[setting.Name] = setting
+	}
+
+	r.mutex.Lock()
+	triggered := make([]*registered, 0)
+	for name, job := range r.jobs {
+		setting := stored[name]
+
+		job.paused = job.def.Paused
+		if setting.Paused != nil {
+			job.paused = *setting.Paused
+		}
+
+		schedule := job.def.Schedule
+		if setting.Schedule != "" {
+			schedule = setting.Schedule
+		}
+		if schedule != job.schedule {
+			entry, err := r.cron.AddFunc(schedule, r.scheduled(ctx, job))
+			if err != nil {
+				level.Error(r.logger).Log("method", "Registry", "job", name, "schedule", schedule, "error", err)
+			} else {
+				r.cron.Remove(job.entry)
+				job.entry, job.schedule = entry, schedule
+				level.Info(r.logger).Log("method", "Registry", "job", name, "schedule", schedule)
+			}
+		}
+
+		if setting.TriggerRequestedAt != "" {
+			triggered = append(triggered, job)
+		}
+	}
+	r.mutex.Unlock()
+
+	if !r.lease.IsLeader() {
+		return nil
+	}
+	for _, job := range triggered {
+		claimed, err := r.store.ClaimJobTrigger(ctx, job.def.Name)
+		if err != nil {
+			level.Error(r.logger).Log("method", "Registry", "job", job.def.Name, "error", err)
+			continue
+		}
+		if claimed {
+			go r.run(ctx, job, constant.JobTriggerManual)
+		}
+	}
+
+	return nil
+}
+
+// SyncEvery calls Sync every interval until ctx is done
+func (r *Registry) SyncEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Sync(ctx); err != nil {
+				level.Error(r.logger).Log("method", "Registry", "error", err)
+			}
+		}
+	}
+}
+
+// RunAtStart runs the job once as soon as this instance holds the lease, replicas which never
+// lead do not run it. Paused jobs are skipped
+func (r *Registry) RunAtStart(ctx context.Context, name string) error {
+	r.mutex.Lock()
+	job, ok := r.jobs[name]
+	r.mutex.Unlock()
+	if !ok {
+		return fmt.Errorf("job %s is not registered", name)
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.lease.ttl / 3)
+		defer ticker.Stop()
+
+		for !r.lease.IsLeader() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+
+		r.mutex.Lock()
+		paused := job.paused
+		r.mutex.Unlock()
+		if !paused {
+			r.run(ctx, job, constant.JobTriggerStartup)
+		}
+	}()
+
+	return nil
+}
+
+func (r *Registry) scheduled(ctx context.Context, job *registered) func() {
+	return func() {
+		r.mutex.Lock()
+		paused := job.paused
+		r.mutex.Unlock()
+
+		if paused || !r.lease.IsLeader() {
+			return
+		}
+		r.run(ctx, job, constant.JobTriggerSchedule)
+	}
+}
+
+// run executes the job within its timeout and records the run, a FORBID job still running
+// from a previous run is skipped without a record. Scheduled runs of frequent jobs are only
+// recorded when they fail or handle items
+func (r *Registry) run(ctx context.Context, job *registered, trigger string) {
+	if job.def.Concurrency != constant.JobConcurrencyAllow {
+		if !atomic.CompareAndSwapInt32(&job.running, 0, 1) {
+			level.Info(r.logger).Log("method", "Registry", "job", job.def.Name, "msg", "previous run still going, skipped")
+			return
+		}
+		defer atomic.StoreInt32(&job.running, 0)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	if job.def.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, job.def.Timeout)
+	}
+	defer cancel()
+
+	startedAt := time.Now()
+	items, err := call(runCtx, job.fn)
+	endedAt := time.Now()
+
+	record := job_domain.Run{
+		Job:        job.def.Name,
+		Trigger:    trigger,
+		Holder:     r.lease.Holder(),
+		StartedAt:  startedAt.UTC().Format(time.RFC3339Nano),
+		EndedAt:    endedAt.UTC().Format(time.RFC3339Nano),
+		DurationMs: endedAt.Sub(startedAt).Milliseconds(),
+		Outcome:    constant.JobOutcomeSuccess,
+		Items:      items,
+		ExpireAt:   endedAt.Add(constant.JobRunRetentionDays * 24 * time.Hour),
+	}
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		record.Outcome = constant.JobOutcomeTimeout
+		record.Error = fmt.Sprintf("job exceeded its timeout of %s", job.def.Timeout)
+	case err != nil:
+		record.Outcome = constant.JobOutcomeFailed
+		record.Error = err.Error()
+	}
+	if record.Outcome != constant.JobOutcomeSuccess {
+		level.Error(r.logger).Log("method", "Registry", "job", job.def.Name, "outcome", record.Outcome, "error", record.Error)
+	}
+
+	r.mutex.Lock()
+	schedule := job.schedule
+	r.mutex.Unlock()
+	if trigger == constant.JobTriggerSchedule && record.Outcome == constant.JobOutcomeSuccess && items == 0 && frequent(schedule) {
+		return
+	}
+
+	if _, err = r.store.InsertJobRun(context.Background(), record); err != nil {
+		level.Error(r.logger).Log("method", "Registry", "job", job.def.Name, "error", err)
+	}
+}
+
+// frequent schedules running more often than JobQuietRunSeconds
+func frequent(schedule string) bool {
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return false
+	}
+	every, ok := parsed.(cron.ConstantDelaySchedule)
+	return ok && every.Delay < constant.JobQuietRunSeconds*time.Second
+}
+
+// call runs fn turning a panic into an error, cron would otherwise take the process down
+func call(ctx context.Context, fn Func) (items int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic occurred: %v", r)
+		}
+	}()
+
+	return fn(ctx)
+}
@@ -0,0 +1,208 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	job_domain "swallow-supplier/mongo/domain/job"
+	"swallow-supplier/scheduler"
+	"swallow-supplier/utils/constant"
+
+	"github.com/go-kit/kit/log"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryJobs struct {
+	mutex    sync.Mutex
+	settings map[string]job_domain.Setting
+	runs     []job_domain.Run
+}
+
+func (m *memoryJobs) GetJobSettings(context.Context) ([]job_domain.Setting, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	settings := make([]job_domain.Setting, 0, len(m.settings))
+	for _, setting := range m.settings {
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+func (m *memoryJobs) ClaimJobTrigger(_ context.Context, name string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	setting := m.settings[name]
+	if setting.TriggerRequestedAt == "" {
+		return false, nil
+	}
+	setting.TriggerRequestedAt = ""
+	m.settings[name] = setting
+	return true, nil
+}
+
+func (m *memoryJobs) InsertJobRun(_ context.Context, run job_domain.Run) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.runs = append(m.runs, run)
+	return "", nil
+}
+
+func (m *memoryJobs) recorded() []job_domain.Run {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]job_domain.Run(nil), m.runs...)
+}
+
+func leader(t *testing.T, ctx context.Context) *scheduler.Lease {
+	lease := scheduler.NewLease(&memoryLeases{crashed: map[string]bool{}}, "scheduler", time.Minute, log.NewNopLogger())
+	go lease.Run(ctx)
+	assert.Eventually(t, lease.IsLeader, time.Second, 5*time.Millisecond)
+	return lease
+}
+
+func TestRegistryManualTrigger(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := &memoryJobs{settings: map[string]job_domain.Setting{}}
+	registry := scheduler.NewRegistry(cron.New(), leader(t, ctx), store, log.NewNopLogger())
+
+	def := scheduler.Definition{Name: "count", Schedule: "@every 1h", Timeout: time.Second, Concurrency: constant.JobConcurrencyForbid, Paused: true}
+	assert.NoError(t, registry.Register(ctx, def, func(context.Context) (int, error) { return 3, nil }))
+	assert.Error(t, registry.Register(ctx, def, func(context.Context) (int, error) { return 0, nil }))
+
+	failing := scheduler.Definition{Name: "failing", Schedule: "@every 1h", Timeout: time.Second}
+	assert.NoError(t, registry.Register(ctx, failing, func(context.Context) (int, error) { return 0, errors.New("supplier down") }))
+
+	slow := scheduler.Definition{Name: "slow", Schedule: "@every 1h", Timeout: 20 * time.Millisecond}
+	assert.NoError(t, registry.Register(ctx, slow, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}))
+
+	// paused jobs still run when triggered by hand
+	store.settings["count"] = job_domain.Setting{Name: "count", TriggerRequestedAt: "2025-03-01T10:00:00Z"}
+	store.settings["failing"] = job_domain.Setting{Name: "failing", TriggerRequestedAt: "2025-03-01T10:00:00Z"}
+	store.settings["slow"] = job_domain.Setting{Name: "slow", TriggerRequestedAt: "2025-03-01T10:00:00Z"}
+	assert.NoError(t, registry.Sync(ctx))
+
+	assert.Eventually(t, func() bool { return len(store.recorded()) == 3 }, time.Second, 5*time.Millisecond)
+	outcomes := make(map[string]job_domain.Run)
+	for _, run := range store.recorded() {
+		outcomes[run.Job] = run
+	}
+	assert.Equal(t, constant.JobOutcomeSuccess, outcomes["count"].Outcome)
+	assert.Equal(t, 3, outcomes["count"].Items)
+	assert.Equal(t, constant.JobTriggerManual, outcomes["count"].Trigger)
+	assert.Equal(t, constant.JobOutcomeFailed, outcomes["failing"].Outcome)
+	assert.Equal(t, "supplier down", outcomes["failing"].Error)
+	assert.Equal(t, constant.JobOutcomeTimeout, outcomes["slow"].Outcome)
+
+	// the trigger was claimed, a second sync does not run it again
+	assert.NoError(t, registry.Sync(ctx))
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, store.recorded(), 3)
+}
+
+func TestRegistrySchedule(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := &memoryJobs{settings: map[string]job_domain.Setting{}}
+	c := cron.New()
+	registry := scheduler.NewRegistry(c, leader(t, ctx), store, log.NewNopLogger())
+
+	def := scheduler.Definition{Name: "tick", Schedule: "@every 1h"}
+	assert.NoError(t, registry.Register(ctx, def, func(context.Context) (int, error) { return 1, nil }))
+	assert.Error(t, registry.Register(ctx, scheduler.Definition{Name: "broken", Schedule: "whenever"}, func(context.Context) (int, error) { return 0, nil }))
+
+	store.settings["tick"] = job_domain.Setting{Name: "tick", Schedule: "@every 1s"}
+	assert.NoError(t, registry.Sync(ctx))
+	assert.Len(t, c.Entries(), 1)
+
+	c.Start()
+	defer c.Stop()
+	assert.Eventually(t, func() bool { return len(store.recorded()) > 0 }, 3*time.Second, 20*time.Millisecond)
+	assert.Equal(t, constant.JobTriggerSchedule, store.recorded()[0].Trigger)
+
+	paused := true
+	store.mutex.Lock()
+	store.settings["tick"] = job_domain.Setting{Name: "tick", Schedule: "@every 1s", Paused: &paused}
+	store.mutex.Unlock()
+	assert.NoError(t, registry.Sync(ctx))
+	time.Sleep(100 * time.Millisecond)
+	runs := len(store.recorded())
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, runs, len(store.recorded()))
+}
+
+func TestRegistryQuietRuns(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := &memoryJobs{settings: map[string]job_domain.Setting{}}
+	c := cron.New()
+	registry := scheduler.NewRegistry(c, leader(t, ctx), store, log.NewNopLogger())
+
+	// scheduled runs of a frequent job are only recorded when they handle items or fail
+	var calls int32
+	assert.NoError(t, registry.Register(ctx, scheduler.Definition{Name: "poll", Schedule: "@every 1s"}, func(context.Context) (int, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			return 0, errors.New("poll failed")
+		}
+		return 0, nil
+	}))
+
+	c.Start()
+	defer c.Stop()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&calls) >= 3 }, 5*time.Second, 20*time.Millisecond)
+	runs := store.recorded()
+	assert.Len(t, runs, 1)
+	assert.Equal(t, constant.JobOutcomeFailed, runs[0].Outcome)
+}
+
+func TestValidateSchedule(t *testing.T) {
+	assert.NoError(t, scheduler.ValidateSchedule("@every 90s"))
+	assert.NoError(t, scheduler.ValidateSchedule("*/5 * * * *"))
+	assert.Error(t, scheduler.ValidateSchedule("every minute"))
+
+	_, ok := scheduler.Lookup("ProductSyncToTrip")
+	assert.True(t, ok)
+}
+
+func TestRegistryRunAtStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := &memoryJobs{settings: map[string]job_domain.Setting{}}
+	registry := scheduler.NewRegistry(cron.New(), leader(t, ctx), store, log.NewNopLogger())
+
+	def := scheduler.Definition{Name: "import", Schedule: "@every 1h"}
+	assert.NoError(t, registry.Register(ctx, def, func(context.Context) (int, error) { return 2, nil }))
+	assert.Error(t, registry.RunAtStart(ctx, "unknown"))
+
+	assert.NoError(t, registry.RunAtStart(ctx, "import"))
+	assert.Eventually(t, func() bool { return len(store.recorded()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, constant.JobTriggerStartup, store.recorded()[0].Trigger)
+
+	// a standby does not run it while another replica holds the lease
+	leases := &memoryLeases{holder: "other", expires: time.Now().Add(time.Hour), crashed: map[string]bool{}}
+	standby := scheduler.NewLease(leases, "scheduler", 30*time.Millisecond, log.NewNopLogger())
+	go standby.Run(ctx)
+
+	standbyStore := &memoryJobs{settings: map[string]job_domain.Setting{}}
+	standbyRegistry := scheduler.NewRegistry(cron.New(), standby, standbyStore, log.NewNopLogger())
+	assert.NoError(t, standbyRegistry.Register(ctx, def, func(context.Context) (int, error) { return 2, nil }))
+	assert.NoError(t, standbyRegistry.RunAtStart(ctx, "import"))
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, standbyStore.recorded())
+}
@@ -7,6 +7,7 @@ import (
 	customError "swallow-supplier/error"
 	"swallow-supplier/utils"
 	"swallow-supplier/utils/client"
+	"time"
 )
 
 // Trip required fields for accessing Customer Orchestrator
@@ -21,6 +22,17 @@ const (
 	ServiceName = "Trip"
 )
 
+// RetryPolicy trip notifications carry the sequenceId and an idempotency key, so POSTs are retried.
+// The delays add up to well below the outbox lease, the outbox deadline ends the retries anyway
+var RetryPolicy client.RetryPolicy = client.BackoffPolicy{
+	Attempts:             5,
+	BaseDelay:            time.Second,
+	MaxDelay:             8 * time.Second,
+	Jitter:               0.3,
+	RetryNonIdempotent:   true,
+	IdempotencyKeyHeader: client.IdempotencyKeyHeader,
+}
+
 // New initialize Trip
 func New(ctx context.Context) (c *Trip, err error) {
 	c = &Trip{}
@@ -29,6 +41,7 @@ func New(ctx context.Context) (c *Trip, err error) {
 	c.Service.CustomRequest = client.CustomRequest{
 		RequestTimeout: client.RequestTimeout,
 		Retries:        5,
+		RetryPolicy:    RetryPolicy,
 	}
 
 	request := client.NewRequest(c.Service.CustomRequest)
@@ -0,0 +1,134 @@
+package odoo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"swallow-supplier/utils/client"
+	"sync/atomic"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// RPCPath path of odoo's json-rpc endpoint
+const RPCPath = "/jsonrpc"
+
+// ErrLogin odoo did not accept the login and api key
+var ErrLogin = errors.New("odoo login failed")
+
+// RPCError error returned by odoo in the json-rpc response, odoo refused the call itself
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	} `json:"data"`
+}
+
+func (e *RPCError) Error() string {
+	if e.Data.Message != "" {
+		return fmt.Sprintf("odoo %s: %s", e.Data.Name, e.Data.Message)
+	}
+	return fmt.Sprintf("odoo error %d: %s", e.Code, e.Message)
+}
+
+// Request json-rpc call of a service method
+type Request struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  Params `json:"params"`
+	Id      int64  `json:"id"`
+}
+
+// Params service, method and positional arguments of a call
+type Params struct {
+	Service string        `json:"service"`
+	Method  string        `json:"method"`
+	Args    []interface{} `json:"args"`
+}
+
+// Response json-rpc response, either Result or Error is set
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Id      int64           `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// call invoke method of the odoo service and decode its result into result
+func (o *Odoo) call(ctx context.Context, service string, method string, result interface{}, args ...interface{}) error {
+	logger := log.With(o.Service.Logger, "method", "odoo.call", "service", service, "call", method)
+
+	payload := Request{
+		JSONRPC: "2.0",
+		Method:  "call",
+		Params:  Params{Service: service, Method: method, Args: args},
+		Id:      atomic.AddInt64(&o.requestId, 1),
+	}
+
+	response, err := o.Service.Send(ctx, ServiceName, o.Settings.Host+RPCPath, http.MethodPost, client.ContentTypeJSON, payload)
+	if err != nil {
+		level.Error(logger).Log("error", "odoo request failed", "err", err)
+		return err
+	}
+	if response.Status != http.StatusOK {
+		return fmt.Errorf("odoo responded with status %d", response.Status)
+	}
+
+	var rpc Response
+	if err = json.Unmarshal([]byte(response.Body), &rpc); err != nil {
+		return fmt.Errorf("odoo response could not be decoded: %w", err)
+	}
+	if rpc.Error != nil {
+		level.Error(logger).Log("error", "odoo refused the call", "err", rpc.Error)
+		return rpc.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpc.Result, result)
+}
+
+// login uid of the configured user, logged in once per client
+func (o *Odoo) login(ctx context.Context) (int64, error) {
+	if o.uid != 0 {
+		return o.uid, nil
+	}
+
+	// odoo answers false instead of an error when the credentials are wrong
+	var uid interface{}
+	if err := o.call(ctx, "common", "login", &uid, o.Settings.DB, o.Settings.Login, o.Settings.ApiKey); err != nil {
+		return 0, err
+	}
+	id, ok := uid.(float64)
+	if !ok || id == 0 {
+		return 0, ErrLogin
+	}
+
+	o.uid = int64(id)
+	return o.uid, nil
+}
+
+// ExecuteKw call method of the model with the positional and keyword arguments
+func (o *Odoo) ExecuteKw(ctx context.Context, model string, method string, args []interface{}, kwargs map[string]interface{}, result interface{}) error {
+	uid, err := o.login(ctx)
+	if err != nil {
+		return err
+	}
+	if kwargs == nil {
+		kwargs = map[string]interface{}{}
+	}
+
+	return o.call(ctx, "object", "execute_kw", result, o.Settings.DB, uid, o.Settings.ApiKey, model, method, args, kwargs)
+}
+
+// IsRecordError true when odoo refused the record itself, sending it again unchanged fails again
+func IsRecordError(err error) bool {
+	var rpcErr *RPCError
+	return errors.As(err, &rpcErr)
+}
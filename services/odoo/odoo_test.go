@@ -0,0 +1,166 @@
+package odoo_test
+
+import (
+	"context"
+	"testing"
+
+	"swallow-supplier/config"
+	"swallow-supplier/mongo/domain/odoo"
+	odooSvc "swallow-supplier/services/odoo"
+	"swallow-supplier/services/odoo/odootest"
+	"swallow-supplier/utils/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T) (*odooSvc.Odoo, *odootest.Server) {
+	config.SetInstance(&config.AppConfig{CircuitBreakerEnable: "0"})
+
+	server := odootest.NewServer()
+	t.Cleanup(server.Close)
+
+	client, err := odooSvc.NewWithSettings(context.Background(), server.Settings)
+	require.NoError(t, err)
+	return client, server
+}
+
+func testProduct() odoo.Product {
+	return odoo.Product{
+		ProductID:   1001,
+		ProductName: "Everland 1 Day Pass",
+		Variants: []odoo.ProductVariant{
+			{VariantID: 1, VariantName: "Adult", SalePrice: 52, SupplierCostPrice: 40, Currency: "USD"},
+			{VariantID: 2, VariantName: "Child", SalePrice: 41, SupplierCostPrice: 31, Currency: "USD"},
+		},
+	}
+}
+
+func testOrder() odoo.Order {
+	return odoo.Order{
+		OrderID:        5001,
+		PartnerOrderID: "TRIP-77",
+		BookingStatus:  constant.ORDERCOMPLETE,
+		Channel:        "Trip",
+		Supplier:       "Yanolja",
+		CustomerName:   "Kim",
+		BookedAt:       "2025-03-01T09:30:00Z",
+		Variants: []odoo.OrderVariant{
+			{OrderVariantID: 11, ProductId: 1001, ProductName: "Everland 1 Day Pass", VariantName: "Adult", VariantSalePrice: 52, Quantity: 2},
+		},
+	}
+}
+
+func TestUpsertProduct(t *testing.T) {
+	client, server := newClient(t)
+	ctx := context.Background()
+
+	// Test case 1: new product creates a template priced from the cheapest variant
+	templateId, productId, err := client.UpsertProduct(ctx, testProduct())
+	require.NoError(t, err)
+	assert.NotZero(t, templateId)
+	assert.NotZero(t, productId)
+
+	template := server.Records(odooSvc.ModelProductTemplate)[templateId]
+	assert.Equal(t, "1001", template["default_code"])
+	assert.Equal(t, float64(41), template["list_price"])
+	assert.Equal(t, float64(31), template["standard_price"])
+
+	// Test case 2: pushing it again without the ids finds the template by its reference
+	product := testProduct()
+	product.ProductName = "Everland Day Pass"
+	againTemplateId, againProductId, err := client.UpsertProduct(ctx, product)
+	require.NoError(t, err)
+	assert.Equal(t, templateId, againTemplateId)
+	assert.Equal(t, productId, againProductId)
+	assert.Len(t, server.Records(odooSvc.ModelProductTemplate), 1)
+	assert.Equal(t, "Everland Day Pass", server.Records(odooSvc.ModelProductTemplate)[templateId]["name"])
+
+	// Test case 3: deleted product archives the template
+	archivedId, err := client.ArchiveProduct(ctx, product.ProductID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, templateId, archivedId)
+	assert.Equal(t, false, server.Records(odooSvc.ModelProductTemplate)[templateId]["active"])
+}
+
+func TestUpsertOrder(t *testing.T) {
+	client, server := newClient(t)
+	ctx := context.Background()
+
+	_, productId, err := client.UpsertProduct(ctx, testProduct())
+	require.NoError(t, err)
+	productIds := map[int64]int64{1001: productId}
+
+	// Test case 1: booked order is created and confirmed
+	order := testOrder()
+	id, err := client.UpsertOrder(ctx, order, productIds)
+	require.NoError(t, err)
+	assert.NotZero(t, id)
+
+	saleOrder := server.Records(odooSvc.ModelSaleOrder)[id]
+	assert.Equal(t, "5001", saleOrder["origin"])
+	assert.Equal(t, "TRIP-77", saleOrder["client_order_ref"])
+	assert.Equal(t, "2025-03-01 09:30:00", saleOrder["date_order"])
+	assert.Equal(t, float64(7), saleOrder["partner_id"])
+	assert.Equal(t, "sale", saleOrder["state"])
+	assert.Len(t, server.Records("sale.order.line"), 1)
+
+	// Test case 2: canceled variant updates its line, a new variant adds one
+	order.OdooId = id
+	order.Variants[0].VariantStatusType = constant.ORDERVARIANTCANCELEDSTATUS
+	order.Variants = append(order.Variants, odoo.OrderVariant{OrderVariantID: 12, ProductId: 1001, VariantName: "Child", VariantSalePrice: 41, Quantity: 1})
+	againId, err := client.UpsertOrder(ctx, order, productIds)
+	require.NoError(t, err)
+	assert.Equal(t, id, againId)
+
+	lines := server.Records("sale.order.line")
+	assert.Len(t, lines, 2)
+	quantities := map[string]interface{}{}
+	for _, line := range lines {
+		quantities[line["name"].(string)[:4]] = line["product_uom_qty"]
+		assert.Equal(t, float64(productId), line["product_id"])
+	}
+	assert.Equal(t, float64(0), quantities["[11]"])
+	assert.Equal(t, float64(1), quantities["[12]"])
+
+	// Test case 3: canceled order cancels the sale order found by its origin
+	canceledId, err := client.CancelOrder(ctx, order.OrderID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, id, canceledId)
+	assert.Equal(t, "cancel", server.Records(odooSvc.ModelSaleOrder)[id]["state"])
+
+	// Test case 4: unknown order is not canceled
+	canceledId, err = client.CancelOrder(ctx, 9999, 0)
+	require.NoError(t, err)
+	assert.Zero(t, canceledId)
+}
+
+func TestOdooErrors(t *testing.T) {
+	client, server := newClient(t)
+	ctx := context.Background()
+
+	// Test case 1: refused record is a record error
+	server.Fail(odooSvc.ModelProductTemplate, "create", "name is required")
+	_, _, err := client.UpsertProduct(ctx, testProduct())
+	require.Error(t, err)
+	assert.True(t, odooSvc.IsRecordError(err))
+	assert.Contains(t, err.Error(), "name is required")
+
+	// Test case 2: wrong api key fails the login
+	settings := server.Settings
+	settings.ApiKey = "wrong"
+	wrong, err := odooSvc.NewWithSettings(ctx, settings)
+	require.NoError(t, err)
+	_, _, err = wrong.UpsertProduct(ctx, testProduct())
+	assert.ErrorIs(t, err, odooSvc.ErrLogin)
+
+	// Test case 3: unreachable odoo is not a record error
+	server.Close()
+	_, _, err = client.UpsertProduct(ctx, testProduct())
+	require.Error(t, err)
+	assert.False(t, odooSvc.IsRecordError(err))
+
+	// Test case 4: missing settings
+	_, err = odooSvc.NewWithSettings(ctx, odooSvc.Settings{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,323 @@
+// Package odootest fake odoo json-rpc server for tests of the odoo client
+package odootest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+
+	"swallow-supplier/services/odoo"
+)
+
+// Record field values of a record, ids are float64 like any json number
+type Record map[string]interface{}
+
+// Server in memory odoo. It knows the login of its settings and the models the client writes,
+// a created product.template gets a product variant like in odoo
+type Server struct {
+	*httptest.Server
+	Settings odoo.Settings
+
+	mutex   sync.Mutex
+	nextId  int64
+	records map[string]map[int64]Record
+	calls   []string
+	fail    map[string]string
+}
+
+// NewServer start the fake server, closed by the caller
+func NewServer() *Server {
+	s := &Server{
+		records: make(map[string]map[int64]Record),
+		fail:    make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.Settings = odoo.Settings{
+		Host:      s.URL,
+		DB:        "test",
+		Login:     "swallow",
+		ApiKey:    "secret",
+		PartnerId: 7,
+	}
+	return s
+}
+
+// Fail answer every call of the method on the model with a validation error
+func (s *Server) Fail(model string, method string, message string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.fail[model+"."+method] = message
+}
+
+// Records copy of the records of the model
+func (s *Server) Records(model string) map[int64]Record {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records := make(map[int64]Record, len(s.records[model]))
+	for id, record := range s.records[model] {
+		records[id] = copyRecord(record)
+	}
+	return records
+}
+
+// Calls model.method of every execute_kw in the order they were received
+func (s *Server) Calls() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Id     int64 `json:"id"`
+		Params struct {
+			Service string            `json:"service"`
+			Method  string            `json:"method"`
+			Args    []json.RawMessage `json:"args"`
+		} `json:"params"`
+	}
+	if r.URL.Path != odoo.RPCPath || json.NewDecoder(r.Body).Decode(&request) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	result, rpcErr := s.dispatch(request.Params.Service, request.Params.Method, request.Params.Args)
+	s.mutex.Unlock()
+
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": request.Id}
+	if rpcErr != nil {
+		response["error"] = rpcErr
+	} else {
+		response["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) dispatch(service string, method string, args []json.RawMessage) (interface{}, *odoo.RPCError) {
+	var values []interface{}
+	for _, arg := range args {
+		var value interface{}
+		json.Unmarshal(arg, &value)
+		values = append(values, value)
+	}
+
+	switch {
+	case service == "common" && method == "login":
+		if len(values) == 3 && values[0] == s.Settings.DB && values[1] == s.Settings.Login && values[2] == s.Settings.ApiKey {
+			return 2, nil
+		}
+		return false, nil
+
+	case service == "object" && method == "execute_kw" && len(values) >= 6:
+		if values[1] != float64(2) || values[2] != s.Settings.ApiKey {
+			return nil, accessError("invalid credentials")
+		}
+		model, _ := values[3].(string)
+		call, _ := values[4].(string)
+		callArgs, _ := values[5].([]interface{})
+		var kwargs map[string]interface{}
+		if len(values) > 6 {
+			kwargs, _ = values[6].(map[string]interface{})
+		}
+
+		s.calls = append(s.calls, model+"."+call)
+		if message, ok := s.fail[model+"."+call]; ok {
+			return nil, validationError(message)
+		}
+		return s.execute(model, call, callArgs, kwargs)
+	}
+
+	return nil, &odoo.RPCError{Code: 404, Message: fmt.Sprintf("unknown method %s.%s", service, method)}
+}
+
+func (s *Server) execute(model string, call string, args []interface{}, kwargs map[string]interface{}) (interface{}, *odoo.RPCError) {
+	switch call {
+	case "search":
+		ids := s.search(model, arg(args, 0))
+		if limit, ok := kwargs["limit"].(float64); ok && int(limit) < len(ids) {
+			ids = ids[:int(limit)]
+		}
+		return ids, nil
+
+	case "create":
+		values, _ := arg(args, 0).(map[string]interface{})
+		return s.create(model, values), nil
+
+	case "write":
+		values, _ := arg(args, 1).(map[string]interface{})
+		for _, id := range ids(arg(args, 0)) {
+			if _, ok := s.records[model][id]; !ok {
+				return nil, missingError(model, id)
+			}
+			s.write(model, id, values)
+		}
+		return true, nil
+
+	case "read":
+		return s.read(model, ids(arg(args, 0)), kwargs), nil
+
+	case "search_read":
+		return s.read(model, s.search(model, arg(args, 0)), kwargs), nil
+
+	case "action_confirm", "action_cancel":
+		state := map[string]string{"action_confirm": "sale", "action_cancel": "cancel"}[call]
+		for _, id := range ids(arg(args, 0)) {
+			if _, ok := s.records[model][id]; !ok {
+				return nil, missingError(model, id)
+			}
+			s.records[model][id]["state"] = state
+		}
+		return true, nil
+	}
+
+	return nil, &odoo.RPCError{Code: 200, Message: fmt.Sprintf("method %s of %s is not supported", call, model)}
+}
+
+// search ids of the records matching every [field, "=", value] of the domain
+func (s *Server) search(model string, domain interface{}) []int64 {
+	conditions, _ := domain.([]interface{})
+
+	found := make([]int64, 0)
+	for id, record := range s.records[model] {
+		match := true
+		for _, condition := range conditions {
+			term, _ := condition.([]interface{})
+			if len(term) != 3 || fmt.Sprint(record[fmt.Sprint(term[0])]) != fmt.Sprint(term[2]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			found = append(found, id)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+	return found
+}
+
+func (s *Server) create(model string, values map[string]interface{}) int64 {
+	s.nextId++
+	id := s.nextId
+	if s.records[model] == nil {
+		s.records[model] = make(map[int64]Record)
+	}
+	s.records[model][id] = Record{"id": float64(id), "active": true}
+	if model == odoo.ModelSaleOrder {
+		s.records[model][id]["state"] = "draft"
+	}
+	s.write(model, id, values)
+
+	if model == odoo.ModelProductTemplate {
+		variant := s.create("product.product", map[string]interface{}{"product_tmpl_id": float64(id)})
+		s.records[model][id]["product_variant_id"] = []interface{}{float64(variant), values["name"]}
+	}
+	return id
+}
+
+// write set the values, order_line commands 0 (create) and 1 (update) are applied to the lines
+func (s *Server) write(model string, id int64, values map[string]interface{}) {
+	for field, value := range values {
+		if model != odoo.ModelSaleOrder || field != "order_line" {
+			s.records[model][id][field] = value
+			continue
+		}
+
+		commands, _ := value.([]interface{})
+		for _, command := range commands {
+			parts, _ := command.([]interface{})
+			if len(parts) != 3 {
+				continue
+			}
+			lineValues, _ := parts[2].(map[string]interface{})
+			switch parts[0] {
+			case float64(0):
+				lineValues["order_id"] = float64(id)
+				s.create("sale.order.line", lineValues)
+			case float64(1):
+				if lineId, ok := parts[1].(float64); ok && s.records["sale.order.line"][int64(lineId)] != nil {
+					s.write("sale.order.line", int64(lineId), lineValues)
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) read(model string, ids []int64, kwargs map[string]interface{}) []Record {
+	fields, _ := kwargs["fields"].([]interface{})
+
+	rows := make([]Record, 0, len(ids))
+	for _, id := range ids {
+		record, ok := s.records[model][id]
+		if !ok {
+			continue
+		}
+		if len(fields) == 0 {
+			rows = append(rows, copyRecord(record))
+			continue
+		}
+		row := Record{"id": record["id"]}
+		for _, field := range fields {
+			name := fmt.Sprint(field)
+			if value, ok := record[name]; ok {
+				row[name] = value
+			} else {
+				row[name] = false
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func arg(args []interface{}, i int) interface{} {
+	if i < len(args) {
+		return args[i]
+	}
+	return nil
+}
+
+func ids(value interface{}) []int64 {
+	list, _ := value.([]interface{})
+	ids := make([]int64, 0, len(list))
+	for _, id := range list {
+		if number, ok := id.(float64); ok {
+			ids = append(ids, int64(number))
+		}
+	}
+	return ids
+}
+
+func copyRecord(record Record) Record {
+	copied := make(Record, len(record))
+	for field, value := range record {
+		copied[field] = value
+	}
+	return copied
+}
+
+func accessError(message string) *odoo.RPCError {
+	rpcErr := &odoo.RPCError{Code: 200, Message: "Odoo Server Error"}
+	rpcErr.Data.Name = "odoo.exceptions.AccessDenied"
+	rpcErr.Data.Message = message
+	return rpcErr
+}
+
+func validationError(message string) *odoo.RPCError {
+	rpcErr := &odoo.RPCError{Code: 200, Message: "Odoo Server Error"}
+	rpcErr.Data.Name = "odoo.exceptions.ValidationError"
+	rpcErr.Data.Message = message
+	return rpcErr
+}
+
+func missingError(model string, id int64) *odoo.RPCError {
+	rpcErr := &odoo.RPCError{Code: 200, Message: "Odoo Server Error"}
+	rpcErr.Data.Name = "odoo.exceptions.MissingError"
+	rpcErr.Data.Message = fmt.Sprintf("record %s(%d) does not exist", model, id)
+	return rpcErr
+}
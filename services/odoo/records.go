@@ -0,0 +1,256 @@
+package odoo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"swallow-supplier/mongo/domain/odoo"
+	"swallow-supplier/utils/constant"
+)
+
+// odoo models the records are written to
+const (
+	ModelSaleOrder       = "sale.order"
+	ModelProductTemplate = "product.template"
+)
+
+// DateTimeLayout odoo datetime fields, always in UTC
+const DateTimeLayout = "2006-01-02 15:04:05"
+
+// Many2One id of a many2one field, odoo reads it as [id, name] or false when empty
+type Many2One int64
+
+// UnmarshalJSON accept both forms of the field
+func (m *Many2One) UnmarshalJSON(data []byte) error {
+	var pair []interface{}
+	if err := json.Unmarshal(data, &pair); err != nil || len(pair) == 0 {
+		*m = 0
+		return nil
+	}
+	if id, ok := pair[0].(float64); ok {
+		*m = Many2One(id)
+	}
+	return nil
+}
+
+// ProductReference internal reference of the product template
+func ProductReference(productId int64) string {
+	return strconv.FormatInt(productId, 10)
+}
+
+// OrderReference origin of the sale order
+func OrderReference(orderId int64) string {
+	return strconv.FormatInt(orderId, 10)
+}
+
+// ProductValues product.template values of the product, priced from its cheapest variant
+func ProductValues(product odoo.Product) map[string]interface{} {
+	values := map[string]interface{}{
+		"name":         product.ProductName,
+		"default_code": ProductReference(product.ProductID),
+		"type":         "service",
+		"sale_ok":      true,
+		"purchase_ok":  true,
+		"active":       true,
+	}
+
+	description := make([]string, 0, len(product.Variants))
+	for i, variant := range product.Variants {
+		if i == 0 || variant.SalePrice < values["list_price"].(float64) {
+			values["list_price"] = variant.SalePrice
+			values["standard_price"] = variant.SupplierCostPrice
+		}
+		description = append(description, fmt.Sprintf("[%d] %s %.2f %s", variant.VariantID, variant.VariantName, variant.SalePrice, variant.Currency))
+	}
+	values["description_sale"] = strings.Join(description, "\n")
+
+	return values
+}
+
+// OrderValues sale.order header values of the order
+func OrderValues(order odoo.Order, partnerId int64) map[string]interface{} {
+	values := map[string]interface{}{
+		"partner_id":       partnerId,
+		"origin":           OrderReference(order.OrderID),
+		"client_order_ref": order.PartnerOrderID,
+		"note":             fmt.Sprintf("%s / %s, %s", order.Channel, order.Supplier, order.CustomerName),
+	}
+	if bookedAt, err := time.Parse(time.RFC3339, order.BookedAt); err == nil {
+		values["date_order"] = bookedAt.UTC().Format(DateTimeLayout)
+	}
+	return values
+}
+
+// LineName name of the order line of a variant, the variant id in front finds the line again
+func LineName(variant odoo.OrderVariant) string {
+	return fmt.Sprintf("[%d] %s - %s", variant.OrderVariantID, variant.ProductName, variant.VariantName)
+}
+
+// LineValues sale.order.line values of the variant, canceled variants keep their line without quantity
+func LineValues(variant odoo.OrderVariant, productIds map[int64]int64) map[string]interface{} {
+	quantity := variant.Quantity
+	if variant.VariantStatusType == constant.ORDERVARIANTCANCELEDSTATUS {
+		quantity = 0
+	}
+	return map[string]interface{}{
+		"product_id":      productIds[variant.ProductId],
+		"name":            LineName(variant),
+		"product_uom_qty": quantity,
+		"price_unit":      variant.VariantSalePrice,
+	}
+}
+
+// search id of the first record matching the domain, archived ones included
+func (o *Odoo) search(ctx context.Context, model string, domain []interface{}) (int64, error) {
+	var ids []int64
+	kwargs := map[string]interface{}{"limit": 1, "context": map[string]interface{}{"active_test": false}}
+	if err := o.ExecuteKw(ctx, model, "search", []interface{}{domain}, kwargs, &ids); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// save create the record when id is zero, write it otherwise
+func (o *Odoo) save(ctx context.Context, model string, id int64, values map[string]interface{}) (int64, error) {
+	if id == 0 {
+		err := o.ExecuteKw(ctx, model, "create", []interface{}{values}, nil, &id)
+		return id, err
+	}
+	return id, o.ExecuteKw(ctx, model, "write", []interface{}{[]int64{id}, values}, nil, nil)
+}
+
+// UpsertProduct create or update the product template, returns its id and the id of its product
+// variant which order lines refer to. A template id of zero is looked up by the product reference
+func (o *Odoo) UpsertProduct(ctx context.Context, product odoo.Product) (templateId int64, productId int64, err error) {
+	templateId = product.OdooTemplateId
+	if templateId == 0 {
+		domain := []interface{}{[]interface{}{"default_code", "=", ProductReference(product.ProductID)}}
+		if templateId, err = o.search(ctx, ModelProductTemplate, domain); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if templateId, err = o.save(ctx, ModelProductTemplate, templateId, ProductValues(product)); err != nil {
+		return templateId, 0, err
+	}
+
+	var rows []struct {
+		ProductVariantId Many2One `json:"product_variant_id"`
+	}
+	args := []interface{}{[]int64{templateId}}
+	kwargs := map[string]interface{}{"fields": []string{"product_variant_id"}}
+	if err = o.ExecuteKw(ctx, ModelProductTemplate, "read", args, kwargs, &rows); err != nil {
+		return templateId, 0, err
+	}
+	if len(rows) > 0 {
+		productId = int64(rows[0].ProductVariantId)
+	}
+
+	return templateId, productId, nil
+}
+
+// ArchiveProduct archive the template of a deleted product, returns zero when odoo never had it
+func (o *Odoo) ArchiveProduct(ctx context.Context, productId int64, templateId int64) (int64, error) {
+	var err error
+	if templateId == 0 {
+		domain := []interface{}{[]interface{}{"default_code", "=", ProductReference(productId)}}
+		if templateId, err = o.search(ctx, ModelProductTemplate, domain); err != nil || templateId == 0 {
+			return 0, err
+		}
+	}
+
+	return templateId, o.ExecuteKw(ctx, ModelProductTemplate, "write", []interface{}{[]int64{templateId}, map[string]interface{}{"active": false}}, nil, nil)
+}
+
+// UpsertOrder create or update the sale order. Lines are matched to the variants by their name,
+// new variants get a line and lines are never removed. New orders which are booked get confirmed.
+// productIds maps our product ids to the odoo product variant ids
+func (o *Odoo) UpsertOrder(ctx context.Context, order odoo.Order, productIds map[int64]int64) (id int64, err error) {
+	id = order.OdooId
+	if id == 0 {
+		domain := []interface{}{[]interface{}{"origin", "=", OrderReference(order.OrderID)}}
+		if id, err = o.search(ctx, ModelSaleOrder, domain); err != nil {
+			return 0, err
+		}
+	}
+	created := id == 0
+
+	lines, err := o.orderLines(ctx, id)
+	if err != nil {
+		return id, err
+	}
+
+	commands := make([]interface{}, 0, len(order.Variants))
+	for _, variant := range order.Variants {
+		values := LineValues(variant, productIds)
+		if lineId, ok := lines[fmt.Sprintf("[%d]", variant.OrderVariantID)]; ok {
+			commands = append(commands, []interface{}{1, lineId, values})
+		} else {
+			commands = append(commands, []interface{}{0, 0, values})
+		}
+	}
+
+	values := OrderValues(order, o.Settings.PartnerId)
+	values["order_line"] = commands
+	if id, err = o.save(ctx, ModelSaleOrder, id, values); err != nil {
+		return id, err
+	}
+
+	if created && order.BookingStatus == constant.ORDERCOMPLETE {
+		if err = o.ExecuteKw(ctx, ModelSaleOrder, "action_confirm", []interface{}{[]int64{id}}, nil, nil); err != nil {
+			return id, err
+		}
+	}
+
+	return id, nil
+}
+
+// orderLines ids of the lines of the sale order keyed by the variant prefix of their name
+func (o *Odoo) orderLines(ctx context.Context, id int64) (map[string]int64, error) {
+	lines := make(map[string]int64)
+	if id == 0 {
+		return lines, nil
+	}
+
+	var rows []struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	domain := []interface{}{[]interface{}{"order_id", "=", id}}
+	kwargs := map[string]interface{}{"fields": []string{"name"}}
+	if err := o.ExecuteKw(ctx, "sale.order.line", "search_read", []interface{}{domain}, kwargs, &rows); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Id < rows[j].Id })
+	for _, row := range rows {
+		if end := strings.Index(row.Name, "]"); strings.HasPrefix(row.Name, "[") && end > 0 {
+			if _, exists := lines[row.Name[:end+1]]; !exists {
+				lines[row.Name[:end+1]] = row.Id
+			}
+		}
+	}
+	return lines, nil
+}
+
+// CancelOrder cancel the sale order of a canceled or deleted order, returns zero when odoo never had it
+func (o *Odoo) CancelOrder(ctx context.Context, orderId int64, id int64) (int64, error) {
+	var err error
+	if id == 0 {
+		domain := []interface{}{[]interface{}{"origin", "=", OrderReference(orderId)}}
+		if id, err = o.search(ctx, ModelSaleOrder, domain); err != nil || id == 0 {
+			return 0, err
+		}
+	}
+
+	var result interface{}
+	return id, o.ExecuteKw(ctx, ModelSaleOrder, "action_cancel", []interface{}{[]int64{id}}, nil, &result)
+}
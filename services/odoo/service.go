@@ -0,0 +1,77 @@
+package odoo
+
+import (
+	"context"
+	"fmt"
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	"swallow-supplier/utils"
+	"swallow-supplier/utils/client"
+	"time"
+)
+
+// Odoo json-rpc client of the odoo instance finance works in
+type Odoo struct {
+	Service  *client.Request
+	Ctx      context.Context
+	Settings Settings
+
+	uid       int64
+	requestId int64
+}
+
+// Settings odoo instance and the user the records are written as. PartnerId is the customer
+// of the sale orders, the channels are not partners of their own in odoo
+type Settings struct {
+	Host      string
+	DB        string
+	Login     string
+	ApiKey    string
+	PartnerId int64
+}
+
+const (
+	// ServiceName represents the name of the service
+	ServiceName = "Odoo"
+)
+
+// RetryPolicy creates are not idempotent in odoo, only transport errors of reads are retried.
+// A failed push is picked up again by the next run, which finds the record by our reference
+var RetryPolicy client.RetryPolicy = client.BackoffPolicy{
+	Attempts:  3,
+	BaseDelay: 500 * time.Millisecond,
+	MaxDelay:  5 * time.Second,
+	Jitter:    0.3,
+}
+
+// New initialize Odoo from the configuration
+func New(ctx context.Context) (o *Odoo, err error) {
+	cf := config.Instance()
+
+	return NewWithSettings(ctx, Settings{
+		Host:      cf.OdooUrl,
+		DB:        cf.OdooDB,
+		Login:     cf.OdooLogin,
+		ApiKey:    cf.OdooApiKey,
+		PartnerId: cf.OdooPartnerId,
+	})
+}
+
+// NewWithSettings initialize Odoo for the given instance
+func NewWithSettings(ctx context.Context, settings Settings) (o *Odoo, err error) {
+	o = &Odoo{Ctx: ctx, Settings: settings}
+
+	request := client.NewRequest(client.CustomRequest{
+		RequestTimeout: "30s",
+		RetryPolicy:    RetryPolicy,
+	})
+	request.AddHeader("Accept", client.ContentTypeJSON)
+	request.AddHeader("X-MM-Request-ID", utils.GenerateUUID("", true))
+	o.Service = request
+
+	if settings.Host == "" || settings.DB == "" || settings.Login == "" {
+		return o, customError.NewError(ctx, "connection_error", fmt.Sprintf(customError.ErrExternalServiceNotConfigured.Error(), ServiceName), nil)
+	}
+
+	return o, nil
+}
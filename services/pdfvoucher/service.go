@@ -7,6 +7,7 @@ import (
 	customError "swallow-supplier/error"
 	"swallow-supplier/utils"
 	"swallow-supplier/utils/client"
+	"time"
 )
 
 // Trip required fields for accessing Customer Orchestrator
@@ -21,6 +22,16 @@ const (
 	ServiceName = "Voucher_Pdf_Generator"
 )
 
+// RetryPolicy generating the same voucher twice overwrites the pdf, so POSTs are retried
+var RetryPolicy client.RetryPolicy = client.BackoffPolicy{
+	Attempts:             3,
+	BaseDelay:            time.Second,
+	MaxDelay:             15 * time.Second,
+	Jitter:               0.3,
+	RetryNonIdempotent:   true,
+	IdempotencyKeyHeader: client.IdempotencyKeyHeader,
+}
+
 // New initialize Trip
 func New(ctx context.Context) (vp *VoucherPdf, err error) {
 	vp = &VoucherPdf{}
@@ -29,6 +40,7 @@ func New(ctx context.Context) (vp *VoucherPdf, err error) {
 	vp.Service.CustomRequest = client.CustomRequest{
 		RequestTimeout: client.RequestTimeout,
 		Retries:        5,
+		RetryPolicy:    RetryPolicy,
 	}
 
 	request := client.NewRequest(vp.Service.CustomRequest)
@@ -0,0 +1,70 @@
+package suppliers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	customError "swallow-supplier/error"
+	"swallow-supplier/request_response/supplier"
+	travolutionSvc "swallow-supplier/services/suppliers/travolution"
+	yanoljaSvc "swallow-supplier/services/suppliers/yanolja"
+	"swallow-supplier/utils/constant"
+)
+
+// SupplierAdapter common booking pipeline implemented by every supplier client
+type SupplierAdapter interface {
+	// Name supplier name as stored on orders and products
+	Name() string
+	// Products list products or fetch a single product
+	Products(ctx context.Context, req supplier.ProductQuery) (supplier.Response, error)
+	// Inventory fetch availability of a product or variant
+	Inventory(ctx context.Context, req supplier.InventoryQuery) (supplier.Response, error)
+	// Prepare create the order in waiting (pre order) state
+	Prepare(ctx context.Context, req supplier.PrepareRequest) (supplier.Response, error)
+	// Confirm complete a prepared order
+	Confirm(ctx context.Context, req supplier.ConfirmRequest) (supplier.Response, error)
+	// Cancel cancel an order
+	Cancel(ctx context.Context, req supplier.CancelRequest) (supplier.Response, error)
+	// Status current state of an order at the supplier
+	Status(ctx context.Context, req supplier.StatusRequest) (supplier.Response, error)
+}
+
+// Factory builds a SupplierAdapter for a request context
+type Factory func(ctx context.Context) (SupplierAdapter, error)
+
+var registry = map[string]Factory{
+	strings.ToUpper(constant.SUPPLIERYANOLJA): func(ctx context.Context) (SupplierAdapter, error) {
+		y, err := yanoljaSvc.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return y, nil
+	},
+	strings.ToUpper(constant.SUPPLIERTRAVOLUTION): func(ctx context.Context) (SupplierAdapter, error) {
+		t, err := travolutionSvc.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	},
+}
+
+// Register adds or replaces the adapter factory of a supplier
+func Register(name string, factory Factory) {
+	registry[strings.ToUpper(name)] = factory
+}
+
+// IsSupported reports whether an adapter is registered for the supplier
+func IsSupported(name string) bool {
+	_, ok := registry[strings.ToUpper(name)]
+	return ok
+}
+
+// New returns the adapter of the supplier, supplier name is matched case insensitive
+func New(ctx context.Context, name string) (SupplierAdapter, error) {
+	factory, ok := registry[strings.ToUpper(name)]
+	if !ok {
+		return nil, customError.NewError(ctx, "leisure-api-1018", fmt.Sprintf("supplier %q is not supported", name), "suppliers.New")
+	}
+	return factory(ctx)
+}
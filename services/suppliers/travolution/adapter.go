@@ -0,0 +1,99 @@
+package travolution
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	customError "swallow-supplier/error"
+	"swallow-supplier/request_response/supplier"
+	"swallow-supplier/request_response/travolution"
+)
+
+// Name supplier name of the adapter
+func (t *Travolution) Name() string {
+	return ServiceName
+}
+
+// Products list products or fetch a single product when ProductId is set
+func (t *Travolution) Products(ctx context.Context, req supplier.ProductQuery) (res supplier.Response, err error) {
+	productReq := travolution.ProductReq{
+		Take: req.PageSize,
+		Lang: req.Lang,
+	}
+	if req.PageNumber > 0 {
+		productReq.Skip = (req.PageNumber - 1) * req.PageSize
+	}
+	if req.ProductId != "" {
+		productReq.ProductUid, err = parseUid(ctx, "productUid", req.ProductId)
+		if err != nil {
+			return t.adapterResponse(travolution.Response{}), err
+		}
+	}
+
+	resp, err := t.GetProducts(ctx, productReq)
+	return t.adapterResponse(resp), err
+}
+
+// Inventory booking schedules of a product option, travolution has no stock for non schedule products
+func (t *Travolution) Inventory(ctx context.Context, req supplier.InventoryQuery) (res supplier.Response, err error) {
+	productUid, err := parseUid(ctx, "productUid", req.ProductId)
+	if err != nil {
+		return t.adapterResponse(travolution.Response{}), err
+	}
+
+	resp, err := t.GetBookingSchedules(ctx, travolution.BookingScheduleReq{
+		ProductUid: productUid,
+		OptionUid:  req.VariantId,
+		Date:       req.DateStart,
+		Time:       req.Time,
+	})
+	return t.adapterResponse(resp), err
+}
+
+// Prepare places the order, travolution has no separate waiting state
+func (t *Travolution) Prepare(ctx context.Context, req supplier.PrepareRequest) (res supplier.Response, err error) {
+	resp, err := t.TravolutionOrder(ctx, req.Travolution, req.BookingType)
+	return t.adapterResponse(resp), err
+}
+
+// Confirm orders are confirmed on creation, the current order is returned
+func (t *Travolution) Confirm(ctx context.Context, req supplier.ConfirmRequest) (res supplier.Response, err error) {
+	resp, err := t.TravolutionGetOrder(ctx, req.OrderId)
+	return t.adapterResponse(resp), err
+}
+
+// Cancel cancel order by orderNumber, the other cancel types end in the same call and partial
+// cancellations are not supported
+func (t *Travolution) Cancel(ctx context.Context, req supplier.CancelRequest) (res supplier.Response, err error) {
+	if req.Type == supplier.CancelPartial {
+		return t.adapterResponse(travolution.Response{}), customError.NewError(ctx, "leisure-api-0006", fmt.Sprintf("order %s can only be canceled in full", req.OrderId), ServiceName)
+	}
+	resp, err := t.TravolutionCancelOrder(ctx, req.OrderId)
+	return t.adapterResponse(resp), err
+}
+
+// Status fetch order by orderNumber
+func (t *Travolution) Status(ctx context.Context, req supplier.StatusRequest) (res supplier.Response, err error) {
+	resp, err := t.TravolutionGetOrder(ctx, req.OrderId)
+	return t.adapterResponse(resp), err
+}
+
+// adapterResponse converts travolution response into supplier response
+func (t *Travolution) adapterResponse(resp travolution.Response) supplier.Response {
+	return supplier.Response{
+		Supplier:    ServiceName,
+		Code:        resp.Code,
+		Body:        resp.Body,
+		ContentType: resp.HtmlTypeContent,
+		Contents:    resp.Contents,
+	}
+}
+
+// parseUid parse numeric travolution uid
+func parseUid(ctx context.Context, field string, value string) (int, error) {
+	uid, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, customError.NewError(ctx, "leisure-api-1016", fmt.Sprintf("invalid %s %q, %v", field, value, err), ServiceName)
+	}
+	return uid, nil
+}
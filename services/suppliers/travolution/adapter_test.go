@@ -0,0 +1,85 @@
+package travolution_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"swallow-supplier/config"
+	"swallow-supplier/request_response/supplier"
+	travolutionSvc "swallow-supplier/services/suppliers/travolution"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTravolution travolution api answering the adapter calls, requests are kept by method and path
+func stubTravolution(t *testing.T) (*travolutionSvc.Travolution, map[string]string) {
+	t.Helper()
+	requests := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests[r.Method+" "+r.URL.Path] = string(body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/partner/v1.1/products/1200":
+			w.Write([]byte(`{"uid":1200,"type":"BK"}`))
+		case "GET /api/partner/v1.1/orders/TRV-1":
+			w.Write([]byte(`{"orderNumber":"TRV-1","status":"CONFIRMED"}`))
+		case "DELETE /api/partner/v1.1/orders/":
+			w.Write([]byte(`{"orderNumber":"TRV-1","status":"CANCELED"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	config.SetInstance(&config.AppConfig{TravolutionDomain: server.URL})
+	tr, err := travolutionSvc.New(context.Background())
+	require.NoError(t, err)
+	return tr, requests
+}
+
+func TestAdapterResponse(t *testing.T) {
+	ctx := context.Background()
+	tr, _ := stubTravolution(t)
+
+	// Test case 1: single product
+	res, err := tr.Products(ctx, supplier.ProductQuery{ProductId: "1200"})
+	require.NoError(t, err)
+	assert.Equal(t, travolutionSvc.ServiceName, res.Supplier)
+	assert.Equal(t, "200", res.Code)
+	assert.Equal(t, "BK", res.Body.(map[string]interface{})["type"])
+
+	// Test case 2: orders are confirmed on creation, confirm and status read the order
+	for _, call := range []func() (supplier.Response, error){
+		func() (supplier.Response, error) { return tr.Confirm(ctx, supplier.ConfirmRequest{OrderId: "TRV-1"}) },
+		func() (supplier.Response, error) { return tr.Status(ctx, supplier.StatusRequest{OrderId: "TRV-1"}) },
+	} {
+		res, err = call()
+		require.NoError(t, err)
+		assert.Equal(t, "CONFIRMED", res.Body.(map[string]interface{})["status"])
+	}
+
+	// Test case 3: uids which are not numbers never reach travolution
+	_, err = tr.Products(ctx, supplier.ProductQuery{ProductId: "product-1200"})
+	assert.Error(t, err)
+}
+
+func TestAdapterCancel(t *testing.T) {
+	ctx := context.Background()
+	tr, requests := stubTravolution(t)
+
+	// Test case 1: the order is canceled by its order number
+	res, err := tr.Cancel(ctx, supplier.CancelRequest{OrderId: "TRV-1", Type: supplier.CancelFull})
+	require.NoError(t, err)
+	assert.Equal(t, "CANCELED", res.Body.(map[string]interface{})["status"])
+	assert.Contains(t, requests["DELETE /api/partner/v1.1/orders/"], "TRV-1")
+
+	// Test case 2: partial cancellations are refused without a call
+	_, err = tr.Cancel(ctx, supplier.CancelRequest{OrderId: "TRV-1", Type: supplier.CancelPartial, OrderVariantIds: []int64{1}})
+	assert.Error(t, err)
+	assert.Len(t, requests, 1)
+}
@@ -6,6 +6,7 @@ import (
 	"swallow-supplier/config"
 	customError "swallow-supplier/error"
 	"swallow-supplier/utils/client"
+	"time"
 )
 
 // Yanolja required fields for accessing services of yanolja
@@ -21,6 +22,16 @@ const (
 	ServiceName = "Travolution"
 )
 
+// RetryPolicy travolution order creation is not idempotent, only reads are retried. Known gap: no
+// idempotency key is sent, a POST which times out after travolution handled it is not retried and
+// has to be reconciled from the order status
+var RetryPolicy client.RetryPolicy = client.BackoffPolicy{
+	Attempts:  4,
+	BaseDelay: 500 * time.Millisecond,
+	MaxDelay:  10 * time.Second,
+	Jitter:    0.3,
+}
+
 // New initialize Yanolja
 func New(ctx context.Context) (t *Travolution, err error) {
 	t = &Travolution{}
@@ -30,6 +41,7 @@ func New(ctx context.Context) (t *Travolution, err error) {
 	t.Service.CustomRequest = client.CustomRequest{
 		RequestTimeout: client.RequestTimeout,
 		Retries:        5,
+		RetryPolicy:    RetryPolicy,
 	}
 
 	request := client.NewRequest(t.Service.CustomRequest)
@@ -0,0 +1,132 @@
+package yanolja
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	customError "swallow-supplier/error"
+	"swallow-supplier/request_response/supplier"
+	"swallow-supplier/request_response/yanolja"
+)
+
+// Name supplier name of the adapter
+func (y *Yanolja) Name() string {
+	return ServiceName
+}
+
+// Products list products or fetch a single product when ProductId is set
+func (y *Yanolja) Products(ctx context.Context, req supplier.ProductQuery) (res supplier.Response, err error) {
+	if req.ProductId != "" {
+		productId, err := parseId(ctx, "productId", req.ProductId)
+		if err != nil {
+			return y.adapterResponse(yanolja.Response{}), err
+		}
+		resp, err := y.GetProductByProductId(ctx, productId)
+		return y.adapterResponse(resp), err
+	}
+
+	resp, err := y.GetProduct(ctx, yanolja.AllProduct{
+		PageNumber:        int32(req.PageNumber),
+		PageSize:          int32(req.PageSize),
+		ProductStatusCode: req.StatusCode,
+	})
+	return y.adapterResponse(resp), err
+}
+
+// Inventory variant inventory when VariantId is set otherwise the product inventory for the date range
+func (y *Yanolja) Inventory(ctx context.Context, req supplier.InventoryQuery) (res supplier.Response, err error) {
+	if req.VariantId != "" {
+		resp, err := y.GetProductVariantInventory(ctx, yanolja.VariantInventory{
+			VariantId: req.VariantId,
+			Date:      req.DateStart,
+			Time:      req.Time,
+		})
+		return y.adapterResponse(resp), err
+	}
+
+	resp, err := y.GetProductsInventories(ctx, yanolja.ProductInventory{
+		ProductId:          req.ProductId,
+		InventoryDateStart: req.DateStart,
+		InventoryDateEnd:   req.DateEnd,
+	})
+	return y.adapterResponse(resp), err
+}
+
+// Prepare create order in waiting state
+func (y *Yanolja) Prepare(ctx context.Context, req supplier.PrepareRequest) (res supplier.Response, err error) {
+	resp, err := y.WaitingForOrder(ctx, req.Yanolja)
+	return y.adapterResponse(resp), err
+}
+
+// Confirm complete the waiting order
+func (y *Yanolja) Confirm(ctx context.Context, req supplier.ConfirmRequest) (res supplier.Response, err error) {
+	orderId, err := parseId(ctx, "orderId", req.OrderId)
+	if err != nil {
+		return y.adapterResponse(yanolja.Response{}), err
+	}
+
+	resp, err := y.OrderComplete(ctx, yanolja.OrderConfirmation{
+		OrderId:        orderId,
+		PartnerOrderId: req.PartnerOrderId,
+	})
+	return y.adapterResponse(resp), err
+}
+
+// Cancel full, timeout or forced cancellation of the order, yanolja has no api to cancel some
+// variants of an order so partial cancellations are not supported
+func (y *Yanolja) Cancel(ctx context.Context, req supplier.CancelRequest) (res supplier.Response, err error) {
+	var resp yanolja.Response
+
+	switch req.Type {
+	case supplier.CancelTimeout:
+		resp, err = y.TimeoutAndForcedOrderCancellation(ctx, req.PartnerOrderId)
+	case supplier.CancelForced:
+		resp, err = y.ForcedOrderCancellation(ctx, req.PartnerOrderId)
+	case supplier.CancelPartial:
+		return y.adapterResponse(resp), customError.NewError(ctx, "leisure-api-0006", fmt.Sprintf("order %s can only be canceled in full", req.OrderId), ServiceName)
+	default:
+		orderId, perr := parseId(ctx, "orderId", req.OrderId)
+		if perr != nil {
+			return y.adapterResponse(resp), perr
+		}
+		resp, err = y.CancelFullOrder(ctx, orderId)
+	}
+
+	return y.adapterResponse(resp), err
+}
+
+// Status fetch order from yanolja
+func (y *Yanolja) Status(ctx context.Context, req supplier.StatusRequest) (res supplier.Response, err error) {
+	orderId, err := parseId(ctx, "orderId", req.OrderId)
+	if err != nil {
+		return y.adapterResponse(yanolja.Response{}), err
+	}
+
+	resp, err := y.GetOrderbyId(ctx, yanolja.OrderConfirmation{
+		OrderId:        orderId,
+		PartnerOrderId: req.PartnerOrderId,
+	})
+	return y.adapterResponse(resp), err
+}
+
+// adapterResponse converts yanolja response into supplier response
+func (y *Yanolja) adapterResponse(resp yanolja.Response) supplier.Response {
+	return supplier.Response{
+		Supplier:    ServiceName,
+		Code:        resp.Code,
+		TraceID:     resp.TraceID,
+		Body:        resp.Body,
+		Page:        resp.Page,
+		Collection:  resp.Collection,
+		ContentType: resp.ContentType,
+	}
+}
+
+// parseId parse numeric yanolja id
+func parseId(ctx context.Context, field string, value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, customError.NewError(ctx, "leisure-api-1016", fmt.Sprintf("invalid %s %q, %v", field, value, err), ServiceName)
+	}
+	return id, nil
+}
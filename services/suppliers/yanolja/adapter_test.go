@@ -0,0 +1,88 @@
+package yanolja_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"swallow-supplier/config"
+	"swallow-supplier/request_response/supplier"
+	yanoljaSvc "swallow-supplier/services/suppliers/yanolja"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubYanolja yanolja api answering the adapter calls, requests are kept by method and path
+func stubYanolja(t *testing.T) (*yanoljaSvc.Yanolja, map[string]string) {
+	t.Helper()
+	requests := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests[r.Method+" "+r.URL.Path] = string(body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/products":
+			w.Write([]byte(`{"code":"200","traceId":"trace-products","body":[{"productId":1001}],"page":{"number":2,"size":1,"totalPageCount":5,"totalElementCount":5},"collection":true}`))
+		case "GET /v1/orders/5001":
+			w.Write([]byte(`{"code":"200","traceId":"trace-status","body":{"orderId":5001,"orderStatusCode":"CONFIRMED"}}`))
+		case "POST /v1/orders/5001/full-cancel":
+			w.Write([]byte(`{"code":"200","traceId":"trace-cancel","body":{"cancelStatusCode":"DIRECT"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	config.SetInstance(&config.AppConfig{YanoljaDomain: server.URL})
+	y, err := yanoljaSvc.New(context.Background())
+	require.NoError(t, err)
+	return y, requests
+}
+
+func TestAdapterResponse(t *testing.T) {
+	ctx := context.Background()
+	y, _ := stubYanolja(t)
+
+	// Test case 1: every field of the yanolja response is kept
+	res, err := y.Products(ctx, supplier.ProductQuery{PageNumber: 2, PageSize: 1})
+	require.NoError(t, err)
+	assert.Equal(t, yanoljaSvc.ServiceName, res.Supplier)
+	assert.Equal(t, "200", res.Code)
+	assert.Equal(t, "trace-products", res.TraceID)
+	assert.Equal(t, 2, res.Page.Number)
+	assert.Equal(t, 5, res.Page.TotalElementCount)
+	assert.True(t, res.Collection)
+	assert.Len(t, res.Body, 1)
+
+	// Test case 2: order status
+	res, err = y.Status(ctx, supplier.StatusRequest{OrderId: "5001", PartnerOrderId: "P-5001"})
+	require.NoError(t, err)
+	assert.Equal(t, "trace-status", res.TraceID)
+	assert.Equal(t, "CONFIRMED", res.Body.(map[string]interface{})["orderStatusCode"])
+}
+
+func TestAdapterCancel(t *testing.T) {
+	ctx := context.Background()
+	y, requests := stubYanolja(t)
+
+	// Test case 1: the order is canceled in full by its order id
+	res, err := y.Cancel(ctx, supplier.CancelRequest{OrderId: "5001", Type: supplier.CancelFull})
+	require.NoError(t, err)
+	assert.Equal(t, "trace-cancel", res.TraceID)
+	assert.Equal(t, "DIRECT", res.Body.(map[string]interface{})["cancelStatusCode"])
+	assert.Contains(t, requests, "POST /v1/orders/5001/full-cancel")
+
+	// Test case 2: partial cancellations are refused without a call
+	_, err = y.Cancel(ctx, supplier.CancelRequest{OrderId: "5001", Type: supplier.CancelPartial, OrderVariantIds: []int64{1, 3}})
+	assert.Error(t, err)
+
+	// Test case 3: ids which are not numbers never reach yanolja
+	_, err = y.Cancel(ctx, supplier.CancelRequest{OrderId: "order-5001", Type: supplier.CancelFull})
+	assert.Error(t, err)
+	_, err = y.Status(ctx, supplier.StatusRequest{OrderId: ""})
+	assert.Error(t, err)
+	assert.Len(t, requests, 1)
+}
@@ -212,6 +212,11 @@ func (y *Yanolja) GetProductVariantInventory(ctx context.Context, req yanolja.Va
 	}
 
 	urls := fmt.Sprintf(`/v1/products/-/variants/%d/inventory`, variantid)
+	if req.Date != "" && req.Time != "" {
+		urls = fmt.Sprintf(`/v1/products/-/variants/%d/inventory?date=%s&time=%s`, variantid, req.Date, req.Time)
+	} else if req.Date != "" {
+		urls = fmt.Sprintf(`/v1/products/-/variants/%d/inventory?date=%s`, variantid, req.Date)
+	}
 	level.Info(logger).Log("info", "url for get request ", urls)
 
 	response, err := y.Service.Send(
@@ -6,6 +6,7 @@ import (
 	"swallow-supplier/config"
 	customError "swallow-supplier/error"
 	"swallow-supplier/utils/client"
+	"time"
 )
 
 // Yanolja required fields for accessing services of yanolja
@@ -21,6 +22,16 @@ const (
 	ServiceName = "Yanolja"
 )
 
+// RetryPolicy order calls (prepare, confirm, cancel) are POSTs yanolja does not deduplicate,
+// so only reads are retried. Known gap: no idempotency key is sent, a POST which times out after
+// yanolja handled it is not retried and has to be reconciled from the order status
+var RetryPolicy client.RetryPolicy = client.BackoffPolicy{
+	Attempts:  4,
+	BaseDelay: 500 * time.Millisecond,
+	MaxDelay:  10 * time.Second,
+	Jitter:    0.3,
+}
+
 // New initialize Yanolja
 func New(ctx context.Context) (c *Yanolja, err error) {
 	c = &Yanolja{}
@@ -30,6 +41,7 @@ func New(ctx context.Context) (c *Yanolja, err error) {
 	c.Service.CustomRequest = client.CustomRequest{
 		RequestTimeout: client.RequestTimeout,
 		Retries:        5,
+		RetryPolicy:    RetryPolicy,
 	}
 
 	request := client.NewRequest(c.Service.CustomRequest)
@@ -5,12 +5,15 @@ import (
 	"fmt"
 
 	svc "swallow-supplier/iface"
+	pricing_domain "swallow-supplier/mongo/domain/pricing"
+	refund_domain "swallow-supplier/mongo/domain/refund"
 	travolution_domain "swallow-supplier/mongo/domain/travolution"
 	domain "swallow-supplier/mongo/domain/yanolja"
 	"swallow-supplier/request_response/common"
 	"swallow-supplier/request_response/travolution"
 	"swallow-supplier/request_response/trip"
 	"swallow-supplier/request_response/yanolja"
+	"swallow-supplier/utils/pagination"
 
 	"github.com/go-kit/kit/endpoint"
 )
@@ -27,6 +30,8 @@ type Endpoints struct {
 	GetVariantInventory            endpoint.Endpoint
 	GetCategories                  endpoint.Endpoint
 	GetRegions                     endpoint.Endpoint
+	GetStoredCategories            endpoint.Endpoint
+	GetStoredRegions               endpoint.Endpoint
 	PostWaitForOrder               endpoint.Endpoint
 	PostOrderConfirmation          endpoint.Endpoint
 	GetOrderByOrderId              endpoint.Endpoint
@@ -91,6 +96,66 @@ type Endpoints struct {
 	GetSearchTravolutionOrder     endpoint.Endpoint
 	PostCancelTravolutionOrder    endpoint.Endpoint
 	PostTravolutionWebHook        endpoint.Endpoint
+
+	// Notification outbox
+	GetNotificationOutbox  endpoint.Endpoint
+	PostReplayNotification endpoint.Endpoint
+
+	// Circuit breaker
+	GetCircuitBreakers endpoint.Endpoint
+	PostCircuitBreaker endpoint.Endpoint
+
+	// Pricing rules
+	GetPricingRules   endpoint.Endpoint
+	GetPricingRule    endpoint.Endpoint
+	PostPricingRule   endpoint.Endpoint
+	PutPricingRule    endpoint.Endpoint
+	DeletePricingRule endpoint.Endpoint
+
+	// Refund policies
+	GetRefundPolicy    endpoint.Endpoint
+	PutRefundPolicy    endpoint.Endpoint
+	DeleteRefundPolicy endpoint.Endpoint
+
+	GetVariantAvailability endpoint.Endpoint
+
+	// Api clients
+	GetApiClients       endpoint.Endpoint
+	PostApiClient       endpoint.Endpoint
+	PostRotateApiKey    endpoint.Endpoint
+	PostRevokeApiClient endpoint.Endpoint
+
+	// Jobs
+	GetJobs        endpoint.Endpoint
+	GetJobRuns     endpoint.Endpoint
+	PostTriggerJob endpoint.Endpoint
+	PostJobPaused  endpoint.Endpoint
+	PutJobSchedule endpoint.Endpoint
+
+	// odoo sync
+	GetOdooSyncChanges endpoint.Endpoint
+	PostOdooSyncAck    endpoint.Endpoint
+
+	// reconciliation reports
+	PostReconciliationReport   endpoint.Endpoint
+	GetReconciliationReports   endpoint.Endpoint
+	GetReconciliationReport    endpoint.Endpoint
+	ExportReconciliationReport endpoint.Endpoint
+
+	// Category mapping
+	GetCategoryMappings         endpoint.Endpoint
+	GetCategoryMapping          endpoint.Endpoint
+	CreateCategoryMapping       endpoint.Endpoint
+	UpdateCategoryMapping       endpoint.Endpoint
+	DeleteCategoryMapping       endpoint.Endpoint
+	UploadCategoryMappings      endpoint.Endpoint
+	ExportCategoryMappings      endpoint.Endpoint
+	GetUnmappedCategoryProducts endpoint.Endpoint
+
+	// Product versions
+	GetProductVersions  endpoint.Endpoint
+	GetProductVersion   endpoint.Endpoint
+	DiffProductVersions endpoint.Endpoint
 }
 
 // MakeEndpoints initializes all Go kit endpoints for the boilerplate.
@@ -105,6 +170,8 @@ func MakeEndpoints(s svc.Service) Endpoints {
 		GetVariantInventory:            makeGetVariantInventoryEndpoints(s),
 		GetCategories:                  makeGetCategoriesEndpoints(s),
 		GetRegions:                     makeGetRegionsEndpoints(s),
+		GetStoredCategories:            makeGetStoredCategoriesEndpoints(s),
+		GetStoredRegions:               makeGetStoredRegionsEndpoints(s),
 		PostWaitForOrder:               makePostWaitForOrderEndpoints(s),
 		PostOrderConfirmation:          makePostOrderConfirmationEndpoints(s),
 		GetOrderByOrderId:              makeGetOrderByOrderIdEndpoints(s),
@@ -168,6 +235,66 @@ func MakeEndpoints(s svc.Service) Endpoints {
 		GetSearchTravolutionOrder:     makeGetSearchTravolutionOrderEndpoint(s),
 		PostCancelTravolutionOrder:    makePostCancelTravolutionOrderEndpoint(s),
 		PostTravolutionWebHook:        makePostTravolutionWebHookEndpoint(s),
+
+		// Notification outbox
+		GetNotificationOutbox:  makeGetNotificationOutboxEndpoint(s),
+		PostReplayNotification: makePostReplayNotificationEndpoint(s),
+
+		// Circuit breaker
+		GetCircuitBreakers: makeGetCircuitBreakersEndpoint(s),
+		PostCircuitBreaker: makePostCircuitBreakerEndpoint(s),
+
+		// Pricing rules
+		GetPricingRules:   makeGetPricingRulesEndpoint(s),
+		GetPricingRule:    makeGetPricingRuleEndpoint(s),
+		PostPricingRule:   makePostPricingRuleEndpoint(s),
+		PutPricingRule:    makePutPricingRuleEndpoint(s),
+		DeletePricingRule: makeDeletePricingRuleEndpoint(s),
+
+		// Refund policies
+		GetRefundPolicy:    makeGetRefundPolicyEndpoint(s),
+		PutRefundPolicy:    makePutRefundPolicyEndpoint(s),
+		DeleteRefundPolicy: makeDeleteRefundPolicyEndpoint(s),
+
+		GetVariantAvailability: makeGetVariantAvailabilityEndpoint(s),
+
+		// Api clients
+		GetApiClients:       makeGetApiClientsEndpoint(s),
+		PostApiClient:       makePostApiClientEndpoint(s),
+		PostRotateApiKey:    makePostRotateApiKeyEndpoint(s),
+		PostRevokeApiClient: makePostRevokeApiClientEndpoint(s),
+
+		// Jobs
+		GetJobs:        makeGetJobsEndpoint(s),
+		GetJobRuns:     makeGetJobRunsEndpoint(s),
+		PostTriggerJob: makePostTriggerJobEndpoint(s),
+		PostJobPaused:  makePostJobPausedEndpoint(s),
+		PutJobSchedule: makePutJobScheduleEndpoint(s),
+
+		// odoo sync
+		GetOdooSyncChanges: makeGetOdooSyncChangesEndpoint(s),
+		PostOdooSyncAck:    makePostOdooSyncAckEndpoint(s),
+
+		// reconciliation reports
+		PostReconciliationReport:   makePostReconciliationReportEndpoint(s),
+		GetReconciliationReports:   makeGetReconciliationReportsEndpoint(s),
+		GetReconciliationReport:    makeGetReconciliationReportEndpoint(s),
+		ExportReconciliationReport: makeExportReconciliationReportEndpoint(s),
+
+		// Category mapping
+		GetCategoryMappings:         makeGetCategoryMappingsEndpoint(s),
+		GetCategoryMapping:          makeGetCategoryMappingEndpoint(s),
+		CreateCategoryMapping:       makeCreateCategoryMappingEndpoint(s),
+		UpdateCategoryMapping:       makeUpdateCategoryMappingEndpoint(s),
+		DeleteCategoryMapping:       makeDeleteCategoryMappingEndpoint(s),
+		UploadCategoryMappings:      makeUploadCategoryMappingsEndpoint(s),
+		ExportCategoryMappings:      makeExportCategoryMappingsEndpoint(s),
+		GetUnmappedCategoryProducts: makeGetUnmappedCategoryProductsEndpoint(s),
+
+		// Product versions
+		GetProductVersions:  makeGetProductVersionsEndpoint(s),
+		GetProductVersion:   makeGetProductVersionEndpoint(s),
+		DiffProductVersions: makeDiffProductVersionsEndpoint(s),
 	}
 
 }
@@ -236,6 +363,22 @@ func makeGetRegionsEndpoints(s svc.Service) endpoint.Endpoint {
 	}
 }
 
+func makeGetStoredCategoriesEndpoints(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		q := request.(pagination.Query)
+		res, err := s.GetStoredCategories(ctx, q)
+		return res, err
+	}
+}
+
+func makeGetStoredRegionsEndpoints(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		q := request.(pagination.Query)
+		res, err := s.GetStoredRegions(ctx, q)
+		return res, err
+	}
+}
+
 func makePostWaitForOrderEndpoints(s svc.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(yanolja.WaitingForOrder)
@@ -393,7 +536,8 @@ func makePPostRegionsEndpoints(s svc.Service) endpoint.Endpoint {
 
 func makeGetRedisDataEndpoints(s svc.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		res, err := s.GetRedisData(ctx)
+		q := request.(pagination.Query)
+		res, err := s.GetRedisData(ctx, q)
 		return res, err
 	}
 }
@@ -667,7 +811,8 @@ func makeGetOrderByPartnerOrderIdSuffixEndpoints(s svc.Service) endpoint.Endpoin
 
 func makeGetProductsFromGGTEndpoints(s svc.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		res, err := s.GetProductsFromGGT(ctx)
+		q := request.(pagination.Query)
+		res, err := s.GetProductsFromGGT(ctx, q)
 		return res, err
 	}
 
@@ -713,3 +858,317 @@ func makePostTravolutionWebHookEndpoint(s svc.Service) endpoint.Endpoint {
 		return res, err
 	}
 }
+
+// Notification outbox
+func makeGetNotificationOutboxEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.NotificationOutboxRequest)
+		res, err := s.GetNotificationOutbox(ctx, req)
+		return res, err
+	}
+}
+
+func makePostReplayNotificationEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		res, err := s.ReplayNotificationOutbox(ctx, id)
+		return res, err
+	}
+}
+
+// Circuit breaker
+func makeGetCircuitBreakersEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		res, err := s.GetCircuitBreakers(ctx)
+		return res, err
+	}
+}
+
+func makePostCircuitBreakerEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.CircuitBreakerRequest)
+		res, err := s.UpdateCircuitBreaker(ctx, req)
+		return res, err
+	}
+}
+
+// Pricing rules
+func makeGetPricingRulesEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.PricingRuleRequest)
+		res, err := s.GetPricingRules(ctx, req)
+		return res, err
+	}
+}
+
+func makeGetPricingRuleEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		res, err := s.GetPricingRule(ctx, id)
+		return res, err
+	}
+}
+
+func makePostPricingRuleEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		rule := request.(pricing_domain.Rule)
+		res, err := s.CreatePricingRule(ctx, rule)
+		return res, err
+	}
+}
+
+func makePutPricingRuleEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		rule := request.(pricing_domain.Rule)
+		res, err := s.UpdatePricingRule(ctx, rule)
+		return res, err
+	}
+}
+
+func makeDeletePricingRuleEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		res, err := s.DeletePricingRule(ctx, id)
+		return res, err
+	}
+}
+
+// Refund policies
+func makeGetRefundPolicyEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.RefundPolicyRequest)
+		res, err := s.GetRefundPolicy(ctx, req)
+		return res, err
+	}
+}
+
+func makePutRefundPolicyEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		policy := request.(refund_domain.Policy)
+		res, err := s.PutRefundPolicy(ctx, policy)
+		return res, err
+	}
+}
+
+func makeDeleteRefundPolicyEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		variantId := request.(int64)
+		res, err := s.DeleteRefundPolicy(ctx, variantId)
+		return res, err
+	}
+}
+
+// Availability
+func makeGetVariantAvailabilityEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.AvailabilityRequest)
+		res, err := s.GetVariantAvailability(ctx, req)
+		return res, err
+	}
+}
+
+// Api clients
+func makeGetApiClientsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		res, err := s.GetApiClients(ctx)
+		return res, err
+	}
+}
+
+func makePostApiClientEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ApiClientRequest)
+		res, err := s.IssueApiClient(ctx, req)
+		return res, err
+	}
+}
+
+func makePostRotateApiKeyEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ApiClientRequest)
+		res, err := s.RotateApiClientKey(ctx, req)
+		return res, err
+	}
+}
+
+func makePostRevokeApiClientEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		res, err := s.RevokeApiClient(ctx, id)
+		return res, err
+	}
+}
+
+func makeGetJobsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		res, err := s.GetJobs(ctx)
+		return res, err
+	}
+}
+
+func makeGetJobRunsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.JobRequest)
+		res, err := s.GetJobRuns(ctx, req)
+		return res, err
+	}
+}
+
+func makePostTriggerJobEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.JobRequest)
+		res, err := s.TriggerJob(ctx, req)
+		return res, err
+	}
+}
+
+func makePostJobPausedEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.JobRequest)
+		res, err := s.SetJobPaused(ctx, req)
+		return res, err
+	}
+}
+
+func makePutJobScheduleEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.JobRequest)
+		res, err := s.UpdateJobSchedule(ctx, req)
+		return res, err
+	}
+}
+
+func makeGetOdooSyncChangesEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.OdooSyncRequest)
+		res, err := s.GetOdooSyncChanges(ctx, req)
+		return res, err
+	}
+}
+
+func makePostOdooSyncAckEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.OdooSyncRequest)
+		res, err := s.PostOdooSyncAck(ctx, req)
+		return res, err
+	}
+}
+
+func makePostReconciliationReportEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ReconciliationReportRequest)
+		res, err := s.GenerateReconciliationReport(ctx, req)
+		return res, err
+	}
+}
+
+func makeGetReconciliationReportsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ReconciliationReportRequest)
+		res, err := s.GetReconciliationReports(ctx, req)
+		return res, err
+	}
+}
+
+func makeGetReconciliationReportEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ReconciliationReportRequest)
+		res, err := s.GetReconciliationReport(ctx, req)
+		return res, err
+	}
+}
+
+func makeExportReconciliationReportEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ReconciliationReportRequest)
+		res, err := s.ExportReconciliationReport(ctx, req)
+		return res, err
+	}
+}
+
+func makeGetCategoryMappingsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.CategoryMappingRequest)
+		res, err := s.GetCategoryMappings(ctx, req)
+		return res, err
+	}
+}
+
+func makeGetCategoryMappingEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.CategoryMappingRequest)
+		res, err := s.GetCategoryMapping(ctx, req)
+		return res, err
+	}
+}
+
+func makeCreateCategoryMappingEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.CategoryMappingRequest)
+		res, err := s.CreateCategoryMapping(ctx, req)
+		return res, err
+	}
+}
+
+func makeUpdateCategoryMappingEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.CategoryMappingRequest)
+		res, err := s.UpdateCategoryMapping(ctx, req)
+		return res, err
+	}
+}
+
+func makeDeleteCategoryMappingEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.CategoryMappingRequest)
+		res, err := s.DeleteCategoryMapping(ctx, req)
+		return res, err
+	}
+}
+
+func makeUploadCategoryMappingsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.CategoryMappingUploadRequest)
+		res, err := s.UploadCategoryMappings(ctx, req)
+		return res, err
+	}
+}
+
+func makeExportCategoryMappingsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.CategoryMappingRequest)
+		res, err := s.ExportCategoryMappings(ctx, req)
+		return res, err
+	}
+}
+
+func makeGetUnmappedCategoryProductsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		res, err := s.GetUnmappedCategoryProducts(ctx)
+		return res, err
+	}
+}
+
+func makeGetProductVersionsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ProductVersionRequest)
+		res, err := s.GetProductVersions(ctx, req)
+		return res, err
+	}
+}
+
+func makeGetProductVersionEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ProductVersionRequest)
+		res, err := s.GetProductVersion(ctx, req)
+		return res, err
+	}
+}
+
+func makeDiffProductVersionsEndpoint(s svc.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(common.ProductVersionRequest)
+		res, err := s.DiffProductVersions(ctx, req)
+		return res, err
+	}
+}
@@ -5,20 +5,28 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
 
+	"swallow-supplier/config"
 	customContext "swallow-supplier/context"
 	customError "swallow-supplier/error"
 	svc "swallow-supplier/iface"
 	"swallow-supplier/middleware"
+	"swallow-supplier/mongo/domain/odoo"
+	pricing_domain "swallow-supplier/mongo/domain/pricing"
+	"swallow-supplier/mongo/domain/reconciliation"
+	refund_domain "swallow-supplier/mongo/domain/refund"
 	travolution_domain "swallow-supplier/mongo/domain/travolution"
 	domain "swallow-supplier/mongo/domain/yanolja"
 
@@ -28,6 +36,10 @@ import (
 	"swallow-supplier/request_response/yanolja"
 	"swallow-supplier/transport"
 	"swallow-supplier/utils"
+	"swallow-supplier/utils/client"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/envelope"
+	"swallow-supplier/utils/pagination"
 )
 
 // NewTransport set-up the router and initialize the http endpoints
@@ -125,6 +137,20 @@ func NewTransport(
 		options...,
 	)
 
+	getStoredCategories := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetStoredCategories),
+		decodeGetStoredCategories,
+		encodeResponse,
+		options...,
+	)
+
+	getStoredRegions := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetStoredRegions),
+		decodeGetStoredRegions,
+		encodeResponse,
+		options...,
+	)
+
 	postWaitForOrder := kithttp.NewServer(
 		svcEndpoints.PostWaitForOrder,
 		decodePostWaitForOrder,
@@ -254,14 +280,14 @@ func NewTransport(
 
 	postCancellationAckClbkHandler := kithttp.NewServer(
 		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.CancellationAckClbk),
-		decodepostCancellationAckClbk,
+		signedDecoder(mongoRepo, constant.SUPPLIERYANOLJA, decodepostCancellationAckClbk),
 		encodeResponse,
 		options...,
 	)
 
 	postRefusalToCancelClbkHandler := kithttp.NewServer(
 		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.RefusalToCancelClbk),
-		decodePostRefusalToCancelClbk,
+		signedDecoder(mongoRepo, constant.SUPPLIERYANOLJA, decodePostRefusalToCancelClbk),
 		encodeResponse,
 		options...,
 	)
@@ -275,33 +301,33 @@ func NewTransport(
 
 	postForcedOrderCancellationClbkHandler := kithttp.NewServer(
 		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.ForcedOrderCancellationClbk),
-		decodePostForcedOrderCancellationClbk,
+		signedDecoder(mongoRepo, constant.SUPPLIERYANOLJA, decodePostForcedOrderCancellationClbk),
 		encodeResponse,
 		options...,
 	)
 
 	postIndividualVoucherUpdateClbkHandler := kithttp.NewServer(
 		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.IndividualVoucherUpdateClbk),
-		decodePostIndividualVoucherUpdateClbk,
+		signedDecoder(mongoRepo, constant.SUPPLIERYANOLJA, decodePostIndividualVoucherUpdateClbk),
 		encodeResponse,
 		options...,
 	)
 	postCombinedVoucherUpdateClbkHandler := kithttp.NewServer(
 		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.CombinedVoucherUpdateClbk),
-		decodePostCombinedVoucherUpdateClbk,
+		signedDecoder(mongoRepo, constant.SUPPLIERYANOLJA, decodePostCombinedVoucherUpdateClbk),
 		encodeResponse,
 		options...,
 	)
 	postProcessingOrRestoringClbkHandler := kithttp.NewServer(
 		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.ProcessingOrRestoringClbk),
-		decodePostProcessingOrRestoringClbk,
+		signedDecoder(mongoRepo, constant.SUPPLIERYANOLJA, decodePostProcessingOrRestoringClbk),
 		encodeResponse,
 		options...,
 	)
 
 	postProductCreationInGGT := kithttp.NewServer(
 		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostProductCreation),
-		decodePostProductCreationInGGT,
+		signedDecoder(mongoRepo, constant.SUPPLIERYANOLJA, decodePostProductCreationInGGT),
 		encodeResponse,
 		options...,
 	)
@@ -319,7 +345,7 @@ func NewTransport(
 	postRequestFromGGT := kithttp.NewServer(
 		svcEndpoints.PostRequestFromGGT,
 		decodePostRequestFromGGT,
-		encodeResponse,
+		encodeGGTResponse,
 		options...,
 	)
 
@@ -515,6 +541,8 @@ func NewTransport(
 	router.Handle("/v1/ggt/package/content/sync", getPackageContentData).Methods("GET")
 	router.Handle("/v1/ggt/update/content/sync/status", putContentSyncStatus).Methods("PUT")
 	router.Handle("/v1/ggt/get/products", getProducts).Methods("GET")
+	router.Handle("/v1/ggt/categories", getStoredCategories).Methods("GET")
+	router.Handle("/v1/ggt/regions", getStoredRegions).Methods("GET")
 	router.Handle("/v1/ggt/get/product/{productId}", getProductByProductId).Methods("GET")
 
 	router.Handle("/v1/ggt/sync/content", postContent).Methods("POST")
@@ -627,7 +655,7 @@ func NewTransport(
 
 	PostTravolutionWebHook := kithttp.NewServer(
 		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostTravolutionWebHook),
-		decodeTravolutionWebHook,
+		signedDecoder(mongoRepo, constant.SUPPLIERTRAVOLUTION, decodeTravolutionWebHook),
 		encodeTravolutionResponse,
 		options...,
 	)
@@ -637,6 +665,374 @@ func NewTransport(
 	router.Handle("/v1/travolution/cancel/order/{orderNumber}", deleteCancelTravolutionOrder).Methods("DELETE")
 	router.Handle("/v1/travolution/webhook", PostTravolutionWebHook).Methods("POST")
 
+	//*********************** Notification outbox  *************************************************
+
+	getNotificationOutbox := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetNotificationOutbox),
+		decodeGetNotificationOutbox,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postReplayNotification := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostReplayNotification),
+		decodePostReplayNotification,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/admin/notifications", getNotificationOutbox).Methods("GET")
+	router.Handle("/v1/admin/notifications/{id}/replay", postReplayNotification).Methods("POST")
+
+	//*********************** Circuit breaker  *************************************************
+
+	getCircuitBreakers := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetCircuitBreakers),
+		decodeGetCircuitBreakers,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postCircuitBreaker := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostCircuitBreaker),
+		decodePostCircuitBreaker,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/admin/breakers", getCircuitBreakers).Methods("GET")
+	router.Handle("/v1/admin/breakers/{service}", postCircuitBreaker).Methods("POST")
+
+	//*********************** Pricing rules  *************************************************
+
+	getPricingRules := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetPricingRules),
+		decodeGetPricingRules,
+		encodeCommonResponse,
+		options...,
+	)
+
+	getPricingRule := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetPricingRule),
+		decodePricingRuleId,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postPricingRule := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostPricingRule),
+		decodePostPricingRule,
+		encodeCommonResponse,
+		options...,
+	)
+
+	putPricingRule := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PutPricingRule),
+		decodePutPricingRule,
+		encodeCommonResponse,
+		options...,
+	)
+
+	deletePricingRule := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.DeletePricingRule),
+		decodePricingRuleId,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/admin/pricing-rules", getPricingRules).Methods("GET")
+	router.Handle("/v1/admin/pricing-rules", postPricingRule).Methods("POST")
+	router.Handle("/v1/admin/pricing-rules/{id}", getPricingRule).Methods("GET")
+	router.Handle("/v1/admin/pricing-rules/{id}", putPricingRule).Methods("PUT")
+	router.Handle("/v1/admin/pricing-rules/{id}", deletePricingRule).Methods("DELETE")
+
+	//*********************** Refund policies  *************************************************
+
+	getRefundPolicy := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetRefundPolicy),
+		decodeGetRefundPolicy,
+		encodeCommonResponse,
+		options...,
+	)
+
+	putRefundPolicy := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PutRefundPolicy),
+		decodePutRefundPolicy,
+		encodeCommonResponse,
+		options...,
+	)
+
+	deleteRefundPolicy := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.DeleteRefundPolicy),
+		decodeRefundPolicyVariantId,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/admin/refund-policies/{variantId}", getRefundPolicy).Methods("GET")
+	router.Handle("/v1/admin/refund-policies/{variantId}", putRefundPolicy).Methods("PUT")
+	router.Handle("/v1/admin/refund-policies/{variantId}", deleteRefundPolicy).Methods("DELETE")
+
+	//*********************** Availability  *************************************************
+
+	getVariantAvailability := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetVariantAvailability),
+		decodeGetVariantAvailability,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/availability/variants/{variantId}", getVariantAvailability).Methods("GET")
+
+	//*********************** Api clients  *************************************************
+
+	getApiClients := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetApiClients),
+		decodeGetApiClients,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postApiClient := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostApiClient),
+		decodePostApiClient,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postRotateApiKey := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostRotateApiKey),
+		decodePostRotateApiKey,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postRevokeApiClient := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostRevokeApiClient),
+		decodeApiClientId,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/admin/api-clients", getApiClients).Methods("GET")
+	router.Handle("/v1/admin/api-clients", postApiClient).Methods("POST")
+	router.Handle("/v1/admin/api-clients/{id}/rotate", postRotateApiKey).Methods("POST")
+	router.Handle("/v1/admin/api-clients/{id}/revoke", postRevokeApiClient).Methods("POST")
+
+	//*********************** Jobs  *************************************************
+
+	getJobs := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetJobs),
+		decodeGetJobs,
+		encodeCommonResponse,
+		options...,
+	)
+
+	getJobRuns := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetJobRuns),
+		decodeGetJobRuns,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postTriggerJob := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostTriggerJob),
+		decodeJobName,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postPauseJob := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostJobPaused),
+		decodePostJobPaused(true),
+		encodeCommonResponse,
+		options...,
+	)
+
+	postResumeJob := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostJobPaused),
+		decodePostJobPaused(false),
+		encodeCommonResponse,
+		options...,
+	)
+
+	putJobSchedule := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PutJobSchedule),
+		decodePutJobSchedule,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/admin/jobs", getJobs).Methods("GET")
+	router.Handle("/v1/admin/jobs/{name}/runs", getJobRuns).Methods("GET")
+	router.Handle("/v1/admin/jobs/{name}/trigger", postTriggerJob).Methods("POST")
+	router.Handle("/v1/admin/jobs/{name}/pause", postPauseJob).Methods("POST")
+	router.Handle("/v1/admin/jobs/{name}/resume", postResumeJob).Methods("POST")
+	router.Handle("/v1/admin/jobs/{name}/schedule", putJobSchedule).Methods("PUT")
+
+	// odoo cursor sync
+	getOdooOrderChanges := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetOdooSyncChanges),
+		decodeGetOdooSyncChanges(odoo.SyncKindOrder),
+		encodeCommonResponse,
+		options...,
+	)
+
+	getOdooProductChanges := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetOdooSyncChanges),
+		decodeGetOdooSyncChanges(odoo.SyncKindProduct),
+		encodeCommonResponse,
+		options...,
+	)
+
+	postOdooOrderAck := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostOdooSyncAck),
+		decodePostOdooSyncAck(odoo.SyncKindOrder),
+		encodeCommonResponse,
+		options...,
+	)
+
+	postOdooProductAck := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostOdooSyncAck),
+		decodePostOdooSyncAck(odoo.SyncKindProduct),
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/odoo/order/changes", getOdooOrderChanges).Methods("GET")
+	router.Handle("/v1/odoo/product/changes", getOdooProductChanges).Methods("GET")
+	router.Handle("/v1/odoo/order/changes/ack", postOdooOrderAck).Methods("POST")
+	router.Handle("/v1/odoo/product/changes/ack", postOdooProductAck).Methods("POST")
+
+	//*********************** Reconciliation reports  *************************************************
+
+	postReconciliationReport := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.PostReconciliationReport),
+		decodeReconciliationReportDate,
+		encodeCommonResponse,
+		options...,
+	)
+
+	getReconciliationReports := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetReconciliationReports),
+		decodeGetReconciliationReports,
+		encodeCommonResponse,
+		options...,
+	)
+
+	getReconciliationReport := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetReconciliationReport),
+		decodeGetReconciliationReport,
+		encodeCommonResponse,
+		options...,
+	)
+
+	exportReconciliationReport := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.ExportReconciliationReport),
+		decodeGetReconciliationReport,
+		encodeFileResponse,
+		options...,
+	)
+
+	router.Handle("/v1/admin/reconciliation/reports", getReconciliationReports).Methods("GET")
+	router.Handle("/v1/admin/reconciliation/reports/{date}", getReconciliationReport).Methods("GET")
+	router.Handle("/v1/admin/reconciliation/reports/{date}", postReconciliationReport).Methods("POST")
+	router.Handle("/v1/admin/reconciliation/reports/{date}/csv", exportReconciliationReport).Methods("GET")
+
+	//*********************** Category mapping  *************************************************
+
+	getCategoryMappings := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetCategoryMappings),
+		decodeGetCategoryMappings,
+		encodeCommonResponse,
+		options...,
+	)
+
+	postCategoryMapping := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.CreateCategoryMapping),
+		decodeCategoryMappingBody,
+		encodeCommonResponse,
+		options...,
+	)
+
+	getCategoryMapping := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetCategoryMapping),
+		decodeCategoryMappingKey,
+		encodeCommonResponse,
+		options...,
+	)
+
+	putCategoryMapping := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.UpdateCategoryMapping),
+		decodeUpdateCategoryMapping,
+		encodeCommonResponse,
+		options...,
+	)
+
+	deleteCategoryMapping := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.DeleteCategoryMapping),
+		decodeCategoryMappingKey,
+		encodeCommonResponse,
+		options...,
+	)
+
+	uploadCategoryMappings := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.UploadCategoryMappings),
+		decodeUploadCategoryMappings,
+		encodeCommonResponse,
+		options...,
+	)
+
+	exportCategoryMappings := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.ExportCategoryMappings),
+		decodeGetCategoryMappings,
+		encodeFileResponse,
+		options...,
+	)
+
+	getUnmappedCategoryProducts := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetUnmappedCategoryProducts),
+		decodeGetUnmappedCategoryProducts,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/category-mappings", getCategoryMappings).Methods("GET")
+	router.Handle("/v1/category-mappings", postCategoryMapping).Methods("POST")
+	router.Handle("/v1/category-mappings/upload", uploadCategoryMappings).Methods("POST")
+	router.Handle("/v1/category-mappings/export", exportCategoryMappings).Methods("GET")
+	router.Handle("/v1/category-mappings/unmapped", getUnmappedCategoryProducts).Methods("GET")
+	router.Handle("/v1/category-mappings/{level}/{code}", getCategoryMapping).Methods("GET")
+	router.Handle("/v1/category-mappings/{level}/{code}", putCategoryMapping).Methods("PUT")
+	router.Handle("/v1/category-mappings/{level}/{code}", deleteCategoryMapping).Methods("DELETE")
+
+	//*********************** Product versions  *************************************************
+
+	getProductVersions := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetProductVersions),
+		decodeProductVersionRequest,
+		encodeCommonResponse,
+		options...,
+	)
+
+	getProductVersion := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.GetProductVersion),
+		decodeProductVersionRequest,
+		encodeCommonResponse,
+		options...,
+	)
+
+	getProductVersionDiff := kithttp.NewServer(
+		middleware.AuthMiddleWare(mongoRepo)(svcEndpoints.DiffProductVersions),
+		decodeDiffProductVersions,
+		encodeCommonResponse,
+		options...,
+	)
+
+	router.Handle("/v1/products/{productid}/versions", getProductVersions).Methods("GET")
+	router.Handle("/v1/products/{productid}/versions/diff", getProductVersionDiff).Methods("GET")
+	router.Handle("/v1/products/{productid}/versions/{version}", getProductVersion).Methods("GET")
+
 	// handling of 404 not found handler
 	router.NotFoundHandler = http.HandlerFunc(DefaultNotFoundRouteHandler)
 	return router
@@ -770,6 +1166,39 @@ func decodeGetCategories(ctx context.Context, r *http.Request) (request interfac
 	return request, nil
 }
 
+// decodeGetStoredCategories page, sort and filters of the stored categories
+func decodeGetStoredCategories(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	return decodeListQuery(ctx, r, common.CategoryList)
+}
+
+// decodeGetStoredRegions page, sort and filters of the stored regions
+func decodeGetStoredRegions(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	return decodeListQuery(ctx, r, common.RegionList)
+}
+
+// decodeListQuery page, sort, filter and cursor query parameters of a list endpoint
+func decodeListQuery(ctx context.Context, r *http.Request, list pagination.List) (pagination.Query, error) {
+	q, queryErrors := pagination.ParseQuery(r.URL.Query(), list)
+	if len(queryErrors) > 0 {
+		problems := make([]string, 0, len(queryErrors))
+		for param, e := range queryErrors {
+			problems = append(problems, param+" "+e.Error())
+		}
+		sort.Strings(problems)
+		return q, customError.NewError(ctx, "leisure-api-0001", "Invalid query parameters, "+strings.Join(problems, "; "), nil)
+	}
+	return q, nil
+}
+
+// decodeUnpagedListQuery query of a list endpoint listing every record unless a page is asked for
+func decodeUnpagedListQuery(ctx context.Context, r *http.Request, list pagination.List) (pagination.Query, error) {
+	q, err := decodeListQuery(ctx, r, list)
+	if err == nil && !q.Paged {
+		q.RecordsPerPage = 0
+	}
+	return q, err
+}
+
 // decodePostCategories
 func decodePostCategories(ctx context.Context, r *http.Request) (request interface{}, err error) {
 	return request, nil
@@ -827,6 +1256,11 @@ func decodeGetOrderByOrderId(ctx context.Context, r *http.Request) (request inte
 func decodeGetEverlandOrders(ctx context.Context, r *http.Request) (request interface{}, err error) {
 	var req yanolja.EverlandGetRequest
 
+	req.Query, err = decodeUnpagedListQuery(ctx, r, common.EverlandOrderList)
+	if err != nil {
+		return nil, err
+	}
+
 	vars := mux.Vars(r)
 	email, ok := vars["customeremail"]
 	if !ok {
@@ -1057,12 +1491,23 @@ func decodePostRequestFromGGT(ctx context.Context, r *http.Request) (request int
 		e = customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
 		return nil, e
 	}
+
+	cfg := config.Instance()
+	if cfg.TripEnvelopeMode != envelope.ModeEncrypted {
+		return req, nil
+	}
+
+	keys := envelope.Keys{AESKey: cfg.TripAesKey, SignKey: cfg.TripSignKey}
+	req, err = envelope.Open(req, keys, time.Now(), constant.TripRequestWindowSeconds*time.Second)
+	if err != nil {
+		return nil, customError.NewErrorCustom(ctx, "401", err.Error(), customError.ErrInvalidSignature.Error(), http.StatusUnauthorized, "decodePostRequestFromGGT")
+	}
 	return req, nil
 }
 
-// decodeGetCategories
+// decodeGetRedisData page, sort and key pattern of the cached keys
 func decodeGetRedisData(ctx context.Context, r *http.Request) (request interface{}, err error) {
-	return request, nil
+	return decodeListQuery(ctx, r, common.RedisKeyList)
 }
 
 func decodeGetOrderForOdoo(_ context.Context, r *http.Request) (request interface{}, err error) {
@@ -1408,7 +1853,7 @@ func decodeGetProductByIdFromGGT(ctx context.Context, r *http.Request) (request
 }
 
 func decodeGetProductsFromGGT(ctx context.Context, r *http.Request) (request interface{}, err error) {
-	return request, nil
+	return decodeUnpagedListQuery(ctx, r, common.ProductViewList)
 }
 
 func decodeCreateTravolutionOrder(ctx context.Context, r *http.Request) (request interface{}, err error) {
@@ -1510,6 +1955,57 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 	return json.NewEncoder(w).Encode(res1)
 }
 
+// encodeGGTResponse encodes the response to trip, in encrypted mode the body is encrypted with
+// the envelope key. Errors keep their code and message inside the encrypted body
+func encodeGGTResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	cfg := config.Instance()
+	if cfg.TripEnvelopeMode != envelope.ModeEncrypted {
+		return encodeResponse(ctx, w, response)
+	}
+
+	rs, _ := response.(yanolja.Response)
+	status, _ := strconv.Atoi(rs.Code)
+	if status < 200 || status >= 300 {
+		rs.Body = yanolja.ErrorMsg{
+			ErrorCode:    rs.Code,
+			ErrorMessage: errorMessage(rs.Body),
+		}
+	}
+
+	sealed, err := envelope.Seal(rs.Body, cfg.TripAesKey)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(yanolja.SupplierResponse{
+		TraceID:     utils.GenerateUUID("", true),
+		Body:        sealed,
+		Page:        rs.Page,
+		Collection:  rs.Collection,
+		ContentType: rs.ContentType,
+	})
+}
+
+// errorMessage message of an error body, bodies which are not text are sent as json
+func errorMessage(body interface{}) string {
+	switch v := body.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case error:
+		return v.Error()
+	}
+
+	message, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Sprint(body)
+	}
+	return string(message)
+}
+
 // encodeResponse encodes final response as json
 func encodeCommonResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -1553,6 +2049,21 @@ func encodeCommonResponse(ctx context.Context, w http.ResponseWriter, response i
 	return json.NewEncoder(w).Encode(res1)
 }
 
+// encodeFileResponse writes a common.File body as download, other responses as json
+func encodeFileResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	rs, _ := response.(common.Response)
+	file, ok := rs.Body.(common.File)
+	if !ok {
+		return encodeCommonResponse(ctx, w, response)
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	_, err := w.Write(file.Content)
+	return err
+}
+
 func encodeTravolutionResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -1570,6 +2081,546 @@ func encodeTravolutionResponse(_ context.Context, w http.ResponseWriter, respons
 	return json.NewEncoder(w).Encode(rs)
 }
 
+// decodeGetNotificationOutbox decodes status and paging of the outbox listing
+func decodeGetNotificationOutbox(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.NotificationOutboxRequest
+
+	q := r.URL.Query()
+	req.Status = strings.ToUpper(q.Get("status"))
+	if req.Status != "" && req.Status != constant.OutboxStatusPending && req.Status != constant.OutboxStatusSent && req.Status != constant.OutboxStatusDead {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "passed status is not valid", nil)
+	}
+
+	if page := q.Get("page"); page != "" {
+		req.Page, err = strconv.ParseInt(page, 10, 64)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		}
+	}
+
+	if pageSize := q.Get("pageSize"); pageSize != "" {
+		req.PageSize, err = strconv.ParseInt(pageSize, 10, 64)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		}
+	}
+
+	return req, nil
+}
+
+// decodePostReplayNotification decodes the outbox notification id
+func decodePostReplayNotification(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "id not passed as path parameter", nil)
+	}
+
+	return id, nil
+}
+
+// decodeGetCircuitBreakers nothing to decode
+func decodeGetCircuitBreakers(_ context.Context, r *http.Request) (request interface{}, err error) {
+	return request, nil
+}
+
+// decodePostCircuitBreaker decodes the service path parameter and the mode of the body
+func decodePostCircuitBreaker(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.CircuitBreakerRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+	}
+
+	service, ok := mux.Vars(r)["service"]
+	if !ok || service == "" {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "service not passed as path parameter", nil)
+	}
+	req.Service = service
+	req.Mode = strings.ToUpper(req.Mode)
+
+	if req.Mode != client.BreakerForceOpen && req.Mode != client.BreakerForceClosed && req.Mode != client.BreakerAuto {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "mode must be one of OPEN, CLOSED, AUTO", nil)
+	}
+
+	return req, nil
+}
+
+// decodeGetPricingRules decodes the supplier, product and channel filter of the pricing rule listing
+func decodeGetPricingRules(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.PricingRuleRequest
+
+	q := r.URL.Query()
+	req.Supplier = q.Get("supplier")
+	req.ChannelCode = q.Get("channelCode")
+	if productId := q.Get("productId"); productId != "" {
+		req.ProductId, err = strconv.ParseInt(productId, 10, 64)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "productId must be a number", nil)
+		}
+	}
+
+	return req, nil
+}
+
+// decodePricingRuleId decodes the pricing rule id
+func decodePricingRuleId(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "id not passed as path parameter", nil)
+	}
+
+	return id, nil
+}
+
+// decodePostPricingRule decodes a new pricing rule
+func decodePostPricingRule(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var rule pricing_domain.Rule
+	if e := json.NewDecoder(r.Body).Decode(&rule); e != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+	}
+	rule.Id = ""
+	rule.MarkupType = strings.ToUpper(rule.MarkupType)
+	rule.Rounding = strings.ToUpper(rule.Rounding)
+
+	return rule, nil
+}
+
+// decodePutPricingRule decodes the pricing rule id and the replacing rule
+func decodePutPricingRule(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var rule pricing_domain.Rule
+	if e := json.NewDecoder(r.Body).Decode(&rule); e != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+	}
+
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "id not passed as path parameter", nil)
+	}
+	rule.Id = id
+	rule.MarkupType = strings.ToUpper(rule.MarkupType)
+	rule.Rounding = strings.ToUpper(rule.Rounding)
+
+	return rule, nil
+}
+
+// decodeGetRefundPolicy decodes the variant id and the optional product id
+func decodeGetRefundPolicy(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.RefundPolicyRequest
+
+	variantId, err := decodeRefundPolicyVariantId(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req.VariantId = variantId.(int64)
+
+	if productId := r.URL.Query().Get("productId"); productId != "" {
+		req.ProductId, err = strconv.ParseInt(productId, 10, 64)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "productId must be a number", nil)
+		}
+	}
+
+	return req, nil
+}
+
+// decodePutRefundPolicy decodes the variant id and its curated refund policy
+func decodePutRefundPolicy(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var policy refund_domain.Policy
+	if e := json.NewDecoder(r.Body).Decode(&policy); e != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+	}
+
+	variantId, err := decodeRefundPolicyVariantId(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	policy.Id = ""
+	policy.VariantId = variantId.(int64)
+	for i := range policy.Tiers {
+		policy.Tiers[i].FeeType = strings.ToUpper(policy.Tiers[i].FeeType)
+	}
+
+	return policy, nil
+}
+
+// decodeRefundPolicyVariantId decodes the variant id of the refund policy
+func decodeRefundPolicyVariantId(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	variantId, err := strconv.ParseInt(mux.Vars(r)["variantId"], 10, 64)
+	if err != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "variantId must be a number", nil)
+	}
+
+	return variantId, nil
+}
+
+// decodeGetVariantAvailability decodes the variant id, the visit date, the optional round and the
+// quantity to book, one unit when not given
+func decodeGetVariantAvailability(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	req := common.AvailabilityRequest{Quantity: 1}
+
+	req.VariantId, err = strconv.ParseInt(mux.Vars(r)["variantId"], 10, 64)
+	if err != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "variantId must be a number", nil)
+	}
+
+	s := r.URL.Query()
+	req.Date = s.Get("date")
+	req.Time = s.Get("time")
+	if req.Date != "" {
+		if _, e := time.Parse(time.DateOnly, req.Date); e != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "date must be formatted as YYYY-MM-DD", nil)
+		}
+	}
+	if quantity := s.Get("quantity"); quantity != "" {
+		q, e := strconv.ParseInt(quantity, 10, 32)
+		if e != nil || q < 1 {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "quantity must be a positive number", nil)
+		}
+		req.Quantity = int32(q)
+	}
+
+	return req, nil
+}
+
+// decodeGetApiClients nothing to decode
+func decodeGetApiClients(_ context.Context, r *http.Request) (request interface{}, err error) {
+	return request, nil
+}
+
+// decodePostApiClient decodes name, channel, scopes and expiry of a new api client
+func decodePostApiClient(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.ApiClientRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+	}
+
+	return req, nil
+}
+
+// decodePostRotateApiKey decodes the api client id and the optional new expiry
+func decodePostRotateApiKey(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.ApiClientRequest
+	if r.ContentLength != 0 {
+		if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		}
+	}
+
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "id not passed as path parameter", nil)
+	}
+	req.Id = id
+
+	return req, nil
+}
+
+// decodeApiClientId decodes the api client id
+func decodeApiClientId(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "id not passed as path parameter", nil)
+	}
+
+	return id, nil
+}
+
+// decodeGetJobs
+func decodeGetJobs(_ context.Context, r *http.Request) (request interface{}, err error) {
+	return request, nil
+}
+
+// decodeJobName decodes the job name of the path
+func decodeJobName(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	name, ok := mux.Vars(r)["name"]
+	if !ok || name == "" {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "name not passed as path parameter", nil)
+	}
+
+	return common.JobRequest{Name: name}, nil
+}
+
+// decodeGetJobRuns decodes the job name and the optional limit query parameter
+func decodeGetJobRuns(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	request, err = decodeJobName(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := request.(common.JobRequest)
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		req.Limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil || req.Limit <= 0 {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid limit", nil)
+		}
+	}
+
+	return req, nil
+}
+
+// decodePostJobPaused decodes the job name of the pause and resume routes
+func decodePostJobPaused(paused bool) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (request interface{}, err error) {
+		request, err = decodeJobName(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		req := request.(common.JobRequest)
+		req.Paused = paused
+
+		return req, nil
+	}
+}
+
+// decodePutJobSchedule decodes the job name and the new schedule
+func decodePutJobSchedule(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.JobRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+	}
+
+	name, ok := mux.Vars(r)["name"]
+	if !ok || name == "" {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "name not passed as path parameter", nil)
+	}
+	req.Name = name
+
+	return req, nil
+}
+
+// decodeGetOdooSyncChanges decodes since and limit of the pull of the kind
+func decodeGetOdooSyncChanges(kind string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (request interface{}, err error) {
+		req := common.OdooSyncRequest{Kind: kind}
+
+		if since := r.URL.Query().Get("since"); since != "" {
+			cursor, err := strconv.ParseInt(since, 10, 64)
+			if err != nil || cursor < 0 {
+				return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid since", nil)
+			}
+			req.Since = &cursor
+		}
+
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			req.Limit, err = strconv.ParseInt(limit, 10, 64)
+			if err != nil || req.Limit <= 0 {
+				return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid limit", nil)
+			}
+		}
+
+		return req, nil
+	}
+}
+
+// decodePostOdooSyncAck decodes the acknowledged cursor of the kind
+func decodePostOdooSyncAck(kind string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (request interface{}, err error) {
+		var req common.OdooSyncRequest
+		if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		}
+		req.Kind = kind
+
+		return req, nil
+	}
+}
+
+// decodeReconciliationReportDate decodes the day of the report from the path
+func decodeReconciliationReportDate(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	date := mux.Vars(r)["date"]
+	if _, err = time.Parse("2006-01-02", date); err != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "date must be in YYYY-MM-DD format", nil)
+	}
+
+	return common.ReconciliationReportRequest{Date: date}, nil
+}
+
+// decodeGetReconciliationReports decodes the optional limit of the report listing
+func decodeGetReconciliationReports(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.ReconciliationReportRequest
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		req.Limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil || req.Limit <= 0 {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid limit", nil)
+		}
+	}
+
+	return req, nil
+}
+
+// decodeGetReconciliationReport decodes the day, mismatch type and paging of the report
+func decodeGetReconciliationReport(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	request, err = decodeReconciliationReportDate(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := request.(common.ReconciliationReportRequest)
+
+	q := r.URL.Query()
+	req.Type = strings.ToUpper(q.Get("type"))
+	switch req.Type {
+	case "", reconciliation.MismatchStatus, reconciliation.MismatchMissingVoucher, reconciliation.MismatchPriceDrift, reconciliation.MismatchOrphanCancellation:
+	default:
+		return nil, customError.NewError(ctx, "leisure-api-0001", "passed type is not valid", nil)
+	}
+
+	if page := q.Get("page"); page != "" {
+		req.Page, err = strconv.ParseInt(page, 10, 64)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		}
+	}
+
+	if pageSize := q.Get("pageSize"); pageSize != "" {
+		req.PageSize, err = strconv.ParseInt(pageSize, 10, 64)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		}
+	}
+
+	return req, nil
+}
+
+// decodeGetCategoryMappings decodes the level and trip category filters and the export format
+func decodeGetCategoryMappings(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.CategoryMappingRequest
+
+	q := r.URL.Query()
+	if categoryLevel := q.Get("level"); categoryLevel != "" {
+		req.Level, err = strconv.Atoi(categoryLevel)
+		if err != nil || req.Level <= 0 {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid level", nil)
+		}
+	}
+	req.TripCategory = strings.TrimSpace(q.Get("tripCategory"))
+	req.Format = strings.ToLower(q.Get("format"))
+
+	return req, nil
+}
+
+// decodeCategoryMappingKey decodes the yanolja category level and code from the path
+func decodeCategoryMappingKey(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.CategoryMappingRequest
+
+	vars := mux.Vars(r)
+	req.Level, err = strconv.Atoi(vars["level"])
+	if err != nil || req.Level <= 0 {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid level", nil)
+	}
+	req.Code, err = strconv.Atoi(vars["code"])
+	if err != nil || req.Code <= 0 {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid code", nil)
+	}
+
+	return req, nil
+}
+
+// decodeCategoryMappingBody decodes a mapping from the body
+func decodeCategoryMappingBody(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.CategoryMappingRequest
+
+	if e := json.NewDecoder(r.Body).Decode(&req.Mapping); e != nil {
+		e = customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		return nil, e
+	}
+
+	return req, nil
+}
+
+// decodeUpdateCategoryMapping decodes the yanolja category from the path and its mapping from the body
+func decodeUpdateCategoryMapping(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	request, err = decodeCategoryMappingKey(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := request.(common.CategoryMappingRequest)
+
+	if e := json.NewDecoder(r.Body).Decode(&req.Mapping); e != nil {
+		e = customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		return nil, e
+	}
+
+	return req, nil
+}
+
+// decodeGetUnmappedCategoryProducts
+func decodeGetUnmappedCategoryProducts(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	return request, nil
+}
+
+// decodeUploadCategoryMappings decodes the mapping sheet from the multipart field file
+func decodeUploadCategoryMappings(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.CategoryMappingUploadRequest
+
+	if err = r.ParseMultipartForm(constant.CategoryMappingUploadMaxSize); err != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "expected a multipart form with the mapping file", nil)
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "file is required", nil)
+	}
+	defer file.Close()
+
+	req.FileName = header.Filename
+	req.Content, err = io.ReadAll(io.LimitReader(file, constant.CategoryMappingUploadMaxSize+1))
+	if err != nil {
+		return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+	}
+	if len(req.Content) > constant.CategoryMappingUploadMaxSize {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "file is too large", nil)
+	}
+
+	if dryRun := r.URL.Query().Get("dryRun"); dryRun != "" {
+		req.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid dryRun", nil)
+		}
+	}
+
+	return req, nil
+}
+
+// decodeProductVersionRequest decodes the product and the optional version from the path
+func decodeProductVersionRequest(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	var req common.ProductVersionRequest
+
+	vars := mux.Vars(r)
+	req.ProductId, err = strconv.ParseInt(vars["productid"], 10, 64)
+	if err != nil || req.ProductId <= 0 {
+		return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid productId", nil)
+	}
+	if version, ok := vars["version"]; ok {
+		number, err := strconv.ParseInt(version, 10, 32)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", "Invalid version", nil)
+		}
+		req.Version = int32(number)
+	}
+
+	return req, nil
+}
+
+// decodeDiffProductVersions decodes the product from the path and the versions to diff from the query
+func decodeDiffProductVersions(ctx context.Context, r *http.Request) (request interface{}, err error) {
+	request, err = decodeProductVersionRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := request.(common.ProductVersionRequest)
+
+	q := r.URL.Query()
+	for name, version := range map[string]*int32{"from": &req.From, "to": &req.To} {
+		number, err := strconv.ParseInt(q.Get(name), 10, 32)
+		if err != nil {
+			return nil, customError.NewError(ctx, "leisure-api-0001", fmt.Sprintf("%s must be a product version", name), nil)
+		}
+		*version = int32(number)
+	}
+
+	return req, nil
+}
+
 // DefaultNotFoundRouteHandler handler for 404 resource not found
 func DefaultNotFoundRouteHandler(w http.ResponseWriter, req *http.Request) {
 	logger := log.NewLogfmtLogger(os.Stdout)
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"swallow-supplier/config"
+	customError "swallow-supplier/error"
+	svc "swallow-supplier/iface"
+	travolution_domain "swallow-supplier/mongo/domain/travolution"
+	"swallow-supplier/mongo/domain/webhook"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/signature"
+)
+
+// signedDecoder verifies the signature of the raw callback body before decode reads it.
+// Every callback is recorded with the outcome, unverified ones are rejected when the
+// signature mode is enforce or a signing secret is configured for the supplier
+func signedDecoder(mongoRepo map[string]svc.MongoRepository, supplier string, decode kithttp.DecodeRequestFunc) kithttp.DecodeRequestFunc {
+	logger := log.With(log.NewLogfmtLogger(os.Stdout), "method", "signedDecoder", "supplier", supplier)
+
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, constant.WebhookMaxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, customError.NewErrorCustom(ctx, "413", "callback body too large", customError.ErrInvalidBody.Error(), http.StatusRequestEntityTooLarge, "signature")
+			}
+			return nil, customError.NewError(ctx, "leisure-api-0001", customError.ErrInvalidBody.Error(), nil)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		cfg := config.Instance()
+		now := time.Now()
+		secret := cfg.WebhookSecret(supplier)
+		verification := webhook.Verification{
+			Enforced:   cfg.WebhookSignatureMode == constant.WEBHOOKSIGNATUREENFORCE || secret != "",
+			Timestamp:  r.Header.Get(signature.HeaderTimestamp),
+			VerifiedAt: now.UTC().Format(time.RFC3339),
+		}
+		err = signature.Verify(secret, verification.Timestamp, r.Header.Get(signature.HeaderSignature), body, now, constant.WebhookSignatureToleranceSeconds*time.Second)
+		verification.Verified = err == nil
+		if err != nil {
+			verification.Reason = err.Error()
+			level.Warn(logger).Log("msg", "callback signature not verified", "path", r.URL.Path, "reason", err, "enforced", verification.Enforced)
+		}
+
+		// an unverified caller may be anyone, only the start of its body is kept
+		record := webhook.Record{
+			Supplier:     supplier,
+			Path:         r.URL.Path,
+			RemoteAddr:   r.RemoteAddr,
+			Body:         string(body),
+			Verification: verification,
+		}
+		if !verification.Verified && len(body) > constant.WebhookUnverifiedBodyBytes {
+			record.Body = string(body[:constant.WebhookUnverifiedBodyBytes])
+			record.BodyTruncated = true
+		}
+		if _, e := mongoRepo[cfg.MongoDBName].InsertWebhookRecord(ctx, record); e != nil {
+			level.Error(logger).Log("msg", "failed to record callback", "path", r.URL.Path, "err", e)
+		}
+
+		if !verification.Verified && verification.Enforced {
+			return nil, customError.NewErrorCustom(ctx, "401", "invalid signature", customError.ErrInvalidSignature.Error(), http.StatusUnauthorized, "signature")
+		}
+
+		request, err := decode(ctx, r)
+		if err != nil {
+			return request, err
+		}
+
+		if hook, ok := request.(travolution_domain.Webhook); ok {
+			hook.Verification = &verification
+			request = hook
+		}
+
+		return request, nil
+	}
+}
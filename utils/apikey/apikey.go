@@ -0,0 +1,110 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"swallow-supplier/mongo/domain/apiclient"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/secure"
+)
+
+// prefixLength characters of the key stored in clear to recognise it
+const prefixLength = 8
+
+// Generate new random api key, its prefix and the hash to store
+func Generate() (key, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+
+	key = hex.EncodeToString(b)
+	return key, key[:prefixLength], Hash(key), nil
+}
+
+// Hash of the key as stored on the api client
+func Hash(key string) string {
+	return secure.SHA256(key)
+}
+
+type cached struct {
+	client   apiclient.ApiClient
+	loadedAt time.Time
+}
+
+// clients resolved clients by key hash, kept for a short time so each call does not hit mongo
+var clients sync.Map
+
+// Cached client of the key hash unless it is older than the cache period
+func Cached(hash string) (apiclient.ApiClient, bool) {
+	v, ok := clients.Load(hash)
+	if !ok {
+		return apiclient.ApiClient{}, false
+	}
+
+	entry := v.(cached)
+	if time.Since(entry.loadedAt) >= constant.ApiClientCacheSeconds*time.Second {
+		clients.Delete(hash)
+		return apiclient.ApiClient{}, false
+	}
+
+	return entry.client, true
+}
+
+// Cache keep the resolved client of the key hash
+func Cache(hash string, client apiclient.ApiClient) {
+	clients.Store(hash, cached{client: client, loadedAt: time.Now()})
+}
+
+// unknown key hashes without an api client and when they were looked up, so a caller retrying
+// a wrong key does not hit mongo on every call
+var (
+	unknown      sync.Map
+	unknownCount atomic.Int64
+)
+
+// Unknown whether the key hash was looked up without finding a client within the short cache period
+func Unknown(hash string) bool {
+	v, ok := unknown.Load(hash)
+	if !ok {
+		return false
+	}
+
+	if time.Since(v.(time.Time)) >= constant.ApiClientUnknownCacheSeconds*time.Second {
+		if _, loaded := unknown.LoadAndDelete(hash); loaded {
+			unknownCount.Add(-1)
+		}
+		return false
+	}
+
+	return true
+}
+
+// CacheUnknown remember the key hash has no api client
+func CacheUnknown(hash string) {
+	if unknownCount.Load() >= constant.ApiClientUnknownCacheSize {
+		forget(&unknown)
+		unknownCount.Store(0)
+	}
+	if _, loaded := unknown.Swap(hash, time.Now()); !loaded {
+		unknownCount.Add(1)
+	}
+}
+
+// Invalidate forget every resolved client and unknown key, called when a key is rotated or revoked
+func Invalidate() {
+	forget(&clients)
+	forget(&unknown)
+	unknownCount.Store(0)
+}
+
+func forget(m *sync.Map) {
+	m.Range(func(k, _ any) bool {
+		m.Delete(k)
+		return true
+	})
+}
@@ -0,0 +1,46 @@
+package apikey_test
+
+import (
+	"testing"
+
+	"swallow-supplier/mongo/domain/apiclient"
+	"swallow-supplier/utils/apikey"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	key, prefix, hash, err := apikey.Generate()
+	assert.NoError(t, err)
+	assert.Len(t, key, 64)
+	assert.Equal(t, key[:8], prefix)
+	assert.Equal(t, apikey.Hash(key), hash)
+	assert.NotEqual(t, key, hash)
+
+	other, _, _, _ := apikey.Generate()
+	assert.NotEqual(t, key, other)
+}
+
+func TestCache(t *testing.T) {
+	client := apiclient.ApiClient{Id: "1", Name: "trip", Scopes: []int{0}, Enabled: true}
+
+	apikey.Cache("hash", client)
+	cached, ok := apikey.Cached("hash")
+	assert.True(t, ok)
+	assert.Equal(t, client, cached)
+
+	apikey.Invalidate()
+	_, ok = apikey.Cached("hash")
+	assert.False(t, ok)
+}
+
+func TestCacheUnknown(t *testing.T) {
+	assert.False(t, apikey.Unknown("missing"))
+
+	apikey.CacheUnknown("missing")
+	assert.True(t, apikey.Unknown("missing"))
+
+	// rotating or revoking a key forgets the unknown ones too
+	apikey.Invalidate()
+	assert.False(t, apikey.Unknown("missing"))
+}
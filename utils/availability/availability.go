@@ -0,0 +1,112 @@
+package availability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"swallow-supplier/utils/constant"
+)
+
+// Store short lived storage of the inventories, implemented by the cache layer
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	SetTTL(ctx context.Context, key string, value string, ttl time.Duration) error
+	Scan(ctx context.Context, pattern string) ([]string, error)
+	Delete(ctx context.Context, keys []string) error
+}
+
+// Fetcher reads the live inventory of the query from the supplier
+type Fetcher func(ctx context.Context, query Query) (Inventory, error)
+
+// Query variant inventory on a visit date, Time is only set for variants sold per round
+type Query struct {
+	VariantId int64  `json:"variantId"`
+	Date      string `json:"date"`
+	Time      string `json:"time,omitempty"`
+}
+
+// Inventory remaining quantity of a variant on a visit date. A negative quantity is not limited
+type Inventory struct {
+	VariantId           int64  `json:"variantId"`
+	Date                string `json:"date"`
+	Time                string `json:"time,omitempty"`
+	Quantity            int32  `json:"quantity"`
+	QuantityPerPerson   int32  `json:"quantityPerPerson"`
+	QuantityPerPurchase int32  `json:"quantityPerPurchase"`
+	CheckedAt           string `json:"checkedAt"`
+	Cached              bool   `json:"cached"`
+}
+
+// Available whether the quantity can still be booked
+func (i Inventory) Available(quantity int32) bool {
+	return i.Quantity < 0 || i.Quantity >= quantity
+}
+
+// Key cache key of the inventory of the query
+func Key(query Query) string {
+	return fmt.Sprintf("%s|%d|%s|%s", constant.InventoryCacheKeyPrefix, query.VariantId, query.Date, query.Time)
+}
+
+// Check inventory of the query, from the store while it is fresh and from the supplier otherwise.
+// The store is only a shortcut, failing to read or write it does not fail the check
+func Check(ctx context.Context, store Store, fetch Fetcher, query Query, now time.Time) (Inventory, error) {
+	if store != nil {
+		if cached, err := store.Get(ctx, Key(query)); err == nil && cached != "" {
+			var inventory Inventory
+			if json.Unmarshal([]byte(cached), &inventory) == nil {
+				inventory.Cached = true
+				return inventory, nil
+			}
+		}
+	}
+
+	inventory, err := fetch(ctx, query)
+	if err != nil {
+		return Inventory{}, err
+	}
+	inventory.VariantId, inventory.Date, inventory.Time = query.VariantId, query.Date, query.Time
+	inventory.CheckedAt = now.UTC().Format(time.RFC3339)
+	inventory.Cached = false
+
+	if store != nil {
+		if data, err := json.Marshal(inventory); err == nil {
+			_ = store.SetTTL(ctx, Key(query), string(data), constant.InventoryCacheSeconds*time.Second)
+		}
+	}
+
+	return inventory, nil
+}
+
+// Invalidate drop the stored inventories of the variants on every date. The inventory keys are
+// scanned once a batch at a time, the store stays responsive while the inventory sync invalidates
+func Invalidate(ctx context.Context, store Store, variantIds []int64) error {
+	if len(variantIds) == 0 {
+		return nil
+	}
+	prefixes := make([]string, 0, len(variantIds))
+	for _, variantId := range variantIds {
+		prefixes = append(prefixes, fmt.Sprintf("%s|%d|", constant.InventoryCacheKeyPrefix, variantId))
+	}
+
+	found, err := store.Scan(ctx, constant.InventoryCacheKeyPrefix+"|*")
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0)
+	for _, key := range found {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return store.Delete(ctx, keys)
+}
@@ -0,0 +1,90 @@
+package availability_test
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"swallow-supplier/utils/availability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore map[string]string
+
+func (m memoryStore) Get(_ context.Context, key string) (string, error) {
+	return m[key], nil
+}
+
+func (m memoryStore) SetTTL(_ context.Context, key string, value string, _ time.Duration) error {
+	m[key] = value
+	return nil
+}
+
+func (m memoryStore) Scan(_ context.Context, pattern string) ([]string, error) {
+	keys := make([]string, 0)
+	for key := range m {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m memoryStore) Delete(_ context.Context, keys []string) error {
+	for _, key := range keys {
+		delete(m, key)
+	}
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore{}
+	calls := 0
+	fetch := func(_ context.Context, query availability.Query) (availability.Inventory, error) {
+		calls++
+		return availability.Inventory{Quantity: 3}, nil
+	}
+	query := availability.Query{VariantId: 7, Date: "2026-10-20", Time: "10:00"}
+
+	inventory, err := availability.Check(ctx, store, fetch, query, time.Now())
+	require.NoError(t, err)
+	assert.False(t, inventory.Cached)
+	assert.Equal(t, int64(7), inventory.VariantId)
+	assert.True(t, inventory.Available(3))
+	assert.False(t, inventory.Available(4))
+
+	inventory, err = availability.Check(ctx, store, fetch, query, time.Now())
+	require.NoError(t, err)
+	assert.True(t, inventory.Cached)
+	assert.Equal(t, int32(3), inventory.Quantity)
+	assert.Equal(t, 1, calls)
+
+	// other variants stay cached
+	other := availability.Query{VariantId: 8, Date: "2026-10-20"}
+	_, err = availability.Check(ctx, store, fetch, other, time.Now())
+	require.NoError(t, err)
+	prefixed := availability.Query{VariantId: 70, Date: "2026-10-20"}
+	_, err = availability.Check(ctx, store, fetch, prefixed, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, availability.Invalidate(ctx, store, []int64{7}))
+	assert.NotContains(t, store, availability.Key(query))
+	assert.Contains(t, store, availability.Key(other))
+	assert.Contains(t, store, availability.Key(prefixed))
+
+	_, err = availability.Check(ctx, store, fetch, query, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+
+	// without a store every check reaches the supplier, supplier errors are returned
+	_, err = availability.Check(ctx, nil, func(context.Context, availability.Query) (availability.Inventory, error) {
+		return availability.Inventory{}, errors.New("timeout")
+	}, query, time.Now())
+	assert.Error(t, err)
+
+	assert.True(t, availability.Inventory{Quantity: -1}.Available(30))
+}
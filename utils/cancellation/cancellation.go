@@ -0,0 +1,94 @@
+package cancellation
+
+import (
+	"fmt"
+
+	"swallow-supplier/mongo/domain/money"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/fx"
+	"swallow-supplier/utils/orderstate"
+)
+
+// Unit quantity of a product variant the channel asks to cancel
+type Unit struct {
+	ProductID int64
+	VariantID int64
+	Quantity  int
+}
+
+// Cancellable the variant may still be asked to cancel: neither used, canceled nor already
+// canceling
+func Cancellable(variant domain.OrderVariant) bool {
+	status := variant.OrderVariantStatusTypeCode
+	return status != constant.ORDERVARIANTCANCELINGSTATUS && orderstate.Variant.Can(status, constant.ORDERVARIANTCANCELINGSTATUS)
+}
+
+// Select order variants canceling the units, the first cancellable ones of each product variant.
+// Units asking for more than the order has left to cancel fail
+func Select(order domain.Model, units []Unit) ([]int64, error) {
+	type key struct{ productId, variantId int64 }
+
+	asked := make(map[key]int)
+	keys := make([]key, 0, len(units))
+	for _, unit := range units {
+		k := key{unit.ProductID, unit.VariantID}
+		if _, ok := asked[k]; !ok {
+			keys = append(keys, k)
+		}
+		asked[k] += unit.Quantity
+	}
+
+	ids := make([]int64, 0)
+	for _, k := range keys {
+		selected := 0
+		for _, variant := range order.OrderVariants {
+			if selected == asked[k] {
+				break
+			}
+			if variant.ProductID == k.productId && variant.VariantID == k.variantId && Cancellable(variant) {
+				ids = append(ids, variant.OrderVariantID)
+				selected++
+			}
+		}
+		if selected < asked[k] {
+			return nil, fmt.Errorf("requested cancel quantity %d of variant %d exceeds the cancellable quantity %d", asked[k], k.variantId, selected)
+		}
+	}
+	return ids, nil
+}
+
+// Full the variants are every variant of the order
+func Full(order domain.Model, orderVariantIds []int64) bool {
+	return len(orderVariantIds) == len(order.OrderVariants)
+}
+
+// Paid price the channel paid for each variant, the unit price of its product variant
+func Paid(order domain.Model, orderVariantIds []int64) map[int64]money.Money {
+	paid := make(map[int64]money.Money, len(orderVariantIds))
+	for _, id := range orderVariantIds {
+		for _, variant := range order.OrderVariants {
+			if variant.OrderVariantID != id {
+				continue
+			}
+			if price, ok := UnitPrice(order, variant.ProductID, variant.VariantID); ok {
+				paid[id] = price
+			}
+		}
+	}
+	return paid
+}
+
+// UnitPrice price the channel paid for one unit of the product variant
+func UnitPrice(order domain.Model, productId, variantId int64) (money.Money, bool) {
+	for _, selected := range order.SelectVariants {
+		if selected.ProductID != productId || selected.VariantID != variantId {
+			continue
+		}
+		if selected.SaleAmount != nil {
+			return selected.SaleAmount.Original, true
+		}
+		return money.Money{Amount: fx.Round(float64(selected.PartnerSalePrice), selected.Currency), Currency: selected.Currency}, true
+	}
+	return money.Money{}, false
+}
@@ -0,0 +1,52 @@
+package cancellation_test
+
+import (
+	"testing"
+
+	"swallow-supplier/mongo/domain/money"
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/cancellation"
+	"swallow-supplier/utils/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func order() domain.Model {
+	return domain.Model{
+		OrderId: 1,
+		SelectVariants: []domain.SelectVariant{
+			{ProductID: 100, VariantID: 1, Quantity: 3, Currency: "KRW", PartnerSalePrice: 12000},
+			{ProductID: 100, VariantID: 2, Quantity: 1, Currency: "KRW", PartnerSalePrice: 8000,
+				SaleAmount: &money.Conversion{Original: money.Money{Amount: 6.1, Currency: "USD"}}},
+		},
+		OrderVariants: []domain.OrderVariant{
+			{OrderVariantID: 11, ProductID: 100, VariantID: 1, OrderVariantStatusTypeCode: constant.ORDERVARIANTUSEDSTATUS},
+			{OrderVariantID: 12, ProductID: 100, VariantID: 1, OrderVariantStatusTypeCode: constant.ORDERVARIANTNOTUSEDSTATUS},
+			{OrderVariantID: 13, ProductID: 100, VariantID: 1, OrderVariantStatusTypeCode: constant.ORDERVARIANTNOTUSEDSTATUS},
+			{OrderVariantID: 21, ProductID: 100, VariantID: 2, OrderVariantStatusTypeCode: constant.ORDERVARIANTCANCELEDSTATUS},
+		},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	ids, err := cancellation.Select(order(), []cancellation.Unit{{ProductID: 100, VariantID: 1, Quantity: 2}})
+	require.NoError(t, err)
+	// the used unit is skipped
+	assert.Equal(t, []int64{12, 13}, ids)
+	assert.False(t, cancellation.Full(order(), ids))
+
+	_, err = cancellation.Select(order(), []cancellation.Unit{{ProductID: 100, VariantID: 1, Quantity: 3}})
+	assert.Error(t, err)
+
+	_, err = cancellation.Select(order(), []cancellation.Unit{{ProductID: 100, VariantID: 2, Quantity: 1}})
+	assert.Error(t, err)
+}
+
+func TestPaid(t *testing.T) {
+	paid := cancellation.Paid(order(), []int64{12, 21})
+	assert.Equal(t, map[int64]money.Money{
+		12: {Amount: 12000, Currency: "KRW"},
+		21: {Amount: 6.1, Currency: "USD"},
+	}, paid)
+}
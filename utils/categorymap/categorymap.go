@@ -0,0 +1,343 @@
+package categorymap
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Columns headers of the mapping sheet in sheet order, exports write them and imports find the
+// columns by them
+var Columns = []string{
+	"yanoljaCategoryId",
+	"yanoljaCategoryCode",
+	"yanoljaCategoryLevel",
+	"yanoljaCategoryKName",
+	"yanoljaCategoryEName",
+	"yanoljaCategoryStatusCode",
+	"GGTCategoryId",
+	"GGTCategoryName",
+	"GGTSubCategoryId",
+	"GGTSubCategoryName",
+	"tripCategoryName",
+}
+
+// numeric columns of the sheet
+var numeric = map[string]bool{
+	"yanoljaCategoryId":    true,
+	"yanoljaCategoryCode":  true,
+	"yanoljaCategoryLevel": true,
+	"GGTCategoryId":        true,
+	"GGTSubCategoryId":     true,
+}
+
+// groups header row above the columns of the xlsx sheet, kept so the sheet still loads with the
+// legacy upload which reads the headers from the second row
+var groups = map[int]string{0: "YANOLJA", 6: "GGT", 10: "TRIP.COM"}
+
+// RowError problems of one row of an uploaded sheet, Row is the row number in the sheet
+type RowError struct {
+	Row    int      `json:"row"`
+	Key    string   `json:"key,omitempty"`
+	Errors []string `json:"errors"`
+}
+
+// Entry mapping read from a row of an uploaded sheet
+type Entry struct {
+	Row     int
+	Mapping domain.CategoryMapping
+}
+
+// Change mapping whose stored fields differ from the upload
+type Change struct {
+	Key    string                 `json:"key"`
+	Fields []string               `json:"fields"`
+	Before domain.CategoryMapping `json:"before"`
+	After  domain.CategoryMapping `json:"after"`
+}
+
+// Diff what applying an upload changes. Missing mappings are stored but not in the upload, an
+// upload never deletes them
+type Diff struct {
+	Added     []domain.CategoryMapping `json:"added"`
+	Changed   []Change                 `json:"changed"`
+	Unchanged int                      `json:"unchanged"`
+	Missing   []domain.CategoryMapping `json:"missing"`
+	Invalid   []RowError               `json:"invalid"`
+}
+
+// ReadRows rows of the uploaded xlsx or csv file, told apart by the file name
+func ReadRows(fileName string, content []byte) ([][]string, error) {
+	name := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(name, ".xlsx"):
+		f, err := excelize.OpenReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open excel file: %w", err)
+		}
+		defer f.Close()
+
+		sheetName := f.GetSheetName(0)
+		if sheetName == "" {
+			return nil, errors.New("no sheets found in excel file")
+		}
+		return f.GetRows(sheetName)
+
+	case strings.HasSuffix(name, ".csv"):
+		reader := csv.NewReader(bytes.NewReader(content))
+		reader.FieldsPerRecord = -1
+		return reader.ReadAll()
+	}
+
+	return nil, fmt.Errorf("unsupported file %q, expected .xlsx or .csv", fileName)
+}
+
+// Parse mappings of the sheet rows. The header row is the first row naming the yanolja category
+// code column, rows above it are ignored. Rows which cannot be read are returned as errors
+func Parse(rows [][]string) ([]Entry, []RowError, error) {
+	header := -1
+	for r := 0; r < len(rows) && header < 0; r++ {
+		for _, cell := range rows[r] {
+			if strings.EqualFold(strings.TrimSpace(cell), "yanoljaCategoryCode") {
+				header = r
+				break
+			}
+		}
+	}
+	if header < 0 {
+		return nil, nil, errors.New("header row with yanoljaCategoryCode not found")
+	}
+
+	index := make(map[string]int)
+	for c, cell := range rows[header] {
+		index[strings.ToLower(strings.TrimSpace(cell))] = c
+	}
+
+	entries := make([]Entry, 0, len(rows)-header-1)
+	invalid := make([]RowError, 0)
+	for r := header + 1; r < len(rows); r++ {
+		row := rows[r]
+		cell := func(column string) string {
+			c, ok := index[strings.ToLower(column)]
+			if !ok || c >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[c])
+		}
+		if strings.Join(row, "") == "" {
+			continue
+		}
+
+		problems := make([]string, 0)
+		number := func(column string) int {
+			value := cell(column)
+			if value == "" {
+				return 0
+			}
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil || n != float64(int(n)) {
+				problems = append(problems, fmt.Sprintf("%s %q is not a whole number", column, value))
+				return 0
+			}
+			return int(n)
+		}
+
+		mapping := domain.CategoryMapping{
+			YanoljaCategoryId:         int64(number("yanoljaCategoryId")),
+			YanoljaCategoryCode:       number("yanoljaCategoryCode"),
+			YanoljaCategoryLevel:      number("yanoljaCategoryLevel"),
+			YanoljaCategoryKName:      cell("yanoljaCategoryKName"),
+			YanoljaCategoryEName:      cell("yanoljaCategoryEName"),
+			YanoljaCategoryStatusCode: cell("yanoljaCategoryStatusCode"),
+			GGTCategoryId:             number("GGTCategoryId"),
+			GGTCategoryName:           cell("GGTCategoryName"),
+			GGTSubCategoryId:          number("GGTSubCategoryId"),
+			GGTSubCategoryName:        cell("GGTSubCategoryName"),
+			TripCategoryName:          cell("tripCategoryName"),
+			SupplierGGTChannel:        constant.YANOLJAGGTTRIP,
+		}
+		if len(problems) > 0 {
+			invalid = append(invalid, RowError{Row: r + 1, Key: mapping.Key(), Errors: problems})
+			continue
+		}
+		entries = append(entries, Entry{Row: r + 1, Mapping: mapping})
+	}
+
+	return entries, invalid, nil
+}
+
+// Validate problems of the mapping, empty when it can be stored. yanolja holds the keys of the
+// stored yanolja categories, the check is skipped when it is nil
+func Validate(mapping domain.CategoryMapping, yanolja map[string]bool) []string {
+	problems := make([]string, 0)
+	if mapping.YanoljaCategoryCode <= 0 {
+		problems = append(problems, "yanoljaCategoryCode is required")
+	}
+	if mapping.YanoljaCategoryLevel <= 0 {
+		problems = append(problems, "yanoljaCategoryLevel is required")
+	}
+	if yanolja != nil && mapping.YanoljaCategoryCode > 0 && mapping.YanoljaCategoryLevel > 0 && !yanolja[mapping.Key()] {
+		problems = append(problems, fmt.Sprintf("yanolja category %d at level %d does not exist", mapping.YanoljaCategoryCode, mapping.YanoljaCategoryLevel))
+	}
+	if mapping.TripCategoryName == "" {
+		problems = append(problems, "tripCategoryName is required")
+	} else if !IsTripCategory(mapping.TripCategoryName) {
+		problems = append(problems, fmt.Sprintf("tripCategoryName %q is not a trip category code", mapping.TripCategoryName))
+	}
+	return problems
+}
+
+// IsTripCategory true when trip knows the category code, in any case
+func IsTripCategory(name string) bool {
+	for _, code := range constant.TRIPCATEGORYCODES {
+		if strings.EqualFold(code, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare diff of applying the upload to the stored mappings, the upload is validated and rows
+// repeating a category of an earlier row are invalid
+func Compare(stored []domain.CategoryMapping, upload []Entry, yanolja map[string]bool) Diff {
+	diff := Diff{
+		Added:   make([]domain.CategoryMapping, 0),
+		Changed: make([]Change, 0),
+		Missing: make([]domain.CategoryMapping, 0),
+		Invalid: make([]RowError, 0),
+	}
+
+	current := make(map[string]domain.CategoryMapping, len(stored))
+	for _, mapping := range stored {
+		current[mapping.Key()] = mapping
+	}
+
+	seen := make(map[string]bool, len(upload))
+	for _, entry := range upload {
+		mapping := entry.Mapping
+		key := mapping.Key()
+		problems := Validate(mapping, yanolja)
+		if seen[key] {
+			problems = append(problems, "category is mapped more than once in the file")
+		}
+		seen[key] = true
+		if len(problems) > 0 {
+			diff.Invalid = append(diff.Invalid, RowError{Row: entry.Row, Key: key, Errors: problems})
+			continue
+		}
+
+		before, exists := current[key]
+		if !exists {
+			diff.Added = append(diff.Added, mapping)
+			continue
+		}
+		if fields := changedFields(before, mapping); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, Change{Key: key, Fields: fields, Before: before, After: mapping})
+		} else {
+			diff.Unchanged++
+		}
+	}
+
+	for _, mapping := range stored {
+		if !seen[mapping.Key()] {
+			diff.Missing = append(diff.Missing, mapping)
+		}
+	}
+	sort.Slice(diff.Missing, func(i, j int) bool { return diff.Missing[i].Key() < diff.Missing[j].Key() })
+
+	return diff
+}
+
+// changedFields sheet columns whose values differ
+func changedFields(before domain.CategoryMapping, after domain.CategoryMapping) []string {
+	fields := make([]string, 0)
+	a, b := record(before), record(after)
+	for i, column := range Columns {
+		if a[i] != b[i] {
+			fields = append(fields, column)
+		}
+	}
+	return fields
+}
+
+// record values of the mapping in column order
+func record(m domain.CategoryMapping) []string {
+	return []string{
+		strconv.FormatInt(m.YanoljaCategoryId, 10),
+		strconv.Itoa(m.YanoljaCategoryCode),
+		strconv.Itoa(m.YanoljaCategoryLevel),
+		m.YanoljaCategoryKName,
+		m.YanoljaCategoryEName,
+		m.YanoljaCategoryStatusCode,
+		strconv.Itoa(m.GGTCategoryId),
+		m.GGTCategoryName,
+		strconv.Itoa(m.GGTSubCategoryId),
+		m.GGTSubCategoryName,
+		m.TripCategoryName,
+	}
+}
+
+// WriteCSV mappings as csv with the sheet columns
+func WriteCSV(w io.Writer, mappings []domain.CategoryMapping) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(Columns); err != nil {
+		return err
+	}
+	for _, mapping := range mappings {
+		if err := writer.Write(record(mapping)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteXLSX mappings in the layout of the mapping sheet, the upload reads it back unchanged
+func WriteXLSX(w io.Writer, mappings []domain.CategoryMapping) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+
+	groupRow := make([]interface{}, len(Columns))
+	for c := range Columns {
+		groupRow[c] = groups[c]
+	}
+	if err := f.SetSheetRow(sheet, "A1", &groupRow); err != nil {
+		return err
+	}
+	headerRow := make([]interface{}, len(Columns))
+	for c, column := range Columns {
+		headerRow[c] = column
+	}
+	if err := f.SetSheetRow(sheet, "A2", &headerRow); err != nil {
+		return err
+	}
+
+	for r, mapping := range mappings {
+		values := record(mapping)
+		row := make([]interface{}, len(values))
+		for c, value := range values {
+			if n, err := strconv.Atoi(value); err == nil && numeric[Columns[c]] {
+				row[c] = n
+			} else {
+				row[c] = value
+			}
+		}
+		cellName, _ := excelize.CoordinatesToCellName(1, r+3)
+		if err := f.SetSheetRow(sheet, cellName, &row); err != nil {
+			return err
+		}
+	}
+
+	return f.Write(w)
+}
@@ -0,0 +1,127 @@
+package categorymap_test
+
+import (
+	"bytes"
+	"testing"
+
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/categorymap"
+	"swallow-supplier/utils/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mapping(code int, trip string) domain.CategoryMapping {
+	return domain.CategoryMapping{
+		YanoljaCategoryId:    int64(code) + 1000,
+		YanoljaCategoryCode:  code,
+		YanoljaCategoryLevel: 3,
+		YanoljaCategoryEName: "Theme park",
+		GGTCategoryId:        2,
+		GGTCategoryName:      "Ticket",
+		TripCategoryName:     trip,
+		SupplierGGTChannel:   constant.YANOLJAGGTTRIP,
+	}
+}
+
+func TestParse(t *testing.T) {
+	// Test case 1: the legacy sheet has a group row above the headers
+	rows := [][]string{
+		{"YANOLJA", "", "TRIP.COM"},
+		{"yanoljaCategoryCode", "yanoljaCategoryLevel", "tripCategoryName"},
+		{"101", "3", "ATTRACTION_TICKET"},
+		{"", "", ""},
+		{"10x", "3", "DAY_TOUR"},
+	}
+	entries, invalid, err := categorymap.Parse(rows)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 3, entries[0].Row)
+	assert.Equal(t, "101|3", entries[0].Mapping.Key())
+	assert.Equal(t, constant.YANOLJAGGTTRIP, entries[0].Mapping.SupplierGGTChannel)
+	require.Len(t, invalid, 1)
+	assert.Equal(t, 5, invalid[0].Row)
+
+	// Test case 2: csv with the headers in the first row, in another order
+	content := []byte("tripCategoryName,yanoljaCategoryLevel,yanoljaCategoryCode\nday_tour,3,102\n")
+	rows, err = categorymap.ReadRows("mapping.CSV", content)
+	require.NoError(t, err)
+	entries, invalid, err = categorymap.Parse(rows)
+	require.NoError(t, err)
+	assert.Empty(t, invalid)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 102, entries[0].Mapping.YanoljaCategoryCode)
+	assert.Equal(t, "day_tour", entries[0].Mapping.TripCategoryName)
+
+	// Test case 3: no header row
+	_, _, err = categorymap.Parse([][]string{{"code", "level"}})
+	assert.Error(t, err)
+
+	_, err = categorymap.ReadRows("mapping.txt", content)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	yanolja := map[string]bool{"101|3": true}
+
+	assert.Empty(t, categorymap.Validate(mapping(101, "attraction_ticket"), yanolja))
+	assert.Len(t, categorymap.Validate(mapping(101, "THEME_PARK"), yanolja), 1)
+	assert.Len(t, categorymap.Validate(mapping(999, "DAY_TOUR"), yanolja), 1)
+	assert.Empty(t, categorymap.Validate(mapping(999, "DAY_TOUR"), nil))
+	assert.Len(t, categorymap.Validate(domain.CategoryMapping{}, nil), 3)
+}
+
+func TestCompare(t *testing.T) {
+	stored := []domain.CategoryMapping{
+		mapping(101, "ATTRACTION_TICKET"),
+		mapping(102, "DAY_TOUR"),
+		mapping(103, "MEALS"),
+	}
+	upload := []categorymap.Entry{
+		{Row: 2, Mapping: mapping(101, "ATTRACTION_TICKET")},
+		{Row: 3, Mapping: mapping(102, "CRUISE_TICKET")},
+		{Row: 4, Mapping: mapping(104, "GOLF")},
+		{Row: 5, Mapping: mapping(104, "GOLF")},
+		{Row: 6, Mapping: mapping(105, "")},
+	}
+
+	diff := categorymap.Compare(stored, upload, nil)
+	assert.Equal(t, 1, diff.Unchanged)
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "104|3", diff.Added[0].Key())
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, []string{"tripCategoryName"}, diff.Changed[0].Fields)
+	assert.Equal(t, "DAY_TOUR", diff.Changed[0].Before.TripCategoryName)
+	require.Len(t, diff.Missing, 1)
+	assert.Equal(t, "103|3", diff.Missing[0].Key())
+
+	require.Len(t, diff.Invalid, 2)
+	assert.Equal(t, 5, diff.Invalid[0].Row)
+	assert.Equal(t, 6, diff.Invalid[1].Row)
+}
+
+func TestWriteXLSX(t *testing.T) {
+	mappings := []domain.CategoryMapping{mapping(101, "ATTRACTION_TICKET"), mapping(102, "DAY_TOUR")}
+
+	var buf bytes.Buffer
+	require.NoError(t, categorymap.WriteXLSX(&buf, mappings))
+
+	rows, err := categorymap.ReadRows("category-mapping.xlsx", buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "YANOLJA", rows[0][0])
+
+	entries, invalid, err := categorymap.Parse(rows)
+	require.NoError(t, err)
+	assert.Empty(t, invalid)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 3, entries[0].Row)
+
+	// what was exported uploads back without changes
+	upload := []domain.CategoryMapping{entries[0].Mapping, entries[1].Mapping}
+	assert.Equal(t, mappings, upload)
+	diff := categorymap.Compare(mappings, entries, nil)
+	assert.Equal(t, 2, diff.Unchanged)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Changed)
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// manual breaker modes, AUTO hands the breaker back to gobreaker
+const (
+	BreakerForceOpen   = "OPEN"
+	BreakerForceClosed = "CLOSED"
+	BreakerAuto        = "AUTO"
+)
+
+// ErrInvalidBreakerMode mode is none of the manual breaker modes
+var ErrInvalidBreakerMode = errors.New("invalid breaker mode")
+
+// breakerRefresh how long a replica follows its last read of a forced mode before reading the
+// store again
+const breakerRefresh = 5 * time.Second
+
+// BreakerStore shares the forced modes of the breakers between the replicas, implemented by the
+// cache layer
+type BreakerStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value string) error
+	Delete(ctx context.Context, keys []string) error
+}
+
+// BreakerState snapshot of a circuit breaker
+type BreakerState struct {
+	Name                 string `json:"name"`
+	State                string `json:"state"`
+	Forced               string `json:"forced,omitempty"`
+	Requests             uint32 `json:"requests"`
+	TotalSuccesses       uint32 `json:"totalSuccesses"`
+	TotalFailures        uint32 `json:"totalFailures"`
+	ConsecutiveSuccesses uint32 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  uint32 `json:"consecutiveFailures"`
+}
+
+// Execute send the request through the breaker of the service, a forced open breaker rejects
+// the call and a forced closed one lets every call through
+func (cbm *CircuitBreakerManager) Execute(serviceName string, r *Request) (interface{}, error) {
+	switch cbm.forcedMode(serviceName) {
+	case BreakerForceOpen:
+		return nil, gobreaker.ErrOpenState
+	case BreakerForceClosed:
+		return r.SendRequest()
+	}
+
+	return cbm.GetBreaker(serviceName, r).Execute(r.SendRequest)
+}
+
+// SetStore keep the forced modes in the store, a breaker forced on one replica is followed by
+// every replica within breakerRefresh
+func (cbm *CircuitBreakerManager) SetStore(store BreakerStore) {
+	cbm.mutex.Lock()
+	defer cbm.mutex.Unlock()
+
+	cbm.store = store
+	cbm.refreshed = make(map[string]time.Time)
+}
+
+// Force set the manual mode of a breaker, leaving a forced mode starts again with clean counts.
+// The mode is written to the store first, so it is never applied to this replica only
+func (cbm *CircuitBreakerManager) Force(ctx context.Context, serviceName string, mode string) (BreakerState, error) {
+	switch mode {
+	case BreakerForceOpen, BreakerForceClosed, BreakerAuto:
+	default:
+		return BreakerState{}, fmt.Errorf("%w %q", ErrInvalidBreakerMode, mode)
+	}
+
+	cbm.mutex.RLock()
+	store := cbm.store
+	cbm.mutex.RUnlock()
+	if store != nil {
+		var err error
+		if mode == BreakerAuto {
+			err = store.Delete(ctx, []string{breakerForcedKey(serviceName)})
+		} else {
+			err = store.Set(ctx, breakerForcedKey(serviceName), mode)
+		}
+		if err != nil {
+			return BreakerState{}, fmt.Errorf("error storing breaker mode: %w", err)
+		}
+	}
+
+	r := NewRequest(CustomRequest{})
+	r.Ctx = context.Background()
+	breaker := r.FormatCircuitBreakerSettings(serviceName)
+
+	cbm.mutex.Lock()
+	cbm.setForced(serviceName, mode)
+	if _, exists := cbm.breakers[serviceName]; !exists || mode != BreakerForceOpen {
+		cbm.breakers[serviceName] = breaker
+	}
+	if cbm.refreshed != nil {
+		cbm.refreshed[serviceName] = time.Now()
+	}
+	cbm.mutex.Unlock()
+
+	return cbm.State(serviceName), nil
+}
+
+// forcedMode manual mode of the breaker, read again from the store once breakerRefresh passed.
+// The last known mode is kept while the store can not be read
+func (cbm *CircuitBreakerManager) forcedMode(serviceName string) string {
+	cbm.mutex.RLock()
+	store, mode, refreshed := cbm.store, cbm.forced[serviceName], cbm.refreshed[serviceName]
+	cbm.mutex.RUnlock()
+
+	if store == nil || time.Since(refreshed) < breakerRefresh {
+		return mode
+	}
+
+	stored, err := store.Get(context.Background(), breakerForcedKey(serviceName))
+	if err != nil {
+		// keep the last known mode until the next refresh instead of asking the store on every call
+		cbm.mutex.Lock()
+		cbm.refreshed[serviceName] = time.Now()
+		cbm.mutex.Unlock()
+		return mode
+	}
+
+	cbm.mutex.Lock()
+	defer cbm.mutex.Unlock()
+
+	if stored == "" {
+		stored = BreakerAuto
+	}
+	if mode == "" {
+		mode = BreakerAuto
+	}
+	if stored != mode && stored != BreakerForceOpen {
+		// leaving a forced mode on another replica starts again with clean counts here too
+		delete(cbm.breakers, serviceName)
+	}
+	cbm.setForced(serviceName, stored)
+	cbm.refreshed[serviceName] = time.Now()
+
+	return cbm.forced[serviceName]
+}
+
+// setForced callers hold the lock
+func (cbm *CircuitBreakerManager) setForced(serviceName string, mode string) {
+	if mode == BreakerAuto {
+		delete(cbm.forced, serviceName)
+		return
+	}
+	cbm.forced[serviceName] = mode
+}
+
+// breakerForcedKey store key of the forced mode of the breaker of the service
+func breakerForcedKey(serviceName string) string {
+	return "breaker|forced|" + serviceName
+}
+
+// State snapshot of the breaker of the service
+func (cbm *CircuitBreakerManager) State(serviceName string) BreakerState {
+	cbm.forcedMode(serviceName)
+
+	cbm.mutex.RLock()
+	defer cbm.mutex.RUnlock()
+
+	return cbm.state(serviceName)
+}
+
+// States snapshot of all breakers created so far, sorted by name
+func (cbm *CircuitBreakerManager) States() []BreakerState {
+	cbm.mutex.RLock()
+	defer cbm.mutex.RUnlock()
+
+	states := make([]BreakerState, 0, len(cbm.breakers))
+	for name := range cbm.breakers {
+		states = append(states, cbm.state(name))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+
+	return states
+}
+
+// state callers hold the lock
+func (cbm *CircuitBreakerManager) state(serviceName string) BreakerState {
+	state := BreakerState{
+		Name:   serviceName,
+		State:  gobreaker.StateClosed.String(),
+		Forced: cbm.forced[serviceName],
+	}
+
+	if breaker, exists := cbm.breakers[serviceName]; exists {
+		counts := breaker.Counts()
+		state.State = breaker.State().String()
+		state.Requests = counts.Requests
+		state.TotalSuccesses = counts.TotalSuccesses
+		state.TotalFailures = counts.TotalFailures
+		state.ConsecutiveSuccesses = counts.ConsecutiveSuccesses
+		state.ConsecutiveFailures = counts.ConsecutiveFailures
+	}
+
+	switch state.Forced {
+	case BreakerForceOpen:
+		state.State = gobreaker.StateOpen.String()
+	case BreakerForceClosed:
+		state.State = gobreaker.StateClosed.String()
+	}
+
+	return state
+}
@@ -0,0 +1,90 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"swallow-supplier/config"
+	"swallow-supplier/utils/client"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBreakers store shared by the replicas
+type memoryBreakers struct {
+	mutex sync.Mutex
+	modes map[string]string
+	down  bool
+	reads int
+}
+
+func (m *memoryBreakers) Get(_ context.Context, key string) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.reads++
+	if m.down {
+		return "", errors.New("store unavailable")
+	}
+	return m.modes[key], nil
+}
+
+func (m *memoryBreakers) Set(_ context.Context, key string, value string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.modes[key] = value
+	return nil
+}
+
+func (m *memoryBreakers) Delete(_ context.Context, keys []string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, key := range keys {
+		delete(m.modes, key)
+	}
+	return nil
+}
+
+func TestBreakerForcedModeShared(t *testing.T) {
+	config.SetInstance(&config.AppConfig{})
+	ctx := context.Background()
+	store := &memoryBreakers{modes: map[string]string{}}
+	manager := client.GetBreakerManager()
+	manager.SetStore(store)
+	defer manager.SetStore(nil)
+
+	// Test case 1: forcing a breaker writes the mode for the other replicas
+	state, err := manager.Force(ctx, "Forced", client.BreakerForceOpen)
+	require.NoError(t, err)
+	assert.Equal(t, client.BreakerForceOpen, state.Forced)
+	assert.Equal(t, client.BreakerForceOpen, store.modes["breaker|forced|Forced"])
+
+	_, err = manager.Force(ctx, "Forced", client.BreakerAuto)
+	require.NoError(t, err)
+	assert.NotContains(t, store.modes, "breaker|forced|Forced")
+
+	_, err = manager.Force(ctx, "Forced", "HALF")
+	assert.ErrorIs(t, err, client.ErrInvalidBreakerMode)
+
+	// Test case 2: a breaker forced open by another replica rejects calls here
+	store.modes["breaker|forced|Remote"] = client.BreakerForceOpen
+	assert.Equal(t, client.BreakerForceOpen, manager.State("Remote").Forced)
+	_, err = manager.Execute("Remote", &client.Request{})
+	assert.ErrorIs(t, err, gobreaker.ErrOpenState)
+}
+
+func TestBreakerForcedModeStoreDown(t *testing.T) {
+	config.SetInstance(&config.AppConfig{})
+	store := &memoryBreakers{modes: map[string]string{"breaker|forced|Flaky": client.BreakerForceOpen}, down: true}
+	manager := client.GetBreakerManager()
+	manager.SetStore(store)
+	defer manager.SetStore(nil)
+
+	// a failing store keeps the last known mode and is not read again on every call
+	assert.Empty(t, manager.State("Flaky").Forced)
+	assert.Empty(t, manager.State("Flaky").Forced)
+	assert.Equal(t, 1, store.reads)
+}
@@ -66,11 +66,16 @@ type Auth struct {
 type CustomRequest struct {
 	RequestTimeout string
 	Retries        int
+	// RetryPolicy overrides Retries, requests without policy retry transport errors only
+	RetryPolicy RetryPolicy
 }
 
 type CircuitBreakerManager struct {
-	breakers map[string]*gobreaker.CircuitBreaker
-	mutex    sync.RWMutex
+	breakers  map[string]*gobreaker.CircuitBreaker
+	forced    map[string]string
+	store     BreakerStore
+	refreshed map[string]time.Time
+	mutex     sync.RWMutex
 }
 
 var breakerManager *CircuitBreakerManager
@@ -433,8 +438,7 @@ func (r *Request) Send(ctx context.Context, serviceName string, url string, meth
 
 	var responseValue interface{}
 	if config.Instance().CircuitBreakerEnable == "1" {
-		cb := GetBreakerManager().GetBreaker(serviceName, r)
-		responseValue, err = cb.Execute(r.SendRequest)
+		responseValue, err = GetBreakerManager().Execute(serviceName, r)
 	} else {
 		responseValue, err = r.SendRequest()
 	}
@@ -495,28 +499,54 @@ func (r *Request) SendRequest() (resp interface{}, err error) {
 		}
 		resetBody(request, originalBody)
 	}
-	var retries = r.CustomRequest.Retries
-	for retries > 0 {
+	policy := r.CustomRequest.retryPolicy()
+	// one key per call, every retry of the call carries the same key
+	if header := policy.IdempotencyHeader(); header != "" && r.Method == http.MethodPost {
+		request.Header.Set(header, idempotencyKey(r.Ctx))
+	}
+
+	maxAttempts := policy.MaxAttempts()
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		logger.Log(
-			"Sending Request Attempt", retries,
+			"Sending Request Attempt", attempt,
 		)
 
-		//logger.Log("***** request body  sent *********** : ", request.Body)
+		var statusCode int
 		response, err = r.Client.Do(request)
 		if err != nil {
 			logger.Log(
 				"Sending HTTP Request Error", err.Error(),
 			)
+		} else {
+			statusCode = response.StatusCode
+		}
 
-			if request.Body != nil && len(originalBody) > 0 {
-				resetBody(request, originalBody)
-			}
+		if attempt == maxAttempts || !policy.Retryable(r.Method, statusCode, err) {
+			break
+		}
 
-			retries--
-			continue
+		var retryAfter string
+		if response != nil {
+			retryAfter = response.Header.Get("Retry-After")
+			// the response is dropped, release the connection before the next attempt
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+			response = nil
 		}
 
-		break
+		delay := policy.Backoff(attempt, retryAfter)
+		logger.Log(
+			"Retrying Request", attempt,
+			"Status Code", statusCode,
+			"Delay", delay.String(),
+		)
+		if err := sleepCtx(r.Ctx, delay); err != nil {
+			return resp, err
+		}
+
+		if request.Body != nil && len(originalBody) > 0 {
+			resetBody(request, originalBody)
+		}
 	}
 	if response != nil {
 		resp = NewResponse(response)
@@ -529,6 +559,7 @@ func GetBreakerManager() *CircuitBreakerManager {
 	once.Do(func() {
 		breakerManager = &CircuitBreakerManager{
 			breakers: make(map[string]*gobreaker.CircuitBreaker),
+			forced:   make(map[string]string),
 		}
 	})
 	return breakerManager
@@ -678,23 +709,35 @@ func (r *Request) FormatCircuitBreakerSettings(name string) *gobreaker.CircuitBr
 
 		defaultRequests             = 10
 		defaultFailureRatio float64 = 60
+		defaultInterval             = 5 * time.Second
+		defaultTimeout              = 3 * time.Second
 	)
 
-	if config.Instance().CircuitBreakerRequests != "" {
-		defaultRequests, _ = strconv.Atoi(config.Instance().CircuitBreakerRequests)
+	cbConfig := config.Instance().CircuitBreaker(name)
+
+	if cbConfig.Requests != "" {
+		defaultRequests, _ = strconv.Atoi(cbConfig.Requests)
+	}
+
+	if cbConfig.FailureRatio != "" {
+		defaultFailureRatio, _ = strconv.ParseFloat(cbConfig.FailureRatio, 64)
+	}
+
+	if d, err := time.ParseDuration(cbConfig.Interval); err == nil && d > 0 {
+		defaultInterval = d
 	}
 
-	if config.Instance().CircuitBreakerFailureRatio != "" {
-		defaultFailureRatio, _ = strconv.ParseFloat(config.Instance().CircuitBreakerFailureRatio, 64)
+	if d, err := time.ParseDuration(cbConfig.Timeout); err == nil && d > 0 {
+		defaultTimeout = d
 	}
 
 	defaultFailureRatio = (defaultFailureRatio / 100)
 
 	settings.Name = name
 	// When to flush the internal counts in the Closed state
-	settings.Interval = 5 * time.Second
+	settings.Interval = defaultInterval
 	// Describes how often we should recheck the service health and switch to Half-Open
-	settings.Timeout = 3 * time.Second
+	settings.Timeout = defaultTimeout
 	// Checks when to switch from Closed to Open
 	settings.ReadyToTrip = func(counts gobreaker.Counts) bool {
 		// circuit breaker will trip when 60% of requests failed
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	mrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IdempotencyKeyHeader header carrying the idempotency key of a POST
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultRetryStatuses status codes retried when the policy does not declare its own
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy decides whether and when a request is sent again
+type RetryPolicy interface {
+	// MaxAttempts total number of attempts including the first one
+	MaxAttempts() int
+
+	// Retryable reports whether the outcome of an attempt should be retried,
+	// statusCode is 0 when err is a transport error
+	Retryable(method string, statusCode int, err error) bool
+
+	// Backoff delay before the given attempt (starting from 1) is retried, retryAfter is the
+	// Retry-After header of the failed response
+	Backoff(attempt int, retryAfter string) time.Duration
+
+	// IdempotencyHeader header used to send the idempotency key of POSTs, empty to send none
+	IdempotencyHeader() string
+}
+
+// BackoffPolicy exponential backoff with jitter
+type BackoffPolicy struct {
+	// Attempts total number of attempts including the first one
+	Attempts int
+	// BaseDelay delay before the first retry, doubled for every next retry
+	BaseDelay time.Duration
+	// MaxDelay upper bound of a single delay, Retry-After included
+	MaxDelay time.Duration
+	// Jitter fraction (0..1) of the delay which is randomised
+	Jitter float64
+	// RetryStatuses status codes to retry, DefaultRetryStatuses when empty
+	RetryStatuses []int
+	// RetryNonIdempotent retry POST and PATCH, only safe when the remote honours the idempotency key
+	RetryNonIdempotent bool
+	// IdempotencyKeyHeader header carrying the idempotency key of POSTs, empty to send none
+	IdempotencyKeyHeader string
+}
+
+// MaxAttempts total number of attempts including the first one
+func (p BackoffPolicy) MaxAttempts() int {
+	if p.Attempts < 1 {
+		return 1
+	}
+	return p.Attempts
+}
+
+// Retryable transport errors and the configured status codes are retried,
+// non idempotent methods only when RetryNonIdempotent is set
+func (p BackoffPolicy) Retryable(method string, statusCode int, err error) bool {
+	if !p.RetryNonIdempotent && (method == http.MethodPost || method == http.MethodPatch) {
+		return false
+	}
+
+	if err != nil {
+		// the caller gave up, another attempt would fail the same way
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	statuses := p.RetryStatuses
+	if len(statuses) == 0 {
+		statuses = DefaultRetryStatuses
+	}
+	for _, status := range statuses {
+		if status == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Backoff exponential delay with jitter, a Retry-After sent by the remote wins over the computed delay
+func (p BackoffPolicy) Backoff(attempt int, retryAfter string) time.Duration {
+	if wait, ok := ParseRetryAfter(retryAfter, time.Now()); ok {
+		return p.capDelay(wait)
+	}
+
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay = delay*(1-jitter) + delay*jitter*mrand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+// IdempotencyHeader header carrying the idempotency key of POSTs
+func (p BackoffPolicy) IdempotencyHeader() string {
+	return p.IdempotencyKeyHeader
+}
+
+func (p BackoffPolicy) capDelay(delay time.Duration) time.Duration {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// legacyRetryPolicy behaviour of requests without a policy, retry transport errors without delay.
+// Retries is the total number of attempts as before, a request with Retries 0 is not sent at all
+type legacyRetryPolicy struct {
+	retries int
+}
+
+func (p legacyRetryPolicy) MaxAttempts() int {
+	if p.retries < 0 {
+		return 0
+	}
+	return p.retries
+}
+
+func (p legacyRetryPolicy) Retryable(method string, statusCode int, err error) bool {
+	return err != nil
+}
+
+func (p legacyRetryPolicy) Backoff(attempt int, retryAfter string) time.Duration {
+	return 0
+}
+
+func (p legacyRetryPolicy) IdempotencyHeader() string {
+	return ""
+}
+
+// retryPolicy policy of the request, falls back to the plain Retries count
+func (cr CustomRequest) retryPolicy() RetryPolicy {
+	if cr.RetryPolicy != nil {
+		return cr.RetryPolicy
+	}
+	return legacyRetryPolicy{retries: cr.Retries}
+}
+
+// ParseRetryAfter parse Retry-After given in seconds or as http date
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := at.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey use the given key for POSTs sent with the context instead of a random one,
+// callers pass a business key (e.g. partnerOrderId) so a replay from another process is recognised too
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// idempotencyKey key of the context or a new random one
+func idempotencyKey(ctx context.Context) string {
+	if ctx != nil {
+		if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok && key != "" {
+			return key
+		}
+	}
+
+	b := make([]byte, 16)
+	crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sleepCtx wait for the delay unless the context is done first
+func sleepCtx(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"swallow-supplier/utils/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffPolicy(t *testing.T) {
+	policy := client.BackoffPolicy{
+		Attempts:  4,
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+	}
+
+	// Test case 1: delay doubles and is capped
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1, ""))
+	assert.Equal(t, 400*time.Millisecond, policy.Backoff(3, ""))
+	assert.Equal(t, time.Second, policy.Backoff(10, ""))
+
+	// Test case 2: Retry-After wins and is capped
+	assert.Equal(t, time.Second, policy.Backoff(1, "1"))
+	assert.Equal(t, time.Second, policy.Backoff(1, "120"))
+
+	// Test case 3: status classification, POSTs are not retried by default
+	assert.True(t, policy.Retryable(http.MethodGet, http.StatusServiceUnavailable, nil))
+	assert.True(t, policy.Retryable(http.MethodGet, http.StatusTooManyRequests, nil))
+	assert.False(t, policy.Retryable(http.MethodGet, http.StatusBadRequest, nil))
+	assert.False(t, policy.Retryable(http.MethodPost, http.StatusServiceUnavailable, nil))
+
+	// the caller giving up is not retried, even wrapped by the transport
+	assert.False(t, policy.Retryable(http.MethodGet, 0, &url.Error{Op: "Get", URL: "http://trip", Err: context.DeadlineExceeded}))
+	assert.False(t, policy.Retryable(http.MethodGet, 0, &url.Error{Op: "Get", URL: "http://trip", Err: context.Canceled}))
+	assert.True(t, policy.Retryable(http.MethodGet, 0, &url.Error{Op: "Get", URL: "http://trip", Err: io.ErrUnexpectedEOF}))
+
+	// Test case 4: jitter keeps the delay within the window
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		delay := policy.Backoff(2, "")
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 200*time.Millisecond)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	wait, ok := client.ParseRetryAfter("3", now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, ok = client.ParseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, wait)
+
+	_, ok = client.ParseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestSendRequestRetry(t *testing.T) {
+	var calls int32
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(client.IdempotencyKeyHeader))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	request := client.NewRequest(client.CustomRequest{
+		RetryPolicy: client.BackoffPolicy{
+			Attempts:             3,
+			BaseDelay:            time.Millisecond,
+			RetryNonIdempotent:   true,
+			IdempotencyKeyHeader: client.IdempotencyKeyHeader,
+		},
+	})
+
+	request.Ctx = client.WithIdempotencyKey(context.Background(), "order-1")
+	request.Method = http.MethodPost
+	request.URL = server.URL
+	request.ContentType = client.ContentTypeJSON
+	request.Data = []byte(`{"a":"b"}`)
+
+	resp, err := request.SendRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.(*client.Response).Status)
+	assert.Equal(t, int32(3), calls)
+	assert.Equal(t, []string{"order-1", "order-1", "order-1"}, keys)
+}
+
+func TestSendRequestLegacyRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// without a policy Retries counts the attempts, a status code is never retried
+	request := client.NewRequest(client.CustomRequest{Retries: 3})
+	request.Ctx = context.Background()
+	request.Method = http.MethodGet
+	request.URL = server.URL
+
+	resp, err := request.SendRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.(*client.Response).Status)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Retries 0 sends nothing, as before the retry policies
+	request = client.NewRequest(client.CustomRequest{})
+	request.Ctx = context.Background()
+	request.Method = http.MethodGet
+	request.URL = server.URL
+
+	_, err = request.SendRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
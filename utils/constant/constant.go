@@ -1,6 +1,10 @@
 package constant
 
 var PRODUCTVARIANTSTATUSCODE = []string{"WAITING_FOR_SALE", "IN_SALE", "SOLD_OUT", "END_OF_SALE"}
+
+// TRIPCATEGORYCODES product category codes trip accepts, mappings store them in any case
+var TRIPCATEGORYCODES = []string{"ATTRACTION_TICKET", "SHOW_EVENT_TICKET", "MULTI_ATTRACTION_TICKETS", "CRUISE_TICKET", "DAY_TOUR", "HELICOPTER_TOURS", "HOT_AIR_BALLOON_RIDES", "PARACHUTING", "JUNGLE_ZIPLINING", "DIVING", "ROCK_CLIMBING", "WI_FI", "SPEEDBOATING", "SEAPLANE_RIDES", "SAILING", "TOUR_GUIDE", "BOAT_SPEEDBOAT_TICKETS", "AIRPORT_TRANSFERS", "POINT_TO_POINT_TRANSFER", "MEALS", "GOLF", "CYCLING", "OFF_ROADING", "CLASSES_COURSES", "SKIING", "PARAGLIDING", "CANOEING_KAYAKING", "SPA_TREATMENTS", "TRAVEL_PHOTO_SHOOTS", "SIGHTSEEING_BUS_RIDES", "SOUVENIRS"}
+
 var RECONCILESTATUSUNKNOWN string = "UNKNOWN" // this is for GGT use not for yanolja
 var RECONCILESTATUSCREATED string = "CREATED"
 var RECONCILESTATUSUSED string = "USED"
@@ -80,6 +84,9 @@ var Tag = map[string]int{
 const SUPPLIERYANOLJA = "Yanolja"
 const SUPPLIERTRAVOLUTION = "Travolution"
 
+// TRAVOLUTIONPLUPREFIX first segment of travolution plu values "TRV|productUid|optionUid|unitUid|date|time"
+const TRAVOLUTIONPLUPREFIX = "TRV"
+
 const YANOLJAGGTTRIP = "Yanolja-GGT-Trip"
 
 const REFUNDDIRECT = "DIRECT"
@@ -89,14 +96,74 @@ const TRIPPREORDERREQUEST = "PreOrder"
 const TRIPPAYMENTREQUEST = "Payment"
 
 const TRIPFULLORDERCANCELREQUEST = "FullCancel"
+const TRIPPARTIALCANCELREQUEST = "PartialCancel"
+
+// TRIPORDERSUPPLIER suffix of the otaOrderId key holding the supplier of a trip order booked
+// outside yanolja
+const TRIPORDERSUPPLIER = "Supplier"
 
 const VALIDSTAUS = "VALID"
 const EXPIREDSTAUS = "EXPIRED"
 
+// MARKUPPERCENTAGE markup used when no pricing rule matches
 var MARKUPPERCENTAGE float32 = 3.0
 
 const PERCENTAGE = "PERCENTAGE"
 const FLATVALUE = "FLATVALUE"
+
+// pricing rule rounding modes
+const ROUNDINGNONE = "NONE"
+const ROUNDINGFLOOR = "FLOOR"
+const ROUNDINGCEIL = "CEIL"
+const ROUNDINGROUND = "ROUND"
+
+// scopes of config/auth.yml scope_definition
+const (
+	ScopeClient    = 0
+	ScopeSuperUser = 99
+)
+
+// ApiClientCacheSeconds how long a resolved api client is trusted before it is read again,
+// a revoked key stops working on other instances within this period
+const ApiClientCacheSeconds = 30
+
+// ApiClientUnknownCacheSeconds how long a key without an api client is refused without reading mongo again
+const ApiClientUnknownCacheSeconds = 5
+
+// ApiClientUnknownCacheSize max unknown keys remembered, the remembered keys are dropped once it is reached
+const ApiClientUnknownCacheSize = 10000
+
+// WebhookSignatureToleranceSeconds max age of a signed callback timestamp, in either direction
+const WebhookSignatureToleranceSeconds = 300
+
+// JobLeaseSeconds scheduler lease period, a standby replica takes the jobs over within it
+const JobLeaseSeconds = 15
+
+// ProductBatchSize products processed together by the product change stream and the sweep
+const ProductBatchSize = 200
+
+// ProductChangeWindowMillis how long the product change stream keeps collecting changes into one batch
+const ProductChangeWindowMillis = 500
+
+// TripRequestWindowSeconds max distance of the trip envelope requestTime from now, in either direction
+const TripRequestWindowSeconds = 300
+
+// webhook signature modes, report records failed verifications without rejecting the callback
+// of a supplier which has no signing secret. Suppliers with a secret are always enforced
+const (
+	WEBHOOKSIGNATUREREPORT  = "report"
+	WEBHOOKSIGNATUREENFORCE = "enforce"
+)
+
+// supplier callback bodies, larger ones are refused and only the start of an unverified one is
+// recorded
+const (
+	WebhookMaxBodyBytes        = 1 << 20
+	WebhookUnverifiedBodyBytes = 1024
+)
+
+// PricingRuleCacheSeconds how long loaded pricing rules are used before reading them again
+const PricingRuleCacheSeconds = 60
 const DEFAULTCURRENCY = "KRW"
 const ORDERCOMPLETE = "CONFIRMED"
 const ORDERDONE = "DONE"
@@ -115,41 +182,6 @@ const BOOKINGPENDING = "Pending"
 const BOOKINGAPPROVED = "Approved" // confirm
 const BOOKINGREJECTED = "Rejected"
 
-type Margine struct {
-	ProductId   int64
-	MargineType string
-	Value       float32
-}
-
-// EverLand record for margine  dev : {10014508, 10012712} prod :{10240621,10240654}
-var MARGINEDETAIL = []Margine{
-	{
-		ProductId:   10240621,
-		MargineType: "FLATVALUE",
-		Value:       1900,
-	}, {
-		ProductId:   10240654,
-		MargineType: "FLATVALUE",
-		Value:       1900,
-	}, {
-		ProductId:   10248417,
-		MargineType: "PERCENTAGE",
-		Value:       1.6,
-	}, {
-		ProductId:   10012383,
-		MargineType: "PERCENTAGE",
-		Value:       1.6,
-	}, {
-		ProductId:   10014508,
-		MargineType: "FLATVALUE",
-		Value:       1900,
-	}, {
-		ProductId:   10012712,
-		MargineType: "FLATVALUE",
-		Value:       1900,
-	},
-}
-
 var ProductForGlobaltix = [...]int64{
 	10017468,
 	10014508,
@@ -199,3 +231,105 @@ const (
 	QR         = "2D"
 	ImageOrPdf = "File"
 )
+
+// OutboxStatus enum for notification outbox
+const (
+	OutboxStatusPending = "PENDING"
+	OutboxStatusSent    = "SENT"
+	OutboxStatusDead    = "DEAD"
+)
+
+// OutboxKind enum for notification outbox
+const (
+	OutboxKindTripNotify = "TRIP_NOTIFY"
+	OutboxKindPdfVoucher = "PDF_VOUCHER"
+)
+
+// retry policy of the notification outbox
+const (
+	OutboxMaxAttempts        = 8
+	OutboxBaseBackoffSeconds = 30
+	OutboxMaxBackoffSeconds  = 3600
+	OutboxLeaseSeconds       = 120
+	OutboxDeliverySeconds    = 90 // bound of one delivery with its retries, below the lease
+	OutboxBatchSize          = 50
+)
+
+// JobTrigger enum for job runs
+const (
+	JobTriggerSchedule = "SCHEDULE"
+	JobTriggerManual   = "MANUAL"
+	JobTriggerStartup  = "STARTUP"
+)
+
+// JobOutcome enum for job runs
+const (
+	JobOutcomeSuccess = "SUCCESS"
+	JobOutcomeFailed  = "FAILED"
+	JobOutcomeTimeout = "TIMEOUT"
+)
+
+// JobConcurrency enum, FORBID skips a run while the previous one is still going
+const (
+	JobConcurrencyForbid = "FORBID"
+	JobConcurrencyAllow  = "ALLOW"
+)
+
+// job registry tuning, scheduled runs of jobs running more often than JobQuietRunSeconds are only
+// recorded when they fail or handle items
+const (
+	JobSyncSeconds      = 5
+	JobRunRetentionDays = 7
+	JobQuietRunSeconds  = 60
+)
+
+// odoo sync paging, events younger than the settle period are held back so a sequence
+// taken by a write still in flight is not skipped by the cursor
+const (
+	OdooSyncPageSize      = 100
+	OdooSyncMaxPageSize   = 1000
+	OdooSyncSettleSeconds = 2
+)
+
+// reconciliation report, prices within the tolerance are not drift. Orders are compared in batches
+const (
+	ReconcilePriceTolerance = 0.01
+	ReconcileBatchSize      = 200
+	ReconcilePageSize       = 100
+)
+
+// pre order hold expiry, orders are expired in batches and an order failing to expire is tried
+// again on the next runs until it ran out of attempts
+const (
+	DefaultHoldMinutes       = 30
+	HoldExpiryBatchSize      = 100
+	HoldExpiryMaxAttempts    = 5
+	HoldExpiryOutcomeExpired = "EXPIRED"
+	HoldExpiryOutcomeFailed  = "FAILED"
+)
+
+// category mapping upload, larger sheets are refused
+const CategoryMappingUploadMaxSize = 10 << 20
+
+// babel translation, texts are sent in batches and products are translated into the default
+// language unless others are asked for
+const (
+	TranslationBatchSize   = 50
+	TranslationDefaultLang = "EN-US"
+)
+
+// refund policies, curated ones are maintained in the back office and the others are parsed
+// from the refund text of the variant. Visit dates are counted down in the supplier timezone
+const (
+	RefundPolicyCurated  = "CURATED"
+	RefundPolicyParsed   = "PARSED"
+	RefundPolicyDefault  = "DEFAULT"
+	RefundPolicyTimezone = "Asia/Seoul"
+)
+
+// variant inventory cache, live inventories are kept for a short while so that pre orders and
+// availability queries of the same variant, date and round do not all reach the supplier
+const (
+	InventoryCacheKeyPrefix = "inventory"
+	InventoryCacheSeconds   = 60
+)
@@ -0,0 +1,132 @@
+package envelope
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"swallow-supplier/common/go-tools/secure"
+	"swallow-supplier/request_response/trip"
+)
+
+// envelope modes, plaintext trusts the json body as is and stays the default until trip sends
+// the encrypted envelope. Encrypted mode refuses every request until trip's sign scheme is agreed
+const (
+	ModeEncrypted = "encrypted"
+	ModePlaintext = "plaintext"
+)
+
+// RequestTimeLayout requestTime format of trip, times without a zone are china standard time
+const RequestTimeLayout = "2006-01-02 15:04:05"
+
+var chinaStandardTime = time.FixedZone("CST", 8*60*60)
+
+var (
+	ErrInvalidKey         = errors.New("envelope key must be a hex encoded 16, 24 or 32 byte aes key")
+	ErrMissingSignKey     = errors.New("envelope sign key is not configured")
+	ErrMissingSign        = errors.New("envelope sign missing")
+	ErrSignNotAgreed      = errors.New("envelope sign scheme of trip is not agreed yet")
+	ErrInvalidRequestTime = errors.New("invalid requestTime")
+	ErrStaleRequest       = errors.New("requestTime outside the allowed window")
+	ErrDecrypt            = errors.New("envelope body could not be decrypted")
+)
+
+// Keys configured keys of the envelope
+type Keys struct {
+	AESKey  string
+	SignKey string
+}
+
+// Open verifies the sign and requestTime of the envelope and returns the request with the
+// decrypted body. Requests older or newer than window are rejected as replays
+func Open(req trip.SwallowRequest, keys Keys, now time.Time, window time.Duration) (trip.SwallowRequest, error) {
+	if err := validateKey(keys.AESKey); err != nil {
+		return req, err
+	}
+	// an empty key signs anything, the sign check would mean nothing
+	if keys.SignKey == "" {
+		return req, ErrMissingSignKey
+	}
+	if req.Header.Sign == "" {
+		return req, ErrMissingSign
+	}
+	if err := verifySign(keys.SignKey, req); err != nil {
+		return req, err
+	}
+
+	requestTime, err := ParseRequestTime(req.Header.RequestTime)
+	if err != nil {
+		return req, err
+	}
+	skew := now.Sub(requestTime)
+	if skew > window || skew < -window {
+		return req, ErrStaleRequest
+	}
+
+	plain, err := decrypt(req.DecryptedBody, keys.AESKey)
+	if err != nil {
+		return req, err
+	}
+	req.DecryptedBody = plain
+
+	return req, nil
+}
+
+// Seal encrypts the json of body with the aes key
+func Seal(body interface{}, aesKey string) (string, error) {
+	if err := validateKey(aesKey); err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	return secure.AESEncrypt(string(raw), aesKey), nil
+}
+
+// ParseRequestTime accepts RFC3339 or RequestTimeLayout, optionally with fractional seconds
+func ParseRequestTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(RequestTimeLayout, value, chinaStandardTime); err == nil {
+		return t, nil
+	}
+	return time.Time{}, ErrInvalidRequestTime
+}
+
+// verifySign trip has not shared how the sign is built, a guessed scheme would either refuse
+// trip's requests or accept forged ones, so every envelope is refused until it is agreed
+func verifySign(signKey string, req trip.SwallowRequest) error {
+	return ErrSignNotAgreed
+}
+
+func validateKey(aesKey string) error {
+	key, err := hex.DecodeString(aesKey)
+	if err != nil {
+		return ErrInvalidKey
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	}
+	return ErrInvalidKey
+}
+
+// decrypt secure.AESDecrypt panics on malformed input, that is turned into ErrDecrypt
+func decrypt(ciphertext, aesKey string) (plain string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			plain, err = "", fmt.Errorf("%w: %v", ErrDecrypt, r)
+		}
+	}()
+
+	if len(ciphertext) <= 24 {
+		return "", ErrDecrypt
+	}
+	return secure.AESDecrypt(ciphertext, aesKey), nil
+}
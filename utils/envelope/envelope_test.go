@@ -0,0 +1,62 @@
+package envelope_test
+
+import (
+	"testing"
+	"time"
+
+	"swallow-supplier/common/go-tools/secure"
+	"swallow-supplier/request_response/trip"
+	"swallow-supplier/utils/envelope"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var keys = envelope.Keys{
+	AESKey:  "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+	SignKey: "sign-key",
+}
+
+func TestOpen(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	req := trip.SwallowRequest{
+		Header:        trip.SwallowHeader{ServiceName: "CreatePreOrder", RequestTime: "2025-03-01T10:00:00Z", Sign: "0a1b"},
+		DecryptedBody: secure.AESEncrypt(`{"otaOrderId":"1234"}`, keys.AESKey),
+	}
+
+	// the sign scheme of trip is not agreed, no envelope is opened yet
+	_, err := envelope.Open(req, keys, now, 5*time.Minute)
+	assert.ErrorIs(t, err, envelope.ErrSignNotAgreed)
+
+	unsigned := req
+	unsigned.Header.Sign = ""
+	_, err = envelope.Open(unsigned, keys, now, 5*time.Minute)
+	assert.ErrorIs(t, err, envelope.ErrMissingSign)
+
+	_, err = envelope.Open(req, envelope.Keys{AESKey: keys.AESKey}, now, 5*time.Minute)
+	assert.ErrorIs(t, err, envelope.ErrMissingSignKey)
+
+	_, err = envelope.Open(req, envelope.Keys{AESKey: "short", SignKey: keys.SignKey}, now, 5*time.Minute)
+	assert.ErrorIs(t, err, envelope.ErrInvalidKey)
+}
+
+func TestParseRequestTime(t *testing.T) {
+	parsed, err := envelope.ParseRequestTime("2025-03-01 18:01:00.123")
+	assert.NoError(t, err)
+	assert.True(t, parsed.Equal(time.Date(2025, 3, 1, 10, 1, 0, 123000000, time.UTC)))
+
+	parsed, err = envelope.ParseRequestTime("2025-03-01T09:50:00Z")
+	assert.NoError(t, err)
+	assert.True(t, parsed.Equal(time.Date(2025, 3, 1, 9, 50, 0, 0, time.UTC)))
+
+	_, err = envelope.ParseRequestTime("yesterday")
+	assert.ErrorIs(t, err, envelope.ErrInvalidRequestTime)
+}
+
+func TestSeal(t *testing.T) {
+	sealedBody, err := envelope.Seal(map[string]string{"otaOrderId": "1234"}, keys.AESKey)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"otaOrderId":"1234"}`, secure.AESDecrypt(sealedBody, keys.AESKey))
+
+	_, err = envelope.Seal("x", "short")
+	assert.ErrorIs(t, err, envelope.ErrInvalidKey)
+}
@@ -0,0 +1,90 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"swallow-supplier/mongo/domain/money"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DateLayout layout of the asOf date of the rates
+const DateLayout = "2006-01-02"
+
+// ErrRateNotFound no rate known for the currency pair on or before the date
+var ErrRateNotFound = errors.New("fx rate not found")
+
+// zeroDecimal currencies without minor units
+var zeroDecimal = map[string]bool{
+	"KRW": true,
+	"JPY": true,
+	"VND": true,
+	"IDR": true,
+	"TWD": true,
+}
+
+// RateSource loads the latest rate of a pair on or before a date, implemented by the mongo repository
+type RateSource interface {
+	GetFxRate(ctx context.Context, base, quote, asOf string) (rate money.FxRate, err error)
+}
+
+// Convert amount into the settlement currency with the latest rate known at the given time.
+// Equal currencies convert at rate 1 without a lookup, a missing direct pair is looked up inverted
+func Convert(ctx context.Context, src RateSource, amount money.Money, settlementCurrency string, at time.Time) (conversion money.Conversion, err error) {
+	from := strings.ToUpper(amount.Currency)
+	to := strings.ToUpper(settlementCurrency)
+
+	conversion.Original = money.Money{Amount: amount.Amount, Currency: from}
+	if from == "" || from == to {
+		conversion.Original.Currency = to
+		conversion.Settlement = money.Money{Amount: amount.Amount, Currency: to}
+		conversion.Rate = 1
+		return conversion, nil
+	}
+
+	if at.IsZero() {
+		at = time.Now()
+	}
+	asOf := at.UTC().Format(DateLayout)
+
+	rate, err := src.GetFxRate(ctx, from, to, asOf)
+	switch {
+	case err == nil:
+		conversion.Rate = rate.Rate
+	case errors.Is(err, mongo.ErrNoDocuments):
+		rate, err = src.GetFxRate(ctx, to, from, asOf)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return conversion, fmt.Errorf("%w: %s/%s on %s", ErrRateNotFound, from, to, asOf)
+		}
+		if err != nil {
+			return conversion, err
+		}
+		if rate.Rate == 0 {
+			return conversion, fmt.Errorf("%w: %s/%s has a zero rate", ErrRateNotFound, to, from)
+		}
+		conversion.Rate = 1 / rate.Rate
+	default:
+		return conversion, err
+	}
+
+	conversion.RateAsOf = rate.AsOf
+	conversion.Settlement = money.Money{
+		Amount:   Round(amount.Amount*conversion.Rate, to),
+		Currency: to,
+	}
+
+	return conversion, nil
+}
+
+// Round amount to the minor unit of the currency
+func Round(amount float64, currency string) float64 {
+	if zeroDecimal[strings.ToUpper(currency)] {
+		return math.Round(amount)
+	}
+	return math.Round(amount*100) / 100
+}
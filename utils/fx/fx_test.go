@@ -0,0 +1,52 @@
+package fx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"swallow-supplier/mongo/domain/money"
+	"swallow-supplier/utils/fx"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type rateSource map[string]money.FxRate
+
+func (s rateSource) GetFxRate(ctx context.Context, base, quote, asOf string) (money.FxRate, error) {
+	if rate, ok := s[base+quote]; ok {
+		return rate, nil
+	}
+	return money.FxRate{}, mongo.ErrNoDocuments
+}
+
+func TestConvert(t *testing.T) {
+	src := rateSource{
+		"USDKRW": {Base: "USD", Quote: "KRW", Rate: 1385.4, AsOf: "2026-10-01"},
+	}
+	at := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+
+	// Test case 1: same currency needs no rate
+	conversion, err := fx.Convert(context.Background(), src, money.Money{Amount: 1000, Currency: "krw"}, "KRW", at)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), conversion.Rate)
+	assert.Equal(t, money.Money{Amount: 1000, Currency: "KRW"}, conversion.Settlement)
+
+	// Test case 2: direct pair, rounded to whole won
+	conversion, err = fx.Convert(context.Background(), src, money.Money{Amount: 10.5, Currency: "USD"}, "KRW", at)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(14547), conversion.Settlement.Amount)
+	assert.Equal(t, "2026-10-01", conversion.RateAsOf)
+	assert.Equal(t, money.Money{Amount: 10.5, Currency: "USD"}, conversion.Original)
+
+	// Test case 3: inverted pair, rounded to cents
+	conversion, err = fx.Convert(context.Background(), src, money.Money{Amount: 13854, Currency: "KRW"}, "USD", at)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(10), conversion.Settlement.Amount)
+
+	// Test case 4: unknown pair
+	_, err = fx.Convert(context.Background(), src, money.Money{Amount: 1, Currency: "EUR"}, "KRW", at)
+	assert.True(t, errors.Is(err, fx.ErrRateNotFound))
+}
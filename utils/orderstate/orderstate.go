@@ -0,0 +1,213 @@
+package orderstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+)
+
+// sources of a transition
+const (
+	SourceTrip      = "TRIP"
+	SourceYanolja   = "YANOLJA_CALLBACK"
+	SourceAdmin     = "ADMIN"
+	SourceScheduler = "SCHEDULER"
+)
+
+// events which move an order or its variants
+const (
+	EventConfirmed       = "CONFIRMED"
+	EventCancelRequested = "CANCEL_REQUESTED"
+	EventCancelAcked     = "CANCEL_ACKNOWLEDGED"
+	EventCancelRefused   = "CANCEL_REFUSED"
+	EventForcedCanceled  = "FORCED_CANCELED"
+	EventTimeoutCanceled = "TIMEOUT_CANCELED"
+	EventUsed            = "USED"
+	EventRestored        = "RESTORED"
+)
+
+// OrderCanceled order status of an order canceled before it was confirmed
+const OrderCanceled = "CANCELED"
+
+var (
+	// ErrIllegalTransition the status may not move to the asked one
+	ErrIllegalTransition = errors.New("illegal status transition")
+	// ErrUnknownVariant the order has no variant with the id
+	ErrUnknownVariant = errors.New("unknown order variant")
+	// ErrStaleTransition the order or a variant left the status the transition starts from
+	// before it was stored
+	ErrStaleTransition = errors.New("order status changed concurrently")
+)
+
+// Machine legal transitions between the statuses of an order or of a variant
+type Machine struct {
+	name    string
+	initial string
+	next    map[string][]string
+}
+
+// Order statuses of an order: prepared, confirmed by yanolja (DONE) or not, and canceled
+var Order = Machine{
+	name: "order",
+	next: map[string][]string{
+		"":                        {constant.ORDERPREPARE},
+		constant.ORDERPREPARE:     {constant.ORDERCOMPLETE, constant.ORDERDONE, constant.ORDERNOTCOMPLETE, OrderCanceled},
+		constant.ORDERCOMPLETE:    {constant.ORDERDONE, OrderCanceled},
+		constant.ORDERDONE:        {OrderCanceled},
+		constant.ORDERNOTCOMPLETE: {},
+		OrderCanceled:             {},
+	},
+}
+
+// Variant statuses of an order variant. A restored voucher goes back from USED to NOT_USED, as
+// does a variant yanolja refused to cancel; CANCELED is final
+var Variant = Machine{
+	name:    "order variant",
+	initial: constant.ORDERVARIANTUNKNOWNSTATUS,
+	next: map[string][]string{
+		constant.ORDERVARIANTUNKNOWNSTATUS:   {constant.ORDERVARIANTNOTUSEDSTATUS, constant.ORDERVARIANTCANCELINGSTATUS, constant.ORDERVARIANTCANCELEDSTATUS},
+		constant.ORDERVARIANTNOTUSEDSTATUS:   {constant.ORDERVARIANTUSEDSTATUS, constant.ORDERVARIANTCANCELINGSTATUS, constant.ORDERVARIANTCANCELEDSTATUS},
+		constant.ORDERVARIANTUSEDSTATUS:      {constant.ORDERVARIANTNOTUSEDSTATUS},
+		constant.ORDERVARIANTCANCELINGSTATUS: {constant.ORDERVARIANTCANCELEDSTATUS, constant.ORDERVARIANTNOTUSEDSTATUS},
+		constant.ORDERVARIANTCANCELEDSTATUS:  {},
+	},
+}
+
+func (m Machine) normalize(status string) string {
+	if status == "" {
+		return m.initial
+	}
+	return status
+}
+
+// Can the status move to the other one, staying in a status always can
+func (m Machine) Can(from string, to string) bool {
+	from, to = m.normalize(from), m.normalize(to)
+	if from == to {
+		return true
+	}
+	for _, next := range m.next[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Check nil when the status may move to the other one, ErrIllegalTransition otherwise
+func (m Machine) Check(from string, to string) error {
+	if m.Can(from, to) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s %s -> %s", ErrIllegalTransition, m.name, m.normalize(from), to)
+}
+
+// Final no transition leaves the status
+func (m Machine) Final(status string) bool {
+	next, ok := m.next[m.normalize(status)]
+	return ok && len(next) == 0
+}
+
+// OrderTransition transition of the order to the status, nil when it already has it
+func OrderTransition(order domain.Model, to string, event string, source string, at time.Time) (*domain.StatusTransition, error) {
+	if err := Order.Check(order.OrderStatusCode, to); err != nil {
+		return nil, fmt.Errorf("order %d: %w", order.OrderId, err)
+	}
+	if order.OrderStatusCode == to {
+		return nil, nil
+	}
+	return &domain.StatusTransition{
+		Scope:  domain.TransitionScopeOrder,
+		From:   order.OrderStatusCode,
+		To:     to,
+		Event:  event,
+		Source: source,
+		At:     at.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// VariantTransitions transitions of the variants with the ids to the status, of every variant
+// when no id is given. Variants already in the status have none, one variant which may not move
+// fails them all
+func VariantTransitions(order domain.Model, orderVariantIds []int64, to string, event string, source string, at time.Time) ([]domain.StatusTransition, error) {
+	variants := order.OrderVariants
+	if len(orderVariantIds) > 0 {
+		byId := make(map[int64]domain.OrderVariant, len(order.OrderVariants))
+		for _, variant := range order.OrderVariants {
+			byId[variant.OrderVariantID] = variant
+		}
+		variants = make([]domain.OrderVariant, 0, len(orderVariantIds))
+		for _, id := range orderVariantIds {
+			variant, ok := byId[id]
+			if !ok {
+				return nil, fmt.Errorf("order %d: %w %d", order.OrderId, ErrUnknownVariant, id)
+			}
+			variants = append(variants, variant)
+		}
+	}
+
+	transitions := make([]domain.StatusTransition, 0, len(variants))
+	for _, variant := range variants {
+		from := variant.OrderVariantStatusTypeCode
+		if err := Variant.Check(from, to); err != nil {
+			return nil, fmt.Errorf("order %d variant %d: %w", order.OrderId, variant.OrderVariantID, err)
+		}
+		if Variant.normalize(from) == to {
+			continue
+		}
+		transitions = append(transitions, domain.StatusTransition{
+			Scope:          domain.TransitionScopeVariant,
+			OrderVariantId: variant.OrderVariantID,
+			From:           from,
+			To:             to,
+			Event:          event,
+			Source:         source,
+			At:             at.UTC().Format(time.RFC3339),
+		})
+	}
+	return transitions, nil
+}
+
+// ForVariant transitions of the variant among the transitions
+func ForVariant(transitions []domain.StatusTransition, orderVariantId int64) []domain.StatusTransition {
+	found := make([]domain.StatusTransition, 0, 1)
+	for _, transition := range transitions {
+		if transition.Scope == domain.TransitionScopeVariant && transition.OrderVariantId == orderVariantId {
+			found = append(found, transition)
+		}
+	}
+	return found
+}
+
+// CancellationSettled yanolja answered the cancellation of every variant: none is still
+// CANCELING without a refusal
+func CancellationSettled(order domain.Model) bool {
+	if len(order.OrderVariants) == 0 {
+		return false
+	}
+	for _, variant := range order.OrderVariants {
+		if variant.CancelRejectTypeCode == "" && variant.OrderVariantStatusTypeCode == constant.ORDERVARIANTCANCELINGSTATUS {
+			return false
+		}
+	}
+	return true
+}
+
+type sourceKey struct{}
+
+// WithSource context whose transitions are recorded with the source
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// Source source of the transitions made with the context, the fallback when none was set
+func Source(ctx context.Context, fallback string) string {
+	if source, ok := ctx.Value(sourceKey{}).(string); ok && source != "" {
+		return source
+	}
+	return fallback
+}
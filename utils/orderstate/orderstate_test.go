@@ -0,0 +1,77 @@
+package orderstate_test
+
+import (
+	"testing"
+	"time"
+
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/orderstate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMachines(t *testing.T) {
+	assert.True(t, orderstate.Order.Can("", constant.ORDERPREPARE))
+	assert.True(t, orderstate.Order.Can(constant.ORDERPREPARE, constant.ORDERDONE))
+	assert.True(t, orderstate.Order.Can(constant.ORDERDONE, orderstate.OrderCanceled))
+	assert.False(t, orderstate.Order.Can(orderstate.OrderCanceled, constant.ORDERDONE))
+	assert.True(t, orderstate.Order.Final(orderstate.OrderCanceled))
+
+	assert.True(t, orderstate.Variant.Can("", constant.ORDERVARIANTNOTUSEDSTATUS))
+	assert.True(t, orderstate.Variant.Can(constant.ORDERVARIANTNOTUSEDSTATUS, constant.ORDERVARIANTCANCELINGSTATUS))
+	assert.True(t, orderstate.Variant.Can(constant.ORDERVARIANTUSEDSTATUS, constant.ORDERVARIANTNOTUSEDSTATUS))
+	assert.False(t, orderstate.Variant.Can(constant.ORDERVARIANTUSEDSTATUS, constant.ORDERVARIANTCANCELEDSTATUS))
+	assert.ErrorIs(t, orderstate.Variant.Check(constant.ORDERVARIANTCANCELEDSTATUS, constant.ORDERVARIANTNOTUSEDSTATUS), orderstate.ErrIllegalTransition)
+}
+
+func TestVariantTransitions(t *testing.T) {
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	order := domain.Model{
+		OrderId: 1,
+		OrderVariants: []domain.OrderVariant{
+			{OrderVariantID: 10, OrderVariantStatusTypeCode: constant.ORDERVARIANTCANCELINGSTATUS},
+			{OrderVariantID: 11, OrderVariantStatusTypeCode: constant.ORDERVARIANTCANCELEDSTATUS},
+		},
+	}
+
+	transitions, err := orderstate.VariantTransitions(order, nil, constant.ORDERVARIANTCANCELEDSTATUS, orderstate.EventCancelAcked, orderstate.SourceYanolja, at)
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, domain.StatusTransition{
+		Scope:          domain.TransitionScopeVariant,
+		OrderVariantId: 10,
+		From:           constant.ORDERVARIANTCANCELINGSTATUS,
+		To:             constant.ORDERVARIANTCANCELEDSTATUS,
+		Event:          orderstate.EventCancelAcked,
+		Source:         orderstate.SourceYanolja,
+		At:             "2026-10-01T09:00:00Z",
+	}, transitions[0])
+	assert.Len(t, orderstate.ForVariant(transitions, 10), 1)
+	assert.Empty(t, orderstate.ForVariant(transitions, 11))
+
+	// a late refusal may not bring a canceled variant back
+	_, err = orderstate.VariantTransitions(order, []int64{11}, constant.ORDERVARIANTNOTUSEDSTATUS, orderstate.EventCancelRefused, orderstate.SourceYanolja, at)
+	assert.ErrorIs(t, err, orderstate.ErrIllegalTransition)
+
+	_, err = orderstate.VariantTransitions(order, []int64{12}, constant.ORDERVARIANTCANCELEDSTATUS, orderstate.EventForcedCanceled, orderstate.SourceYanolja, at)
+	assert.ErrorIs(t, err, orderstate.ErrUnknownVariant)
+}
+
+func TestOrderTransition(t *testing.T) {
+	order := domain.Model{OrderId: 1, OrderStatusCode: constant.ORDERPREPARE}
+
+	transition, err := orderstate.OrderTransition(order, constant.ORDERDONE, orderstate.EventConfirmed, orderstate.SourceTrip, time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, transition)
+	assert.Equal(t, constant.ORDERPREPARE, transition.From)
+
+	transition, err = orderstate.OrderTransition(order, constant.ORDERPREPARE, orderstate.EventConfirmed, orderstate.SourceTrip, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, transition)
+
+	order.OrderStatusCode = constant.ORDERNOTCOMPLETE
+	_, err = orderstate.OrderTransition(order, constant.ORDERDONE, orderstate.EventConfirmed, orderstate.SourceTrip, time.Now())
+	assert.ErrorIs(t, err, orderstate.ErrIllegalTransition)
+}
@@ -41,6 +41,7 @@ type Pagination struct {
 	TotalRecords   int64  `json:"total_records,omitempty"`
 	RecordsPerPage int    `json:"records_per_page,omitempty"`
 	TotalPages     int    `json:"total_pages,omitempty"`
+	NextCursor     string `json:"next_cursor,omitempty"`
 	Links          *Links `json:"links,omitempty"`
 }
 
@@ -0,0 +1,246 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"swallow-supplier/config"
+	customContext "swallow-supplier/context"
+	"swallow-supplier/utils/array"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// QuerySort represents the sort param
+	QuerySort = "sort"
+	// QuerySortOrder represents the sort_order param
+	QuerySortOrder = "sort_order"
+	// QueryCondition represents the condition param combining the filters
+	QueryCondition = "condition"
+	// QueryCursor represents the cursor param, present even empty it switches to cursor pages
+	QueryCursor = "cursor"
+
+	// FilterExact filter matching the value as is
+	FilterExact = "exact"
+	// FilterText filter matching values containing the text, case insensitive
+	FilterText = "text"
+	// FilterNumber filter matching an integer value
+	FilterNumber = "number"
+	// FilterBool filter matching true or false
+	FilterBool = "bool"
+)
+
+// List sortable and filterable fields of a list endpoint. Key is the unique field cursor pages
+// follow and ties of the sort are broken by, filters are keyed by field with their kind
+type List struct {
+	Key    string
+	Sort   []string
+	Filter map[string]string
+}
+
+// Query page, sort, filters and cursor of a list request. The zero value lists everything
+type Query struct {
+	List           List
+	Page           int64
+	RecordsPerPage int64
+	Sort           string
+	SortOrder      string
+	Condition      string
+	Filters        map[string]any
+
+	// Paged a page was asked for with the page, records_per_page or cursor param
+	Paged bool
+
+	// CursorMode pages follow the key instead of page numbers, After is the key value the page
+	// starts after, nil for the first page
+	CursorMode bool
+	After      any
+}
+
+// Skip records before the page, cursor pages skip none
+func (q Query) Skip() int64 {
+	if q.CursorMode || q.Page < 2 || q.RecordsPerPage == 0 {
+		return 0
+	}
+	return (q.Page - 1) * q.RecordsPerPage
+}
+
+// Descending sort order is DESC
+func (q Query) Descending() bool {
+	return strings.ToUpper(q.SortOrder) == "DESC"
+}
+
+// SortField field the records are sorted by, the key unless another one is asked for
+func (q Query) SortField() string {
+	if q.Sort == "" || q.CursorMode {
+		return q.List.Key
+	}
+	return q.Sort
+}
+
+// ParseQuery query of a list request from its query parameters, with the errors per parameter
+func ParseQuery(values url.Values, list List) (Query, map[string]error) {
+	req := Request{
+		Page:           values.Get(QueryPage),
+		RecordsPerPage: values.Get(QueryRecordsPerPage),
+		Condition:      values.Get(QueryCondition),
+		SortDirection:  values.Get(QuerySortOrder),
+	}
+	queryErrors := req.ValidatePaginationRequest()
+
+	q := Query{
+		List:      list,
+		Page:      1,
+		Sort:      values.Get(QuerySort),
+		SortOrder: strings.ToUpper(req.SortDirection),
+		Condition: strings.ToUpper(req.Condition),
+		Filters:   make(map[string]any),
+	}
+	for _, param := range []string{QueryPage, QueryRecordsPerPage, QueryCursor} {
+		if _, ok := values[param]; ok {
+			q.Paged = true
+		}
+	}
+	if q.SortOrder == "" {
+		q.SortOrder = DefaultSortOrder
+	}
+	if q.Condition == "" {
+		q.Condition = DefaultCondition
+	}
+	if page, err := strconv.ParseInt(req.Page, 10, 64); err == nil && page > 0 {
+		q.Page = page
+	}
+	q.RecordsPerPage, _ = strconv.ParseInt(DefaultRecordsPerPage, 10, 64)
+	if records, err := strconv.ParseInt(req.RecordsPerPage, 10, 64); err == nil && records > 0 {
+		q.RecordsPerPage = records
+	}
+
+	if q.Sort != "" {
+		if exists, _ := array.InArray(q.Sort, list.Sort); !exists && q.Sort != list.Key {
+			queryErrors[QuerySort] = fmt.Errorf("unsupported value, one of %s", strings.Join(append([]string{list.Key}, list.Sort...), ", "))
+		}
+	}
+
+	if _, ok := values[QueryCursor]; ok {
+		q.CursorMode = true
+		if q.Sort != "" && q.Sort != list.Key {
+			queryErrors[QuerySort] = fmt.Errorf("cursor pages are sorted by %s", list.Key)
+		}
+		if cursor := values.Get(QueryCursor); cursor != "" {
+			after, err := DecodeCursor(cursor)
+			if err != nil {
+				queryErrors[QueryCursor] = err
+			}
+			q.After = after
+		}
+	}
+
+	for field, kind := range list.Filter {
+		raw := values.Get(field)
+		if raw == "" {
+			continue
+		}
+		value, err := filterValue(kind, raw)
+		if err != nil {
+			queryErrors[field] = err
+			continue
+		}
+		q.Filters[field] = value
+	}
+
+	return q, queryErrors
+}
+
+// filterValue value of a filter parameter of the kind
+func filterValue(kind string, raw string) (any, error) {
+	switch kind {
+	case FilterNumber:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, errors.New("should be a number")
+		}
+		return n, nil
+	case FilterBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("should be true or false")
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// EncodeCursor opaque cursor of the key value a page ends with
+func EncodeCursor(value any) (string, error) {
+	raw, err := bson.Marshal(bson.M{"k": value})
+	if err != nil {
+		return "", fmt.Errorf("error encoding cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor key value of an opaque cursor, keeping its bson type
+func DecodeCursor(cursor string) (any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var doc bson.M
+	if err = bson.Unmarshal(raw, &doc); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	value, ok := doc["k"]
+	if !ok {
+		return nil, errors.New("invalid cursor")
+	}
+	return value, nil
+}
+
+// FormatCursorResponse formats the pagination and data object of a cursor page, next is the
+// cursor of the following page and empty on the last one
+func FormatCursorResponse(ctx context.Context, data interface{}, count int64, next string) Reports {
+	var r Reports
+	if ctx.Value(customContext.CtxLabelRequestURL) != nil {
+		requestURL := ctx.Value(customContext.CtxLabelRequestURL).(*url.URL)
+		r.Pagination = FormatCursorPagination(requestURL, count, next)
+	}
+
+	if data != nil {
+		r.Data = data
+	}
+
+	return r
+}
+
+// FormatCursorPagination creates the pagination object of a cursor page, cursor pages only link
+// forward
+func FormatCursorPagination(url *url.URL, totalRecords int64, next string) *Pagination {
+	page := Pagination{TotalRecords: totalRecords, NextCursor: next}
+	if recordsPerPage, err := strconv.Atoi(url.Query().Get(QueryRecordsPerPage)); err == nil && recordsPerPage > 0 {
+		page.RecordsPerPage = recordsPerPage
+	} else {
+		page.RecordsPerPage, _ = strconv.Atoi(DefaultRecordsPerPage)
+	}
+	page.TotalPages = int((totalRecords + int64(page.RecordsPerPage) - 1) / int64(page.RecordsPerPage))
+
+	if totalRecords == 0 {
+		return &page
+	}
+
+	path := config.Instance().AppDomain + url.Path + "?"
+	query := url.Query()
+	query.Set(QueryRecordsPerPage, strconv.Itoa(page.RecordsPerPage))
+	page.Links = &Links{Self: path + query.Encode()}
+	if next != "" {
+		query.Set(QueryCursor, next)
+		page.Links.Next = path + query.Encode()
+	}
+
+	return &page
+}
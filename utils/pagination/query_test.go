@@ -0,0 +1,85 @@
+package pagination_test
+
+import (
+	"net/url"
+	"testing"
+
+	"swallow-supplier/utils/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testList = pagination.List{
+	Key:  "productId",
+	Sort: []string{"productName"},
+	Filter: map[string]string{
+		"productName": pagination.FilterText,
+		"productId":   pagination.FilterNumber,
+		"isUsed":      pagination.FilterBool,
+	},
+}
+
+func TestParseQuery(t *testing.T) {
+	// Test case 1: defaults
+	q, errs := pagination.ParseQuery(url.Values{}, testList)
+	require.Empty(t, errs)
+	assert.Equal(t, int64(1), q.Page)
+	assert.Equal(t, int64(10), q.RecordsPerPage)
+	assert.Equal(t, "productId", q.SortField())
+	assert.False(t, q.Descending())
+	assert.Equal(t, int64(0), q.Skip())
+	assert.False(t, q.Paged)
+
+	// Test case 2: page, sort and typed filters
+	values, _ := url.ParseQuery("page=3&records_per_page=20&sort=productName&sort_order=desc&productName=land&productId=1001&isUsed=true&unknown=x")
+	q, errs = pagination.ParseQuery(values, testList)
+	require.Empty(t, errs)
+	assert.Equal(t, int64(40), q.Skip())
+	assert.Equal(t, "productName", q.SortField())
+	assert.True(t, q.Descending())
+	assert.True(t, q.Paged)
+	assert.Equal(t, map[string]any{"productName": "land", "productId": int64(1001), "isUsed": true}, q.Filters)
+
+	// Test case 3: invalid values are reported per parameter
+	values, _ = url.ParseQuery("page=0&records_per_page=51&sort=price&productId=abc&condition=XOR")
+	_, errs = pagination.ParseQuery(values, testList)
+	assert.Contains(t, errs, "page")
+	assert.Contains(t, errs, "records_per_page")
+	assert.Contains(t, errs, "sort")
+	assert.Contains(t, errs, "productId")
+	assert.Contains(t, errs, "condition")
+}
+
+func TestCursor(t *testing.T) {
+	cursor, err := pagination.EncodeCursor(int64(10141711))
+	require.NoError(t, err)
+
+	// Test case 1: cursor pages follow the key and skip nothing
+	values := url.Values{"cursor": {cursor}, "page": {"4"}}
+	q, errs := pagination.ParseQuery(values, testList)
+	require.Empty(t, errs)
+	assert.True(t, q.CursorMode)
+	assert.Equal(t, int64(10141711), q.After)
+	assert.Equal(t, int64(0), q.Skip())
+	assert.True(t, q.Paged)
+
+	// Test case 2: an empty cursor starts at the first page
+	q, errs = pagination.ParseQuery(url.Values{"cursor": {""}}, testList)
+	require.Empty(t, errs)
+	assert.True(t, q.CursorMode)
+	assert.Nil(t, q.After)
+
+	// Test case 3: cursors cannot be combined with another sort, or be made up
+	values = url.Values{"cursor": {"not-a-cursor"}, "sort": {"productName"}}
+	_, errs = pagination.ParseQuery(values, testList)
+	assert.Contains(t, errs, "cursor")
+	assert.Contains(t, errs, "sort")
+
+	// Test case 4: string keys keep their type
+	cursor, err = pagination.EncodeCursor("product:1001")
+	require.NoError(t, err)
+	key, err := pagination.DecodeCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, "product:1001", key)
+}
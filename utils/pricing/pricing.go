@@ -0,0 +1,239 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	domain "swallow-supplier/mongo/domain/pricing"
+	"swallow-supplier/utils/constant"
+)
+
+// RuleSource loads the stored pricing rules, implemented by the mongo repository
+type RuleSource interface {
+	GetPricingRules(ctx context.Context) (rules []domain.Rule, err error)
+}
+
+// Scope the price being evaluated
+type Scope struct {
+	Supplier    string
+	ProductId   int64
+	VariantId   int64
+	ChannelCode string
+	At          time.Time
+}
+
+// DefaultRule applied when no stored rule matches the scope
+var DefaultRule = domain.Rule{
+	Name:       "default",
+	MarkupType: constant.PERCENTAGE,
+	Value:      float64(constant.MARKUPPERCENTAGE),
+	Rounding:   constant.ROUNDINGFLOOR,
+	Enabled:    true,
+}
+
+// SeedRules rules stored when the collection is still empty, they carry over the margins
+// which used to be hardcoded. Everland dev : {10014508, 10012712} prod :{10240621,10240654}
+var SeedRules = []domain.Rule{
+	{Name: "default", MarkupType: constant.PERCENTAGE, Value: float64(constant.MARKUPPERCENTAGE), Rounding: constant.ROUNDINGFLOOR, Enabled: true},
+	{Name: "everland 10240621", ProductId: 10240621, ChannelCode: "GGT_EVERLAND", MarkupType: constant.FLATVALUE, Value: 1900, Rounding: constant.ROUNDINGNONE, Enabled: true},
+	{Name: "everland 10240654", ProductId: 10240654, ChannelCode: "GGT_EVERLAND", MarkupType: constant.FLATVALUE, Value: 1900, Rounding: constant.ROUNDINGNONE, Enabled: true},
+	{Name: "everland 10014508", ProductId: 10014508, ChannelCode: "GGT_EVERLAND", MarkupType: constant.FLATVALUE, Value: 1900, Rounding: constant.ROUNDINGNONE, Enabled: true},
+	{Name: "everland 10012712", ProductId: 10012712, ChannelCode: "GGT_EVERLAND", MarkupType: constant.FLATVALUE, Value: 1900, Rounding: constant.ROUNDINGNONE, Enabled: true},
+	{Name: "product 10248417", ProductId: 10248417, MarkupType: constant.PERCENTAGE, Value: 1.6, Rounding: constant.ROUNDINGFLOOR, Enabled: true},
+	{Name: "product 10012383", ProductId: 10012383, MarkupType: constant.PERCENTAGE, Value: 1.6, Rounding: constant.ROUNDINGFLOOR, Enabled: true},
+}
+
+var cache struct {
+	sync.RWMutex
+	rules    []domain.Rule
+	loadedAt time.Time
+}
+
+// Invalidate drop the loaded rules, the next evaluation reads them again
+func Invalidate() {
+	cache.Lock()
+	cache.rules = nil
+	cache.loadedAt = time.Time{}
+	cache.Unlock()
+}
+
+// rules loaded rules, read again from the source once they are older than the cache period.
+// When the source fails the previously loaded rules are kept, the error is only returned
+// when there is nothing loaded yet
+func rules(ctx context.Context, src RuleSource) ([]domain.Rule, error) {
+	cache.RLock()
+	loaded, loadedAt := cache.rules, cache.loadedAt
+	cache.RUnlock()
+
+	if loaded != nil && time.Since(loadedAt) < constant.PricingRuleCacheSeconds*time.Second {
+		return loaded, nil
+	}
+
+	fresh, err := src.GetPricingRules(ctx)
+	if err != nil {
+		if loaded != nil {
+			return loaded, nil
+		}
+		return nil, err
+	}
+	if fresh == nil {
+		fresh = make([]domain.Rule, 0)
+	}
+
+	cache.Lock()
+	cache.rules = fresh
+	cache.loadedAt = time.Now()
+	cache.Unlock()
+
+	return fresh, nil
+}
+
+// Evaluate rule of the scope, DefaultRule when nothing matches. When the rules could not be
+// loaded the default rule is returned together with the error
+func Evaluate(ctx context.Context, src RuleSource, scope Scope) (domain.Rule, error) {
+	loaded, err := rules(ctx, src)
+	if rule, ok := Match(loaded, scope); ok {
+		return rule, err
+	}
+	return DefaultRule, err
+}
+
+// Match most specific enabled rule of the scope. Variant beats product beats channel beats
+// supplier, equal specificity is decided by the priority and then the latest update
+func Match(rules []domain.Rule, scope Scope) (domain.Rule, bool) {
+	at := scope.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	candidates := make([]domain.Rule, 0)
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if rule.Supplier != "" && rule.Supplier != scope.Supplier {
+			continue
+		}
+		if rule.ProductId != 0 && rule.ProductId != scope.ProductId {
+			continue
+		}
+		if rule.VariantId != 0 && rule.VariantId != scope.VariantId {
+			continue
+		}
+		if rule.ChannelCode != "" && rule.ChannelCode != scope.ChannelCode {
+			continue
+		}
+		if rule.ValidFrom != nil && at.Before(*rule.ValidFrom) {
+			continue
+		}
+		if rule.ValidTo != nil && !at.Before(*rule.ValidTo) {
+			continue
+		}
+		candidates = append(candidates, rule)
+	}
+
+	if len(candidates) == 0 {
+		return domain.Rule{}, false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		si, sj := specificity(candidates[i]), specificity(candidates[j])
+		if si != sj {
+			return si > sj
+		}
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority > candidates[j].Priority
+		}
+		return candidates[i].UpdatedAt > candidates[j].UpdatedAt
+	})
+
+	return candidates[0], true
+}
+
+func specificity(rule domain.Rule) (score int) {
+	if rule.VariantId != 0 {
+		score += 8
+	}
+	if rule.ProductId != 0 {
+		score += 4
+	}
+	if rule.ChannelCode != "" {
+		score += 2
+	}
+	if rule.Supplier != "" {
+		score++
+	}
+	return score
+}
+
+// AddMarkup price of quantity units costing cost in total once the markup is added
+func AddMarkup(rule domain.Rule, cost float64, quantity int32) float64 {
+	switch rule.MarkupType {
+	case constant.FLATVALUE:
+		return round(rule.Rounding, cost+rule.Value*float64(quantity))
+	case constant.PERCENTAGE:
+		return round(rule.Rounding, cost*(1+rule.Value/100))
+	}
+	return cost
+}
+
+// RemoveMarkup supplier cost of a unit sold at price with the markup included
+func RemoveMarkup(rule domain.Rule, price float64) float64 {
+	switch rule.MarkupType {
+	case constant.FLATVALUE:
+		return round(rule.Rounding, price-rule.Value)
+	case constant.PERCENTAGE:
+		return round(rule.Rounding, price/(1+rule.Value/100))
+	}
+	return price
+}
+
+// Markup markup amount of the rule for quantity units costing cost in total
+func Markup(rule domain.Rule, cost float64, quantity int32) float64 {
+	return AddMarkup(rule, cost, quantity) - cost
+}
+
+func round(mode string, value float64) float64 {
+	switch mode {
+	case constant.ROUNDINGFLOOR:
+		return math.Floor(value)
+	case constant.ROUNDINGCEIL:
+		return math.Ceil(value)
+	case constant.ROUNDINGROUND:
+		return math.Round(value)
+	}
+	return value
+}
+
+// Validate checks markup type, value, rounding and date range of a rule
+func Validate(rule domain.Rule) error {
+	switch rule.MarkupType {
+	case constant.PERCENTAGE:
+		if rule.Value <= -100 {
+			return errors.New("percentage markup must be greater than -100")
+		}
+	case constant.FLATVALUE:
+	default:
+		return errors.New("markupType must be one of PERCENTAGE, FLATVALUE")
+	}
+
+	switch rule.Rounding {
+	case "", constant.ROUNDINGNONE, constant.ROUNDINGFLOOR, constant.ROUNDINGCEIL, constant.ROUNDINGROUND:
+	default:
+		return errors.New("rounding must be one of NONE, FLOOR, CEIL, ROUND")
+	}
+
+	if rule.ProductId < 0 || rule.VariantId < 0 {
+		return errors.New("productId and variantId must not be negative")
+	}
+
+	if rule.ValidFrom != nil && rule.ValidTo != nil && !rule.ValidFrom.Before(*rule.ValidTo) {
+		return errors.New("validFrom must be before validTo")
+	}
+
+	return nil
+}
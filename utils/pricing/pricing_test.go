@@ -0,0 +1,104 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "swallow-supplier/mongo/domain/pricing"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/pricing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ruleSource struct {
+	rules []domain.Rule
+	err   error
+}
+
+func (s ruleSource) GetPricingRules(ctx context.Context) ([]domain.Rule, error) {
+	return s.rules, s.err
+}
+
+func TestMatch(t *testing.T) {
+	// Test case 1: the seeded rules keep the legacy margins
+	rule, ok := pricing.Match(pricing.SeedRules, pricing.Scope{ProductId: 10240621, ChannelCode: "GGT_EVERLAND"})
+	assert.True(t, ok)
+	assert.Equal(t, constant.FLATVALUE, rule.MarkupType)
+	assert.Equal(t, float32(8100), float32(pricing.RemoveMarkup(rule, 10000)))
+
+	rule, _ = pricing.Match(pricing.SeedRules, pricing.Scope{ProductId: 10240621, ChannelCode: "GGT_TRIP"})
+	assert.Equal(t, "default", rule.Name)
+	assert.Equal(t, float64(9708), pricing.RemoveMarkup(rule, 10000))
+
+	rule, _ = pricing.Match(pricing.SeedRules, pricing.Scope{ProductId: 10248417, ChannelCode: "GGT_TRIP"})
+	assert.Equal(t, float64(9842), pricing.RemoveMarkup(rule, 10000))
+
+	// Test case 2: variant beats product, priority decides equal specificity
+	rules := []domain.Rule{
+		{Name: "product", ProductId: 1, MarkupType: constant.PERCENTAGE, Value: 5, Enabled: true},
+		{Name: "variant", ProductId: 1, VariantId: 2, MarkupType: constant.PERCENTAGE, Value: 7, Enabled: true},
+		{Name: "variant high", ProductId: 1, VariantId: 2, MarkupType: constant.PERCENTAGE, Value: 9, Priority: 1, Enabled: true},
+		{Name: "disabled", ProductId: 1, VariantId: 2, ChannelCode: "GGT_TRIP", MarkupType: constant.PERCENTAGE, Value: 1, Enabled: false},
+	}
+	rule, _ = pricing.Match(rules, pricing.Scope{ProductId: 1, VariantId: 2, ChannelCode: "GGT_TRIP"})
+	assert.Equal(t, "variant high", rule.Name)
+	rule, _ = pricing.Match(rules, pricing.Scope{ProductId: 1, VariantId: 3})
+	assert.Equal(t, "product", rule.Name)
+
+	// Test case 3: date range is half open
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	rules = []domain.Rule{{Name: "promo", ValidFrom: &from, ValidTo: &to, MarkupType: constant.FLATVALUE, Value: 100, Enabled: true}}
+	_, ok = pricing.Match(rules, pricing.Scope{At: from})
+	assert.True(t, ok)
+	_, ok = pricing.Match(rules, pricing.Scope{At: to})
+	assert.False(t, ok)
+}
+
+func TestMarkupRounding(t *testing.T) {
+	rule := domain.Rule{MarkupType: constant.PERCENTAGE, Value: 1.6}
+
+	assert.InDelta(t, 10160, pricing.AddMarkup(rule, 10000, 2), 0.001)
+	rule.Rounding = constant.ROUNDINGCEIL
+	assert.Equal(t, float64(9843), pricing.RemoveMarkup(rule, 10000))
+	rule.Rounding = constant.ROUNDINGROUND
+	assert.Equal(t, float64(9843), pricing.RemoveMarkup(rule, 10000))
+
+	rule = domain.Rule{MarkupType: constant.FLATVALUE, Value: 1900}
+	assert.Equal(t, float64(23800), pricing.AddMarkup(rule, 20000, 2))
+	assert.Equal(t, float64(3800), pricing.Markup(rule, 20000, 2))
+}
+
+func TestEvaluate(t *testing.T) {
+	pricing.Invalidate()
+	defer pricing.Invalidate()
+
+	// Test case 1: nothing loaded and the source fails, default rule with the error
+	rule, err := pricing.Evaluate(context.Background(), ruleSource{err: errors.New("down")}, pricing.Scope{})
+	assert.Error(t, err)
+	assert.Equal(t, pricing.DefaultRule, rule)
+
+	// Test case 2: loaded rules are kept until invalidated
+	src := ruleSource{rules: []domain.Rule{{Name: "all", MarkupType: constant.FLATVALUE, Value: 10, Enabled: true}}}
+	rule, err = pricing.Evaluate(context.Background(), src, pricing.Scope{})
+	assert.NoError(t, err)
+	assert.Equal(t, "all", rule.Name)
+
+	rule, err = pricing.Evaluate(context.Background(), ruleSource{}, pricing.Scope{})
+	assert.NoError(t, err)
+	assert.Equal(t, "all", rule.Name)
+
+	pricing.Invalidate()
+	rule, _ = pricing.Evaluate(context.Background(), ruleSource{}, pricing.Scope{})
+	assert.Equal(t, "default", rule.Name)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, pricing.Validate(domain.Rule{MarkupType: constant.PERCENTAGE, Value: 3}))
+	assert.Error(t, pricing.Validate(domain.Rule{MarkupType: "MARGIN", Value: 3}))
+	assert.Error(t, pricing.Validate(domain.Rule{MarkupType: constant.FLATVALUE, Rounding: "UP"}))
+	assert.Error(t, pricing.Validate(domain.Rule{MarkupType: constant.PERCENTAGE, Value: -100}))
+}
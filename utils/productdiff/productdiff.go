@@ -0,0 +1,229 @@
+package productdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	domain "swallow-supplier/mongo/domain/yanolja"
+)
+
+// ignored fields which change without the product changing: ids and timestamps of the stored
+// document, the version itself and the flags of our own jobs
+var ignored = map[string]bool{
+	"id":                    true,
+	"productVersion":        true,
+	"createdAt":             true,
+	"updatedAt":             true,
+	"imageScheduleStatus":   true,
+	"viewScheduleStatus":    true,
+	"contentScheduleStatus": true,
+	"oodoSyncStatus":        true,
+	"odooTemplateId":        true,
+	"odooProductId":         true,
+	"odooPushedAt":          true,
+	"odooPushError":         true,
+}
+
+// keys id fields list elements are matched by, elements without one are matched by position
+var keys = []string{
+	"productOptionGroupId",
+	"variantId",
+	"productOptionId",
+	"productOptionItemId",
+	"facilityId",
+	"supplyItemId",
+	"regionId",
+}
+
+// Compare changes from before to after, one per changed leaf. List elements with an id are
+// matched by it and an added or removed element is one change; lists of plain values change as
+// a whole. Paths use the json names with the element id in brackets, e.g.
+// productOptionGroups[7].variants[9].price.salePrice
+func Compare(before domain.Product, after domain.Product) ([]domain.ProductChange, error) {
+	a, err := document(before)
+	if err != nil {
+		return nil, err
+	}
+	b, err := document(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]domain.ProductChange, 0)
+	compare("", a, b, &changes)
+	return changes, nil
+}
+
+// document product as the generic json document the comparison walks
+func document(product domain.Product) (map[string]any, error) {
+	raw, err := json.Marshal(product)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling product %d: %w", product.ProductID, err)
+	}
+	// numbers stay json.Number so large ids keep their digits in the paths
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	doc := make(map[string]any)
+	if err = decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error unmarshaling product %d: %w", product.ProductID, err)
+	}
+	for field := range ignored {
+		delete(doc, field)
+	}
+	return doc, nil
+}
+
+func compare(path string, a any, b any, changes *[]domain.ProductChange) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+
+	switch {
+	case a == nil:
+		*changes = append(*changes, change(path, domain.ProductChangeAdded, nil, b))
+		return
+	case b == nil:
+		*changes = append(*changes, change(path, domain.ProductChangeRemoved, a, nil))
+		return
+	}
+
+	objectA, okA := a.(map[string]any)
+	objectB, okB := b.(map[string]any)
+	if okA && okB {
+		fields := make([]string, 0, len(objectA)+len(objectB))
+		for field := range objectA {
+			fields = append(fields, field)
+		}
+		for field := range objectB {
+			if _, ok := objectA[field]; !ok {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			compare(join(path, field), objectA[field], objectB[field], changes)
+		}
+		return
+	}
+
+	listA, okA := a.([]any)
+	listB, okB := b.([]any)
+	if okA && okB {
+		if field := listKey(listA, listB); field != "" {
+			compareKeyed(path, field, listA, listB, changes)
+			return
+		}
+	}
+
+	*changes = append(*changes, change(path, domain.ProductChangeChanged, a, b))
+}
+
+// compareKeyed compare the elements of two lists matched by their id field
+func compareKeyed(path string, field string, a []any, b []any, changes *[]domain.ProductChange) {
+	elementsA := keyed(field, a)
+	elementsB := keyed(field, b)
+
+	ids := order(field, a)
+	for _, id := range order(field, b) {
+		if _, ok := elementsA[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range ids {
+		compare(fmt.Sprintf("%s[%s]", path, id), elementsA[id], elementsB[id], changes)
+	}
+}
+
+// listKey id field every element of both lists has, empty when they are not all keyed alike
+func listKey(a []any, b []any) string {
+	elements := append(append(make([]any, 0, len(a)+len(b)), a...), b...)
+	if len(elements) == 0 {
+		return ""
+	}
+	for _, field := range keys {
+		all := true
+		for _, element := range elements {
+			object, ok := element.(map[string]any)
+			if !ok || object[field] == nil {
+				all = false
+				break
+			}
+		}
+		if all {
+			return field
+		}
+	}
+	return ""
+}
+
+// keyed elements of the list by their id
+func keyed(field string, list []any) map[string]any {
+	elements := make(map[string]any, len(list))
+	for _, element := range list {
+		elements[id(field, element)] = element
+	}
+	return elements
+}
+
+// order ids of the list in list order
+func order(field string, list []any) []string {
+	ids := make([]string, 0, len(list))
+	for _, element := range list {
+		ids = append(ids, id(field, element))
+	}
+	return ids
+}
+
+func id(field string, element any) string {
+	return fmt.Sprint(element.(map[string]any)[field])
+}
+
+func join(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func change(path string, kind string, before any, after any) domain.ProductChange {
+	return domain.ProductChange{Path: path, Area: Area(path), Kind: kind, Before: before, After: after}
+}
+
+// Area part of the product the path belongs to: price, status, salePeriod, variants or content
+func Area(path string) string {
+	last := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		last = path[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(path, "price") || strings.Contains(path, ".price") || strings.Contains(path, ".fee"):
+		return domain.ProductAreaPrice
+	case last == "productStatusCode" || last == "variantStatusCode":
+		return domain.ProductAreaStatus
+	case strings.Contains(path, "salePeriod"):
+		return domain.ProductAreaSalePeriod
+	case strings.Contains(path, "variants"):
+		return domain.ProductAreaVariants
+	}
+	return domain.ProductAreaContent
+}
+
+// Areas distinct areas of the changes in a stable order
+func Areas(changes []domain.ProductChange) []string {
+	seen := make(map[string]bool)
+	for _, change := range changes {
+		seen[change.Area] = true
+	}
+	areas := make([]string, 0, len(seen))
+	for _, area := range []string{domain.ProductAreaPrice, domain.ProductAreaStatus, domain.ProductAreaSalePeriod, domain.ProductAreaVariants, domain.ProductAreaContent} {
+		if seen[area] {
+			areas = append(areas, area)
+		}
+	}
+	return areas
+}
@@ -0,0 +1,109 @@
+package productdiff_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	domain "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/productdiff"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProduct() domain.Product {
+	return domain.Product{
+		ProductID:         1001,
+		ProductName:       "Everland",
+		ProductVersion:    6,
+		ProductStatusCode: "IN_SALE",
+		Price:             domain.Price{Currency: "KRW", SalePrice: 52000},
+		SalePeriod:        domain.SalePeriod{StartDateTime: "2025-01-01T00:00:00Z", EndDateTime: "2025-12-31T23:59:59Z"},
+		SearchKeywords:    []string{"park"},
+		ProductOptionGroups: []domain.ProductOptionGroup{{
+			ProductOptionGroupID:   7,
+			ProductOptionGroupName: "Admission",
+			Variants: []domain.Variant{
+				{VariantID: 12345678, VariantName: "Adult", Price: domain.VariantPrice{Currency: "KRW", SalePrice: 52000}, VariantStatusCode: "IN_SALE"},
+				{VariantID: 12345679, VariantName: "Child", Price: domain.VariantPrice{Currency: "KRW", SalePrice: 42000}, VariantStatusCode: "IN_SALE"},
+			},
+		}},
+		UpdatedAt: "2025-03-01T00:00:00Z",
+	}
+}
+
+func paths(changes []domain.ProductChange) map[string]domain.ProductChange {
+	byPath := make(map[string]domain.ProductChange, len(changes))
+	for _, change := range changes {
+		byPath[change.Path] = change
+	}
+	return byPath
+}
+
+func TestCompare(t *testing.T) {
+	before := testProduct()
+
+	// Test case 1: a new version with the same content has no changes
+	after := testProduct()
+	after.ProductVersion = 7
+	after.UpdatedAt = "2025-03-02T00:00:00Z"
+	after.OodoSyncStatus = true
+	changes, err := productdiff.Compare(before, after)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Test case 2: variants are matched by id, whatever their order
+	after.ProductOptionGroups[0].Variants[0], after.ProductOptionGroups[0].Variants[1] = after.ProductOptionGroups[0].Variants[1], after.ProductOptionGroups[0].Variants[0]
+	after.ProductOptionGroups[0].Variants[0].Price.SalePrice = 40000
+	after.ProductOptionGroups[0].Variants[1].VariantStatusCode = "SOLD_OUT"
+	after.ProductOptionGroups[0].Variants = append(after.ProductOptionGroups[0].Variants, domain.Variant{VariantID: 12345680, VariantName: "Senior"})
+	after.SalePeriod.EndDateTime = "2026-06-30T23:59:59Z"
+	after.SearchKeywords = []string{"park", "zoo"}
+
+	changes, err = productdiff.Compare(before, after)
+	require.NoError(t, err)
+	byPath := paths(changes)
+	require.Len(t, changes, 5)
+
+	price := byPath["productOptionGroups[7].variants[12345679].price.salePrice"]
+	assert.Equal(t, domain.ProductChangeChanged, price.Kind)
+	assert.Equal(t, domain.ProductAreaPrice, price.Area)
+	assert.Equal(t, json.Number("42000"), price.Before)
+	assert.Equal(t, json.Number("40000"), price.After)
+
+	status := byPath["productOptionGroups[7].variants[12345678].variantStatusCode"]
+	assert.Equal(t, domain.ProductAreaStatus, status.Area)
+	assert.Equal(t, "SOLD_OUT", status.After)
+
+	added := byPath["productOptionGroups[7].variants[12345680]"]
+	assert.Equal(t, domain.ProductChangeAdded, added.Kind)
+	assert.Equal(t, domain.ProductAreaVariants, added.Area)
+	assert.Nil(t, added.Before)
+
+	assert.Equal(t, domain.ProductAreaSalePeriod, byPath["salePeriod.endDateTime"].Area)
+	assert.Equal(t, domain.ProductAreaContent, byPath["searchKeywords"].Area)
+
+	assert.Equal(t, []string{
+		domain.ProductAreaPrice,
+		domain.ProductAreaStatus,
+		domain.ProductAreaSalePeriod,
+		domain.ProductAreaVariants,
+		domain.ProductAreaContent,
+	}, productdiff.Areas(changes))
+
+	// Test case 3: a removed variant is one change
+	changes, err = productdiff.Compare(after, before)
+	require.NoError(t, err)
+	removed := paths(changes)["productOptionGroups[7].variants[12345680]"]
+	assert.Equal(t, domain.ProductChangeRemoved, removed.Kind)
+	assert.Nil(t, removed.After)
+}
+
+func TestArea(t *testing.T) {
+	assert.Equal(t, domain.ProductAreaPrice, productdiff.Area("price.salePrice"))
+	assert.Equal(t, domain.ProductAreaPrice, productdiff.Area("productOptionGroups[7].variants[9].fee.feeRate"))
+	assert.Equal(t, domain.ProductAreaStatus, productdiff.Area("productStatusCode"))
+	assert.Equal(t, domain.ProductAreaSalePeriod, productdiff.Area("productOptionGroups[7].variants[9].salePeriod.endDateTime"))
+	assert.Equal(t, domain.ProductAreaVariants, productdiff.Area("productOptionGroups[7].variants[9].variantName"))
+	assert.Equal(t, domain.ProductAreaContent, productdiff.Area("productInfo.refundInfo"))
+}
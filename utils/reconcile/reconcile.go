@@ -0,0 +1,267 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"swallow-supplier/mongo/domain/reconciliation"
+	"swallow-supplier/mongo/domain/trip"
+	"swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+)
+
+// State reconciliation status a variant status corresponds to. Restored variants are back to
+// created, a pending cancellation is still created until yanolja confirms it
+func State(variantStatus string) string {
+	switch variantStatus {
+	case constant.ORDERVARIANTUSEDSTATUS, constant.RECONCILESTATUSUSED:
+		return constant.RECONCILESTATUSUSED
+	case constant.ORDERVARIANTCANCELEDSTATUS:
+		return constant.RECONCILESTATUSCANCELED
+	case constant.ORDERVARIANTNOTUSEDSTATUS, constant.ORDERVARIANTCANCELINGSTATUS, constant.RECONCILESTATUSCREATED, constant.RECONCILESTATUSRESTORED:
+		return constant.RECONCILESTATUSCREATED
+	}
+	return variantStatus
+}
+
+// TripView order as trip last received it, the snapshot of the latest delivered callback.
+// nil when no callback of the order reached trip
+func TripView(callbacks []trip.CallBackDetail) *yanolja.Model {
+	var latest *trip.CallBackDetail
+	for i := range callbacks {
+		if callbacks[i].GGTToChannelStatus != "SUCCESS" {
+			continue
+		}
+		if latest == nil || callbacks[i].CreatedAt >= latest.CreatedAt {
+			latest = &callbacks[i]
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	return &latest.ChannelCallBackInfo.ChannelReq.Order
+}
+
+// Compare mismatches of the order variants between our order, its latest yanolja reconciliation
+// status and the callbacks sent to trip for it
+func Compare(order yanolja.Model, callbacks []trip.CallBackDetail) []reconciliation.Mismatch {
+	mismatches := make([]reconciliation.Mismatch, 0)
+	booked := order.OrderStatusCode == constant.ORDERDONE
+	tripOrder := TripView(callbacks)
+
+	for _, variant := range order.OrderVariants {
+		base := reconciliation.Mismatch{
+			OrderId:        order.OrderId,
+			PartnerOrderId: order.PartnerOrderID,
+			Channel:        order.PartnerOrderChannelCode,
+			OrderVariantId: variant.OrderVariantID,
+			ProductId:      variant.ProductID,
+			VariantId:      variant.VariantID,
+		}
+
+		ours := State(variant.OrderVariantStatusTypeCode)
+		yanoljaState := latestReconciliation(variant.ReconciliationByDate)
+		tripState := ""
+		var tripVariant *yanolja.OrderVariant
+		if tripOrder != nil {
+			if tripVariant = findVariant(tripOrder.OrderVariants, variant.OrderVariantID); tripVariant != nil {
+				tripState = State(tripVariant.OrderVariantStatusTypeCode)
+			}
+		}
+		// yanolja marks orders which are not confirmed yet as unknown
+		if !booked && yanoljaState == constant.RECONCILESTATUSUNKNOWN {
+			yanoljaState = ""
+		}
+
+		canceled := ours == constant.RECONCILESTATUSCANCELED || yanoljaState == constant.RECONCILESTATUSCANCELED || tripState == constant.RECONCILESTATUSCANCELED
+		agreed := (yanoljaState == "" || yanoljaState == ours) && (tripState == "" || tripState == ours)
+
+		switch {
+		case canceled && !agreed:
+			m := base
+			m.Type = reconciliation.MismatchOrphanCancellation
+			m.Ours, m.Yanolja, m.Trip = ours, yanoljaState, tripState
+			m.Detail = "cancellation is not recorded on every side"
+			mismatches = append(mismatches, m)
+
+		case !agreed && (booked || yanoljaState != ""):
+			m := base
+			m.Type = reconciliation.MismatchStatus
+			m.Ours, m.Yanolja, m.Trip = ours, yanoljaState, tripState
+			m.Detail = "variant status differs"
+			mismatches = append(mismatches, m)
+		}
+
+		if booked && ours != constant.RECONCILESTATUSCANCELED {
+			ourVoucher := voucherCodes(variant)
+			tripVoucher := ""
+			if tripVariant != nil {
+				tripVoucher = voucherCodes(*tripVariant)
+			}
+			if ourVoucher == "" || (tripOrder != nil && tripVoucher != ourVoucher) {
+				m := base
+				m.Type = reconciliation.MismatchMissingVoucher
+				m.Ours, m.Trip = ourVoucher, tripVoucher
+				m.Detail = "voucher was not issued"
+				if ourVoucher != "" {
+					m.Detail = "voucher trip received differs"
+				}
+				mismatches = append(mismatches, m)
+			}
+		}
+
+		if tripOrder == nil {
+			continue
+		}
+		ourPrice, found := findSelectVariant(order.SelectVariants, variant.ProductID, variant.VariantID)
+		tripPrice, tripFound := findSelectVariant(tripOrder.SelectVariants, variant.ProductID, variant.VariantID)
+		if !found || !tripFound {
+			continue
+		}
+		if drift(ourPrice.PartnerSalePrice, tripPrice.PartnerSalePrice) || drift(ourPrice.CostPrice, tripPrice.CostPrice) {
+			m := base
+			m.Type = reconciliation.MismatchPriceDrift
+			m.Ours = fmt.Sprintf("%.2f/%.2f", ourPrice.PartnerSalePrice, ourPrice.CostPrice)
+			m.Trip = fmt.Sprintf("%.2f/%.2f", tripPrice.PartnerSalePrice, tripPrice.CostPrice)
+			m.Detail = "sale/cost price differs from what trip received"
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	return mismatches
+}
+
+// Orphans cancellations trip was told about for an order we have no record of
+func Orphans(orderId int64, callbacks []trip.CallBackDetail) []reconciliation.Mismatch {
+	mismatches := make([]reconciliation.Mismatch, 0)
+
+	tripOrder := TripView(callbacks)
+	if tripOrder == nil {
+		return mismatches
+	}
+	for _, variant := range tripOrder.OrderVariants {
+		if State(variant.OrderVariantStatusTypeCode) != constant.RECONCILESTATUSCANCELED {
+			continue
+		}
+		mismatches = append(mismatches, reconciliation.Mismatch{
+			Type:           reconciliation.MismatchOrphanCancellation,
+			OrderId:        orderId,
+			PartnerOrderId: tripOrder.PartnerOrderID,
+			Channel:        tripOrder.PartnerOrderChannelCode,
+			OrderVariantId: variant.OrderVariantID,
+			ProductId:      variant.ProductID,
+			VariantId:      variant.VariantID,
+			Trip:           constant.RECONCILESTATUSCANCELED,
+			Detail:         "trip was told of a cancellation of an order we do not have",
+		})
+	}
+	return mismatches
+}
+
+// Count mismatches per type
+func Count(mismatches []reconciliation.Mismatch) map[string]int64 {
+	counts := map[string]int64{
+		reconciliation.MismatchStatus:             0,
+		reconciliation.MismatchMissingVoucher:     0,
+		reconciliation.MismatchPriceDrift:         0,
+		reconciliation.MismatchOrphanCancellation: 0,
+	}
+	for _, m := range mismatches {
+		counts[m.Type]++
+	}
+	return counts
+}
+
+// CSVHeader columns of the csv export
+var CSVHeader = []string{"date", "type", "orderId", "partnerOrderId", "channel", "orderVariantId", "productId", "variantId", "ours", "yanolja", "trip", "detail"}
+
+// WriteCSV write the mismatches of the report as csv for finance
+func WriteCSV(w io.Writer, date string, mismatches []reconciliation.Mismatch) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return err
+	}
+	for _, m := range mismatches {
+		record := []string{
+			date,
+			m.Type,
+			strconv.FormatInt(m.OrderId, 10),
+			m.PartnerOrderId,
+			m.Channel,
+			strconv.FormatInt(m.OrderVariantId, 10),
+			strconv.FormatInt(m.ProductId, 10),
+			strconv.FormatInt(m.VariantId, 10),
+			m.Ours,
+			m.Yanolja,
+			m.Trip,
+			m.Detail,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// latestReconciliation status of the latest reconciliation entry, the dates are stored either
+// as day or as timestamp so only the day is compared
+func latestReconciliation(details []yanolja.ReconcilationDetail) string {
+	if len(details) == 0 {
+		return ""
+	}
+	sorted := append([]yanolja.ReconcilationDetail(nil), details...)
+	sort.SliceStable(sorted, func(i, j int) bool { return day(sorted[i].ReconciliationDate) < day(sorted[j].ReconciliationDate) })
+	return State(sorted[len(sorted)-1].ReconcileOrderStatusCode)
+}
+
+func day(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
+}
+
+func findVariant(variants []yanolja.OrderVariant, orderVariantId int64) *yanolja.OrderVariant {
+	for i := range variants {
+		if variants[i].OrderVariantID == orderVariantId {
+			return &variants[i]
+		}
+	}
+	return nil
+}
+
+func findSelectVariant(variants []yanolja.SelectVariant, productId int64, variantId int64) (yanolja.SelectVariant, bool) {
+	for _, variant := range variants {
+		if variant.ProductID == productId && variant.VariantID == variantId {
+			return variant, true
+		}
+	}
+	return yanolja.SelectVariant{}, false
+}
+
+// voucherCodes voucher codes of the variant items in item order, empty when one is missing
+func voucherCodes(variant yanolja.OrderVariant) string {
+	if len(variant.OrderVariantItems) == 0 {
+		return ""
+	}
+	codes := ""
+	for i, item := range variant.OrderVariantItems {
+		if item.Voucher.VoucherCode == "" {
+			return ""
+		}
+		if i > 0 {
+			codes += ","
+		}
+		codes += item.Voucher.VoucherCode
+	}
+	return codes
+}
+
+func drift(ours float32, theirs float32) bool {
+	return math.Abs(float64(ours-theirs)) > constant.ReconcilePriceTolerance
+}
@@ -0,0 +1,133 @@
+package reconcile_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"swallow-supplier/mongo/domain/reconciliation"
+	"swallow-supplier/mongo/domain/trip"
+	"swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/reconcile"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrder(status string, reconStatus string, voucher string) yanolja.Model {
+	return yanolja.Model{
+		OrderId:                 5001,
+		PartnerOrderID:          "TRIP-77",
+		PartnerOrderChannelCode: "TRIP",
+		OrderStatusCode:         constant.ORDERDONE,
+		SelectVariants: []yanolja.SelectVariant{
+			{ProductID: 1001, VariantID: 1, PartnerSalePrice: 52, CostPrice: 40},
+		},
+		OrderVariants: []yanolja.OrderVariant{{
+			OrderVariantID:             11,
+			ProductID:                  1001,
+			VariantID:                  1,
+			OrderVariantStatusTypeCode: status,
+			OrderVariantItems:          []yanolja.OrderVariantItem{{Voucher: yanolja.Voucher{VoucherCode: voucher}}},
+			ReconciliationByDate: []yanolja.ReconcilationDetail{
+				{ReconciliationDate: "2025-03-01", ReconcileOrderStatusCode: constant.RECONCILESTATUSCREATED},
+				{ReconciliationDate: "2025-03-02T10:00:00Z", ReconcileOrderStatusCode: reconStatus},
+			},
+		}},
+	}
+}
+
+func callback(order yanolja.Model, status string, createdAt string) trip.CallBackDetail {
+	return trip.CallBackDetail{
+		ChannelCallBackInfo: trip.CallBackRequest{ChannelReq: trip.ChannelReqest{Order: order}},
+		GGTToChannelStatus:  status,
+		CreatedAt:           createdAt,
+	}
+}
+
+func types(mismatches []reconciliation.Mismatch) []string {
+	found := make([]string, 0, len(mismatches))
+	for _, m := range mismatches {
+		found = append(found, m.Type)
+	}
+	return found
+}
+
+func TestCompare(t *testing.T) {
+	// Test case 1: every side agrees
+	order := testOrder(constant.ORDERVARIANTNOTUSEDSTATUS, constant.RECONCILESTATUSCREATED, "V-1")
+	callbacks := []trip.CallBackDetail{callback(order, "SUCCESS", "2025-03-02T10:00:00Z")}
+	assert.Empty(t, reconcile.Compare(order, callbacks))
+
+	// Test case 2: used with us, still created at yanolja
+	order = testOrder(constant.ORDERVARIANTUSEDSTATUS, constant.RECONCILESTATUSCREATED, "V-1")
+	mismatches := reconcile.Compare(order, nil)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, reconciliation.MismatchStatus, mismatches[0].Type)
+	assert.Equal(t, constant.RECONCILESTATUSUSED, mismatches[0].Ours)
+	assert.Equal(t, constant.RECONCILESTATUSCREATED, mismatches[0].Yanolja)
+
+	// Test case 3: canceled at yanolja only
+	order = testOrder(constant.ORDERVARIANTNOTUSEDSTATUS, constant.RECONCILESTATUSCANCELED, "V-1")
+	assert.Equal(t, []string{reconciliation.MismatchOrphanCancellation}, types(reconcile.Compare(order, nil)))
+
+	// Test case 4: booked without voucher, and trip received an older voucher
+	order = testOrder(constant.ORDERVARIANTNOTUSEDSTATUS, constant.RECONCILESTATUSCREATED, "")
+	assert.Equal(t, []string{reconciliation.MismatchMissingVoucher}, types(reconcile.Compare(order, nil)))
+
+	order = testOrder(constant.ORDERVARIANTNOTUSEDSTATUS, constant.RECONCILESTATUSCREATED, "V-2")
+	sent := testOrder(constant.ORDERVARIANTNOTUSEDSTATUS, constant.RECONCILESTATUSCREATED, "V-1")
+	mismatches = reconcile.Compare(order, []trip.CallBackDetail{callback(sent, "SUCCESS", "2025-03-02T10:00:00Z")})
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, "V-2", mismatches[0].Ours)
+	assert.Equal(t, "V-1", mismatches[0].Trip)
+
+	// Test case 5: trip received another price, the failed later callback does not count
+	order = testOrder(constant.ORDERVARIANTNOTUSEDSTATUS, constant.RECONCILESTATUSCREATED, "V-1")
+	sent = testOrder(constant.ORDERVARIANTNOTUSEDSTATUS, constant.RECONCILESTATUSCREATED, "V-1")
+	sent.SelectVariants[0].PartnerSalePrice = 55
+	callbacks = []trip.CallBackDetail{
+		callback(sent, "SUCCESS", "2025-03-02T10:00:00Z"),
+		callback(order, "FAILED", "2025-03-02T11:00:00Z"),
+	}
+	mismatches = reconcile.Compare(order, callbacks)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, reconciliation.MismatchPriceDrift, mismatches[0].Type)
+	assert.Equal(t, "52.00/40.00", mismatches[0].Ours)
+	assert.Equal(t, "55.00/40.00", mismatches[0].Trip)
+
+	// Test case 6: orders which are not confirmed yet are not flagged for yanolja's unknown status
+	order = testOrder(constant.ORDERVARIANTUNKNOWNSTATUS, constant.RECONCILESTATUSUNKNOWN, "")
+	order.OrderStatusCode = constant.ORDERPREPARE
+	assert.Empty(t, reconcile.Compare(order, nil))
+}
+
+func TestOrphans(t *testing.T) {
+	order := testOrder(constant.ORDERVARIANTCANCELEDSTATUS, constant.RECONCILESTATUSCANCELED, "V-1")
+
+	mismatches := reconcile.Orphans(order.OrderId, []trip.CallBackDetail{callback(order, "SUCCESS", "2025-03-02T10:00:00Z")})
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, reconciliation.MismatchOrphanCancellation, mismatches[0].Type)
+	assert.Equal(t, int64(11), mismatches[0].OrderVariantId)
+
+	assert.Empty(t, reconcile.Orphans(order.OrderId, []trip.CallBackDetail{callback(order, "FAILED", "2025-03-02T10:00:00Z")}))
+}
+
+func TestWriteCSV(t *testing.T) {
+	mismatches := []reconciliation.Mismatch{
+		{Type: reconciliation.MismatchStatus, OrderId: 5001, PartnerOrderId: "TRIP-77", OrderVariantId: 11, Ours: "USED", Yanolja: "CREATED", Detail: "variant status differs, check"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, reconcile.WriteCSV(&buf, "2025-03-02", mismatches))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, strings.Join(reconcile.CSVHeader, ","), lines[0])
+	assert.Equal(t, `2025-03-02,STATUS_MISMATCH,5001,TRIP-77,,11,0,0,USED,CREATED,,"variant status differs, check"`, lines[1])
+
+	counts := reconcile.Count(mismatches)
+	assert.Equal(t, int64(1), counts[reconciliation.MismatchStatus])
+	assert.Equal(t, int64(0), counts[reconciliation.MismatchPriceDrift])
+}
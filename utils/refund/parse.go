@@ -0,0 +1,88 @@
+package refund
+
+import (
+	"regexp"
+	"strconv"
+
+	domain "swallow-supplier/mongo/domain/refund"
+	"swallow-supplier/utils/constant"
+)
+
+var (
+	// 7일 전까지 100% 환불, 3일 전 50% 환불 / 7 days before the visit 100% refund
+	percentPattern = regexp.MustCompile(`(?i)(\d+)\s*(?:일|days?)\s*(?:전|before)[^%\d\n]*?(\d+(?:\.\d+)?)\s*%\s*(?:환불|refund)`)
+	// 7일 전까지 환불 가능, 3일 전까지 전액 취소 가능 / full refund up to 3 days before
+	untilPattern = regexp.MustCompile(`(?i)(?:(\d+)\s*일\s*전\s*까지[^\d%\n]*?(?:취소|환불)\s*가능|(?:full\s+refund|free\s+cancellation)[^\d\n]*?(?:up\s+to|until)\s*(\d+)\s*days?\s*before)`)
+	// 2일 전부터 취소 불가 / no refund within 2 days
+	fromPattern = regexp.MustCompile(`(?i)(?:(\d+)\s*일\s*전\s*부터[^\n.]*?(?:취소|환불)\s*불가|(?:no\s+refunds?|non-refundable)[^\d\n]*?within\s*(\d+)\s*days?)`)
+	// 당일 취소 불가 / no refund on the day of the visit
+	sameDayPattern = regexp.MustCompile(`(?i)(?:당일[^\n.]*?(?:취소|환불)\s*불가|(?:no\s+refunds?|non-refundable)[^\d\n]*?on\s+the\s+(?:same\s+)?day)`)
+	// 취소 불가, 환불 불가 / non-refundable
+	nonePattern = regexp.MustCompile(`(?i)(?:(?:취소|환불)\s*불가|non-refundable|no\s+refunds?)`)
+)
+
+// Parse policy out of the free refund text of a variant or product, ok is false when none of the
+// known phrasings were found
+func Parse(info string) (policy domain.Policy, ok bool) {
+	policy.Source = constant.RefundPolicyParsed
+
+	seen := make(map[int]bool)
+	addTier := func(days int, fee float64) {
+		if seen[days] {
+			return
+		}
+		seen[days] = true
+		policy.Tiers = append(policy.Tiers, domain.Tier{MinDaysBefore: days, FeeType: constant.PERCENTAGE, Fee: fee})
+	}
+
+	for _, match := range percentPattern.FindAllStringSubmatch(info, -1) {
+		days, _ := strconv.Atoi(match[1])
+		refunded, _ := strconv.ParseFloat(match[2], 64)
+		if refunded > 100 {
+			continue
+		}
+		addTier(days, 100-refunded)
+	}
+	for _, match := range untilPattern.FindAllStringSubmatch(info, -1) {
+		days, _ := strconv.Atoi(firstOf(match[1:]))
+		addTier(days, 0)
+	}
+
+	for _, match := range fromPattern.FindAllStringSubmatch(info, -1) {
+		days, _ := strconv.Atoi(match[1])
+		if match[1] == "" {
+			// within n days leaves the n-th day before the visit refundable
+			days, _ = strconv.Atoi(match[2])
+			days--
+		}
+		if days >= 0 {
+			policy.NonRefundableWindows = append(policy.NonRefundableWindows, domain.Window{FromDaysBefore: 0, ToDaysBefore: days})
+		}
+	}
+	if sameDayPattern.MatchString(info) {
+		policy.NonRefundableWindows = append(policy.NonRefundableWindows, domain.Window{FromDaysBefore: 0, ToDaysBefore: 0})
+	}
+
+	switch {
+	case len(policy.Tiers) > 0:
+	case len(policy.NonRefundableWindows) > 0:
+		// refunded in full outside of the windows
+		addTier(0, 0)
+	case nonePattern.MatchString(info):
+		// no tier, nothing is ever refunded
+	default:
+		return policy, false
+	}
+
+	sortTiers(policy.Tiers)
+	return policy, true
+}
+
+func firstOf(values []string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
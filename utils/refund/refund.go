@@ -0,0 +1,208 @@
+package refund
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"swallow-supplier/mongo/domain/money"
+	domain "swallow-supplier/mongo/domain/refund"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/fx"
+)
+
+// Line canceled units of an order variant, Paid is what the channel paid for all of them
+type Line struct {
+	OrderVariantId int64
+	ProductId      int64
+	VariantId      int64
+	Quantity       int
+	VisitDate      string // 2006-01-02
+	Paid           money.Money
+}
+
+// Default policy of a variant without curated or parsed rules, everything is refunded
+func Default(productId, variantId int64) domain.Policy {
+	return domain.Policy{
+		VariantId: variantId,
+		ProductId: productId,
+		Source:    constant.RefundPolicyDefault,
+		Tiers:     []domain.Tier{{MinDaysBefore: 0, FeeType: constant.PERCENTAGE, Fee: 0}},
+	}
+}
+
+// Validate checks the tiers and windows of a policy
+func Validate(policy domain.Policy) error {
+	if policy.VariantId <= 0 {
+		return errors.New("variantId must be positive")
+	}
+
+	for _, tier := range policy.Tiers {
+		if tier.MinDaysBefore < 0 {
+			return errors.New("minDaysBefore must not be negative")
+		}
+		switch tier.FeeType {
+		case constant.PERCENTAGE:
+			if tier.Fee < 0 || tier.Fee > 100 {
+				return errors.New("percentage fee must be between 0 and 100")
+			}
+		case constant.FLATVALUE:
+			if tier.Fee < 0 {
+				return errors.New("flat fee must not be negative")
+			}
+		default:
+			return errors.New("feeType must be one of PERCENTAGE, FLATVALUE")
+		}
+	}
+
+	for _, window := range policy.NonRefundableWindows {
+		if window.FromDaysBefore < 0 || window.FromDaysBefore > window.ToDaysBefore {
+			return errors.New("non refundable windows must run from a non negative fromDaysBefore to a toDaysBefore not below it")
+		}
+	}
+
+	return nil
+}
+
+// DaysBefore calendar days from now to the visit date in the location of now, negative once the
+// visit date passed
+func DaysBefore(visitDate string, now time.Time) (int, error) {
+	visit, err := time.ParseInLocation(time.DateOnly, visitDate, now.Location())
+	if err != nil {
+		return 0, fmt.Errorf("invalid visit date %q: %w", visitDate, err)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	return int(math.Round(visit.Sub(today).Hours() / 24)), nil
+}
+
+// Calculate refund of the line under the policy
+func Calculate(policy domain.Policy, line Line, now time.Time) (domain.QuoteLine, error) {
+	quote := domain.QuoteLine{
+		OrderVariantId: line.OrderVariantId,
+		ProductId:      line.ProductId,
+		VariantId:      line.VariantId,
+		Quantity:       line.Quantity,
+		VisitDate:      line.VisitDate,
+		PolicySource:   policy.Source,
+		Paid:           line.Paid,
+		Fee:            line.Paid,
+		Refund:         money.Money{Currency: line.Paid.Currency},
+	}
+
+	// a line without visit date is an open dated voucher which did not expire, it is quoted under
+	// the earliest tier and no non refundable window applies
+	days := math.MaxInt32
+	if line.VisitDate != "" {
+		var err error
+		days, err = DaysBefore(line.VisitDate, now)
+		if err != nil {
+			return quote, err
+		}
+		quote.DaysBefore = days
+
+		if days < 0 {
+			if !policy.RefundableAfterExpiration {
+				return quote, nil
+			}
+			days = 0
+		}
+
+		for _, window := range policy.NonRefundableWindows {
+			if days >= window.FromDaysBefore && days <= window.ToDaysBefore {
+				return quote, nil
+			}
+		}
+	}
+
+	tier, ok := applicableTier(policy.Tiers, days)
+	if !ok {
+		return quote, nil
+	}
+
+	fee := tier.Fee * float64(line.Quantity)
+	if tier.FeeType == constant.PERCENTAGE {
+		fee = line.Paid.Amount * tier.Fee / 100
+	}
+	fee = math.Min(math.Max(fx.Round(fee, line.Paid.Currency), 0), line.Paid.Amount)
+
+	quote.Fee.Amount = fee
+	quote.Refund.Amount = fx.Round(line.Paid.Amount-fee, line.Paid.Currency)
+	quote.Refundable = quote.Refund.Amount > 0
+
+	return quote, nil
+}
+
+// applicableTier tier with the largest MinDaysBefore not above the days left
+func applicableTier(tiers []domain.Tier, days int) (domain.Tier, bool) {
+	var applicable domain.Tier
+	found := false
+	for _, tier := range tiers {
+		if tier.MinDaysBefore > days {
+			continue
+		}
+		if !found || tier.MinDaysBefore > applicable.MinDaysBefore {
+			applicable, found = tier, true
+		}
+	}
+	return applicable, found
+}
+
+// Quote refund of the lines, each under the policy of its variant or the default one when the
+// variant has none
+func Quote(policies map[int64]domain.Policy, lines []Line, now time.Time) (domain.Quote, error) {
+	quote := domain.Quote{
+		Lines:    make([]domain.QuoteLine, 0, len(lines)),
+		QuotedAt: now.UTC().Format(time.RFC3339),
+	}
+
+	for i, line := range lines {
+		if i == 0 {
+			quote.Paid.Currency = line.Paid.Currency
+			quote.Fee.Currency = line.Paid.Currency
+			quote.Refund.Currency = line.Paid.Currency
+		}
+		if line.Paid.Currency != quote.Paid.Currency {
+			return quote, fmt.Errorf("order variants paid in %s and %s can not be quoted together", quote.Paid.Currency, line.Paid.Currency)
+		}
+
+		policy, ok := policies[line.VariantId]
+		if !ok {
+			policy = Default(line.ProductId, line.VariantId)
+		}
+		quoted, err := Calculate(policy, line, now)
+		if err != nil {
+			return quote, err
+		}
+
+		quote.Lines = append(quote.Lines, quoted)
+		quote.Paid.Amount += quoted.Paid.Amount
+		quote.Fee.Amount += quoted.Fee.Amount
+		quote.Refund.Amount += quoted.Refund.Amount
+	}
+
+	quote.Paid.Amount = fx.Round(quote.Paid.Amount, quote.Paid.Currency)
+	quote.Fee.Amount = fx.Round(quote.Fee.Amount, quote.Fee.Currency)
+	quote.Refund.Amount = fx.Round(quote.Refund.Amount, quote.Refund.Currency)
+	quote.Refundable = quote.Refund.Amount > 0
+
+	return quote, nil
+}
+
+// Refunds refund of each quoted order variant
+func Refunds(quote domain.Quote) map[int64]money.Money {
+	refunds := make(map[int64]money.Money, len(quote.Lines))
+	for _, line := range quote.Lines {
+		if line.OrderVariantId != 0 {
+			refunds[line.OrderVariantId] = line.Refund
+		}
+	}
+	return refunds
+}
+
+// sortTiers orders the tiers by the days before the visit they start at
+func sortTiers(tiers []domain.Tier) {
+	sort.SliceStable(tiers, func(i, j int) bool { return tiers[i].MinDaysBefore > tiers[j].MinDaysBefore })
+}
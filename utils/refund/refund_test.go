@@ -0,0 +1,110 @@
+package refund_test
+
+import (
+	"testing"
+	"time"
+
+	"swallow-supplier/mongo/domain/money"
+	domain "swallow-supplier/mongo/domain/refund"
+	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/refund"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 10, 1, 22, 0, 0, 0, time.UTC)
+
+func line(visitDate string) refund.Line {
+	return refund.Line{OrderVariantId: 11, ProductId: 100, VariantId: 1, Quantity: 2, VisitDate: visitDate, Paid: money.Money{Amount: 20000, Currency: "KRW"}}
+}
+
+func TestCalculate(t *testing.T) {
+	policy := domain.Policy{
+		VariantId: 1,
+		Source:    constant.RefundPolicyCurated,
+		Tiers: []domain.Tier{
+			{MinDaysBefore: 7, FeeType: constant.PERCENTAGE, Fee: 0},
+			{MinDaysBefore: 3, FeeType: constant.PERCENTAGE, Fee: 30},
+			{MinDaysBefore: 1, FeeType: constant.FLATVALUE, Fee: 4000},
+		},
+		NonRefundableWindows: []domain.Window{{FromDaysBefore: 0, ToDaysBefore: 0}},
+	}
+
+	cases := []struct {
+		visit      string
+		refund     float64
+		refundable bool
+	}{
+		{"2026-10-10", 20000, true},
+		{"2026-10-05", 14000, true},
+		{"2026-10-02", 12000, true},
+		{"2026-10-01", 0, false},
+		{"2026-09-30", 0, false},
+	}
+	for _, c := range cases {
+		quoted, err := refund.Calculate(policy, line(c.visit), now)
+		require.NoError(t, err)
+		assert.Equal(t, c.refund, quoted.Refund.Amount, c.visit)
+		assert.Equal(t, 20000-c.refund, quoted.Fee.Amount, c.visit)
+		assert.Equal(t, c.refundable, quoted.Refundable, c.visit)
+	}
+
+	// an expired visit is refunded under the tier of the visit day
+	policy.NonRefundableWindows = nil
+	policy.Tiers = append(policy.Tiers, domain.Tier{MinDaysBefore: 0, FeeType: constant.PERCENTAGE, Fee: 50})
+	policy.RefundableAfterExpiration = true
+	quoted, err := refund.Calculate(policy, line("2026-09-30"), now)
+	require.NoError(t, err)
+	assert.Equal(t, -1, quoted.DaysBefore)
+	assert.Equal(t, float64(10000), quoted.Refund.Amount)
+
+	// an open dated voucher is not expired, it is refunded under the earliest tier
+	policy.RefundableAfterExpiration = false
+	quoted, err = refund.Calculate(policy, line(""), now)
+	require.NoError(t, err)
+	assert.Equal(t, float64(20000), quoted.Refund.Amount)
+	assert.True(t, quoted.Refundable)
+}
+
+func TestQuote(t *testing.T) {
+	parsed, ok := refund.Parse("이용일 7일 전까지 100% 환불, 3일 전까지 50% 환불. 당일 취소 불가")
+	require.True(t, ok)
+	parsed.VariantId = 1
+
+	other := line("2026-10-05")
+	other.OrderVariantId, other.VariantId = 12, 2
+
+	quote, err := refund.Quote(map[int64]domain.Policy{1: parsed}, []refund.Line{line("2026-10-05"), other}, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Money{Amount: 40000, Currency: "KRW"}, quote.Paid)
+	assert.Equal(t, money.Money{Amount: 30000, Currency: "KRW"}, quote.Refund)
+	assert.True(t, quote.Refundable)
+	assert.Equal(t, constant.RefundPolicyDefault, quote.Lines[1].PolicySource)
+	assert.Equal(t, map[int64]money.Money{
+		11: {Amount: 10000, Currency: "KRW"},
+		12: {Amount: 20000, Currency: "KRW"},
+	}, refund.Refunds(quote))
+
+	other.Paid.Currency = "USD"
+	_, err = refund.Quote(nil, []refund.Line{line("2026-10-05"), other}, now)
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	policy, ok := refund.Parse("7일 전까지 환불 가능")
+	require.True(t, ok)
+	assert.Equal(t, []domain.Tier{{MinDaysBefore: 7, FeeType: constant.PERCENTAGE, Fee: 0}}, policy.Tiers)
+
+	policy, ok = refund.Parse("Full refund up to 3 days before the visit. No refunds within 2 days.")
+	require.True(t, ok)
+	assert.Equal(t, []domain.Tier{{MinDaysBefore: 3, FeeType: constant.PERCENTAGE, Fee: 0}}, policy.Tiers)
+	assert.Equal(t, []domain.Window{{FromDaysBefore: 0, ToDaysBefore: 1}}, policy.NonRefundableWindows)
+
+	policy, ok = refund.Parse("구매 후 취소 불가 상품입니다")
+	require.True(t, ok)
+	assert.Empty(t, policy.Tiers)
+
+	_, ok = refund.Parse("유효기간 내 사용")
+	assert.False(t, ok)
+}
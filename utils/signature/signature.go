@@ -0,0 +1,63 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"swallow-supplier/utils/secure"
+)
+
+// headers of a signed callback, the signature is the hex HMAC-SHA256 of "<timestamp>.<raw body>"
+const (
+	HeaderSignature = "X-Signature"
+	HeaderTimestamp = "X-Signature-Timestamp"
+)
+
+var (
+	ErrMissingSecret    = errors.New("no signing secret configured")
+	ErrMissingSignature = errors.New("signature or timestamp header missing")
+	ErrInvalidTimestamp = errors.New("invalid signature timestamp")
+	ErrStaleTimestamp   = errors.New("signature timestamp outside the allowed window")
+	ErrInvalidSignature = errors.New("signature mismatch")
+)
+
+// Sign signature of the raw body sent at timestamp (unix seconds)
+func Sign(secret, timestamp string, body []byte) string {
+	payload := make([]byte, 0, len(timestamp)+1+len(body))
+	payload = append(payload, timestamp...)
+	payload = append(payload, '.')
+	payload = append(payload, body...)
+
+	return hex.EncodeToString(secure.HMAC(payload, []byte(secret)))
+}
+
+// Verify checks the signature of the raw body, timestamps further than tolerance from now
+// are rejected so a captured callback can not be replayed later. A "sha256=" prefix is accepted
+func Verify(secret, timestamp, sig string, body []byte, now time.Time, tolerance time.Duration) error {
+	if secret == "" {
+		return ErrMissingSecret
+	}
+	if timestamp == "" || sig == "" {
+		return ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	skew := now.Sub(time.Unix(unix, 0))
+	if skew > tolerance || skew < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	sig = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(sig)), "sha256=")
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(sig)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package signature_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"swallow-supplier/utils/signature"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"orderId":1,"partnerOrderId":"GGT-1"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := signature.Sign("secret", ts, body)
+
+	assert.NoError(t, signature.Verify("secret", ts, sig, body, now, 5*time.Minute))
+	assert.NoError(t, signature.Verify("secret", ts, "sha256="+sig, body, now.Add(4*time.Minute), 5*time.Minute))
+
+	assert.ErrorIs(t, signature.Verify("", ts, sig, body, now, 5*time.Minute), signature.ErrMissingSecret)
+	assert.ErrorIs(t, signature.Verify("secret", "", sig, body, now, 5*time.Minute), signature.ErrMissingSignature)
+	assert.ErrorIs(t, signature.Verify("secret", "yesterday", sig, body, now, 5*time.Minute), signature.ErrInvalidTimestamp)
+	assert.ErrorIs(t, signature.Verify("secret", ts, sig, body, now.Add(6*time.Minute), 5*time.Minute), signature.ErrStaleTimestamp)
+	assert.ErrorIs(t, signature.Verify("secret", ts, sig, body, now.Add(-6*time.Minute), 5*time.Minute), signature.ErrStaleTimestamp)
+	assert.ErrorIs(t, signature.Verify("other", ts, sig, body, now, 5*time.Minute), signature.ErrInvalidSignature)
+	assert.ErrorIs(t, signature.Verify("secret", ts, sig, []byte(`{"orderId":2,"partnerOrderId":"GGT-1"}`), now, 5*time.Minute), signature.ErrInvalidSignature)
+}
@@ -0,0 +1,269 @@
+package translate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	babel "swallow-supplier/Babel"
+	"swallow-supplier/mongo/domain/translation"
+	model "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/constant"
+)
+
+// Client translates texts, babel.Babel in the service
+type Client interface {
+	TranslateText(ctx context.Context, req babel.BabelTextRequest) (babel.BabelResponse, error)
+}
+
+// Memory stored translations keyed by source hash, the mongo repository in the service
+type Memory interface {
+	GetTranslations(ctx context.Context, targetLang string, sourceHashes []string) (map[string]string, error)
+	InsertTranslations(ctx context.Context, memories []translation.Memory) error
+}
+
+// Field translatable text of a product. Path names the field by the ids of the elements
+// holding it, so it stays the same when yanolja reorders them
+type Field struct {
+	Path string
+	Text string
+}
+
+// Stats how the texts of one language were translated
+type Stats struct {
+	Fields     int `json:"fields"`
+	Cached     int `json:"cached"`
+	Translated int `json:"translated"`
+}
+
+// target field of the product and the text it holds
+type target struct {
+	path string
+	text *string
+}
+
+// key element of a list in a path, by id and by position when it has none
+func key(id int64, index int) string {
+	if id == 0 {
+		return fmt.Sprintf("#%d", index)
+	}
+	return fmt.Sprintf("%d", id)
+}
+
+// targets translatable fields of the product: names, descriptions, product and facility
+// information and refund information
+func targets(p *model.Product) []target {
+	t := []target{
+		{"productName", &p.ProductName},
+		{"productBriefIntroduction", &p.ProductBriefIntroduction},
+		{"productInfo.productBasicInfo", &p.ProductInfo.ProductBasicInfo},
+		{"productInfo.productUsageInfo", &p.ProductInfo.ProductUsageInfo},
+		{"productInfo.noticeInfo", &p.ProductInfo.NoticeInfo},
+		{"productInfo.serviceCenterInfo", &p.ProductInfo.ServiceCenterInfo},
+		{"productInfo.refundInfo", &p.ProductInfo.RefundInfo},
+		{"productInfo.voucherUsageInfo", &p.ProductInfo.VoucherUsageInfo},
+	}
+
+	for i := range p.ProductInfo.FacilityInfos {
+		f := &p.ProductInfo.FacilityInfos[i]
+		prefix := "productInfo.facilityInfos[" + key(f.FacilityID, i) + "]."
+		t = append(t,
+			target{prefix + "facilityName", &f.FacilityName},
+			target{prefix + "facilityDetailInfo", &f.FacilityDetailInfo},
+			target{prefix + "noticeInfo", &f.NoticeInfo},
+			target{prefix + "refundInfo", &f.RefundInfo},
+			target{prefix + "voucherUsageInfo", &f.VoucherUsageInfo},
+		)
+	}
+
+	for i := range p.ProductOptionGroups {
+		g := &p.ProductOptionGroups[i]
+		group := "productOptionGroups[" + key(g.ProductOptionGroupID, i) + "]."
+		t = append(t,
+			target{group + "productOptionGroupname", &g.ProductOptionGroupName},
+			target{group + "productOptionGroupDescription", &g.ProductOptionGroupDescription},
+		)
+
+		for j := range g.ProductOptions {
+			o := &g.ProductOptions[j]
+			option := group + "productOptions[" + key(o.ProductOptionID, j) + "]."
+			t = append(t, target{option + "productOptionName", &o.ProductOptionName})
+			t = append(t, itemTargets(option, o.ProductOptionItems)...)
+		}
+
+		for j := range g.Variants {
+			v := &g.Variants[j]
+			variant := group + "variants[" + key(v.VariantID, j) + "]."
+			t = append(t,
+				target{variant + "variantName", &v.VariantName},
+				target{variant + "variantDescription", &v.VariantDescription},
+				target{variant + "refundInfo", &v.RefundInfo},
+			)
+			t = append(t, itemTargets(variant, v.ProductOptionItems)...)
+		}
+	}
+
+	return t
+}
+
+// itemTargets names of the option items under the prefix
+func itemTargets(prefix string, items []model.ProductOptionItem) []target {
+	t := make([]target, 0, len(items))
+	for k := range items {
+		item := &items[k]
+		t = append(t, target{prefix + "productOptionItems[" + key(item.ProductOptionItemID, k) + "].productOptionItemName", &item.ProductOptionItemName})
+	}
+	return t
+}
+
+// Extract translatable fields of the product which hold text, in a stable order
+func Extract(product model.Product) []Field {
+	fields := make([]Field, 0)
+	for _, t := range targets(&product) {
+		if strings.TrimSpace(*t.text) == "" {
+			continue
+		}
+		fields = append(fields, Field{Path: t.path, Text: *t.text})
+	}
+	return fields
+}
+
+// Merge copy of the product with the texts set on the fields of their paths, fields without a
+// text keep their value
+func Merge(product model.Product, texts map[string]string) (model.Product, error) {
+	merged, err := clone(product)
+	if err != nil {
+		return product, err
+	}
+	for _, t := range targets(&merged) {
+		if text, ok := texts[t.path]; ok {
+			*t.text = text
+		}
+	}
+	return merged, nil
+}
+
+// clone deep copy of the product, Merge must not write through the slices of the original
+func clone(product model.Product) (model.Product, error) {
+	var copied model.Product
+	raw, err := json.Marshal(product)
+	if err != nil {
+		return copied, fmt.Errorf("error copying product: %w", err)
+	}
+	if err = json.Unmarshal(raw, &copied); err != nil {
+		return copied, fmt.Errorf("error copying product: %w", err)
+	}
+	return copied, nil
+}
+
+// Hash key of the source text in the translation memory
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Product translations of the product into each language keyed by language. Texts found in the
+// memory are not sent to the client, new translations are stored in it. A text repeated in
+// several fields is translated once
+func Product(ctx context.Context, client Client, memory Memory, product model.Product, targetLangs []string) (map[string]model.Product, map[string]Stats, error) {
+	fields := Extract(product)
+
+	sources := make(map[string]string, len(fields))
+	hashes := make([]string, 0, len(fields))
+	for _, field := range fields {
+		hash := Hash(field.Text)
+		if _, ok := sources[hash]; !ok {
+			sources[hash] = field.Text
+			hashes = append(hashes, hash)
+		}
+	}
+
+	products := make(map[string]model.Product, len(targetLangs))
+	stats := make(map[string]Stats, len(targetLangs))
+	for _, lang := range targetLangs {
+		translated, stat, err := texts(ctx, client, memory, lang, hashes, sources)
+		if err != nil {
+			return nil, nil, fmt.Errorf("translating product %d into %s: %w", product.ProductID, lang, err)
+		}
+		stat.Fields = len(fields)
+
+		byPath := make(map[string]string, len(fields))
+		for _, field := range fields {
+			byPath[field.Path] = translated[Hash(field.Text)]
+		}
+		if products[lang], err = Merge(product, byPath); err != nil {
+			return nil, nil, err
+		}
+		stats[lang] = stat
+	}
+
+	return products, stats, nil
+}
+
+// texts translations of the sources into the language keyed by source hash
+func texts(ctx context.Context, client Client, memory Memory, lang string, hashes []string, sources map[string]string) (map[string]string, Stats, error) {
+	var stat Stats
+	if len(hashes) == 0 {
+		return map[string]string{}, stat, nil
+	}
+
+	translated, err := memory.GetTranslations(ctx, lang, hashes)
+	if err != nil {
+		return nil, stat, fmt.Errorf("reading translation memory: %w", err)
+	}
+	if translated == nil {
+		translated = make(map[string]string, len(hashes))
+	}
+	stat.Cached = len(translated)
+
+	missing := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		if _, ok := translated[hash]; !ok {
+			missing = append(missing, hash)
+		}
+	}
+
+	for start := 0; start < len(missing); start += constant.TranslationBatchSize {
+		batch := missing[start:min(start+constant.TranslationBatchSize, len(missing))]
+
+		req := babel.BabelTextRequest{Text: make([]string, 0, len(batch)), TargetLang: lang}
+		for _, hash := range batch {
+			req.Text = append(req.Text, sources[hash])
+		}
+		resp, err := client.TranslateText(ctx, req)
+		if err != nil {
+			return nil, stat, err
+		}
+		results, err := resp.Texts()
+		if err != nil {
+			return nil, stat, err
+		}
+		if len(results) != len(batch) {
+			return nil, stat, fmt.Errorf("babel returned %d translations for %d texts", len(results), len(batch))
+		}
+
+		memories := make([]translation.Memory, 0, len(batch))
+		createdAt := time.Now().UTC().Format(time.RFC3339)
+		for i, hash := range batch {
+			translated[hash] = results[i]
+			memories = append(memories, translation.Memory{
+				Id:         translation.MemoryId(hash, lang),
+				SourceHash: hash,
+				TargetLang: lang,
+				Source:     sources[hash],
+				Text:       results[i],
+				CreatedAt:  createdAt,
+			})
+		}
+		if err = memory.InsertTranslations(ctx, memories); err != nil {
+			return nil, stat, fmt.Errorf("storing translation memory: %w", err)
+		}
+		stat.Translated += len(batch)
+	}
+
+	return translated, stat, nil
+}
@@ -0,0 +1,150 @@
+package translate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	babel "swallow-supplier/Babel"
+	"swallow-supplier/mongo/domain/translation"
+	model "swallow-supplier/mongo/domain/yanolja"
+	"swallow-supplier/utils/translate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient translates by prefixing the target language, drop removes the last translation
+type fakeClient struct {
+	requests []babel.BabelTextRequest
+	drop     bool
+	err      error
+}
+
+func (c *fakeClient) TranslateText(ctx context.Context, req babel.BabelTextRequest) (babel.BabelResponse, error) {
+	c.requests = append(c.requests, req)
+	if c.err != nil {
+		return babel.BabelResponse{}, c.err
+	}
+
+	translations := make([]babel.BabelTranslation, 0, len(req.Text))
+	for _, text := range req.Text {
+		translations = append(translations, babel.BabelTranslation{DetectedSourceLanguage: "KO", Text: req.TargetLang + ":" + text})
+	}
+	if c.drop {
+		translations = translations[:len(translations)-1]
+	}
+	body, _ := json.Marshal(map[string]any{"translations": translations})
+	return babel.BabelResponse{Translations: []string{string(body)}, Code: "200"}, nil
+}
+
+type fakeMemory map[string]translation.Memory
+
+func (m fakeMemory) GetTranslations(ctx context.Context, targetLang string, sourceHashes []string) (map[string]string, error) {
+	found := make(map[string]string)
+	for _, hash := range sourceHashes {
+		if memory, ok := m[translation.MemoryId(hash, targetLang)]; ok {
+			found[hash] = memory.Text
+		}
+	}
+	return found, nil
+}
+
+func (m fakeMemory) InsertTranslations(ctx context.Context, memories []translation.Memory) error {
+	for _, memory := range memories {
+		m[memory.Id] = memory
+	}
+	return nil
+}
+
+func testProduct() model.Product {
+	return model.Product{
+		ProductID:   1001,
+		ProductName: "에버랜드",
+		ProductInfo: model.ProductInfo{
+			ProductBasicInfo: "기본 정보",
+			RefundInfo:       "환불 불가",
+		},
+		ProductOptionGroups: []model.ProductOptionGroup{{
+			ProductOptionGroupID:   7,
+			ProductOptionGroupName: "입장권",
+			ProductOptions: []model.ProductOption{{
+				ProductOptionID:    70,
+				ProductOptionName:  "권종",
+				ProductOptionItems: []model.ProductOptionItem{{ProductOptionItemID: 700, ProductOptionItemName: "성인"}},
+			}},
+			Variants: []model.Variant{{
+				VariantID:          9,
+				VariantName:        "입장권",
+				RefundInfo:         "환불 불가",
+				ProductOptionItems: []model.ProductOptionItem{{ProductOptionItemID: 700, ProductOptionItemName: "성인"}},
+			}},
+		}},
+	}
+}
+
+func TestExtract(t *testing.T) {
+	paths := make([]string, 0)
+	for _, field := range translate.Extract(testProduct()) {
+		paths = append(paths, field.Path)
+	}
+
+	assert.Equal(t, []string{
+		"productName",
+		"productInfo.productBasicInfo",
+		"productInfo.refundInfo",
+		"productOptionGroups[7].productOptionGroupname",
+		"productOptionGroups[7].productOptions[70].productOptionName",
+		"productOptionGroups[7].productOptions[70].productOptionItems[700].productOptionItemName",
+		"productOptionGroups[7].variants[9].variantName",
+		"productOptionGroups[7].variants[9].refundInfo",
+		"productOptionGroups[7].variants[9].productOptionItems[700].productOptionItemName",
+	}, paths)
+}
+
+func TestProduct(t *testing.T) {
+	client := &fakeClient{}
+	memory := fakeMemory{}
+	product := testProduct()
+
+	// Test case 1: every distinct text is sent once per language and merged back
+	products, stats, err := translate.Product(context.Background(), client, memory, product, []string{"EN-US", "JA"})
+	require.NoError(t, err)
+	require.Len(t, client.requests, 2)
+	assert.Len(t, client.requests[0].Text, 6)
+	assert.Equal(t, translate.Stats{Fields: 9, Cached: 0, Translated: 6}, stats["EN-US"])
+
+	english := products["EN-US"]
+	assert.Equal(t, "EN-US:에버랜드", english.ProductName)
+	assert.Equal(t, "EN-US:환불 불가", english.ProductOptionGroups[0].Variants[0].RefundInfo)
+	assert.Equal(t, "JA:성인", products["JA"].ProductOptionGroups[0].ProductOptions[0].ProductOptionItems[0].ProductOptionItemName)
+	assert.Equal(t, int64(1001), english.ProductID)
+
+	// the original keeps its texts
+	assert.Equal(t, "성인", product.ProductOptionGroups[0].Variants[0].ProductOptionItems[0].ProductOptionItemName)
+
+	// Test case 2: unchanged texts come from the memory, only the new one is sent
+	product.ProductInfo.RefundInfo = "7일 전까지 환불 가능"
+	client.requests = nil
+	products, stats, err = translate.Product(context.Background(), client, memory, product, []string{"EN-US"})
+	require.NoError(t, err)
+	require.Len(t, client.requests, 1)
+	assert.Equal(t, []string{"7일 전까지 환불 가능"}, client.requests[0].Text)
+	assert.Equal(t, translate.Stats{Fields: 9, Cached: 6, Translated: 1}, stats["EN-US"])
+	assert.Equal(t, "EN-US:7일 전까지 환불 가능", products["EN-US"].ProductInfo.RefundInfo)
+}
+
+func TestProductErrors(t *testing.T) {
+	// Test case 1: a missing translation fails the product and stores nothing
+	memory := fakeMemory{}
+	_, _, err := translate.Product(context.Background(), &fakeClient{drop: true}, memory, testProduct(), []string{"EN-US"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "returned 5 translations for 6 texts")
+	assert.Empty(t, memory)
+
+	// Test case 2: client errors are returned
+	failure := errors.New("babel is down")
+	_, _, err = translate.Product(context.Background(), &fakeClient{err: failure}, memory, testProduct(), []string{"EN-US"})
+	assert.ErrorIs(t, err, failure)
+}
@@ -11,7 +11,6 @@ import (
 	_ "image/jpeg" // JPEG image support
 	_ "image/png"  // PNG image support
 	"io"
-	"math"
 	"math/big"
 	"math/rand"
 	"net/http"
@@ -23,8 +22,9 @@ import (
 	"github.com/google/uuid"
 
 	"swallow-supplier/config"
+	pricingDomain "swallow-supplier/mongo/domain/pricing"
 	"swallow-supplier/utils/array"
-	"swallow-supplier/utils/constant"
+	"swallow-supplier/utils/pricing"
 	"swallow-supplier/utils/secure"
 )
 
@@ -449,19 +449,7 @@ func toBase62(num *big.Int) string {
 	return string(result)
 }
 
-func RemoveMargineFromProductCostPrice(productId int64, cost float64) (costPrice float32) {
-	for _, margine := range constant.MARGINEDETAIL {
-		if margine.ProductId == productId {
-			switch margine.MargineType {
-			case "FLATVALUE":
-				costPrice = float32(cost - float64(margine.Value))
-			case "PERCENTAGE":
-				mergineval := float64(1 + margine.Value/100)
-				costPrice = float32(math.Floor(cost / mergineval))
-			}
-			break
-		}
-	}
-
-	return costPrice
+// RemoveMargineFromProductCostPrice supplier cost of a unit sold at cost with the markup of the rule included
+func RemoveMargineFromProductCostPrice(rule pricingDomain.Rule, cost float64) (costPrice float32) {
+	return float32(pricing.RemoveMarkup(rule, cost))
 }